@@ -0,0 +1,190 @@
+// Package charset transcodes text data between character encodings.
+// dataset data is always utf-8 once read, Structure.Encoding names the
+// encoding of stored data, assuming utf-8 if not specified
+package charset
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/htmlindex"
+	"golang.org/x/text/encoding/unicode"
+	"golang.org/x/text/transform"
+)
+
+const (
+	// UTF8 is the name of the default utf-8 encoding
+	UTF8 = "utf-8"
+	// UTF16LE is the name of the little-endian utf-16 encoding
+	UTF16LE = "utf-16le"
+	// UTF16BE is the name of the big-endian utf-16 encoding
+	UTF16BE = "utf-16be"
+	// Windows1252 is the name of the windows-1252 encoding, a superset of
+	// latin-1 common in files exported on windows
+	Windows1252 = "windows-1252"
+)
+
+// overrides are encodings that differ from the html standard, which maps
+// latin-1 to windows-1252 & ignores byte order marks for utf-16
+var overrides = map[string]struct {
+	name string
+	enc  encoding.Encoding
+}{
+	"iso-8859-1": {"iso-8859-1", charmap.ISO8859_1},
+	"iso8859-1":  {"iso-8859-1", charmap.ISO8859_1},
+	"latin1":     {"iso-8859-1", charmap.ISO8859_1},
+	"latin-1":    {"iso-8859-1", charmap.ISO8859_1},
+	"l1":         {"iso-8859-1", charmap.ISO8859_1},
+	"utf-16":     {"utf-16", unicode.UTF16(unicode.LittleEndian, unicode.UseBOM)},
+	"utf16":      {"utf-16", unicode.UTF16(unicode.LittleEndian, unicode.UseBOM)},
+}
+
+// Lookup finds an encoding by name, returning it's canonical name.
+// names are case insensitive, the empty string is utf-8
+func Lookup(name string) (string, encoding.Encoding, error) {
+	key := strings.ToLower(strings.TrimSpace(name))
+	if key == "" || key == "utf8" {
+		key = UTF8
+	}
+	if o, ok := overrides[key]; ok {
+		return o.name, o.enc, nil
+	}
+
+	enc, err := htmlindex.Get(key)
+	if err != nil {
+		return "", nil, fmt.Errorf("unsupported encoding: '%s'", name)
+	}
+	canonical, err := htmlindex.Name(enc)
+	if err != nil {
+		canonical = key
+	}
+	return canonical, enc, nil
+}
+
+// IsUTF8 reports weather name specifies utf-8 encoding
+func IsUTF8(name string) bool {
+	canonical, _, err := Lookup(name)
+	return err == nil && canonical == UTF8
+}
+
+// NewReader wraps r in a reader that transcodes data in the named encoding
+// to utf-8. byte order marks are removed, and take precedence over name.
+// utf-8 data is otherwise passed through unmodified
+func NewReader(name string, r io.Reader) (io.Reader, error) {
+	canonical, enc, err := Lookup(name)
+	if err != nil {
+		return nil, err
+	}
+	var dec transform.Transformer = enc.NewDecoder()
+	if canonical == UTF8 {
+		dec = transform.Nop
+	}
+	return transform.NewReader(r, unicode.BOMOverride(dec)), nil
+}
+
+// NewWriter wraps w in a writer that transcodes utf-8 data to the named
+// encoding. the returned writer must be closed to flush buffered data,
+// closing does not close w
+func NewWriter(name string, w io.Writer) (io.WriteCloser, error) {
+	canonical, enc, err := Lookup(name)
+	if err != nil {
+		return nil, err
+	}
+	if canonical == UTF8 {
+		return nopCloser{w}, nil
+	}
+	return transform.NewWriter(w, encoding.ReplaceUnsupported(enc.NewEncoder())), nil
+}
+
+type nopCloser struct {
+	io.Writer
+}
+
+func (nopCloser) Close() error { return nil }
+
+// byte order marks that start utf-8 & utf-16 encoded text
+var boms = []struct {
+	name string
+	bom  []byte
+}{
+	{UTF8, []byte{0xef, 0xbb, 0xbf}},
+	{UTF16LE, []byte{0xff, 0xfe}},
+	{UTF16BE, []byte{0xfe, 0xff}},
+}
+
+// SniffBOM checks the start of a stream for a byte order mark, returning
+// the name of the encoding it marks, or the empty string if none is found
+func SniffBOM(header []byte) string {
+	for _, b := range boms {
+		if bytes.HasPrefix(header, b.bom) {
+			return b.name
+		}
+	}
+	return ""
+}
+
+// sniffLen is the number of bytes examined to guess an encoding
+const sniffLen = 4096
+
+// SniffType guesses the encoding of a sample of text. byte order marks are
+// definitive, otherwise text with alternating null bytes is utf-16, valid
+// utf-8 is utf-8 and anything else is assumed to be windows-1252
+func SniffType(sample []byte) string {
+	if name := SniffBOM(sample); name != "" {
+		return name
+	}
+
+	// ascii characters in utf-16 text have a null high byte
+	var even, odd int
+	for i, b := range sample {
+		if b == 0 {
+			if i%2 == 0 {
+				even++
+			} else {
+				odd++
+			}
+		}
+	}
+	if quarter := len(sample) / 4; odd > quarter && even < odd/4 {
+		return UTF16LE
+	} else if even > quarter && odd < even/4 {
+		return UTF16BE
+	}
+
+	if utf8.Valid(trimPartialRune(sample)) {
+		return UTF8
+	}
+	return Windows1252
+}
+
+// trimPartialRune drops an incomplete utf-8 sequence cut off at the end
+// of a sample
+func trimPartialRune(sample []byte) []byte {
+	for i := 1; i < utf8.UTFMax && i <= len(sample); i++ {
+		if utf8.RuneStart(sample[len(sample)-i]) {
+			if !utf8.FullRune(sample[len(sample)-i:]) {
+				return sample[:len(sample)-i]
+			}
+			break
+		}
+	}
+	return sample
+}
+
+// Sniff peeks at the start of a reader to guess it's encoding.
+// the returned reader must be used in place of r, and yields all bytes
+// read from r, including those used to sniff the encoding
+func Sniff(r io.Reader) (string, io.Reader, error) {
+	br := bufio.NewReaderSize(r, sniffLen)
+	sample, err := br.Peek(sniffLen)
+	if err != nil && err != io.EOF && err != bufio.ErrBufferFull {
+		return "", br, err
+	}
+	return SniffType(sample), br, nil
+}
@@ -0,0 +1,137 @@
+package charset
+
+import (
+	"bytes"
+	"io/ioutil"
+	"testing"
+)
+
+func TestLookup(t *testing.T) {
+	cases := []struct {
+		name, expect string
+		err          string
+	}{
+		{"", "utf-8", ""},
+		{"UTF8", "utf-8", ""},
+		{"utf-8", "utf-8", ""},
+		{"latin1", "iso-8859-1", ""},
+		{"ISO-8859-1", "iso-8859-1", ""},
+		{"cp1252", "windows-1252", ""},
+		{"utf-16", "utf-16", ""},
+		{"utf-16le", "utf-16le", ""},
+		{"shift_jis", "shift_jis", ""},
+		{"klingon", "", "unsupported encoding: 'klingon'"},
+	}
+
+	for i, c := range cases {
+		got, _, err := Lookup(c.name)
+		if !(err == nil && c.err == "" || err != nil && err.Error() == c.err) {
+			t.Errorf("case %d error mismatch. expected: '%s', got: '%s'", i, c.err, err)
+			continue
+		}
+		if got != c.expect {
+			t.Errorf("case %d name mismatch. expected: '%s', got: '%s'", i, c.expect, got)
+		}
+	}
+}
+
+func TestSniffType(t *testing.T) {
+	cases := []struct {
+		sample []byte
+		expect string
+	}{
+		{[]byte("city,pop\ntoronto,40000000\n"), UTF8},
+		{[]byte("\xef\xbb\xbfcity,pop\n"), UTF8},
+		{[]byte("caf\xc3\xa9"), UTF8},
+		// utf-8 sequence cut off at the end of the sample
+		{[]byte("caf\xc3"), UTF8},
+		{[]byte("caf\xe9,montr\xe9al\n"), Windows1252},
+		{[]byte("\xff\xfec\x00i\x00"), UTF16LE},
+		{[]byte("\xfe\xff\x00c\x00i"), UTF16BE},
+		{[]byte("c\x00i\x00t\x00y\x00"), UTF16LE},
+		{[]byte("\x00c\x00i\x00t\x00y"), UTF16BE},
+	}
+
+	for i, c := range cases {
+		if got := SniffType(c.sample); got != c.expect {
+			t.Errorf("case %d mismatch. expected: '%s', got: '%s'", i, c.expect, got)
+		}
+	}
+}
+
+func TestReadWrite(t *testing.T) {
+	data := []byte("name,city\nCafé Zoë,Montréal\n")
+
+	cases := []struct {
+		name    string
+		encoded []byte
+	}{
+		{"", data},
+		{"utf-8", data},
+		{"latin1", []byte("name,city\nCaf\xe9 Zo\xeb,Montr\xe9al\n")},
+		{"windows-1252", []byte("name,city\nCaf\xe9 Zo\xeb,Montr\xe9al\n")},
+	}
+
+	for i, c := range cases {
+		buf := &bytes.Buffer{}
+		w, err := NewWriter(c.name, buf)
+		if err != nil {
+			t.Errorf("case %d error creating writer: %s", i, err.Error())
+			continue
+		}
+		if _, err := w.Write(data); err != nil {
+			t.Errorf("case %d write error: %s", i, err.Error())
+			continue
+		}
+		if err := w.Close(); err != nil {
+			t.Errorf("case %d close error: %s", i, err.Error())
+			continue
+		}
+		if !bytes.Equal(c.encoded, buf.Bytes()) {
+			t.Errorf("case %d encoded mismatch. expected: %q, got: %q", i, c.encoded, buf.Bytes())
+		}
+
+		r, err := NewReader(c.name, buf)
+		if err != nil {
+			t.Errorf("case %d error creating reader: %s", i, err.Error())
+			continue
+		}
+		got, err := ioutil.ReadAll(r)
+		if err != nil {
+			t.Errorf("case %d read error: %s", i, err.Error())
+			continue
+		}
+		if !bytes.Equal(data, got) {
+			t.Errorf("case %d data mismatch. expected: %q, got: %q", i, data, got)
+		}
+	}
+}
+
+func TestReaderBOM(t *testing.T) {
+	cases := []struct {
+		name  string
+		input []byte
+	}{
+		{"", []byte("\xef\xbb\xbfcaf\xc3\xa9")},
+		{"utf-16", []byte("\xff\xfec\x00a\x00f\x00\xe9\x00")},
+		{"utf-16", []byte("\xfe\xff\x00c\x00a\x00f\x00\xe9")},
+		// byte order marks take precedence over the named encoding
+		{"windows-1252", []byte("\xff\xfec\x00a\x00f\x00\xe9\x00")},
+	}
+
+	for i, c := range cases {
+		r, err := NewReader(c.name, bytes.NewReader(c.input))
+		if err != nil {
+			t.Errorf("case %d error creating reader: %s", i, err.Error())
+			continue
+		}
+		got, err := ioutil.ReadAll(r)
+		if err != nil {
+			t.Errorf("case %d read error: %s", i, err.Error())
+			continue
+		}
+		if string(got) != "café" {
+			t.Errorf("case %d mismatch. expected: 'café', got: %q", i, got)
+		}
+	}
+}
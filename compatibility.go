@@ -0,0 +1,592 @@
+package dataset
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+
+	"github.com/qri-io/dataset/datatypes"
+)
+
+// Compatibility describes which readers can read data after a structure
+// changes. Backward compatible changes let readers of the new structure read
+// data written with the old structure, forward compatible changes let
+// readers of the old structure read data written with the new structure
+type Compatibility int
+
+const (
+	// Breaking changes are neither backward nor forward compatible
+	Breaking Compatibility = 0
+	// BackwardCompatible changes can read data written before the change
+	BackwardCompatible Compatibility = 1
+	// ForwardCompatible changes can be read by readers from before the change
+	ForwardCompatible Compatibility = 2
+	// FullyCompatible changes are both backward & forward compatible
+	FullyCompatible = BackwardCompatible | ForwardCompatible
+)
+
+// String gives a lowercase name for a Compatibility
+func (c Compatibility) String() string {
+	switch c {
+	case BackwardCompatible:
+		return "backward"
+	case ForwardCompatible:
+		return "forward"
+	case FullyCompatible:
+		return "full"
+	}
+	return "breaking"
+}
+
+// Backward reports weather c is backward compatible
+func (c Compatibility) Backward() bool {
+	return c&BackwardCompatible != 0
+}
+
+// Forward reports weather c is forward compatible
+func (c Compatibility) Forward() bool {
+	return c&ForwardCompatible != 0
+}
+
+// MarshalJSON implements json.Marshaler on Compatibility
+func (c Compatibility) MarshalJSON() ([]byte, error) {
+	return []byte(strconv.Quote(c.String())), nil
+}
+
+// UnmarshalJSON implements json.Unmarshaler on Compatibility
+func (c *Compatibility) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("compatibility should be a string, got %s", data)
+	}
+	switch s {
+	case "breaking":
+		*c = Breaking
+	case "backward":
+		*c = BackwardCompatible
+	case "forward":
+		*c = ForwardCompatible
+	case "full":
+		*c = FullyCompatible
+	default:
+		return fmt.Errorf("unknown compatibility '%s'", s)
+	}
+	return nil
+}
+
+// ChangeKind classifies a difference between two structures
+type ChangeKind string
+
+const (
+	// ChangeFormat is a change of data format
+	ChangeFormat ChangeKind = "format"
+	// ChangeFormatConfig is a change to a format configuration option
+	ChangeFormatConfig ChangeKind = "formatConfig"
+	// ChangeEncoding is a change of character encoding
+	ChangeEncoding ChangeKind = "encoding"
+	// ChangeCompression is a change of compression
+	ChangeCompression ChangeKind = "compression"
+	// ChangeFieldAdded is a field in the new structure only
+	ChangeFieldAdded ChangeKind = "fieldAdded"
+	// ChangeFieldRemoved is a field in the old structure only
+	ChangeFieldRemoved ChangeKind = "fieldRemoved"
+	// ChangeFieldRenamed is a field with a new name at the same position
+	// & with the same type
+	ChangeFieldRenamed ChangeKind = "fieldRenamed"
+	// ChangeFieldMoved is a field at a new position
+	ChangeFieldMoved ChangeKind = "fieldMoved"
+	// ChangeTypeWidened is a type change that accepts all old values
+	ChangeTypeWidened ChangeKind = "typeWidened"
+	// ChangeTypeNarrowed is a type change that only accepts some old values
+	ChangeTypeNarrowed ChangeKind = "typeNarrowed"
+	// ChangeTypeChanged is a change between unrelated types
+	ChangeTypeChanged ChangeKind = "typeChanged"
+	// ChangeFieldFormat is a change to a field's format string
+	ChangeFieldFormat ChangeKind = "fieldFormat"
+	// ChangeNumberFormat is a change to how a field's numbers are written
+	ChangeNumberFormat ChangeKind = "numberFormat"
+	// ChangeConstraintTightened is a constraint that rejects more values
+	ChangeConstraintTightened ChangeKind = "constraintTightened"
+	// ChangeConstraintLoosened is a constraint that accepts more values
+	ChangeConstraintLoosened ChangeKind = "constraintLoosened"
+	// ChangeConstraintChanged is a constraint change that both rejects &
+	// accepts different values
+	ChangeConstraintChanged ChangeKind = "constraintChanged"
+	// ChangePrimaryKey is a change to the schema's primary key
+	ChangePrimaryKey ChangeKind = "primaryKey"
+	// ChangeForeignKeys is a change to the schema's foreign keys
+	ChangeForeignKeys ChangeKind = "foreignKeys"
+	// ChangeMetadata is a change to descriptive details like field titles
+	ChangeMetadata ChangeKind = "metadata"
+)
+
+// StructureChange is a single difference between two structures
+type StructureChange struct {
+	// Kind classifies the change
+	Kind ChangeKind `json:"kind"`
+	// Path locates the change, eg: "formatConfig.delimiter" or
+	// "fields.address.zip"
+	Path string `json:"path"`
+	// Compatibility of this change alone
+	Compatibility Compatibility `json:"compatibility"`
+	// Message describes the change
+	Message string `json:"message"`
+	// Before & After are the changed values, if any
+	Before interface{} `json:"before,omitempty"`
+	After  interface{} `json:"after,omitempty"`
+}
+
+// CompatibilityReport lists the differences between two structures
+type CompatibilityReport struct {
+	// Compatibility of all changes combined
+	Compatibility Compatibility `json:"compatibility"`
+	// Changes lists each difference, structure level changes first
+	Changes []*StructureChange `json:"changes"`
+}
+
+// add records a change, reducing the report's compatibility to that of
+// the change
+func (r *CompatibilityReport) add(kind ChangeKind, path string, c Compatibility, before, after interface{}, msg string, args ...interface{}) {
+	r.Changes = append(r.Changes, &StructureChange{
+		Kind:          kind,
+		Path:          path,
+		Compatibility: c,
+		Message:       fmt.Sprintf(msg, args...),
+		Before:        before,
+		After:         after,
+	})
+	r.Compatibility &= c
+}
+
+// CheckCompatibility classifies the differences between a previous & next
+// version of a structure. fields are matched by name, with removed &
+// added fields at the same position & with the same type treated as renames.
+// formats that match values to fields by position, like csv, can't add,
+// remove or move fields compatibly. formats with keyed values, like json
+// objects, can
+func CheckCompatibility(prev, next *Structure) *CompatibilityReport {
+	r := &CompatibilityReport{Compatibility: FullyCompatible, Changes: []*StructureChange{}}
+	if prev == nil {
+		prev = &Structure{}
+	}
+	if next == nil {
+		next = &Structure{}
+	}
+
+	if prev.Format != next.Format {
+		r.add(ChangeFormat, "format", Breaking, prev.Format.String(), next.Format.String(), "data format changed from %s to %s", prev.Format.String(), next.Format.String())
+	} else {
+		checkFormatConfig(r, prev.FormatConfig, next.FormatConfig)
+	}
+	if prev.Encoding != next.Encoding {
+		r.add(ChangeEncoding, "encoding", Breaking, prev.Encoding, next.Encoding, "encoding changed from '%s' to '%s'", prev.Encoding, next.Encoding)
+	}
+	if prev.Compression != next.Compression {
+		r.add(ChangeCompression, "compression", Breaking, prev.Compression.String(), next.Compression.String(), "compression changed from %s to %s", prev.Compression.String(), next.Compression.String())
+	}
+
+	ps, ns := prev.Schema, next.Schema
+	if ps == nil {
+		ps = &Schema{}
+	}
+	if ns == nil {
+		ns = &Schema{}
+	}
+	checkKeys(r, ps, ns)
+	checkFieldLists(r, "fields", ps.Fields, ns.Fields, positionalFormat(next))
+	return r
+}
+
+// positionalFormat reports weather data of a structure matches values to
+// fields by position rather than by name
+func positionalFormat(st *Structure) bool {
+	switch st.Format {
+	case JSONDataFormat:
+		opts, ok := st.FormatConfig.(*JSONOptions)
+		return ok && opts != nil && opts.ArrayEntries
+	case NDJSONDataFormat:
+		opts, ok := st.FormatConfig.(*NDJSONOptions)
+		return ok && opts != nil && opts.ArrayEntries
+	case XMLDataFormat, ParquetDataFormat, GeoJSONDataFormat:
+		return false
+	}
+	return true
+}
+
+// checkFormatConfig compares the options of two format configurations,
+// any change of option is breaking
+func checkFormatConfig(r *CompatibilityReport, prev, next FormatConfig) {
+	pm, nm := map[string]interface{}{}, map[string]interface{}{}
+	if prev != nil && !reflect.ValueOf(prev).IsNil() {
+		pm = prev.Map()
+	}
+	if next != nil && !reflect.ValueOf(next).IsNil() {
+		nm = next.Map()
+	}
+
+	keys := []string{}
+	for k := range pm {
+		keys = append(keys, k)
+	}
+	for k := range nm {
+		if _, ok := pm[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		if !reflect.DeepEqual(pm[k], nm[k]) {
+			r.add(ChangeFormatConfig, "formatConfig."+k, Breaking, pm[k], nm[k], "format option %s changed from %v to %v", k, pm[k], nm[k])
+		}
+	}
+}
+
+// checkKeys compares the primary & foreign keys of two schemas. adding a
+// key tightens the schema, removing one loosens it
+func checkKeys(r *CompatibilityReport, prev, next *Schema) {
+	switch {
+	case reflect.DeepEqual(prev.PrimaryKey, next.PrimaryKey) || len(prev.PrimaryKey) == 0 && len(next.PrimaryKey) == 0:
+	case len(prev.PrimaryKey) == 0:
+		r.add(ChangePrimaryKey, "primaryKey", ForwardCompatible, nil, next.PrimaryKey, "primary key %v added", next.PrimaryKey)
+	case len(next.PrimaryKey) == 0:
+		r.add(ChangePrimaryKey, "primaryKey", BackwardCompatible, prev.PrimaryKey, nil, "primary key %v removed", prev.PrimaryKey)
+	default:
+		r.add(ChangePrimaryKey, "primaryKey", Breaking, prev.PrimaryKey, next.PrimaryKey, "primary key changed from %v to %v", prev.PrimaryKey, next.PrimaryKey)
+	}
+
+	for i, fk := range next.ForeignKeys {
+		if !containsForeignKey(prev.ForeignKeys, fk) {
+			r.add(ChangeForeignKeys, "foreignKeys."+strconv.Itoa(i), ForwardCompatible, nil, fk, "foreign key %v added", fk.Fields)
+		}
+	}
+	for i, fk := range prev.ForeignKeys {
+		if !containsForeignKey(next.ForeignKeys, fk) {
+			r.add(ChangeForeignKeys, "foreignKeys."+strconv.Itoa(i), BackwardCompatible, fk, nil, "foreign key %v removed", fk.Fields)
+		}
+	}
+}
+
+func containsForeignKey(keys []*ForeignKey, fk *ForeignKey) bool {
+	for _, k := range keys {
+		if CompareForeignKeys(k, fk) == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// checkFieldLists compares two lists of fields, matching fields by name.
+// in positional lists, like the fields of csv data, added, removed & moved
+// fields change the position of values, and are breaking
+func checkFieldLists(r *CompatibilityReport, path string, prev, next []*Field, positional bool) {
+	prevIdx := map[string]int{}
+	for i, f := range prev {
+		if f != nil {
+			prevIdx[f.Name] = i
+		}
+	}
+	nextIdx := map[string]int{}
+	for i, f := range next {
+		if f != nil {
+			nextIdx[f.Name] = i
+		}
+	}
+
+	renamed := map[int]bool{}
+	for i, nf := range next {
+		if nf == nil {
+			continue
+		}
+		fpath := path + "." + nf.Name
+		j, ok := prevIdx[nf.Name]
+		if !ok {
+			// a removed field in the same position with the same type is
+			// taken to be renamed
+			if i < len(prev) && prev[i] != nil && prev[i].Type == nf.Type {
+				if _, kept := nextIdx[prev[i].Name]; !kept {
+					renamed[i] = true
+					r.add(ChangeFieldRenamed, fpath, Breaking, prev[i].Name, nf.Name, "field %s renamed to %s", prev[i].Name, nf.Name)
+					checkField(r, fpath, prev[i], nf)
+					continue
+				}
+			}
+			switch {
+			case positional:
+				r.add(ChangeFieldAdded, fpath, Breaking, nil, nf.Name, "field %s added at position %d", nf.Name, i)
+			case isRequired(nf):
+				r.add(ChangeFieldAdded, fpath, ForwardCompatible, nil, nf.Name, "required field %s added", nf.Name)
+			default:
+				r.add(ChangeFieldAdded, fpath, FullyCompatible, nil, nf.Name, "field %s added", nf.Name)
+			}
+			continue
+		}
+		if i != j {
+			c := FullyCompatible
+			if positional {
+				c = Breaking
+			}
+			r.add(ChangeFieldMoved, fpath, c, j, i, "field %s moved from position %d to %d", nf.Name, j, i)
+		}
+		checkField(r, fpath, prev[j], nf)
+	}
+
+	for i, pf := range prev {
+		if pf == nil || renamed[i] {
+			continue
+		}
+		if _, ok := nextIdx[pf.Name]; ok {
+			continue
+		}
+		fpath := path + "." + pf.Name
+		switch {
+		case positional:
+			r.add(ChangeFieldRemoved, fpath, Breaking, pf.Name, nil, "field %s removed from position %d", pf.Name, i)
+		case isRequired(pf):
+			r.add(ChangeFieldRemoved, fpath, BackwardCompatible, pf.Name, nil, "required field %s removed", pf.Name)
+		default:
+			r.add(ChangeFieldRemoved, fpath, FullyCompatible, pf.Name, nil, "field %s removed", pf.Name)
+		}
+	}
+}
+
+func isRequired(f *Field) bool {
+	return f.Constraints != nil && f.Constraints.Required != nil && *f.Constraints.Required
+}
+
+// checkField compares two versions of the same field
+func checkField(r *CompatibilityReport, path string, prev, next *Field) {
+	if prev.Title != next.Title || prev.Description != next.Description {
+		r.add(ChangeMetadata, path, FullyCompatible, nil, nil, "field %s title or description changed", next.Name)
+	}
+
+	if prev.Type != next.Type {
+		switch {
+		case typeWidens(prev.Type, next.Type):
+			r.add(ChangeTypeWidened, path, BackwardCompatible, prev.Type.String(), next.Type.String(), "field %s type widened from %s to %s", next.Name, prev.Type.String(), next.Type.String())
+		case typeWidens(next.Type, prev.Type):
+			r.add(ChangeTypeNarrowed, path, ForwardCompatible, prev.Type.String(), next.Type.String(), "field %s type narrowed from %s to %s", next.Name, prev.Type.String(), next.Type.String())
+		default:
+			r.add(ChangeTypeChanged, path, Breaking, prev.Type.String(), next.Type.String(), "field %s type changed from %s to %s", next.Name, prev.Type.String(), next.Type.String())
+		}
+		// nested schemas & formats of unrelated types aren't comparable
+		if !typeWidens(prev.Type, next.Type) && !typeWidens(next.Type, prev.Type) {
+			return
+		}
+	}
+
+	if prev.Format != next.Format {
+		r.add(ChangeFieldFormat, path, Breaking, prev.Format, next.Format, "field %s format changed from '%s' to '%s'", next.Name, prev.Format, next.Format)
+	}
+	checkNumberFormat(r, path, prev, next)
+	checkConstraints(r, path, next, prev.Constraints, next.Constraints)
+
+	if prev.Items != nil || next.Items != nil {
+		switch {
+		case prev.Items == nil:
+			r.add(ChangeConstraintTightened, path+".items", ForwardCompatible, nil, next.Items.Type.String(), "field %s items schema added", next.Name)
+		case next.Items == nil:
+			r.add(ChangeConstraintLoosened, path+".items", BackwardCompatible, prev.Items.Type.String(), nil, "field %s items schema removed", next.Name)
+		default:
+			checkField(r, path+".items", prev.Items, next.Items)
+		}
+	}
+	checkFieldLists(r, path, prev.Properties, next.Properties, false)
+}
+
+// checkNumberFormat compares how two versions of a field write numbers.
+// permitting symbols loosens a field, other changes are breaking
+func checkNumberFormat(r *CompatibilityReport, path string, prev, next *Field) {
+	pnf, perr := prev.NumberFormat()
+	nnf, nerr := next.NumberFormat()
+	if perr != nil || nerr != nil {
+		if prev.Locale != next.Locale || prev.DecimalChar != next.DecimalChar || prev.GroupChar != next.GroupChar {
+			r.add(ChangeNumberFormat, path, Breaking, nil, nil, "field %s number format changed", next.Name)
+		}
+		return
+	}
+	pplain, nplain := pnf, nnf
+	pplain.Symbols, nplain.Symbols = false, false
+	switch {
+	case pplain != nplain:
+		r.add(ChangeNumberFormat, path, Breaking, pnf, nnf, "field %s number format changed", next.Name)
+	case !pnf.Symbols && nnf.Symbols:
+		r.add(ChangeNumberFormat, path, BackwardCompatible, pnf, nnf, "field %s numbers may include symbols", next.Name)
+	case pnf.Symbols && !nnf.Symbols:
+		r.add(ChangeNumberFormat, path, ForwardCompatible, pnf, nnf, "field %s numbers must be bare", next.Name)
+	}
+}
+
+// typeWidens reports weather values of type from are always valid values
+// of type to
+func typeWidens(from, to datatypes.Type) bool {
+	if from == to || from == datatypes.Unknown {
+		return false
+	}
+	switch to {
+	case datatypes.Any, datatypes.String:
+		return true
+	case datatypes.Float, datatypes.Decimal:
+		return from == datatypes.Integer || from == datatypes.Float
+	case datatypes.JSON:
+		return from == datatypes.Array || from == datatypes.Object || from == datatypes.GeoJSON
+	case datatypes.Object:
+		return from == datatypes.GeoJSON
+	}
+	return false
+}
+
+// checkConstraints compares two versions of a field's constraints
+func checkConstraints(r *CompatibilityReport, path string, f *Field, prev, next *FieldConstraints) {
+	if prev == nil {
+		prev = &FieldConstraints{}
+	}
+	if next == nil {
+		next = &FieldConstraints{}
+	}
+	tightened := func(name string, before, after interface{}) {
+		r.add(ChangeConstraintTightened, path+".constraints."+name, ForwardCompatible, before, after, "field %s %s constraint tightened", f.Name, name)
+	}
+	loosened := func(name string, before, after interface{}) {
+		r.add(ChangeConstraintLoosened, path+".constraints."+name, BackwardCompatible, before, after, "field %s %s constraint loosened", f.Name, name)
+	}
+	changed := func(name string, before, after interface{}) {
+		r.add(ChangeConstraintChanged, path+".constraints."+name, Breaking, before, after, "field %s %s constraint changed", f.Name, name)
+	}
+
+	for _, flag := range []struct {
+		name       string
+		prev, next *bool
+	}{
+		{"required", prev.Required, next.Required},
+		{"unique", prev.Unique, next.Unique},
+	} {
+		p, n := flag.prev != nil && *flag.prev, flag.next != nil && *flag.next
+		if !p && n {
+			tightened(flag.name, p, n)
+		} else if p && !n {
+			loosened(flag.name, p, n)
+		}
+	}
+
+	// lower bounds tighten as they increase, upper bounds as they decrease
+	for _, bound := range []struct {
+		name       string
+		prev, next *int64
+		lower      bool
+	}{
+		{"minLength", prev.MinLength, next.MinLength, true},
+		{"maxLength", prev.MaxLength, next.MaxLength, false},
+	} {
+		switch {
+		case bound.prev == nil && bound.next == nil:
+		case bound.prev == nil:
+			tightened(bound.name, nil, *bound.next)
+		case bound.next == nil:
+			loosened(bound.name, *bound.prev, nil)
+		case *bound.prev != *bound.next:
+			if (*bound.next > *bound.prev) == bound.lower {
+				tightened(bound.name, *bound.prev, *bound.next)
+			} else {
+				loosened(bound.name, *bound.prev, *bound.next)
+			}
+		}
+	}
+
+	for _, bound := range []struct {
+		name       string
+		prev, next interface{}
+		lower      bool
+	}{
+		{"minimum", prev.Minimum, next.Minimum, true},
+		{"maximum", prev.Maximum, next.Maximum, false},
+	} {
+		switch {
+		case bound.prev == nil && bound.next == nil:
+		case bound.prev == nil:
+			tightened(bound.name, nil, bound.next)
+		case bound.next == nil:
+			loosened(bound.name, bound.prev, nil)
+		default:
+			c, ok := compareBounds(f, bound.prev, bound.next)
+			switch {
+			case !ok:
+				changed(bound.name, bound.prev, bound.next)
+			case c == 0:
+			case (c < 0) == bound.lower:
+				tightened(bound.name, bound.prev, bound.next)
+			default:
+				loosened(bound.name, bound.prev, bound.next)
+			}
+		}
+	}
+
+	switch {
+	case prev.Pattern == next.Pattern:
+	case prev.Pattern == "":
+		tightened("pattern", nil, next.Pattern)
+	case next.Pattern == "":
+		loosened("pattern", prev.Pattern, nil)
+	default:
+		changed("pattern", prev.Pattern, next.Pattern)
+	}
+
+	switch {
+	case prev.Enum == nil && next.Enum == nil:
+	case prev.Enum == nil:
+		tightened("enum", nil, next.Enum)
+	case next.Enum == nil:
+		loosened("enum", prev.Enum, nil)
+	default:
+		sub, super := valuesSubset(next.Enum, prev.Enum), valuesSubset(prev.Enum, next.Enum)
+		switch {
+		case sub && super:
+		case sub:
+			tightened("enum", prev.Enum, next.Enum)
+		case super:
+			loosened("enum", prev.Enum, next.Enum)
+		default:
+			changed("enum", prev.Enum, next.Enum)
+		}
+	}
+}
+
+// compareBounds compares two minimum or maximum constraint values as values
+// of a field's type, returning false if they can't be compared
+func compareBounds(f *Field, a, b interface{}) (int, bool) {
+	cmp, err := datatypes.NewComparer(f.Type, f.Format, datatypes.NullsLow)
+	if err != nil {
+		return 0, false
+	}
+	c, err := cmp.Compare(boundBytes(a), boundBytes(b))
+	return c, err == nil
+}
+
+// boundBytes converts a constraint value decoded from json to raw bytes
+func boundBytes(v interface{}) []byte {
+	switch val := v.(type) {
+	case string:
+		return []byte(val)
+	case float64:
+		return []byte(strconv.FormatFloat(val, 'f', -1, 64))
+	}
+	return []byte(fmt.Sprintf("%v", v))
+}
+
+// valuesSubset reports weather every value of a is in b
+func valuesSubset(a, b []interface{}) bool {
+	for _, av := range a {
+		found := false
+		for _, bv := range b {
+			if reflect.DeepEqual(av, bv) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
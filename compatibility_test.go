@@ -0,0 +1,202 @@
+package dataset
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/qri-io/dataset/datatypes"
+)
+
+func TestCompatibilityString(t *testing.T) {
+	cases := []struct {
+		c                 Compatibility
+		str               string
+		backward, forward bool
+	}{
+		{Breaking, "breaking", false, false},
+		{BackwardCompatible, "backward", true, false},
+		{ForwardCompatible, "forward", false, true},
+		{FullyCompatible, "full", true, true},
+	}
+
+	for i, c := range cases {
+		if c.c.String() != c.str || c.c.Backward() != c.backward || c.c.Forward() != c.forward {
+			t.Errorf("case %d mismatch. expected: %s %t %t, got: %s %t %t", i, c.str, c.backward, c.forward, c.c.String(), c.c.Backward(), c.c.Forward())
+		}
+		data, err := json.Marshal(c.c)
+		if err != nil {
+			t.Errorf("case %d error marshaling: %s", i, err.Error())
+			continue
+		}
+		var got Compatibility
+		if err := json.Unmarshal(data, &got); err != nil {
+			t.Errorf("case %d error unmarshaling: %s", i, err.Error())
+			continue
+		}
+		if got != c.c {
+			t.Errorf("case %d round trip mismatch. expected: %s, got: %s", i, c.c, got)
+		}
+	}
+
+	var c Compatibility
+	if err := json.Unmarshal([]byte(`"sideways"`), &c); err == nil || err.Error() != "unknown compatibility 'sideways'" {
+		t.Errorf("expected unknown compatibility error, got: %v", err)
+	}
+}
+
+func TestCheckCompatibility(t *testing.T) {
+	yes, one, five := true, int64(1), int64(5)
+	base := func() *Structure {
+		return &Structure{
+			Format:       CSVDataFormat,
+			FormatConfig: &CSVOptions{HeaderRow: true},
+			Schema: &Schema{
+				Fields: []*Field{
+					{Name: "id", Type: datatypes.Integer},
+					{Name: "name", Type: datatypes.String, Constraints: &FieldConstraints{MaxLength: &five}},
+					{Name: "score", Type: datatypes.Float, Constraints: &FieldConstraints{Minimum: 0.0}},
+					{Name: "status", Type: datatypes.String, Constraints: &FieldConstraints{Enum: []interface{}{"a", "b"}}},
+				},
+			},
+		}
+	}
+
+	keyed := func(st *Structure) {
+		st.Format = JSONDataFormat
+		st.FormatConfig = nil
+	}
+	arrays := func(st *Structure) {
+		st.Format = NDJSONDataFormat
+		st.FormatConfig = &NDJSONOptions{ArrayEntries: true}
+	}
+
+	cases := []struct {
+		description string
+		before      func(st *Structure)
+		change      func(st *Structure)
+		compat      Compatibility
+		kinds       []ChangeKind
+		paths       []string
+	}{
+		{"no change", nil, func(st *Structure) {}, FullyCompatible, []ChangeKind{}, []string{}},
+		{"description", nil, func(st *Structure) { st.Schema.Fields[0].Description = "identifier" },
+			FullyCompatible, []ChangeKind{ChangeMetadata}, []string{"fields.id"}},
+		// csv values are matched to fields by position
+		{"optional field added", nil, func(st *Structure) {
+			st.Schema.Fields = append(st.Schema.Fields, &Field{Name: "notes", Type: datatypes.String})
+		}, Breaking, []ChangeKind{ChangeFieldAdded}, []string{"fields.notes"}},
+		{"required field added", nil, func(st *Structure) {
+			st.Schema.Fields = append(st.Schema.Fields, &Field{Name: "notes", Type: datatypes.Boolean, Constraints: &FieldConstraints{Required: &yes}})
+		}, Breaking, []ChangeKind{ChangeFieldAdded}, []string{"fields.notes"}},
+		{"field removed", nil, func(st *Structure) { st.Schema.Fields = st.Schema.Fields[:3] },
+			Breaking, []ChangeKind{ChangeFieldRemoved}, []string{"fields.status"}},
+		{"field renamed", nil, func(st *Structure) { st.Schema.Fields[1].Name = "full_name" },
+			Breaking, []ChangeKind{ChangeFieldRenamed}, []string{"fields.full_name"}},
+		{"field moved", nil, func(st *Structure) {
+			f := st.Schema.Fields
+			f[2], f[3] = f[3], f[2]
+		}, Breaking, []ChangeKind{ChangeFieldMoved, ChangeFieldMoved}, []string{"fields.status", "fields.score"}},
+		// json object values are matched to fields by name
+		{"keyed optional field added", keyed, func(st *Structure) {
+			keyed(st)
+			st.Schema.Fields = append(st.Schema.Fields, &Field{Name: "notes", Type: datatypes.String})
+		}, FullyCompatible, []ChangeKind{ChangeFieldAdded}, []string{"fields.notes"}},
+		{"keyed required field added", keyed, func(st *Structure) {
+			keyed(st)
+			st.Schema.Fields = append(st.Schema.Fields, &Field{Name: "notes", Type: datatypes.Boolean, Constraints: &FieldConstraints{Required: &yes}})
+		}, ForwardCompatible, []ChangeKind{ChangeFieldAdded}, []string{"fields.notes"}},
+		{"keyed field removed", keyed, func(st *Structure) {
+			keyed(st)
+			st.Schema.Fields = st.Schema.Fields[:3]
+		}, FullyCompatible, []ChangeKind{ChangeFieldRemoved}, []string{"fields.status"}},
+		{"keyed field moved", keyed, func(st *Structure) {
+			keyed(st)
+			f := st.Schema.Fields
+			f[2], f[3] = f[3], f[2]
+		}, FullyCompatible, []ChangeKind{ChangeFieldMoved, ChangeFieldMoved}, []string{"fields.status", "fields.score"}},
+		{"json array entry field moved", arrays, func(st *Structure) {
+			arrays(st)
+			f := st.Schema.Fields
+			f[2], f[3] = f[3], f[2]
+		}, Breaking, []ChangeKind{ChangeFieldMoved, ChangeFieldMoved}, []string{"fields.status", "fields.score"}},
+		{"type widened", nil, func(st *Structure) { st.Schema.Fields[0].Type = datatypes.Decimal },
+			BackwardCompatible, []ChangeKind{ChangeTypeWidened}, []string{"fields.id"}},
+		{"type narrowed", nil, func(st *Structure) { st.Schema.Fields[2].Type = datatypes.Integer },
+			ForwardCompatible, []ChangeKind{ChangeTypeNarrowed}, []string{"fields.score"}},
+		{"type changed", nil, func(st *Structure) { st.Schema.Fields[0].Type = datatypes.Boolean },
+			Breaking, []ChangeKind{ChangeTypeChanged}, []string{"fields.id"}},
+		{"constraints tightened", nil, func(st *Structure) {
+			st.Schema.Fields[1].Constraints = &FieldConstraints{MaxLength: &one, Required: &yes}
+			st.Schema.Fields[2].Constraints.Minimum = 10.0
+			st.Schema.Fields[3].Constraints.Enum = []interface{}{"a"}
+		}, ForwardCompatible, []ChangeKind{ChangeConstraintTightened, ChangeConstraintTightened, ChangeConstraintTightened, ChangeConstraintTightened},
+			[]string{"fields.name.constraints.required", "fields.name.constraints.maxLength", "fields.score.constraints.minimum", "fields.status.constraints.enum"}},
+		{"constraints loosened", nil, func(st *Structure) {
+			st.Schema.Fields[1].Constraints = nil
+			st.Schema.Fields[2].Constraints.Minimum = -1.0
+			st.Schema.Fields[3].Constraints.Enum = []interface{}{"a", "b", "c"}
+		}, BackwardCompatible, []ChangeKind{ChangeConstraintLoosened, ChangeConstraintLoosened, ChangeConstraintLoosened},
+			[]string{"fields.name.constraints.maxLength", "fields.score.constraints.minimum", "fields.status.constraints.enum"}},
+		{"enum changed", nil, func(st *Structure) { st.Schema.Fields[3].Constraints.Enum = []interface{}{"a", "c"} },
+			Breaking, []ChangeKind{ChangeConstraintChanged}, []string{"fields.status.constraints.enum"}},
+		{"format config", nil, func(st *Structure) { st.FormatConfig = &CSVOptions{HeaderRow: true, Delimiter: ";"} },
+			Breaking, []ChangeKind{ChangeFormatConfig}, []string{"formatConfig.delimiter"}},
+		{"format", nil, func(st *Structure) {
+			st.Format = JSONDataFormat
+			st.FormatConfig = nil
+		}, Breaking, []ChangeKind{ChangeFormat}, []string{"format"}},
+		{"number format", nil, func(st *Structure) { st.Schema.Fields[2].Locale = "de" },
+			Breaking, []ChangeKind{ChangeNumberFormat}, []string{"fields.score"}},
+		{"primary key added", nil, func(st *Structure) { st.Schema.PrimaryKey = FieldKey{"id"} },
+			ForwardCompatible, []ChangeKind{ChangePrimaryKey}, []string{"primaryKey"}},
+		{"nested property added & item type widened", func(st *Structure) {
+			st.Schema.Fields[3] = &Field{Name: "status", Type: datatypes.Object, Properties: []*Field{
+				{Name: "code", Type: datatypes.String},
+				{Name: "history", Type: datatypes.Array, Items: &Field{Type: datatypes.Integer}},
+			}}
+		}, func(st *Structure) {
+			st.Schema.Fields[3] = &Field{Name: "status", Type: datatypes.Object, Properties: []*Field{
+				{Name: "code", Type: datatypes.String},
+				{Name: "history", Type: datatypes.Array, Items: &Field{Type: datatypes.Float}},
+				{Name: "reason", Type: datatypes.String, Constraints: &FieldConstraints{Required: &yes}},
+			}}
+		}, Breaking, []ChangeKind{ChangeTypeWidened, ChangeFieldAdded}, []string{"fields.status.history.items", "fields.status.reason"}},
+	}
+
+	for i, c := range cases {
+		prev, next := base(), base()
+		if c.before != nil {
+			c.before(prev)
+		}
+		c.change(next)
+		got := CheckCompatibility(prev, next)
+		if got.Compatibility != c.compat {
+			t.Errorf("case %d %s compatibility mismatch. expected: %s, got: %s", i, c.description, c.compat, got.Compatibility)
+		}
+		if len(got.Changes) != len(c.kinds) {
+			t.Errorf("case %d %s changes length mismatch. expected: %d, got: %d", i, c.description, len(c.kinds), len(got.Changes))
+			for _, ch := range got.Changes {
+				t.Logf("%s %s: %s", ch.Kind, ch.Path, ch.Message)
+			}
+			continue
+		}
+		for j, ch := range got.Changes {
+			if ch.Kind != c.kinds[j] || ch.Path != c.paths[j] {
+				t.Errorf("case %d %s change %d mismatch. expected: %s %s, got: %s %s", i, c.description, j, c.kinds[j], c.paths[j], ch.Kind, ch.Path)
+			}
+		}
+	}
+}
+
+func TestCompatibilityReportJSON(t *testing.T) {
+	prev := &Structure{Format: CSVDataFormat, Schema: &Schema{Fields: []*Field{{Name: "a", Type: datatypes.Integer}}}}
+	next := &Structure{Format: CSVDataFormat, Schema: &Schema{Fields: []*Field{{Name: "a", Type: datatypes.Float}}}}
+	data, err := json.Marshal(CheckCompatibility(prev, next))
+	if err != nil {
+		t.Fatalf("error marshaling report: %s", err.Error())
+	}
+	expect := `{"compatibility":"backward","changes":[{"kind":"typeWidened","path":"fields.a","compatibility":"backward","message":"field a type widened from integer to float","before":"integer","after":"float"}]}`
+	if string(data) != expect {
+		t.Errorf("json mismatch. expected:\n%s\ngot:\n%s", expect, string(data))
+	}
+}
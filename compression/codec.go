@@ -0,0 +1,125 @@
+package compression
+
+import (
+	"bufio"
+	"bytes"
+	"compress/bzip2"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/ioutil"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// magic numbers that start the stream of each compression type
+var magic = map[Type][]byte{
+	Gzip:  {0x1f, 0x8b},
+	Zstd:  {0x28, 0xb5, 0x2f, 0xfd},
+	Bzip2: {'B', 'Z', 'h'},
+}
+
+// bzip2 magic is followed by a block size of 1-9, then the magic number of
+// the first block, or the end of stream marker for empty streams. "BZh" is
+// plain text, so the full header is checked
+var (
+	bzip2BlockMagic = []byte{0x31, 0x41, 0x59, 0x26, 0x53, 0x59}
+	bzip2EndMagic   = []byte{0x17, 0x72, 0x45, 0x38, 0x50, 0x90}
+)
+
+// sniffLen is the number of bytes needed to recognize any compression type
+const sniffLen = 10
+
+// extensions maps file extensions to compression types
+var extensions = map[string]Type{
+	".gz":   Gzip,
+	".gzip": Gzip,
+	".zst":  Zstd,
+	".zstd": Zstd,
+	".bz2":  Bzip2,
+}
+
+// ExtensionType gives the compression type for a file extension like ".gz",
+// returning None for extensions that don't specify compression
+func ExtensionType(ext string) Type {
+	return extensions[ext]
+}
+
+// SniffType checks the start of a stream for compression magic numbers,
+// returning None if no match is found
+func SniffType(header []byte) Type {
+	for t, m := range magic {
+		if bytes.HasPrefix(header, m) {
+			if t == Bzip2 && !isBzip2Header(header) {
+				continue
+			}
+			return t
+		}
+	}
+	return None
+}
+
+// isBzip2Header checks the 10 byte header of a bzip2 stream
+func isBzip2Header(header []byte) bool {
+	if len(header) < sniffLen || header[3] < '1' || header[3] > '9' {
+		return false
+	}
+	return bytes.Equal(header[4:sniffLen], bzip2BlockMagic) || bytes.Equal(header[4:sniffLen], bzip2EndMagic)
+}
+
+// Sniff peeks at the start of a reader to determine it's compression type.
+// the returned reader must be used in place of r, and yields all bytes
+// read from r, including those used to sniff the type
+func Sniff(r io.Reader) (Type, io.Reader, error) {
+	br := bufio.NewReader(r)
+	header, err := br.Peek(sniffLen)
+	if err != nil && err != io.EOF && err != bufio.ErrBufferFull {
+		return None, br, err
+	}
+	return SniffType(header), br, nil
+}
+
+// NewReader wraps r in a reader that decompresses data of type t.
+// closing the returned reader does not close r
+func NewReader(t Type, r io.Reader) (io.ReadCloser, error) {
+	switch t {
+	case None:
+		return ioutil.NopCloser(r), nil
+	case Gzip:
+		return gzip.NewReader(r)
+	case Zstd:
+		dec, err := zstd.NewReader(r, zstd.WithDecoderConcurrency(1))
+		if err != nil {
+			return nil, err
+		}
+		return dec.IOReadCloser(), nil
+	case Bzip2:
+		return ioutil.NopCloser(bzip2.NewReader(r)), nil
+	default:
+		return nil, fmt.Errorf("unsupported compression type: %d", t)
+	}
+}
+
+// NewWriter wraps w in a writer that compresses data with type t.
+// the returned writer must be closed to flush compressed data, closing
+// it does not close w
+func NewWriter(t Type, w io.Writer) (io.WriteCloser, error) {
+	switch t {
+	case None:
+		return nopWriteCloser{w}, nil
+	case Gzip:
+		return gzip.NewWriter(w), nil
+	case Zstd:
+		return zstd.NewWriter(w, zstd.WithEncoderConcurrency(1))
+	case Bzip2:
+		return nil, fmt.Errorf("bzip2 compression is read-only")
+	default:
+		return nil, fmt.Errorf("unsupported compression type: %d", t)
+	}
+}
+
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error { return nil }
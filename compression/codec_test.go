@@ -0,0 +1,116 @@
+package compression
+
+import (
+	"bytes"
+	"io/ioutil"
+	"testing"
+)
+
+func TestReadWrite(t *testing.T) {
+	data := []byte("city,pop\ntoronto,40000000\n")
+
+	for _, typ := range []Type{None, Gzip, Zstd} {
+		buf := &bytes.Buffer{}
+		w, err := NewWriter(typ, buf)
+		if err != nil {
+			t.Errorf("%s error creating writer: %s", typ, err.Error())
+			continue
+		}
+		if _, err := w.Write(data); err != nil {
+			t.Errorf("%s write error: %s", typ, err.Error())
+			continue
+		}
+		if err := w.Close(); err != nil {
+			t.Errorf("%s close error: %s", typ, err.Error())
+			continue
+		}
+
+		sniffed, rd, err := Sniff(buf)
+		if err != nil {
+			t.Errorf("%s sniff error: %s", typ, err.Error())
+			continue
+		}
+		if sniffed != typ {
+			t.Errorf("sniff mismatch. expected: '%s', got: '%s'", typ, sniffed)
+		}
+
+		r, err := NewReader(sniffed, rd)
+		if err != nil {
+			t.Errorf("%s error creating reader: %s", typ, err.Error())
+			continue
+		}
+		got, err := ioutil.ReadAll(r)
+		if err != nil {
+			t.Errorf("%s read error: %s", typ, err.Error())
+			continue
+		}
+		if !bytes.Equal(data, got) {
+			t.Errorf("%s data mismatch. expected: %q, got: %q", typ, data, got)
+		}
+	}
+}
+
+func TestBzip2(t *testing.T) {
+	if _, err := NewWriter(Bzip2, &bytes.Buffer{}); err == nil || err.Error() != "bzip2 compression is read-only" {
+		t.Errorf("expected bzip2 writer to error, got: %v", err)
+	}
+
+	data, err := ioutil.ReadFile("testdata/cities.csv.bz2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if typ := SniffType(data); typ != Bzip2 {
+		t.Errorf("sniff mismatch. expected: 'bzip2', got: '%s'", typ)
+	}
+	r, err := NewReader(Bzip2, bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := ioutil.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != "city,pop\ntoronto,40000000\n" {
+		t.Errorf("data mismatch. got: %q", got)
+	}
+}
+
+func TestSniffType(t *testing.T) {
+	cases := []struct {
+		header []byte
+		expect Type
+	}{
+		{nil, None},
+		{[]byte("city,pop"), None},
+		{[]byte{0x1f}, None},
+		{[]byte{0x1f, 0x8b, 0x08}, Gzip},
+		{[]byte{0x28, 0xb5, 0x2f, 0xfd}, Zstd},
+		{[]byte("BZh91AY&SY"), Bzip2},
+		{[]byte{'B', 'Z', 'h', '1', 0x17, 0x72, 0x45, 0x38, 0x50, 0x90}, Bzip2},
+		{[]byte("BZh9"), None},
+		{[]byte("BZh,"), None},
+		// text that starts like a bzip2 header
+		{[]byte("BZh1,BZh2,BZh3\n"), None},
+	}
+
+	for i, c := range cases {
+		if got := SniffType(c.header); got != c.expect {
+			t.Errorf("case %d mismatch. expected: '%s', got: '%s'", i, c.expect, got)
+		}
+	}
+}
+
+func TestParseTypeString(t *testing.T) {
+	for typ, name := range Names {
+		got, err := ParseTypeString(name)
+		if err != nil {
+			t.Errorf("error parsing '%s': %s", name, err.Error())
+		}
+		if got != typ {
+			t.Errorf("parse mismatch. expected: '%s', got: '%s'", typ, got)
+		}
+	}
+	if _, err := ParseTypeString("lzma"); err == nil {
+		t.Errorf("expected invalid compression type to error")
+	}
+}
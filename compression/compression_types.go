@@ -11,16 +11,29 @@ type Type int
 const (
 	// None speficies no compression
 	None Type = iota
+	// Gzip specifies gzip compression, RFC 1952
+	Gzip
+	// Zstd specifies zstandard compression, RFC 8478
+	Zstd
+	// Bzip2 specifies bzip2 compression. bzip2 data can be
+	// read, but not written
+	Bzip2
 )
 
 // Names maps the name of a hash to codes
 var Names = map[Type]string{
-	None: "",
+	None:  "",
+	Gzip:  "gzip",
+	Zstd:  "zstd",
+	Bzip2: "bzip2",
 }
 
 // Codes maps a hash code to it's name
 var Codes = map[string]Type{
-	"": None,
+	"":      None,
+	"gzip":  Gzip,
+	"zstd":  Zstd,
+	"bzip2": Bzip2,
 }
 
 // ParseTypeString returns a compression type for a given string
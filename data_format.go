@@ -25,14 +25,27 @@ const (
 	// JSONDataFormat specifies Javascript Object Notation-formatted data
 	JSONDataFormat
 	// XMLDataFormat specifies eXtensible Markup Language-formatted data
-	// currently not supported.
 	XMLDataFormat
-	// XLSDataFormat specifies microsoft excel formatted data
-	// currently not supported.
+	// XLSDataFormat specifies microsoft excel formatted data.
+	// workbooks are read & written in the office open xml (.xlsx) format
 	XLSDataFormat
 	// CDXJDataFormat specifies the Wayback machine's CDX-Json formated data
 	// https://github.com/iipc/warc-specifications/blob/gh-pages/specifications/cdx-format/openwayback-cdxj/index.md
 	CDXJDataFormat
+	// NDJSONDataFormat specifies newline-delimited JSON, where each line
+	// is a single JSON value, also known as JSON Lines
+	// http://ndjson.org
+	NDJSONDataFormat
+	// ParquetDataFormat specifies the Apache Parquet columnar storage format
+	// https://parquet.apache.org
+	ParquetDataFormat
+	// GeoJSONDataFormat specifies a GeoJSON FeatureCollection, where each
+	// feature is a row
+	// https://tools.ietf.org/html/rfc7946
+	GeoJSONDataFormat
+	// SQLDataFormat specifies SQL statements that create & fill a table.
+	// sql data can be written but not read
+	SQLDataFormat
 	// TODO - make this list more exhaustive
 )
 
@@ -45,6 +58,10 @@ func (f DataFormat) String() string {
 		XMLDataFormat:     "xml",
 		XLSDataFormat:     "xls",
 		CDXJDataFormat:    "cdxj",
+		NDJSONDataFormat:  "ndjson",
+		ParquetDataFormat: "parquet",
+		GeoJSONDataFormat: "geojson",
+		SQLDataFormat:     "sql",
 	}[f]
 
 	if !ok {
@@ -57,17 +74,29 @@ func (f DataFormat) String() string {
 // ParseDataFormatString takes a string representation of a data format
 func ParseDataFormatString(s string) (df DataFormat, err error) {
 	df, ok := map[string]DataFormat{
-		"":      UnknownDataFormat,
-		".csv":  CSVDataFormat,
-		"csv":   CSVDataFormat,
-		".json": JSONDataFormat,
-		"json":  JSONDataFormat,
-		".xml":  XMLDataFormat,
-		"xml":   XMLDataFormat,
-		".xls":  XLSDataFormat,
-		"xls":   XLSDataFormat,
-		".cdxj": CDXJDataFormat,
-		"cdxj":  CDXJDataFormat,
+		"":         UnknownDataFormat,
+		".csv":     CSVDataFormat,
+		"csv":      CSVDataFormat,
+		".json":    JSONDataFormat,
+		"json":     JSONDataFormat,
+		".xml":     XMLDataFormat,
+		"xml":      XMLDataFormat,
+		".xls":     XLSDataFormat,
+		"xls":      XLSDataFormat,
+		".xlsx":    XLSDataFormat,
+		"xlsx":     XLSDataFormat,
+		".cdxj":    CDXJDataFormat,
+		"cdxj":     CDXJDataFormat,
+		".ndjson":  NDJSONDataFormat,
+		"ndjson":   NDJSONDataFormat,
+		".jsonl":   NDJSONDataFormat,
+		"jsonl":    NDJSONDataFormat,
+		".parquet": ParquetDataFormat,
+		"parquet":  ParquetDataFormat,
+		".geojson": GeoJSONDataFormat,
+		"geojson":  GeoJSONDataFormat,
+		".sql":     SQLDataFormat,
+		"sql":      SQLDataFormat,
 	}[s]
 	if !ok {
 		err = fmt.Errorf("invalid data format: `%s`", s)
@@ -2,6 +2,8 @@ package dataset
 
 import (
 	"fmt"
+	"strings"
+	"unicode/utf8"
 )
 
 // FormatConfig is the interface for data format configurations
@@ -20,6 +22,18 @@ func ParseFormatConfigMap(f DataFormat, opts map[string]interface{}) (FormatConf
 		return NewCSVOptions(opts)
 	case JSONDataFormat:
 		return NewJSONOptions(opts)
+	case XLSDataFormat:
+		return NewXLSOptions(opts)
+	case XMLDataFormat:
+		return NewXMLOptions(opts)
+	case NDJSONDataFormat:
+		return NewNDJSONOptions(opts)
+	case ParquetDataFormat:
+		return NewParquetOptions(opts)
+	case GeoJSONDataFormat:
+		return NewGeoJSONOptions(opts)
+	case SQLDataFormat:
+		return NewSQLOptions(opts)
 	default:
 		return nil, fmt.Errorf("cannot parse configuration for format: %s", f.String())
 	}
@@ -40,7 +54,52 @@ func NewCSVOptions(opts map[string]interface{}) (FormatConfig, error) {
 			return nil, fmt.Errorf("invalid headerRow value: %s", opts["headerRow"])
 		}
 	}
+	if opts["delimiter"] != nil {
+		if delimiter, ok := opts["delimiter"].(string); ok {
+			o.Delimiter = delimiter
+		} else {
+			return nil, fmt.Errorf("invalid delimiter value: %v", opts["delimiter"])
+		}
+	}
+	if opts["quote"] != nil {
+		if quote, ok := opts["quote"].(string); ok {
+			o.Quote = quote
+		} else {
+			return nil, fmt.Errorf("invalid quote value: %v", opts["quote"])
+		}
+	}
+	if opts["comment"] != nil {
+		if comment, ok := opts["comment"].(string); ok {
+			o.Comment = comment
+		} else {
+			return nil, fmt.Errorf("invalid comment value: %v", opts["comment"])
+		}
+	}
+	if opts["lazyQuotes"] != nil {
+		if lazyQuotes, ok := opts["lazyQuotes"].(bool); ok {
+			o.LazyQuotes = lazyQuotes
+		} else {
+			return nil, fmt.Errorf("invalid lazyQuotes value: %v", opts["lazyQuotes"])
+		}
+	}
+	if opts["trimLeadingSpace"] != nil {
+		if trimLeadingSpace, ok := opts["trimLeadingSpace"].(bool); ok {
+			o.TrimLeadingSpace = trimLeadingSpace
+		} else {
+			return nil, fmt.Errorf("invalid trimLeadingSpace value: %v", opts["trimLeadingSpace"])
+		}
+	}
+	if opts["lineTerminator"] != nil {
+		if lineTerminator, ok := opts["lineTerminator"].(string); ok {
+			o.LineTerminator = lineTerminator
+		} else {
+			return nil, fmt.Errorf("invalid lineTerminator value: %v", opts["lineTerminator"])
+		}
+	}
 
+	if err := o.Validate(); err != nil {
+		return nil, err
+	}
 	return o, nil
 }
 
@@ -49,6 +108,51 @@ func NewCSVOptions(opts map[string]interface{}) (FormatConfig, error) {
 type CSVOptions struct {
 	// HeaderRow specifies weather this csv file has a header row or not
 	HeaderRow bool `json:"headerRow"`
+	// Delimiter is the character that separates fields, defaults to ","
+	Delimiter string `json:"delimiter,omitempty"`
+	// Quote is the character used to quote fields, defaults to a double quote.
+	// must be a single ascii character
+	Quote string `json:"quote,omitempty"`
+	// Comment marks lines that begin with this character as comments to be
+	// ignored when reading. comments are disabled by default
+	Comment string `json:"comment,omitempty"`
+	// LazyQuotes allows quotes to appear in unquoted fields, and
+	// non-doubled quotes to appear in quoted fields
+	LazyQuotes bool `json:"lazyQuotes,omitempty"`
+	// TrimLeadingSpace ignores leading whitespace in fields
+	TrimLeadingSpace bool `json:"trimLeadingSpace,omitempty"`
+	// LineTerminator is the line ending used when writing, either "\n" or "\r\n".
+	// defaults to "\n". both are accepted when reading
+	LineTerminator string `json:"lineTerminator,omitempty"`
+}
+
+// Validate checks that dialect characters are usable together
+func (o *CSVOptions) Validate() error {
+	if o.Delimiter != "" {
+		if utf8.RuneCountInString(o.Delimiter) != 1 || strings.ContainsAny(o.Delimiter, "\r\n\uFFFD") {
+			return fmt.Errorf("invalid delimiter: '%s'. delimiter must be a single character other than a line break", o.Delimiter)
+		}
+	}
+	if o.Quote != "" {
+		if len(o.Quote) != 1 || o.Quote[0] >= utf8.RuneSelf || strings.ContainsAny(o.Quote, "\r\n") {
+			return fmt.Errorf("invalid quote: '%s'. quote must be a single ascii character other than a line break", o.Quote)
+		}
+		if o.Quote == o.Delimiter || o.Quote == o.Comment || o.Delimiter == "" && o.Quote == "," {
+			return fmt.Errorf("invalid quote: '%s'. quote must differ from delimiter & comment characters", o.Quote)
+		}
+	}
+	if o.Comment != "" {
+		if utf8.RuneCountInString(o.Comment) != 1 || strings.ContainsAny(o.Comment, "\r\n\uFFFD") {
+			return fmt.Errorf("invalid comment: '%s'. comment must be a single character other than a line break", o.Comment)
+		}
+		if o.Comment == o.Delimiter || o.Delimiter == "" && o.Comment == "," {
+			return fmt.Errorf("invalid comment: '%s'. comment must differ from the delimiter", o.Comment)
+		}
+	}
+	if o.LineTerminator != "" && o.LineTerminator != "\n" && o.LineTerminator != "\r\n" {
+		return fmt.Errorf("invalid lineTerminator: %q. lineTerminator must be either \"\\n\" or \"\\r\\n\"", o.LineTerminator)
+	}
+	return nil
 }
 
 // Format announces the CSV Data Format for the FormatConfig interface
@@ -61,9 +165,28 @@ func (o *CSVOptions) Map() map[string]interface{} {
 	if o == nil {
 		return nil
 	}
-	return map[string]interface{}{
+	m := map[string]interface{}{
 		"headerRow": o.HeaderRow,
 	}
+	if o.Delimiter != "" {
+		m["delimiter"] = o.Delimiter
+	}
+	if o.Quote != "" {
+		m["quote"] = o.Quote
+	}
+	if o.Comment != "" {
+		m["comment"] = o.Comment
+	}
+	if o.LazyQuotes {
+		m["lazyQuotes"] = true
+	}
+	if o.TrimLeadingSpace {
+		m["trimLeadingSpace"] = true
+	}
+	if o.LineTerminator != "" {
+		m["lineTerminator"] = o.LineTerminator
+	}
+	return m
 }
 
 // NewJSONOptions creates a JSONOptions pointer from a map
@@ -79,6 +202,20 @@ func NewJSONOptions(opts map[string]interface{}) (FormatConfig, error) {
 			return nil, fmt.Errorf("invalid arrayEntries value: %s", opts["arrayEntries"])
 		}
 	}
+	if opts["disallowMissingKeys"] != nil {
+		if disallowMissingKeys, ok := opts["disallowMissingKeys"].(bool); ok {
+			o.DisallowMissingKeys = disallowMissingKeys
+		} else {
+			return nil, fmt.Errorf("invalid disallowMissingKeys value: %v", opts["disallowMissingKeys"])
+		}
+	}
+	if opts["disallowExtraKeys"] != nil {
+		if disallowExtraKeys, ok := opts["disallowExtraKeys"].(bool); ok {
+			o.DisallowExtraKeys = disallowExtraKeys
+		} else {
+			return nil, fmt.Errorf("invalid disallowExtraKeys value: %v", opts["disallowExtraKeys"])
+		}
+	}
 	return o, nil
 }
 
@@ -87,7 +224,17 @@ func NewJSONOptions(opts map[string]interface{}) (FormatConfig, error) {
 // the JSON datatype from the github.com/qri-io/dataset/datatypes
 // package
 type JSONOptions struct {
+	// ArrayEntries specifies weather entries are written as arrays of
+	// values instead of objects. entries are read by position when
+	// they're arrays, and by key when they're objects
 	ArrayEntries bool `json:"arrayEntries"`
+	// DisallowMissingKeys makes reading an entry that lacks a value for
+	// any schema field an error. By default missing values are read as
+	// empty cells
+	DisallowMissingKeys bool `json:"disallowMissingKeys,omitempty"`
+	// DisallowExtraKeys makes reading an entry with values that don't
+	// map to a schema field an error. By default extra values are ignored
+	DisallowExtraKeys bool `json:"disallowExtraKeys,omitempty"`
 }
 
 // Format announces the JSON Data Format for the FormatConfig interface
@@ -100,7 +247,457 @@ func (o *JSONOptions) Map() map[string]interface{} {
 	if o == nil {
 		return nil
 	}
-	return map[string]interface{}{
+	m := map[string]interface{}{
+		"arrayEntries": o.ArrayEntries,
+	}
+	if o.DisallowMissingKeys {
+		m["disallowMissingKeys"] = true
+	}
+	if o.DisallowExtraKeys {
+		m["disallowExtraKeys"] = true
+	}
+	return m
+}
+
+// NewNDJSONOptions creates a NDJSONOptions pointer from a map
+func NewNDJSONOptions(opts map[string]interface{}) (FormatConfig, error) {
+	o := &NDJSONOptions{}
+	if opts == nil {
+		return o, nil
+	}
+	if opts["arrayEntries"] != nil {
+		if arrayEntries, ok := opts["arrayEntries"].(bool); ok {
+			o.ArrayEntries = arrayEntries
+		} else {
+			return nil, fmt.Errorf("invalid arrayEntries value: %s", opts["arrayEntries"])
+		}
+	}
+	if opts["disallowMissingKeys"] != nil {
+		if disallowMissingKeys, ok := opts["disallowMissingKeys"].(bool); ok {
+			o.DisallowMissingKeys = disallowMissingKeys
+		} else {
+			return nil, fmt.Errorf("invalid disallowMissingKeys value: %v", opts["disallowMissingKeys"])
+		}
+	}
+	if opts["disallowExtraKeys"] != nil {
+		if disallowExtraKeys, ok := opts["disallowExtraKeys"].(bool); ok {
+			o.DisallowExtraKeys = disallowExtraKeys
+		} else {
+			return nil, fmt.Errorf("invalid disallowExtraKeys value: %v", opts["disallowExtraKeys"])
+		}
+	}
+	return o, nil
+}
+
+// NDJSONOptions specifies configuration details for newline-delimited
+// json files
+type NDJSONOptions struct {
+	// ArrayEntries specifies weather each line is an array of values
+	// instead of an object
+	ArrayEntries bool `json:"arrayEntries"`
+	// DisallowMissingKeys makes reading a line that lacks a value for
+	// any schema field an error
+	DisallowMissingKeys bool `json:"disallowMissingKeys,omitempty"`
+	// DisallowExtraKeys makes reading a line with values that don't
+	// map to a schema field an error
+	DisallowExtraKeys bool `json:"disallowExtraKeys,omitempty"`
+}
+
+// Format announces the NDJSON Data Format for the FormatConfig interface
+func (*NDJSONOptions) Format() DataFormat {
+	return NDJSONDataFormat
+}
+
+// Map returns a map[string]interface representation of the configuration
+func (o *NDJSONOptions) Map() map[string]interface{} {
+	if o == nil {
+		return nil
+	}
+	m := map[string]interface{}{
 		"arrayEntries": o.ArrayEntries,
 	}
+	if o.DisallowMissingKeys {
+		m["disallowMissingKeys"] = true
+	}
+	if o.DisallowExtraKeys {
+		m["disallowExtraKeys"] = true
+	}
+	return m
+}
+
+// NewXLSOptions creates a XLSOptions pointer from a map
+func NewXLSOptions(opts map[string]interface{}) (FormatConfig, error) {
+	o := &XLSOptions{}
+	if opts == nil {
+		return o, nil
+	}
+	if opts["sheetName"] != nil {
+		if sheetName, ok := opts["sheetName"].(string); ok {
+			o.SheetName = sheetName
+		} else {
+			return nil, fmt.Errorf("invalid sheetName value: %v", opts["sheetName"])
+		}
+	}
+	if opts["sheetIndex"] != nil {
+		// numbers decoded from json arrive as float64
+		switch sheetIndex := opts["sheetIndex"].(type) {
+		case int:
+			o.SheetIndex = sheetIndex
+		case float64:
+			o.SheetIndex = int(sheetIndex)
+		default:
+			return nil, fmt.Errorf("invalid sheetIndex value: %v", opts["sheetIndex"])
+		}
+	}
+	if opts["headerRow"] != nil {
+		if headerRow, ok := opts["headerRow"].(bool); ok {
+			o.HeaderRow = headerRow
+		} else {
+			return nil, fmt.Errorf("invalid headerRow value: %v", opts["headerRow"])
+		}
+	}
+	if opts["cellRange"] != nil {
+		if cellRange, ok := opts["cellRange"].(string); ok {
+			o.CellRange = cellRange
+		} else {
+			return nil, fmt.Errorf("invalid cellRange value: %v", opts["cellRange"])
+		}
+	}
+	return o, nil
+}
+
+// XLSOptions specifies configuration details for excel workbooks
+type XLSOptions struct {
+	// SheetName selects the worksheet to read by name, taking
+	// precedence over SheetIndex when set
+	SheetName string `json:"sheetName,omitempty"`
+	// SheetIndex selects the worksheet to read by it's zero-based
+	// position in the workbook
+	SheetIndex int `json:"sheetIndex,omitempty"`
+	// HeaderRow specifies weather the first row of the selected
+	// range contains field names
+	HeaderRow bool `json:"headerRow"`
+	// CellRange limits reading to a rectangle of cells in A1 notation,
+	// eg: "B2:F100". A single cell like "B2" reads from that cell onward
+	CellRange string `json:"cellRange,omitempty"`
+}
+
+// Format announces the XLS Data Format for the FormatConfig interface
+func (*XLSOptions) Format() DataFormat {
+	return XLSDataFormat
+}
+
+// Map returns a map[string]interface representation of the configuration
+func (o *XLSOptions) Map() map[string]interface{} {
+	if o == nil {
+		return nil
+	}
+	m := map[string]interface{}{
+		"headerRow": o.HeaderRow,
+	}
+	if o.SheetName != "" {
+		m["sheetName"] = o.SheetName
+	}
+	if o.SheetIndex != 0 {
+		m["sheetIndex"] = o.SheetIndex
+	}
+	if o.CellRange != "" {
+		m["cellRange"] = o.CellRange
+	}
+	return m
+}
+
+// NewXMLOptions creates a XMLOptions pointer from a map
+func NewXMLOptions(opts map[string]interface{}) (FormatConfig, error) {
+	o := &XMLOptions{}
+	if opts == nil {
+		return o, nil
+	}
+	if opts["rowElement"] != nil {
+		if rowElement, ok := opts["rowElement"].(string); ok {
+			o.RowElement = rowElement
+		} else {
+			return nil, fmt.Errorf("invalid rowElement value: %v", opts["rowElement"])
+		}
+	}
+	if opts["attributeFields"] != nil {
+		if attributeFields, ok := opts["attributeFields"].(bool); ok {
+			o.AttributeFields = attributeFields
+		} else {
+			return nil, fmt.Errorf("invalid attributeFields value: %v", opts["attributeFields"])
+		}
+	}
+	return o, nil
+}
+
+// XMLOptions specifies configuration details for xml documents
+type XMLOptions struct {
+	// RowElement is a slash-separated path of element names that
+	// identifies row elements, eg: "catalog/book" matches any "book"
+	// element that is a direct child of a "catalog" element. A leading
+	// slash anchors the path to the document root.
+	// When empty, each child of the document root is a row
+	RowElement string `json:"rowElement,omitempty"`
+	// AttributeFields maps fields to attributes of the row element
+	// instead of child elements. When reading, values from both
+	// attributes and child elements are accepted, with AttributeFields
+	// deciding which wins if a name is used for both
+	AttributeFields bool `json:"attributeFields"`
+}
+
+// Format announces the XML Data Format for the FormatConfig interface
+func (*XMLOptions) Format() DataFormat {
+	return XMLDataFormat
+}
+
+// Map returns a map[string]interface representation of the configuration
+func (o *XMLOptions) Map() map[string]interface{} {
+	if o == nil {
+		return nil
+	}
+	m := map[string]interface{}{
+		"attributeFields": o.AttributeFields,
+	}
+	if o.RowElement != "" {
+		m["rowElement"] = o.RowElement
+	}
+	return m
+}
+
+// ParquetCompressionCodecs lists valid values for ParquetOptions.Compression
+var ParquetCompressionCodecs = []string{"uncompressed", "snappy", "gzip", "zstd"}
+
+// NewParquetOptions creates a ParquetOptions pointer from a map
+func NewParquetOptions(opts map[string]interface{}) (FormatConfig, error) {
+	o := &ParquetOptions{}
+	if opts == nil {
+		return o, nil
+	}
+	if opts["compression"] != nil {
+		if compression, ok := opts["compression"].(string); ok {
+			o.Compression = compression
+		} else {
+			return nil, fmt.Errorf("invalid compression value: %v", opts["compression"])
+		}
+	}
+	if opts["rowGroupSize"] != nil {
+		// numbers decoded from json arrive as float64
+		switch rowGroupSize := opts["rowGroupSize"].(type) {
+		case int:
+			o.RowGroupSize = int64(rowGroupSize)
+		case int64:
+			o.RowGroupSize = rowGroupSize
+		case float64:
+			o.RowGroupSize = int64(rowGroupSize)
+		default:
+			return nil, fmt.Errorf("invalid rowGroupSize value: %v", opts["rowGroupSize"])
+		}
+	}
+	if err := o.Validate(); err != nil {
+		return nil, err
+	}
+	return o, nil
+}
+
+// ParquetOptions specifies configuration details for parquet files.
+// options only affect writing, readers take all details from the file
+type ParquetOptions struct {
+	// Compression is the codec used to compress column chunks, one of
+	// ParquetCompressionCodecs. defaults to "snappy"
+	Compression string `json:"compression,omitempty"`
+	// RowGroupSize is the approximate size of each row group in bytes,
+	// defaults to 128 megabytes
+	RowGroupSize int64 `json:"rowGroupSize,omitempty"`
+}
+
+// Validate checks the options for invalid values
+func (o *ParquetOptions) Validate() error {
+	if o.Compression != "" {
+		valid := false
+		for _, c := range ParquetCompressionCodecs {
+			if o.Compression == c {
+				valid = true
+				break
+			}
+		}
+		if !valid {
+			return fmt.Errorf("invalid compression: '%s'. compression must be one of: %s", o.Compression, strings.Join(ParquetCompressionCodecs, ", "))
+		}
+	}
+	if o.RowGroupSize < 0 {
+		return fmt.Errorf("invalid rowGroupSize: %d. rowGroupSize cannot be negative", o.RowGroupSize)
+	}
+	return nil
+}
+
+// Format announces the Parquet Data Format for the FormatConfig interface
+func (*ParquetOptions) Format() DataFormat {
+	return ParquetDataFormat
+}
+
+// Map returns a map[string]interface representation of the configuration
+func (o *ParquetOptions) Map() map[string]interface{} {
+	if o == nil {
+		return nil
+	}
+	m := map[string]interface{}{}
+	if o.Compression != "" {
+		m["compression"] = o.Compression
+	}
+	if o.RowGroupSize != 0 {
+		m["rowGroupSize"] = o.RowGroupSize
+	}
+	return m
+}
+
+// NewGeoJSONOptions creates a GeoJSONOptions pointer from a map
+func NewGeoJSONOptions(opts map[string]interface{}) (FormatConfig, error) {
+	o := &GeoJSONOptions{}
+	if opts == nil {
+		return o, nil
+	}
+	if opts["geometryField"] != nil {
+		if geometryField, ok := opts["geometryField"].(string); ok {
+			o.GeometryField = geometryField
+		} else {
+			return nil, fmt.Errorf("invalid geometryField value: %v", opts["geometryField"])
+		}
+	}
+	return o, nil
+}
+
+// GeoJSONOptions specifies configuration details for GeoJSON feature
+// collections. feature properties map to schema fields by name
+type GeoJSONOptions struct {
+	// GeometryField names the schema field that holds each feature's
+	// geometry. When empty a field named "geometry" is used, falling back
+	// to the first geojson or geopoint field
+	GeometryField string `json:"geometryField,omitempty"`
+}
+
+// Format announces the GeoJSON Data Format for the FormatConfig interface
+func (*GeoJSONOptions) Format() DataFormat {
+	return GeoJSONDataFormat
+}
+
+// Map returns a map[string]interface representation of the configuration
+func (o *GeoJSONOptions) Map() map[string]interface{} {
+	if o == nil {
+		return nil
+	}
+	m := map[string]interface{}{}
+	if o.GeometryField != "" {
+		m["geometryField"] = o.GeometryField
+	}
+	return m
+}
+
+// SQLDialects lists valid values for SQLOptions.Dialect
+var SQLDialects = []string{"postgres", "sqlite"}
+
+// NewSQLOptions creates a SQLOptions pointer from a map
+func NewSQLOptions(opts map[string]interface{}) (FormatConfig, error) {
+	o := &SQLOptions{}
+	if opts == nil {
+		return o, nil
+	}
+	if opts["dialect"] != nil {
+		if dialect, ok := opts["dialect"].(string); ok {
+			o.Dialect = dialect
+		} else {
+			return nil, fmt.Errorf("invalid dialect value: %v", opts["dialect"])
+		}
+	}
+	if opts["table"] != nil {
+		if table, ok := opts["table"].(string); ok {
+			o.Table = table
+		} else {
+			return nil, fmt.Errorf("invalid table value: %v", opts["table"])
+		}
+	}
+	if opts["batchSize"] != nil {
+		// numbers decoded from json arrive as float64
+		switch batchSize := opts["batchSize"].(type) {
+		case int:
+			o.BatchSize = batchSize
+		case int64:
+			o.BatchSize = int(batchSize)
+		case float64:
+			o.BatchSize = int(batchSize)
+		default:
+			return nil, fmt.Errorf("invalid batchSize value: %v", opts["batchSize"])
+		}
+	}
+	if opts["createTable"] != nil {
+		if createTable, ok := opts["createTable"].(bool); ok {
+			o.CreateTable = createTable
+		} else {
+			return nil, fmt.Errorf("invalid createTable value: %v", opts["createTable"])
+		}
+	}
+	if err := o.Validate(); err != nil {
+		return nil, err
+	}
+	return o, nil
+}
+
+// SQLOptions specifies configuration details for writing data as SQL
+// statements
+type SQLOptions struct {
+	// Dialect is the flavour of SQL to write, one of SQLDialects.
+	// defaults to "postgres"
+	Dialect string `json:"dialect,omitempty"`
+	// Table is the name of the table rows are inserted into,
+	// defaults to "data"
+	Table string `json:"table,omitempty"`
+	// BatchSize is the number of rows written by each INSERT statement,
+	// defaults to 100
+	BatchSize int `json:"batchSize,omitempty"`
+	// CreateTable writes a CREATE TABLE statement before any rows
+	CreateTable bool `json:"createTable,omitempty"`
+}
+
+// Validate checks the options for invalid values
+func (o *SQLOptions) Validate() error {
+	if o.Dialect != "" {
+		valid := false
+		for _, d := range SQLDialects {
+			if o.Dialect == d {
+				valid = true
+				break
+			}
+		}
+		if !valid {
+			return fmt.Errorf("invalid dialect: '%s'. dialect must be one of: %s", o.Dialect, strings.Join(SQLDialects, ", "))
+		}
+	}
+	if o.BatchSize < 0 {
+		return fmt.Errorf("invalid batchSize: %d. batchSize cannot be negative", o.BatchSize)
+	}
+	return nil
+}
+
+// Format announces the SQL Data Format for the FormatConfig interface
+func (*SQLOptions) Format() DataFormat {
+	return SQLDataFormat
+}
+
+// Map returns a map[string]interface representation of the configuration
+func (o *SQLOptions) Map() map[string]interface{} {
+	if o == nil {
+		return nil
+	}
+	m := map[string]interface{}{
+		"createTable": o.CreateTable,
+	}
+	if o.Dialect != "" {
+		m["dialect"] = o.Dialect
+	}
+	if o.Table != "" {
+		m["table"] = o.Table
+	}
+	if o.BatchSize != 0 {
+		m["batchSize"] = o.BatchSize
+	}
+	return m
 }
@@ -13,8 +13,20 @@ func TestParseFormatConfigMap(t *testing.T) {
 		err  error
 	}{
 		{CSVDataFormat, map[string]interface{}{}, &CSVOptions{}, nil},
+		{CSVDataFormat, map[string]interface{}{"delimiter": "\t", "quote": "'", "comment": "#", "lazyQuotes": true, "trimLeadingSpace": true, "lineTerminator": "\r\n"}, &CSVOptions{Delimiter: "\t", Quote: "'", Comment: "#", LazyQuotes: true, TrimLeadingSpace: true, LineTerminator: "\r\n"}, nil},
 		{JSONDataFormat, map[string]interface{}{}, &JSONOptions{}, nil},
 		{JSONDataFormat, map[string]interface{}{"arrayEntries": true}, &JSONOptions{ArrayEntries: true}, nil},
+		{JSONDataFormat, map[string]interface{}{"disallowMissingKeys": true, "disallowExtraKeys": true}, &JSONOptions{DisallowMissingKeys: true, DisallowExtraKeys: true}, nil},
+		{XLSDataFormat, map[string]interface{}{}, &XLSOptions{}, nil},
+		{NDJSONDataFormat, map[string]interface{}{"arrayEntries": true}, &NDJSONOptions{ArrayEntries: true}, nil},
+		{XMLDataFormat, map[string]interface{}{"rowElement": "catalog/book", "attributeFields": true}, &XMLOptions{RowElement: "catalog/book", AttributeFields: true}, nil},
+		{XLSDataFormat, map[string]interface{}{"sheetName": "data", "sheetIndex": float64(2), "headerRow": true, "cellRange": "B2:D20"}, &XLSOptions{SheetName: "data", SheetIndex: 2, HeaderRow: true, CellRange: "B2:D20"}, nil},
+		{ParquetDataFormat, map[string]interface{}{}, &ParquetOptions{}, nil},
+		{ParquetDataFormat, map[string]interface{}{"compression": "gzip", "rowGroupSize": float64(1024)}, &ParquetOptions{Compression: "gzip", RowGroupSize: 1024}, nil},
+		{GeoJSONDataFormat, map[string]interface{}{}, &GeoJSONOptions{}, nil},
+		{GeoJSONDataFormat, map[string]interface{}{"geometryField": "shape"}, &GeoJSONOptions{GeometryField: "shape"}, nil},
+		{SQLDataFormat, map[string]interface{}{}, &SQLOptions{}, nil},
+		{SQLDataFormat, map[string]interface{}{"dialect": "sqlite", "table": "cities", "batchSize": float64(50), "createTable": true}, &SQLOptions{Dialect: "sqlite", Table: "cities", BatchSize: 50, CreateTable: true}, nil},
 	}
 
 	for i, c := range cases {
@@ -30,6 +42,80 @@ func TestParseFormatConfigMap(t *testing.T) {
 	}
 }
 
+func TestCSVOptionsValidate(t *testing.T) {
+	cases := []struct {
+		opts *CSVOptions
+		err  string
+	}{
+		{&CSVOptions{}, ""},
+		{&CSVOptions{Delimiter: ";", Quote: "'", Comment: "#", LineTerminator: "\r\n"}, ""},
+		{&CSVOptions{Delimiter: "|"}, ""},
+		{&CSVOptions{Delimiter: "ab"}, "invalid delimiter: 'ab'. delimiter must be a single character other than a line break"},
+		{&CSVOptions{Delimiter: "\n"}, "invalid delimiter: '\n'. delimiter must be a single character other than a line break"},
+		{&CSVOptions{Quote: "«"}, "invalid quote: '«'. quote must be a single ascii character other than a line break"},
+		{&CSVOptions{Quote: ","}, "invalid quote: ','. quote must differ from delimiter & comment characters"},
+		{&CSVOptions{Delimiter: ";", Quote: ";"}, "invalid quote: ';'. quote must differ from delimiter & comment characters"},
+		{&CSVOptions{Comment: ","}, "invalid comment: ','. comment must differ from the delimiter"},
+		{&CSVOptions{LineTerminator: "\r"}, `invalid lineTerminator: "\r". lineTerminator must be either "\n" or "\r\n"`},
+	}
+
+	for i, c := range cases {
+		err := c.opts.Validate()
+		if !(err == nil && c.err == "" || err != nil && err.Error() == c.err) {
+			t.Errorf("case %d error mismatch. expected: '%s', got: '%s'", i, c.err, err)
+		}
+	}
+}
+
+func TestCSVOptionsMap(t *testing.T) {
+	opts := &CSVOptions{HeaderRow: true, Delimiter: "\t", Quote: "'", Comment: "#", LazyQuotes: true, TrimLeadingSpace: true, LineTerminator: "\r\n"}
+	got, err := NewCSVOptions(opts.Map())
+	if err != nil {
+		t.Fatalf("error creating options from map: %s", err.Error())
+	}
+	if *got.(*CSVOptions) != *opts {
+		t.Errorf("round trip mismatch. expected: %v, got: %v", opts, got)
+	}
+}
+
+func TestParquetOptionsValidate(t *testing.T) {
+	cases := []struct {
+		opts *ParquetOptions
+		err  string
+	}{
+		{&ParquetOptions{}, ""},
+		{&ParquetOptions{Compression: "zstd", RowGroupSize: 1024}, ""},
+		{&ParquetOptions{Compression: "lzo"}, "invalid compression: 'lzo'. compression must be one of: uncompressed, snappy, gzip, zstd"},
+		{&ParquetOptions{RowGroupSize: -1}, "invalid rowGroupSize: -1. rowGroupSize cannot be negative"},
+	}
+
+	for i, c := range cases {
+		err := c.opts.Validate()
+		if !(err == nil && c.err == "" || err != nil && err.Error() == c.err) {
+			t.Errorf("case %d error mismatch. expected: '%s', got: '%s'", i, c.err, err)
+		}
+	}
+}
+
+func TestSQLOptionsValidate(t *testing.T) {
+	cases := []struct {
+		opts *SQLOptions
+		err  string
+	}{
+		{&SQLOptions{}, ""},
+		{&SQLOptions{Dialect: "sqlite", BatchSize: 10}, ""},
+		{&SQLOptions{Dialect: "oracle"}, "invalid dialect: 'oracle'. dialect must be one of: postgres, sqlite"},
+		{&SQLOptions{BatchSize: -1}, "invalid batchSize: -1. batchSize cannot be negative"},
+	}
+
+	for i, c := range cases {
+		err := c.opts.Validate()
+		if !(err == nil && c.err == "" || err != nil && err.Error() == c.err) {
+			t.Errorf("case %d error mismatch. expected: '%s', got: '%s'", i, c.err, err)
+		}
+	}
+}
+
 func CompareFormatConfigs(a, b FormatConfig) error {
 	if a == nil && b == nil {
 		return nil
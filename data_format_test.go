@@ -16,6 +16,10 @@ func TestDataFormatString(t *testing.T) {
 		{XMLDataFormat, "xml"},
 		{XLSDataFormat, "xls"},
 		{CDXJDataFormat, "cdxj"},
+		{NDJSONDataFormat, "ndjson"},
+		{ParquetDataFormat, "parquet"},
+		{GeoJSONDataFormat, "geojson"},
+		{SQLDataFormat, "sql"},
 	}
 
 	for i, c := range cases {
@@ -41,8 +45,20 @@ func TestParseDataFormatString(t *testing.T) {
 		{"xml", XMLDataFormat, ""},
 		{".xls", XLSDataFormat, ""},
 		{"xls", XLSDataFormat, ""},
+		{".xlsx", XLSDataFormat, ""},
+		{"xlsx", XLSDataFormat, ""},
 		{".cdxj", CDXJDataFormat, ""},
 		{"cdxj", CDXJDataFormat, ""},
+		{".ndjson", NDJSONDataFormat, ""},
+		{"ndjson", NDJSONDataFormat, ""},
+		{".jsonl", NDJSONDataFormat, ""},
+		{"jsonl", NDJSONDataFormat, ""},
+		{".parquet", ParquetDataFormat, ""},
+		{"parquet", ParquetDataFormat, ""},
+		{".geojson", GeoJSONDataFormat, ""},
+		{"geojson", GeoJSONDataFormat, ""},
+		{".sql", SQLDataFormat, ""},
+		{"sql", SQLDataFormat, ""},
 	}
 
 	for i, c := range cases {
@@ -69,6 +85,7 @@ func TestDataFormatMarshalJSON(t *testing.T) {
 		{XMLDataFormat, []byte(`"xml"`), ""},
 		{XLSDataFormat, []byte(`"xls"`), ""},
 		{CDXJDataFormat, []byte(`"cdxj"`), ""},
+		{NDJSONDataFormat, []byte(`"ndjson"`), ""},
 	}
 	for i, c := range cases {
 		got, err := c.format.MarshalJSON()
@@ -94,6 +111,8 @@ func TestDataFormatUnmarshalJSON(t *testing.T) {
 		{[]byte(`"xml"`), XMLDataFormat, ""},
 		{[]byte(`"xls"`), XLSDataFormat, ""},
 		{[]byte(`"cdxj"`), CDXJDataFormat, ""},
+		{[]byte(`"ndjson"`), NDJSONDataFormat, ""},
+		{[]byte(`"jsonl"`), NDJSONDataFormat, ""},
 	}
 
 	for i, c := range cases {
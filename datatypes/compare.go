@@ -2,34 +2,143 @@ package datatypes
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
+	"math"
+	"math/big"
+	"sort"
+	"time"
 )
 
-// CompareTypeBytes compares two byte slices with a known type
-// real on the real, this is a bit of a work in progress
-// TODO - up tests
-func CompareTypeBytes(a, b []byte, t Type) (int, error) {
-	if len(a) == 0 && len(b) > 0 {
-		return -1, nil
-	} else if len(b) == 0 && len(a) > 0 {
-		return 1, nil
-	} else if len(b) == 0 && len(a) == 0 {
-		return 0, nil
+// NullOrder specifies where null (empty) values sort relative to other values
+type NullOrder int
+
+const (
+	// NullsLow sorts nulls as the lowest of all values, placing them first in
+	// ascending orders & last in descending orders. This is the default
+	NullsLow NullOrder = iota
+	// NullsFirst places nulls first regardless of sort direction
+	NullsFirst
+	// NullsLast places nulls last regardless of sort direction
+	NullsLast
+)
+
+// Comparer gives a total ordering of raw values of a datatype. values of
+// Any type are ordered by their detected type first, then by value
+type Comparer struct {
+	// Type is the datatype of compared values
+	Type Type
+	// Nulls specifies where empty values sort. Comparers only know about
+	// ascending order, so NullsLow & NullsFirst behave the same
+	Nulls NullOrder
+	// Number is the format of integer, float & decimal values
+	Number NumberFormat
+	// Missing lists raw values that are null in addition to empty values
+	Missing []string
+
+	// time format for date, time & datetime values, nil for any format
+	tf *TimeFormat
+}
+
+// NewComparer creates a Comparer for values of type t. format is the field
+// format used to read date, time & datetime values, and is ignored for all
+// other types
+func NewComparer(t Type, format string, nulls NullOrder) (*Comparer, error) {
+	c := &Comparer{Type: t, Nulls: nulls}
+	if (t == Date || t == Time || t == DateTime) && format != "" {
+		tf, err := ParseTimeFormat(t, format)
+		if err != nil {
+			return nil, err
+		}
+		c.tf = &tf
 	}
+	return c, nil
+}
 
-	switch t {
+// Compare returns -1 if a sorts before b, 1 if a sorts after b, and 0 if
+// the two are equal. values that can't be read as the comparer's type are
+// an error
+func (c *Comparer) Compare(a, b []byte) (int, error) {
+	if aNull, bNull := c.isNull(a), c.isNull(b); aNull || bNull {
+		nulls := -1
+		if c.Nulls == NullsLast {
+			nulls = 1
+		}
+		switch {
+		case aNull && bNull:
+			return 0, nil
+		case aNull:
+			return nulls, nil
+		default:
+			return -nulls, nil
+		}
+	}
+
+	if (c.Type == Integer || c.Type == Float || c.Type == Decimal) && !c.Number.IsPlain() {
+		var err error
+		if a, err = c.Number.Normalize(a); err != nil {
+			return 0, err
+		}
+		if b, err = c.Number.Normalize(b); err != nil {
+			return 0, err
+		}
+	}
+
+	switch c.Type {
 	case String:
 		return bytes.Compare(a, b), nil
 	case Integer:
 		return CompareIntegerBytes(a, b)
 	case Float:
 		return CompareFloatBytes(a, b)
+	case Decimal:
+		return CompareDecimalBytes(a, b)
+	case Boolean:
+		return CompareBooleanBytes(a, b)
+	case Date, Time, DateTime:
+		tf := c.tf
+		if tf == nil {
+			tf = anyTimeFormat(c.Type)
+		}
+		return tf.CompareBytes(a, b)
+	case Duration:
+		return CompareDurationBytes(a, b)
+	case URL:
+		return CompareURLBytes(a, b)
+	case JSON, Array, Object:
+		return CompareJSONBytes(a, b)
+	case GeoPoint:
+		return CompareGeoPointBytes(a, b)
+	case GeoJSON:
+		return CompareGeoJSONBytes(a, b)
+	case Any:
+		return CompareAnyBytes(a, b)
 	default:
-		// TODO - other types
 		return 0, fmt.Errorf("invalid type comparison")
 	}
 }
 
+// isNull reports weather a raw value is empty or one of the comparer's
+// missing values
+func (c *Comparer) isNull(value []byte) bool {
+	if len(value) == 0 {
+		return true
+	}
+	for _, m := range c.Missing {
+		if string(value) == m {
+			return true
+		}
+	}
+	return false
+}
+
+// CompareTypeBytes compares two byte slices with a known type. empty
+// values & any missing values are null, and sort before all other values
+func CompareTypeBytes(a, b []byte, t Type, missing ...string) (int, error) {
+	c := &Comparer{Type: t, Missing: missing}
+	return c.Compare(a, b)
+}
+
 // CompareIntegerBytes compares two byte slices of interger data
 func CompareIntegerBytes(a, b []byte) (int, error) {
 	at, err := ParseInteger(a)
@@ -48,7 +157,8 @@ func CompareIntegerBytes(a, b []byte) (int, error) {
 	return -1, nil
 }
 
-// CompareFloatBytes compares two byte slices of float data
+// CompareFloatBytes compares two byte slices of float data. NaN values
+// sort before all other numbers
 func CompareFloatBytes(a, b []byte) (int, error) {
 	at, err := ParseFloat(a)
 	if err != nil {
@@ -58,10 +168,295 @@ func CompareFloatBytes(a, b []byte) (int, error) {
 	if err != nil {
 		return 0, err
 	}
-	if at > bt {
+	return compareFloats(at, bt), nil
+}
+
+func compareFloats(a, b float64) int {
+	switch {
+	case math.IsNaN(a) && math.IsNaN(b):
+		return 0
+	case math.IsNaN(a):
+		return -1
+	case math.IsNaN(b):
+		return 1
+	case a > b:
+		return 1
+	case a == b:
+		return 0
+	}
+	return -1
+}
+
+// CompareDecimalBytes compares two byte slices of decimal data
+func CompareDecimalBytes(a, b []byte) (int, error) {
+	at, err := ParseDecimal(a)
+	if err != nil {
+		return 0, err
+	}
+	bt, err := ParseDecimal(b)
+	if err != nil {
+		return 0, err
+	}
+	return at.Cmp(bt), nil
+}
+
+// CompareBooleanBytes compares two byte slices of boolean data, with
+// false sorting before true
+func CompareBooleanBytes(a, b []byte) (int, error) {
+	at, err := ParseBoolean(a)
+	if err != nil {
+		return 0, err
+	}
+	bt, err := ParseBoolean(b)
+	if err != nil {
+		return 0, err
+	}
+	return compareBools(at, bt), nil
+}
+
+func compareBools(a, b bool) int {
+	switch {
+	case a == b:
+		return 0
+	case a:
+		return 1
+	}
+	return -1
+}
+
+// CompareBytes compares two byte slices of time data read with the format
+func (f TimeFormat) CompareBytes(a, b []byte) (int, error) {
+	at, err := f.ParseValue(a)
+	if err != nil {
+		return 0, err
+	}
+	bt, err := f.ParseValue(b)
+	if err != nil {
+		return 0, err
+	}
+	return compareTimes(at, bt), nil
+}
+
+func compareTimes(a, b time.Time) int {
+	switch {
+	case a.Before(b):
+		return -1
+	case a.After(b):
+		return 1
+	}
+	return 0
+}
+
+// anyTimeFormat gives the format that reads any known value of a time type
+func anyTimeFormat(t Type) *TimeFormat {
+	switch t {
+	case Date:
+		return &anyDate
+	case Time:
+		return &anyTime
+	}
+	return &anyDateTime
+}
+
+// CompareDurationBytes compares two byte slices of duration data
+func CompareDurationBytes(a, b []byte) (int, error) {
+	at, err := ParseDuration(a)
+	if err != nil {
+		return 0, err
+	}
+	bt, err := ParseDuration(b)
+	if err != nil {
+		return 0, err
+	}
+	switch {
+	case at < bt:
+		return -1, nil
+	case at > bt:
 		return 1, nil
-	} else if at == bt {
-		return 0, nil
 	}
-	return -1, nil
+	return 0, nil
+}
+
+// CompareURLBytes compares two byte slices of url data by their
+// normalized string form
+func CompareURLBytes(a, b []byte) (int, error) {
+	au, err := ParseURL(a)
+	if err != nil {
+		return 0, err
+	}
+	bu, err := ParseURL(b)
+	if err != nil {
+		return 0, err
+	}
+	return bytes.Compare([]byte(au.String()), []byte(bu.String())), nil
+}
+
+// CompareGeoPointBytes compares two byte slices of geopoint data by
+// latitude, then longitude
+func CompareGeoPointBytes(a, b []byte) (int, error) {
+	ap, err := ParseGeoPoint(a)
+	if err != nil {
+		return 0, err
+	}
+	bp, err := ParseGeoPoint(b)
+	if err != nil {
+		return 0, err
+	}
+	if c := compareFloats(ap.Lat, bp.Lat); c != 0 {
+		return c, nil
+	}
+	return compareFloats(ap.Lon, bp.Lon), nil
+}
+
+// CompareGeoJSONBytes compares two byte slices of geojson data, ordering
+// geometries as json values
+func CompareGeoJSONBytes(a, b []byte) (int, error) {
+	if _, err := ParseGeoJSON(a); err != nil {
+		return 0, err
+	}
+	if _, err := ParseGeoJSON(b); err != nil {
+		return 0, err
+	}
+	return CompareJSONBytes(a, b)
+}
+
+// CompareJSONBytes compares two byte slices of json data. values of
+// different kinds sort null, boolean, number, string, array then object.
+// arrays compare element-wise, objects compare by sorted keys then values
+func CompareJSONBytes(a, b []byte) (int, error) {
+	var av, bv interface{}
+	if err := json.Unmarshal(a, &av); err != nil {
+		return 0, fmt.Errorf("invalid json: %s", err.Error())
+	}
+	if err := json.Unmarshal(b, &bv); err != nil {
+		return 0, fmt.Errorf("invalid json: %s", err.Error())
+	}
+	return compareJSONValues(av, bv), nil
+}
+
+// jsonRank gives the sort rank of a decoded json value's kind
+func jsonRank(v interface{}) int {
+	switch v.(type) {
+	case nil:
+		return 0
+	case bool:
+		return 1
+	case float64:
+		return 2
+	case string:
+		return 3
+	case []interface{}:
+		return 4
+	}
+	return 5
+}
+
+func compareJSONValues(a, b interface{}) int {
+	ar, br := jsonRank(a), jsonRank(b)
+	if ar != br {
+		if ar < br {
+			return -1
+		}
+		return 1
+	}
+
+	switch av := a.(type) {
+	case bool:
+		return compareBools(av, b.(bool))
+	case float64:
+		return compareFloats(av, b.(float64))
+	case string:
+		return bytes.Compare([]byte(av), []byte(b.(string)))
+	case []interface{}:
+		bv := b.([]interface{})
+		for i := 0; i < len(av) && i < len(bv); i++ {
+			if c := compareJSONValues(av[i], bv[i]); c != 0 {
+				return c
+			}
+		}
+		return compareInts(len(av), len(bv))
+	case map[string]interface{}:
+		bv := b.(map[string]interface{})
+		ak, bk := sortedKeys(av), sortedKeys(bv)
+		for i := 0; i < len(ak) && i < len(bk); i++ {
+			if ak[i] != bk[i] {
+				if ak[i] < bk[i] {
+					return -1
+				}
+				return 1
+			}
+			if c := compareJSONValues(av[ak[i]], bv[bk[i]]); c != 0 {
+				return c
+			}
+		}
+		return compareInts(len(ak), len(bk))
+	}
+	return 0
+}
+
+func compareInts(a, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// CompareAnyBytes compares two byte slices of any datatype. each value's
+// type is detected with ParseDatatype. values of the same type compare
+// by value, as do numbers of any numeric type & dates with datetimes.
+// otherwise values are ordered by datatype
+func CompareAnyBytes(a, b []byte) (int, error) {
+	at, bt := ParseDatatype(a), ParseDatatype(b)
+	ac, bc := anyClass(at), anyClass(bt)
+	if ac != bc {
+		return compareInts(int(ac), int(bc)), nil
+	}
+
+	switch {
+	case at == bt:
+		return CompareTypeBytes(a, b, at)
+	case ac == Decimal:
+		// compare exactly where possible, falling back to floats for
+		// values like NaN & Inf that have no exact representation
+		ar, aok := new(big.Rat).SetString(string(a))
+		br, bok := new(big.Rat).SetString(string(b))
+		if aok && bok {
+			return ar.Cmp(br), nil
+		}
+		return CompareFloatBytes(a, b)
+	case ac == DateTime:
+		av, err := at.Parse(a)
+		if err != nil {
+			return 0, err
+		}
+		bv, err := bt.Parse(b)
+		if err != nil {
+			return 0, err
+		}
+		return compareTimes(av.(time.Time), bv.(time.Time)), nil
+	}
+	return CompareTypeBytes(a, b, at)
+}
+
+// anyClass groups types that compare by value when mixed in Any fields
+func anyClass(t Type) Type {
+	switch t {
+	case Integer, Float, Decimal:
+		return Decimal
+	case Date, DateTime:
+		return DateTime
+	}
+	return t
 }
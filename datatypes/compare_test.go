@@ -19,12 +19,47 @@ func TestCompareTypeBytes(t *testing.T) {
 		{"bar", "foo", String, -1, ""},
 		{"0", "0", Float, 0, ""},
 		{"0", "0", Integer, 0, ""},
+		{"0", "0", Decimal, 0, ""},
+		{"9007199254740993", "9007199254740992", Decimal, 1, ""},
+		{"", "true", Boolean, -1, ""},
+		{"false", "true", Boolean, -1, ""},
+		{"true", "true", Boolean, 0, ""},
+		{"true", "0", Boolean, 1, ""},
+		{"yes", "true", Boolean, 0, "strconv.ParseBool: parsing \"yes\": invalid syntax"},
+		{"2017-01-02", "2017-01-10", Date, -1, ""},
+		{"01/10/2017", "2017-01-02", Date, 1, ""},
+		{"2017-01-02", "soon", Date, 0, "invalid date: soon"},
+		{"13:00", "9:30 AM", Time, 1, ""},
+		{"2017-01-02T10:00:00Z", "2017-01-02T11:00:00+02:00", DateTime, 1, ""},
+		{"PT90M", "PT1H", Duration, 1, ""},
+		{"P1D", "PT24H", Duration, 0, ""},
+		{"http://a.com", "http://b.com", URL, -1, ""},
+		{"http://a.com/x", "http://a.com/x", URL, 0, ""},
+		{"nope", "http://a.com", URL, 0, "invalid url: nope"},
+		{"[1,2]", "[1,2,0]", JSON, -1, ""},
+		{`{"a":2}`, `{"a":1,"b":1}`, JSON, 1, ""},
+		{`{"b":1}`, `{"a":1,"b":1}`, JSON, 1, ""},
+		{`"a"`, "1", JSON, 1, ""},
+		{"[1,", "[]", JSON, 0, "invalid json: unexpected end of JSON input"},
+		{"[[1],[2]]", "[[1],[1,5]]", Array, 1, ""},
+		{`{"a":[true]}`, `{"a":[false]}`, Object, 1, ""},
+		{"10,20", "10,-20", GeoPoint, 1, ""},
+		{"-10,20", "10,-20", GeoPoint, -1, ""},
+		{`{"type":"Point","coordinates":[1,2]}`, `{"type":"Point","coordinates":[1,3]}`, GeoJSON, -1, ""},
+		{"2", "10", Any, -1, ""},
+		{"2.5", "10", Any, -1, ""},
+		{"12345678901234567890", "12345678901234567891", Any, -1, ""},
+		{"NaN", "1", Any, -1, ""},
+		{"b", "a", Any, 1, ""},
+		{"10", "a", Any, 1, ""},
+		{"2017-01-02", "2017-01-01T12:00:00Z", Any, 1, ""},
+		{"true", "false", Any, 1, ""},
 	}
 
 	for i, c := range cases {
 		got, err := CompareTypeBytes([]byte(c.a), []byte(c.b), c.t)
 		if !(err == nil && c.err == "" || err != nil && err.Error() == c.err) {
-			t.Errorf("case %d error mismatch. expected: %d, got: %s", i, c.err, err)
+			t.Errorf("case %d error mismatch. expected: %s, got: %s", i, c.err, err)
 			continue
 		}
 		if got != c.expect {
@@ -34,6 +69,68 @@ func TestCompareTypeBytes(t *testing.T) {
 	}
 }
 
+func TestCompareTypeBytesMissing(t *testing.T) {
+	cases := []struct {
+		a, b   string
+		expect int
+		err    string
+	}{
+		{"NA", "1", -1, ""},
+		{"1", "-", 1, ""},
+		{"NA", "", 0, ""},
+		{"NA", "-", 0, ""},
+		{"n/a", "1", 0, "strconv.ParseInt: parsing \"n/a\": invalid syntax"},
+	}
+
+	for i, c := range cases {
+		got, err := CompareTypeBytes([]byte(c.a), []byte(c.b), Integer, "NA", "-")
+		if !(err == nil && c.err == "" || err != nil && err.Error() == c.err) {
+			t.Errorf("case %d error mismatch. expected: %s, got: %s", i, c.err, err)
+			continue
+		}
+		if got != c.expect {
+			t.Errorf("case %d response mismatch: %d != %d", i, c.expect, got)
+		}
+	}
+}
+
+func TestComparer(t *testing.T) {
+	cases := []struct {
+		t      Type
+		format string
+		nulls  NullOrder
+		a, b   string
+		expect int
+		err    string
+	}{
+		{Integer, "", NullsLow, "", "1", -1, ""},
+		{Integer, "", NullsFirst, "", "1", -1, ""},
+		{Integer, "", NullsLast, "", "1", 1, ""},
+		{Integer, "", NullsLast, "1", "", -1, ""},
+		{Integer, "", NullsLast, "", "", 0, ""},
+		{Date, "%d/%m/%Y", NullsLow, "02/01/2017", "10/01/2017", -1, ""},
+		{Date, "%d/%m/%Y", NullsLow, "02/10/2017", "10/01/2017", 1, ""},
+		{Date, "%d/%m/%Y", NullsLow, "2017-01-02", "10/01/2017", 0, "invalid date: 2017-01-02. expected format '%d/%m/%Y'"},
+		{Date, "%Q", NullsLow, "", "", 0, "invalid time format: '%Q'. unsupported directive '%Q'"},
+		{Unknown, "", NullsLow, "a", "b", 0, "invalid type comparison"},
+	}
+
+	for i, c := range cases {
+		cmp, err := NewComparer(c.t, c.format, c.nulls)
+		if err == nil {
+			var got int
+			got, err = cmp.Compare([]byte(c.a), []byte(c.b))
+			if err == nil && got != c.expect {
+				t.Errorf("case %d response mismatch: %d != %d", i, c.expect, got)
+				continue
+			}
+		}
+		if !(err == nil && c.err == "" || err != nil && err.Error() == c.err) {
+			t.Errorf("case %d error mismatch. expected: %s, got: %s", i, c.err, err)
+		}
+	}
+}
+
 func TestCompareIntegerBytes(t *testing.T) {
 	cases := []struct {
 		a, b   string
@@ -85,3 +182,33 @@ func TestCompareFloatBytes(t *testing.T) {
 		}
 	}
 }
+
+func TestCompareDecimalBytes(t *testing.T) {
+	cases := []struct {
+		a, b   string
+		expect int
+		err    string
+	}{
+		{"0", "", 0, "invalid decimal: "},
+		{"", "0", 0, "invalid decimal: "},
+		{"0", "0.00", 0, ""},
+		{"-1", "0", -1, ""},
+		{"0", "-1", 1, ""},
+		{"1e2", "100", 0, ""},
+		// equal as float64 values
+		{"12345678901234567890.01", "12345678901234567890.02", -1, ""},
+		{"0.30000000000000000001", "0.3", 1, ""},
+	}
+
+	for i, c := range cases {
+		got, err := CompareDecimalBytes([]byte(c.a), []byte(c.b))
+		if !(err == nil && c.err == "" || err != nil && err.Error() == c.err) {
+			t.Errorf("case %d error mismatch. expected: %s, got: %s", i, c.err, err)
+			continue
+		}
+		if got != c.expect {
+			t.Errorf("case %d response mismatch: %d != %d", i, c.expect, got)
+			continue
+		}
+	}
+}
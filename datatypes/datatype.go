@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"math/big"
 	"net/url"
 	"strconv"
 	"time"
@@ -28,29 +29,53 @@ const (
 	Float
 	// Boolean species true/false values
 	Boolean
-	// Date specifies point-in-time values
+	// Date specifies calendar date values
 	Date
 	// URL specifies Universal Resource Locations
 	URL
 	// JSON speficies Javascript Object Notation data
 	JSON
+	// Decimal specifies arbitrary-precision base-10 numbers
+	Decimal
+	// Time specifies time of day values
+	Time
+	// DateTime specifies point-in-time values
+	DateTime
+	// Duration specifies lengths of time
+	Duration
+	// GeoPoint specifies geographic latitude & longitude points
+	GeoPoint
+	// GeoJSON specifies GeoJSON geometry objects
+	GeoJSON
+	// Array specifies lists of values, with an optional schema for elements
+	Array
+	// Object specifies key-value maps, with optional schemas for properties
+	Object
 )
 
 // NumDatatypes is the total count of data types, including unknown type
-const NumDatatypes = 8
+const NumDatatypes = 16
 
 // TypeFromString takes a string & tries to return it's type
 // defaulting to unknown if the type is unrecognized
 func TypeFromString(t string) Type {
 	got, ok := map[string]Type{
-		"any":     Any,
-		"string":  String,
-		"integer": Integer,
-		"float":   Float,
-		"boolean": Boolean,
-		"date":    Date,
-		"url":     URL,
-		"json":    JSON,
+		"any":      Any,
+		"string":   String,
+		"integer":  Integer,
+		"float":    Float,
+		"boolean":  Boolean,
+		"date":     Date,
+		"url":      URL,
+		"json":     JSON,
+		"decimal":  Decimal,
+		"time":     Time,
+		"datetime": DateTime,
+		"duration": Duration,
+		"geopoint": GeoPoint,
+		"geojson":  GeoJSON,
+		"array":    Array,
+		"object":   Object,
 	}[t]
 	if !ok {
 		return Unknown
@@ -69,18 +94,36 @@ func ParseDatatype(value []byte) Type {
 	if _, err = ParseInteger(value); err == nil {
 		return Integer
 	}
+	if isDecimal(value) {
+		return Decimal
+	}
 	if _, err = ParseFloat(value); err == nil {
 		return Float
 	}
 	if _, err = ParseBoolean(value); err == nil {
 		return Boolean
 	}
+	if isGeoJSON(value) {
+		return GeoJSON
+	}
+	if isGeoPoint(value) {
+		return GeoPoint
+	}
 	if _, err = ParseJSON(value); err == nil {
 		return JSON
 	}
+	if _, err = ParseDateTime(value); err == nil {
+		return DateTime
+	}
 	if _, err = ParseDate(value); err == nil {
 		return Date
 	}
+	if _, err = ParseTime(value); err == nil {
+		return Time
+	}
+	if isISODuration(value) {
+		return Duration
+	}
 	// if _, err = ParseURL(value); err == nil {
 	// 	return URL
 	// }
@@ -93,15 +136,23 @@ func ParseDatatype(value []byte) Type {
 // String satsfies the stringer interface
 func (dt Type) String() string {
 	s, ok := map[Type]string{
-		Unknown: "",
-		Any:     "any",
-		String:  "string",
-		Integer: "integer",
-		Float:   "float",
-		Boolean: "boolean",
-		Date:    "date",
-		URL:     "url",
-		JSON:    "json",
+		Unknown:  "",
+		Any:      "any",
+		String:   "string",
+		Integer:  "integer",
+		Float:    "float",
+		Boolean:  "boolean",
+		Date:     "date",
+		URL:      "url",
+		JSON:     "json",
+		Decimal:  "decimal",
+		Time:     "time",
+		DateTime: "datetime",
+		Duration: "duration",
+		GeoPoint: "geopoint",
+		GeoJSON:  "geojson",
+		Array:    "array",
+		Object:   "object",
 	}[dt]
 
 	if !ok {
@@ -133,8 +184,36 @@ func (dt *Type) UnmarshalJSON(data []byte) error {
 	return nil
 }
 
+// ParseOptions configures how Type.Parse reads raw values
+type ParseOptions struct {
+	// Number is the format of integer, float & decimal values. numbers are
+	// normalized to plain numbers before parsing
+	Number NumberFormat
+	// Time is the format of date, time & datetime values, nil to accept
+	// any known format
+	Time *TimeFormat
+}
+
 // Parse turns raw byte slices into data formatted according to the type receiver
-func (dt Type) Parse(value []byte) (parsed interface{}, err error) {
+func (dt Type) Parse(value []byte, options ...func(o *ParseOptions)) (parsed interface{}, err error) {
+	opt := &ParseOptions{}
+	for _, option := range options {
+		option(opt)
+	}
+	if (dt == Integer || dt == Float || dt == Decimal) && !opt.Number.IsPlain() {
+		norm, err := opt.Number.Normalize(value)
+		if err == nil {
+			parsed, err = dt.Parse(norm)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %s", dt.String(), string(value))
+		}
+		return parsed, nil
+	}
+	if opt.Time != nil && opt.Time.Type == dt {
+		return opt.Time.ParseValue(value)
+	}
+
 	switch dt {
 	case Any:
 		parsed, err = ParseAny(value)
@@ -152,6 +231,22 @@ func (dt Type) Parse(value []byte) (parsed interface{}, err error) {
 		parsed, err = ParseURL(value)
 	case JSON:
 		parsed, err = ParseJSON(value)
+	case Decimal:
+		parsed, err = ParseDecimal(value)
+	case Time:
+		parsed, err = ParseTime(value)
+	case DateTime:
+		parsed, err = ParseDateTime(value)
+	case Duration:
+		parsed, err = ParseDuration(value)
+	case GeoPoint:
+		parsed, err = ParseGeoPoint(value)
+	case GeoJSON:
+		parsed, err = ParseGeoJSON(value)
+	case Array:
+		parsed, err = ParseArray(value)
+	case Object:
+		parsed, err = ParseObject(value)
 	default:
 		return nil, errors.New("cannot parse unknown data type")
 	}
@@ -162,8 +257,12 @@ func (dt Type) Parse(value []byte) (parsed interface{}, err error) {
 func (dt Type) ValueToString(value interface{}) (str string, err error) {
 	switch dt {
 	case Any:
-		// TODO
-		return "", fmt.Errorf("converting 'any' value to string not yet supported")
+		t := ValueType(value)
+		if t == Unknown {
+			err = fmt.Errorf("cannot determine datatype of %v", value)
+			return
+		}
+		return t.ValueToString(value)
 	case String:
 		s, ok := value.(string)
 		if !ok {
@@ -172,19 +271,41 @@ func (dt Type) ValueToString(value interface{}) (str string, err error) {
 		}
 		str = s
 	case Integer:
-		num, ok := value.(int)
-		if !ok {
+		switch num := value.(type) {
+		case int:
+			str = strconv.FormatInt(int64(num), 10)
+		case int64:
+			str = strconv.FormatInt(num, 10)
+		case int32:
+			str = strconv.FormatInt(int64(num), 10)
+		default:
 			err = fmt.Errorf("%v is not an %s value", value, dt.String())
 			return
 		}
-		str = strconv.FormatInt(int64(num), 10)
 	case Float:
-		num, ok := value.(float32)
-		if !ok {
+		switch num := value.(type) {
+		case float32:
+			str = strconv.FormatFloat(float64(num), 'g', -1, 64)
+		case float64:
+			str = strconv.FormatFloat(num, 'g', -1, 64)
+		default:
+			err = fmt.Errorf("%v is not a %s value", value, dt.String())
+			return
+		}
+	case Decimal:
+		switch num := value.(type) {
+		case *big.Rat:
+			str = decimalString(num)
+		case int:
+			str = strconv.FormatInt(int64(num), 10)
+		case int64:
+			str = strconv.FormatInt(num, 10)
+		case float64:
+			str = strconv.FormatFloat(num, 'f', -1, 64)
+		default:
 			err = fmt.Errorf("%v is not a %s value", value, dt.String())
 			return
 		}
-		str = strconv.FormatFloat(float64(num), 'g', -1, 64)
 	case Boolean:
 		val, ok := value.(bool)
 		if !ok {
@@ -199,13 +320,63 @@ func (dt Type) ValueToString(value interface{}) (str string, err error) {
 			return
 		}
 		str = string(data)
-	case Date:
+	case Date, Time, DateTime:
 		val, ok := value.(time.Time)
 		if !ok {
 			err = fmt.Errorf("%v is not a %s value", value, dt.String())
 			return
 		}
-		str = val.Format(time.RFC3339)
+		str = TimeFormat{Type: dt}.FormatValue(val)
+	case Duration:
+		val, ok := value.(time.Duration)
+		if !ok {
+			err = fmt.Errorf("%v is not a %s value", value, dt.String())
+			return
+		}
+		str = durationString(val)
+	case GeoPoint:
+		val, ok := value.(Point)
+		if !ok {
+			err = fmt.Errorf("%v is not a %s value", value, dt.String())
+			return
+		}
+		str = val.String()
+	case GeoJSON:
+		val, ok := value.(*Geometry)
+		if !ok {
+			err = fmt.Errorf("%v is not a %s value", value, dt.String())
+			return
+		}
+		data, e := json.Marshal(val)
+		if e != nil {
+			err = e
+			return
+		}
+		str = string(data)
+	case Array:
+		val, ok := value.([]interface{})
+		if !ok {
+			err = fmt.Errorf("%v is not an %s value", value, dt.String())
+			return
+		}
+		data, e := json.Marshal(val)
+		if e != nil {
+			err = e
+			return
+		}
+		str = string(data)
+	case Object:
+		val, ok := value.(map[string]interface{})
+		if !ok {
+			err = fmt.Errorf("%v is not an %s value", value, dt.String())
+			return
+		}
+		data, e := json.Marshal(val)
+		if e != nil {
+			err = e
+			return
+		}
+		str = string(data)
 	case URL:
 		val, ok := value.(*url.URL)
 		if !ok {
@@ -220,6 +391,36 @@ func (dt Type) ValueToString(value interface{}) (str string, err error) {
 	return
 }
 
+// ValueType gives the datatype of an already-parsed value, the inverse of
+// Type.Parse. values of unrecognized go types are Unknown
+func ValueType(value interface{}) Type {
+	switch value.(type) {
+	case string:
+		return String
+	case int, int32, int64:
+		return Integer
+	case float32, float64:
+		return Float
+	case *big.Rat:
+		return Decimal
+	case bool:
+		return Boolean
+	case time.Time:
+		return DateTime
+	case time.Duration:
+		return Duration
+	case Point:
+		return GeoPoint
+	case *Geometry:
+		return GeoJSON
+	case *url.URL:
+		return URL
+	case map[string]interface{}, []interface{}:
+		return JSON
+	}
+	return Unknown
+}
+
 // ValueToBytes takes already-parsed values & converts them to a slice of bytes
 func (dt Type) ValueToBytes(value interface{}) (data []byte, err error) {
 	// TODO - for now we just wrap ToString
@@ -258,26 +459,23 @@ func ParseBoolean(value []byte) (bool, error) {
 	return strconv.ParseBool(string(value))
 }
 
-// ParseDate converts raw bytes to a time.Time value
-func ParseDate(value []byte) (t time.Time, err error) {
-	str := string(value)
-	for _, format := range []string{
-		time.RFC3339,
-		time.RFC3339Nano,
-		time.ANSIC,
-		time.UnixDate,
-		time.RubyDate,
-		time.RFC822,
-		time.RFC822Z,
-		time.RFC850,
-		time.RFC1123,
-		time.RFC1123Z,
-	} {
-		if t, err = time.Parse(format, str); err == nil {
-			return
-		}
-	}
-	return time.Now(), fmt.Errorf("invalid date: %s", str)
+// ParseDate converts raw bytes to a time.Time value at midnight UTC,
+// accepting any known date format. full timestamps are truncated to their
+// date
+func ParseDate(value []byte) (time.Time, error) {
+	return anyDate.ParseValue(value)
+}
+
+// ParseTime converts raw bytes to a time.Time time of day value,
+// accepting any known time format
+func ParseTime(value []byte) (time.Time, error) {
+	return anyTime.ParseValue(value)
+}
+
+// ParseDateTime converts raw bytes to a time.Time value, accepting
+// any known datetime format
+func ParseDateTime(value []byte) (time.Time, error) {
+	return anyDateTime.ParseValue(value)
 }
 
 // ParseURL converts raw bytes to a *url.URL value
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"errors"
 	"github.com/qri-io/compare"
+	"math/big"
 	"testing"
 	"time"
 )
@@ -23,6 +24,14 @@ func TestTypeString(t *testing.T) {
 		{Date, "date"},
 		{URL, "url"},
 		{JSON, "json"},
+		{Decimal, "decimal"},
+		{Time, "time"},
+		{DateTime, "datetime"},
+		{Duration, "duration"},
+		{GeoPoint, "geopoint"},
+		{GeoJSON, "geojson"},
+		{Array, "array"},
+		{Object, "object"},
 	}
 
 	for i, c := range cases {
@@ -48,6 +57,14 @@ func TestTypeFromString(t *testing.T) {
 		{"date", Date},
 		{"url", URL},
 		{"json", JSON},
+		{"decimal", Decimal},
+		{"time", Time},
+		{"datetime", DateTime},
+		{"duration", Duration},
+		{"geopoint", GeoPoint},
+		{"geojson", GeoJSON},
+		{"array", Array},
+		{"object", Object},
 	}
 
 	for i, c := range cases {
@@ -112,6 +129,44 @@ func TestTypeParse(t *testing.T) {
 	}
 }
 
+func TestTypeParseNumberFormat(t *testing.T) {
+	de := NumberFormat{DecimalChar: ",", GroupChar: "."}
+	cases := []struct {
+		typ    Type
+		format NumberFormat
+		data   string
+		parsed interface{}
+		err    string
+	}{
+		{Integer, de, "1.234", int64(1234), ""},
+		{Integer, de, "1.234,5", nil, "invalid integer: 1.234,5"},
+		{Float, de, "-1.234,5", -1234.5, ""},
+		{Float, de, "1,234.5", nil, "invalid float: 1,234.5"},
+		{Float, NumberFormat{Symbols: true}, "12.5%", 12.5, ""},
+		{Decimal, de, "0,10", big.NewRat(1, 10), ""},
+		{String, de, "1.234", "1.234", ""},
+	}
+
+	for i, c := range cases {
+		got, err := c.typ.Parse([]byte(c.data), func(o *ParseOptions) {
+			o.Number = c.format
+		})
+		if !(err == nil && c.err == "" || err != nil && err.Error() == c.err) {
+			t.Errorf("case %d error mismatch. expected: '%s', got: '%s'", i, c.err, err)
+			continue
+		}
+		if r, ok := got.(*big.Rat); ok {
+			if r.Cmp(c.parsed.(*big.Rat)) != 0 {
+				t.Errorf("case %d mismatch. expected: %v, got: %v", i, c.parsed, got)
+			}
+			continue
+		}
+		if c.err == "" && got != c.parsed {
+			t.Errorf("case %d mismatch. expected: %v, got: %v", i, c.parsed, got)
+		}
+	}
+}
+
 func TestParseDatatype(t *testing.T) {
 	cases := []struct {
 		value  string
@@ -121,9 +176,28 @@ func TestParseDatatype(t *testing.T) {
 		{"[]", JSON},
 		{"1", Integer},
 		{"1.5", Float},
+		{"1.5e300", Float},
+		{"12345678901234567890", Decimal},
+		{"-0.1234567890123456789", Decimal},
+		{"0.000000000000001", Float},
 		{"false", Boolean},
 		{"true", Boolean},
-		{"2015-09-03T13:27:52Z", Date},
+		{"2015-09-03T13:27:52Z", DateTime},
+		{"2015-09-03 13:27", DateTime},
+		{"2015-09-03", Date},
+		{"03/15/2021", Date},
+		{"15.03.2021", Date},
+		{"13:27:52", Time},
+		{"1:27 PM", Time},
+		{"P1DT2H", Duration},
+		{"PT", String},
+		{"1h30m", String},
+		{"40.7128,-74.0060", GeoPoint},
+		{`{"lat":40.7,"lon":-74}`, GeoPoint},
+		{`{"type":"Point","coordinates":[-74,40.7]}`, GeoJSON},
+		{`{"type":"Point","coordinates":[-74,140.7]}`, JSON},
+		{"[-74.0060,40.7128]", JSON},
+		{"40.7128,-274.0060", String},
 		{"", String},
 	}
 	for i, c := range cases {
@@ -284,20 +358,28 @@ func TestParseJSON(t *testing.T) {
 	}
 }
 
-// TODO
 func TestParseDate(t *testing.T) {
 	cases := []struct {
-		input  []byte
-		expect *time.Time
-		err    error
-	}{}
+		input  string
+		expect time.Time
+		err    string
+	}{
+		{"2015-09-03", time.Date(2015, 9, 3, 0, 0, 0, 0, time.UTC), ""},
+		{"03/09/2015", time.Date(2015, 3, 9, 0, 0, 0, 0, time.UTC), ""},
+		// timestamps are truncated to their date
+		{"2015-09-03T13:27:52Z", time.Date(2015, 9, 3, 0, 0, 0, 0, time.UTC), ""},
+		{"Thu Sep  3 13:27:52 2015", time.Date(2015, 9, 3, 0, 0, 0, 0, time.UTC), ""},
+		{"Thu, 03 Sep 2015 13:27:52 UTC", time.Date(2015, 9, 3, 0, 0, 0, 0, time.UTC), ""},
+		{"soon", time.Time{}, "invalid date: soon"},
+	}
 	for i, c := range cases {
-		value, got := ParseDate(c.input)
-		if value.String() != c.expect.String() {
-			t.Errorf("case %d value mismatch. expected: %s, got: %s", i, c.expect, value)
+		value, err := ParseDate([]byte(c.input))
+		if !(err == nil && c.err == "" || err != nil && err.Error() == c.err) {
+			t.Errorf("case %d error mismatch. expected: %s, got: %s", i, c.err, err)
+			continue
 		}
-		if c.err != got {
-			t.Errorf("case %d error mismatch. expected: %s, got: %s", i, c.err, got)
+		if !value.Equal(c.expect) {
+			t.Errorf("case %d value mismatch. expected: %s, got: %s", i, c.expect, value)
 		}
 	}
 }
@@ -339,16 +421,45 @@ func TestValueToString(t *testing.T) {
 		{Unknown, "", "", "cannot get string value of unknown datatype"},
 		{Integer, 234, "234", ""},
 		{Integer, "234", "", "234 is not an integer value"},
+		{Integer, int64(-234), "-234", ""},
 		{Float, float32(234.0), "234", ""},
+		{Float, float64(234.5), "234.5", ""},
+		{Any, int64(234), "234", ""},
+		{Any, true, "true", ""},
+		{Any, []interface{}{"a"}, `["a"]`, ""},
+		{Any, struct{}{}, "", "cannot determine datatype of {}"},
 		{Float, float32(234.12339782714844), "234.12339782714844", ""},
 		{Float, "234", "", "234 is not a float value"},
+		{Decimal, big.NewRat(12345, 100), "123.45", ""},
+		{Decimal, big.NewRat(-1, 8), "-0.125", ""},
+		{Decimal, big.NewRat(1, 3), "0.3333333333333333333333333333333333", ""},
+		{Decimal, big.NewRat(20, 1), "20", ""},
+		{Decimal, int64(234), "234", ""},
+		{Decimal, 1.5, "1.5", ""},
+		{Decimal, "234", "", "234 is not a decimal value"},
+		{Any, big.NewRat(3, 2), "1.5", ""},
 		{Boolean, false, "false", ""},
 		{Boolean, true, "true", ""},
 		{Boolean, "234", "", "234 is not a boolean value"},
 		{JSON, map[string]interface{}{"a": "b"}, `{"a":"b"}`, ""},
 		// {JSON, "234", "", "234 is not a json value"},
-		{Date, time.Date(2001, 1, 1, 0, 0, 0, 0, time.UTC), "2001-01-01T00:00:00Z", ""},
+		{Date, time.Date(2001, 1, 1, 0, 0, 0, 0, time.UTC), "2001-01-01", ""},
 		{Date, "234", "", "234 is not a date value"},
+		{DateTime, time.Date(2001, 1, 1, 0, 0, 0, 0, time.UTC), "2001-01-01T00:00:00Z", ""},
+		{DateTime, time.Date(2001, 1, 1, 12, 30, 0, 500, time.FixedZone("", -5*3600)), "2001-01-01T12:30:00.0000005-05:00", ""},
+		{Time, time.Date(0, 1, 1, 13, 27, 0, 0, time.UTC), "13:27:00", ""},
+		{Time, time.Date(0, 1, 1, 13, 27, 0, 0, time.FixedZone("", 3600)), "13:27:00+01:00", ""},
+		{Duration, 90 * time.Minute, "PT1H30M", ""},
+		{Duration, "PT1H", "", "PT1H is not a duration value"},
+		{Any, time.Date(2001, 1, 1, 0, 0, 0, 0, time.UTC), "2001-01-01T00:00:00Z", ""},
+		{GeoPoint, Point{Lat: 40.7128, Lon: -74.006}, "40.7128,-74.006", ""},
+		{GeoPoint, "40,-74", "", "40,-74 is not a geopoint value"},
+		{GeoJSON, &Geometry{Type: "Point", Coordinates: []float64{-74, 40.7}}, `{"type":"Point","coordinates":[-74,40.7]}`, ""},
+		{Any, Point{Lat: 1, Lon: 2}, "1,2", ""},
+		{Array, []interface{}{float64(1), "a"}, `[1,"a"]`, ""},
+		{Array, map[string]interface{}{}, "", "map[] is not an array value"},
+		{Object, map[string]interface{}{"a": []interface{}{true}}, `{"a":[true]}`, ""},
+		{Object, "{}", "", "{} is not an object value"},
 		{String, "foo", "foo", ""},
 		{String, 234, "", "234 is not a string value"},
 	}
@@ -384,7 +495,7 @@ func TestValueToBytes(t *testing.T) {
 		{Boolean, "234", "", "234 is not a boolean value"},
 		{JSON, map[string]interface{}{"a": "b"}, `{"a":"b"}`, ""},
 		// {JSON, "234", "", "234 is not a json value"},
-		{Date, time.Date(2001, 1, 1, 0, 0, 0, 0, time.UTC), "2001-01-01T00:00:00Z", ""},
+		{Date, time.Date(2001, 1, 1, 0, 0, 0, 0, time.UTC), "2001-01-01", ""},
 		{Date, "234", "", "234 is not a date value"},
 		{String, "foo", "foo", ""},
 		{String, 234, "", "234 is not a string value"},
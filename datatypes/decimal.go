@@ -0,0 +1,171 @@
+package datatypes
+
+import (
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// maxFloatDigits is the number of significant decimal digits a float64
+// can always represent exactly. numbers with more digits are decimals
+const maxFloatDigits = 15
+
+// maxDecimalScale caps the number of fractional digits written for
+// values with no exact decimal representation, like 1/3
+const maxDecimalScale = 34
+
+// ParseDecimal converts raw bytes to an arbitrary-precision *big.Rat value.
+// values must be base-10 numbers with an optional sign, fractional part
+// and exponent
+func ParseDecimal(value []byte) (*big.Rat, error) {
+	if _, _, ok := scanDecimal(value); !ok {
+		return nil, fmt.Errorf("invalid decimal: %s", string(value))
+	}
+	r, ok := new(big.Rat).SetString(string(value))
+	if !ok {
+		return nil, fmt.Errorf("invalid decimal: %s", string(value))
+	}
+	return r, nil
+}
+
+// isDecimal reports weather value is a plain base-10 number with more
+// significant digits than a float can hold
+func isDecimal(value []byte) bool {
+	digits, exp, ok := scanDecimal(value)
+	return ok && !exp && digits > maxFloatDigits
+}
+
+// scanDecimal checks value is a base-10 number, returning the count of
+// significant digits before any exponent & weather an exponent is present
+func scanDecimal(value []byte) (digits int, exp bool, ok bool) {
+	i := 0
+	if i < len(value) && (value[i] == '-' || value[i] == '+') {
+		i++
+	}
+
+	seen, point, leading := 0, false, true
+	for ; i < len(value); i++ {
+		c := value[i]
+		if c == '.' && !point {
+			point = true
+			continue
+		}
+		if c < '0' || c > '9' {
+			break
+		}
+		seen++
+		if c != '0' || !leading {
+			leading = false
+			digits++
+		}
+	}
+	if seen == 0 {
+		return 0, false, false
+	}
+
+	if i < len(value) && (value[i] == 'e' || value[i] == 'E') {
+		exp = true
+		i++
+		if i < len(value) && (value[i] == '-' || value[i] == '+') {
+			i++
+		}
+		start := i
+		for i < len(value) && value[i] >= '0' && value[i] <= '9' {
+			i++
+		}
+		if i == start {
+			return 0, false, false
+		}
+	}
+	return digits, exp, i == len(value)
+}
+
+// decimalString writes a decimal value with as many fractional digits as
+// needed to represent it exactly. values that don't terminate in base-10
+// are rounded to maxDecimalScale digits
+func decimalString(r *big.Rat) string {
+	if r.IsInt() {
+		return r.Num().String()
+	}
+
+	// a fraction terminates in base-10 if it's denominator only has
+	// factors of two and five, with scale of the larger exponent
+	d := new(big.Int).Set(r.Denom())
+	rem, twos, fives := new(big.Int), 0, 0
+	two, five := big.NewInt(2), big.NewInt(5)
+	for d.Cmp(big.NewInt(1)) != 0 {
+		if q, m := new(big.Int).QuoRem(d, two, rem); m.Sign() == 0 {
+			d, twos = q, twos+1
+		} else if q, m := new(big.Int).QuoRem(d, five, rem); m.Sign() == 0 {
+			d, fives = q, fives+1
+		} else {
+			return strings.TrimRight(r.FloatString(maxDecimalScale), "0")
+		}
+	}
+	if twos > fives {
+		return r.FloatString(twos)
+	}
+	return r.FloatString(fives)
+}
+
+// DecimalFormat specifies the precision & scale of decimal values, read from
+// a field's Format. Precision is the total number of digits, with Scale
+// digits after the decimal point. a zero Precision is unbounded, a negative
+// Scale writes as many fractional digits as a value needs
+type DecimalFormat struct {
+	Precision int
+	Scale     int
+}
+
+// ParseDecimalFormat reads a decimal format from a field format string of
+// the form "precision,scale" or "precision", where precision-only formats
+// have a scale of zero. the empty string is an unbounded format
+func ParseDecimalFormat(format string) (DecimalFormat, error) {
+	f := DecimalFormat{Scale: -1}
+	if strings.TrimSpace(format) == "" {
+		return f, nil
+	}
+
+	parts := strings.Split(format, ",")
+	if len(parts) > 2 {
+		return f, fmt.Errorf("invalid decimal format: '%s'", format)
+	}
+	nums := make([]int, len(parts))
+	for i, p := range parts {
+		n, err := strconv.Atoi(strings.TrimSpace(p))
+		if err != nil || n < 0 {
+			return f, fmt.Errorf("invalid decimal format: '%s'", format)
+		}
+		nums[i] = n
+	}
+
+	f.Precision, f.Scale = nums[0], 0
+	if len(nums) == 2 {
+		f.Scale = nums[1]
+	}
+	if f.Precision == 0 || f.Scale > f.Precision {
+		return f, fmt.Errorf("invalid decimal format: '%s'. scale must be between 0 and precision", format)
+	}
+	return f, nil
+}
+
+// FormatValue encodes a decimal value according to the format, rounding
+// to Scale fractional digits. values with more integer digits than
+// Precision allows are an error
+func (f DecimalFormat) FormatValue(r *big.Rat) (string, error) {
+	var str string
+	if f.Scale < 0 {
+		str = decimalString(r)
+	} else {
+		str = r.FloatString(f.Scale)
+	}
+
+	if f.Precision > 0 {
+		integer := strings.TrimLeft(strings.SplitN(str, ".", 2)[0], "-0")
+		if len(integer) > f.Precision-f.Scale {
+			return "", fmt.Errorf("%s exceeds decimal precision %d, scale %d", str, f.Precision, f.Scale)
+		}
+	}
+	return str, nil
+}
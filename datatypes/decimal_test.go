@@ -0,0 +1,102 @@
+package datatypes
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestParseDecimal(t *testing.T) {
+	cases := []struct {
+		input  string
+		expect *big.Rat
+		err    string
+	}{
+		{"0", big.NewRat(0, 1), ""},
+		{"123.45", big.NewRat(12345, 100), ""},
+		{"-0.125", big.NewRat(-1, 8), ""},
+		{"+1.5", big.NewRat(3, 2), ""},
+		{".5", big.NewRat(1, 2), ""},
+		{"1.5e3", big.NewRat(1500, 1), ""},
+		{"15E-1", big.NewRat(3, 2), ""},
+		{"", nil, "invalid decimal: "},
+		{"1/3", nil, "invalid decimal: 1/3"},
+		{"1.2.3", nil, "invalid decimal: 1.2.3"},
+		{"1e", nil, "invalid decimal: 1e"},
+		{"0x10", nil, "invalid decimal: 0x10"},
+		{"ten", nil, "invalid decimal: ten"},
+	}
+
+	for i, c := range cases {
+		got, err := ParseDecimal([]byte(c.input))
+		if !(err == nil && c.err == "" || err != nil && err.Error() == c.err) {
+			t.Errorf("case %d error mismatch. expected: '%s', got: '%s'", i, c.err, err)
+			continue
+		}
+		if c.expect != nil && got.Cmp(c.expect) != 0 {
+			t.Errorf("case %d mismatch. expected: %s, got: %s", i, c.expect, got)
+		}
+	}
+}
+
+func TestParseDecimalFormat(t *testing.T) {
+	cases := []struct {
+		format string
+		expect DecimalFormat
+		err    string
+	}{
+		{"", DecimalFormat{Scale: -1}, ""},
+		{"10,2", DecimalFormat{Precision: 10, Scale: 2}, ""},
+		{" 10, 2 ", DecimalFormat{Precision: 10, Scale: 2}, ""},
+		{"5", DecimalFormat{Precision: 5}, ""},
+		{"2,3", DecimalFormat{}, "invalid decimal format: '2,3'. scale must be between 0 and precision"},
+		{"0", DecimalFormat{}, "invalid decimal format: '0'. scale must be between 0 and precision"},
+		{"10,-2", DecimalFormat{}, "invalid decimal format: '10,-2'"},
+		{"1,2,3", DecimalFormat{}, "invalid decimal format: '1,2,3'"},
+		{"currency", DecimalFormat{}, "invalid decimal format: 'currency'"},
+	}
+
+	for i, c := range cases {
+		got, err := ParseDecimalFormat(c.format)
+		if !(err == nil && c.err == "" || err != nil && err.Error() == c.err) {
+			t.Errorf("case %d error mismatch. expected: '%s', got: '%s'", i, c.err, err)
+			continue
+		}
+		if c.err == "" && got != c.expect {
+			t.Errorf("case %d mismatch. expected: %v, got: %v", i, c.expect, got)
+		}
+	}
+}
+
+func TestDecimalFormatValue(t *testing.T) {
+	cases := []struct {
+		format DecimalFormat
+		value  string
+		expect string
+		err    string
+	}{
+		{DecimalFormat{Scale: -1}, "1.50", "1.5", ""},
+		{DecimalFormat{Scale: -1}, "12345678901234567890.123", "12345678901234567890.123", ""},
+		{DecimalFormat{Precision: 10, Scale: 2}, "1.5", "1.50", ""},
+		{DecimalFormat{Precision: 10, Scale: 2}, "1.005", "1.01", ""},
+		{DecimalFormat{Precision: 10, Scale: 2}, "-1.005", "-1.01", ""},
+		{DecimalFormat{Precision: 4, Scale: 2}, "99.99", "99.99", ""},
+		{DecimalFormat{Precision: 4, Scale: 2}, "-0.5", "-0.50", ""},
+		{DecimalFormat{Precision: 4, Scale: 2}, "99.999", "", "100.00 exceeds decimal precision 4, scale 2"},
+		{DecimalFormat{Precision: 3}, "1234", "", "1234 exceeds decimal precision 3, scale 0"},
+	}
+
+	for i, c := range cases {
+		r, ok := new(big.Rat).SetString(c.value)
+		if !ok {
+			t.Fatalf("case %d invalid test value: %s", i, c.value)
+		}
+		got, err := c.format.FormatValue(r)
+		if !(err == nil && c.err == "" || err != nil && err.Error() == c.err) {
+			t.Errorf("case %d error mismatch. expected: '%s', got: '%s'", i, c.err, err)
+			continue
+		}
+		if got != c.expect {
+			t.Errorf("case %d mismatch. expected: '%s', got: '%s'", i, c.expect, got)
+		}
+	}
+}
@@ -0,0 +1,249 @@
+package datatypes
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Point is a geographic point value, in decimal degrees
+type Point struct {
+	Lat float64
+	Lon float64
+}
+
+// String writes a point as "lat,lon"
+func (p Point) String() string {
+	return strconv.FormatFloat(p.Lat, 'f', -1, 64) + "," + strconv.FormatFloat(p.Lon, 'f', -1, 64)
+}
+
+// BBox gives the bounding box of a single point
+func (p Point) BBox() BBox {
+	return BBox{p.Lon, p.Lat, p.Lon, p.Lat}
+}
+
+// ParseGeoPoint converts raw bytes to a Point value. points are one of
+// a "lat,lon" string, a [lon, lat] array in GeoJSON position order, or
+// an object with "lat" & "lon" keys
+func ParseGeoPoint(value []byte) (p Point, err error) {
+	value = bytes.TrimSpace(value)
+	switch {
+	case len(value) > 0 && value[0] == '[':
+		pos := []float64{}
+		if err = json.Unmarshal(value, &pos); err != nil || len(pos) != 2 {
+			return p, fmt.Errorf("invalid geopoint: %s", string(value))
+		}
+		p = Point{Lon: pos[0], Lat: pos[1]}
+	case len(value) > 0 && value[0] == '{':
+		obj := map[string]*float64{}
+		if err = json.Unmarshal(value, &obj); err != nil || len(obj) != 2 || obj["lat"] == nil || obj["lon"] == nil {
+			return p, fmt.Errorf("invalid geopoint: %s", string(value))
+		}
+		p = Point{Lat: *obj["lat"], Lon: *obj["lon"]}
+	default:
+		parts := strings.Split(string(value), ",")
+		if len(parts) != 2 {
+			return p, fmt.Errorf("invalid geopoint: %s", string(value))
+		}
+		if p.Lat, err = strconv.ParseFloat(strings.TrimSpace(parts[0]), 64); err != nil {
+			return p, fmt.Errorf("invalid geopoint: %s", string(value))
+		}
+		if p.Lon, err = strconv.ParseFloat(strings.TrimSpace(parts[1]), 64); err != nil {
+			return p, fmt.Errorf("invalid geopoint: %s", string(value))
+		}
+	}
+
+	if err = checkPosition(p.Lon, p.Lat); err != nil {
+		return p, fmt.Errorf("invalid geopoint: %s. %s", string(value), err.Error())
+	}
+	return p, nil
+}
+
+// isGeoPoint reports weather value is a geopoint. only "lat,lon" strings
+// with decimal coordinates & lat/lon objects are detected, lists of numbers
+// are too common to assume they're points
+func isGeoPoint(value []byte) bool {
+	value = bytes.TrimSpace(value)
+	if len(value) == 0 || value[0] == '[' {
+		return false
+	}
+	if value[0] != '{' && bytes.Count(value, []byte(".")) != 2 {
+		return false
+	}
+	_, err := ParseGeoPoint(value)
+	return err == nil
+}
+
+// checkPosition ensures coordinates are within range
+func checkPosition(lon, lat float64) error {
+	if lat < -90 || lat > 90 {
+		return fmt.Errorf("latitude %s must be between -90 and 90", strconv.FormatFloat(lat, 'f', -1, 64))
+	}
+	if lon < -180 || lon > 180 {
+		return fmt.Errorf("longitude %s must be between -180 and 180", strconv.FormatFloat(lon, 'f', -1, 64))
+	}
+	return nil
+}
+
+// Geometry is a GeoJSON geometry object
+// https://tools.ietf.org/html/rfc7946#section-3.1
+type Geometry struct {
+	Type        string      `json:"type"`
+	Coordinates interface{} `json:"coordinates,omitempty"`
+	Geometries  []*Geometry `json:"geometries,omitempty"`
+}
+
+// geometryDepths is the number of levels of arrays that contain
+// positions in the coordinates of each geometry type
+var geometryDepths = map[string]int{
+	"Point":           0,
+	"MultiPoint":      1,
+	"LineString":      1,
+	"MultiLineString": 2,
+	"Polygon":         2,
+	"MultiPolygon":    3,
+}
+
+// ParseGeoJSON converts raw bytes to a *Geometry value, checking
+// coordinates are well-formed & within range
+func ParseGeoJSON(value []byte) (*Geometry, error) {
+	g := &Geometry{}
+	if err := json.Unmarshal(value, g); err != nil {
+		return nil, fmt.Errorf("invalid geojson: %s", err.Error())
+	}
+	if err := g.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid geojson: %s", err.Error())
+	}
+	return g, nil
+}
+
+// isGeoJSON reports weather value is a GeoJSON geometry object
+func isGeoJSON(value []byte) bool {
+	value = bytes.TrimSpace(value)
+	if len(value) == 0 || value[0] != '{' || !bytes.Contains(value, []byte(`"type"`)) {
+		return false
+	}
+	_, err := ParseGeoJSON(value)
+	return err == nil
+}
+
+// Validate checks a geometry has a known type & valid coordinates
+func (g *Geometry) Validate() error {
+	if g.Type == "GeometryCollection" {
+		for _, child := range g.Geometries {
+			if child == nil {
+				return fmt.Errorf("geometry collections cannot contain null geometries")
+			}
+			if err := child.Validate(); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	depth, ok := geometryDepths[g.Type]
+	if !ok {
+		return fmt.Errorf("unknown geometry type '%s'", g.Type)
+	}
+	if g.Coordinates == nil {
+		return fmt.Errorf("%s must have coordinates", g.Type)
+	}
+	return g.eachPosition(func(lon, lat float64) error {
+		return checkPosition(lon, lat)
+	}, depth)
+}
+
+// BBox gives the bounding box of all positions in a geometry, returning
+// false if the geometry has no positions
+func (g *Geometry) BBox() (bbox BBox, ok bool) {
+	if g.Type == "GeometryCollection" {
+		for _, child := range g.Geometries {
+			if b, childOK := child.BBox(); childOK {
+				if ok {
+					bbox = bbox.Union(b)
+				} else {
+					bbox, ok = b, true
+				}
+			}
+		}
+		return bbox, ok
+	}
+
+	g.eachPosition(func(lon, lat float64) error {
+		p := Point{Lat: lat, Lon: lon}
+		if ok {
+			bbox = bbox.Union(p.BBox())
+		} else {
+			bbox, ok = p.BBox(), true
+		}
+		return nil
+	}, geometryDepths[g.Type])
+	return bbox, ok
+}
+
+// eachPosition calls fn for each position in a geometry's coordinates,
+// which are nested depth arrays deep
+func (g *Geometry) eachPosition(fn func(lon, lat float64) error, depth int) error {
+	var walk func(coords interface{}, depth int) error
+	walk = func(coords interface{}, depth int) error {
+		arr, ok := coords.([]interface{})
+		if !ok {
+			return fmt.Errorf("%s coordinates must be arrays", g.Type)
+		}
+		if depth > 0 {
+			for _, c := range arr {
+				if err := walk(c, depth-1); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+
+		if len(arr) < 2 {
+			return fmt.Errorf("%s positions must have at least two coordinates", g.Type)
+		}
+		lon, lonOK := arr[0].(float64)
+		lat, latOK := arr[1].(float64)
+		if !lonOK || !latOK {
+			return fmt.Errorf("%s positions must be numbers", g.Type)
+		}
+		return fn(lon, lat)
+	}
+	return walk(g.Coordinates, depth)
+}
+
+// BBox is a geographic bounding box of [west, south, east, north]
+// coordinates, matching the GeoJSON bbox member. boxes that cross the
+// antimeridian are not supported
+type BBox [4]float64
+
+// Union gives the smallest bounding box containing both boxes
+func (b BBox) Union(o BBox) BBox {
+	if o[0] < b[0] {
+		b[0] = o[0]
+	}
+	if o[1] < b[1] {
+		b[1] = o[1]
+	}
+	if o[2] > b[2] {
+		b[2] = o[2]
+	}
+	if o[3] > b[3] {
+		b[3] = o[3]
+	}
+	return b
+}
+
+// ValueBBox gives the bounding box of a parsed GeoPoint or GeoJSON value,
+// returning false for other values & geometries without positions
+func ValueBBox(value interface{}) (BBox, bool) {
+	switch v := value.(type) {
+	case Point:
+		return v.BBox(), true
+	case *Geometry:
+		return v.BBox()
+	}
+	return BBox{}, false
+}
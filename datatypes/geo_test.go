@@ -0,0 +1,121 @@
+package datatypes
+
+import (
+	"testing"
+)
+
+func TestParseGeoPoint(t *testing.T) {
+	cases := []struct {
+		input  string
+		expect Point
+		err    string
+	}{
+		{"40.7128,-74.006", Point{Lat: 40.7128, Lon: -74.006}, ""},
+		{" 40.7128 , -74.006 ", Point{Lat: 40.7128, Lon: -74.006}, ""},
+		{"[-74.006,40.7128]", Point{Lat: 40.7128, Lon: -74.006}, ""},
+		{`{"lat":40.7128,"lon":-74.006}`, Point{Lat: 40.7128, Lon: -74.006}, ""},
+		{"-90,180", Point{Lat: -90, Lon: 180}, ""},
+		{"", Point{}, "invalid geopoint: "},
+		{"40.7128", Point{}, "invalid geopoint: 40.7128"},
+		{"1,2,3", Point{}, "invalid geopoint: 1,2,3"},
+		{"north,west", Point{}, "invalid geopoint: north,west"},
+		{"[1,2,3]", Point{}, "invalid geopoint: [1,2,3]"},
+		{`{"lat":1}`, Point{}, `invalid geopoint: {"lat":1}`},
+		{`{"lat":1,"lng":2}`, Point{}, `invalid geopoint: {"lat":1,"lng":2}`},
+		{"91,0", Point{}, "invalid geopoint: 91,0. latitude 91 must be between -90 and 90"},
+		{"0,-180.5", Point{}, "invalid geopoint: 0,-180.5. longitude -180.5 must be between -180 and 180"},
+		{"[0,91]", Point{}, "invalid geopoint: [0,91]. latitude 91 must be between -90 and 90"},
+	}
+
+	for i, c := range cases {
+		got, err := ParseGeoPoint([]byte(c.input))
+		if !(err == nil && c.err == "" || err != nil && err.Error() == c.err) {
+			t.Errorf("case %d error mismatch. expected: '%s', got: '%s'", i, c.err, err)
+			continue
+		}
+		if got != c.expect && c.err == "" {
+			t.Errorf("case %d mismatch. expected: %v, got: %v", i, c.expect, got)
+		}
+	}
+}
+
+func TestParseGeoJSON(t *testing.T) {
+	cases := []struct {
+		input string
+		err   string
+	}{
+		{`{"type":"Point","coordinates":[-74.006,40.7128]}`, ""},
+		{`{"type":"Point","coordinates":[-74.006,40.7128,10]}`, ""},
+		{`{"type":"MultiPoint","coordinates":[[0,0],[1,1]]}`, ""},
+		{`{"type":"LineString","coordinates":[[0,0],[1,1]]}`, ""},
+		{`{"type":"MultiLineString","coordinates":[[[0,0],[1,1]]]}`, ""},
+		{`{"type":"Polygon","coordinates":[[[0,0],[1,0],[1,1],[0,0]]]}`, ""},
+		{`{"type":"MultiPolygon","coordinates":[[[[0,0],[1,0],[1,1],[0,0]]]]}`, ""},
+		{`{"type":"GeometryCollection","geometries":[{"type":"Point","coordinates":[0,0]}]}`, ""},
+		{`{"type":"GeometryCollection","geometries":[]}`, ""},
+		{`[]`, "invalid geojson: json: cannot unmarshal array into Go value of type datatypes.Geometry"},
+		{`{"type":"Circle","coordinates":[0,0]}`, "invalid geojson: unknown geometry type 'Circle'"},
+		{`{"type":"Point"}`, "invalid geojson: Point must have coordinates"},
+		{`{"type":"Point","coordinates":[0]}`, "invalid geojson: Point positions must have at least two coordinates"},
+		{`{"type":"Point","coordinates":["a","b"]}`, "invalid geojson: Point positions must be numbers"},
+		{`{"type":"LineString","coordinates":[0,0]}`, "invalid geojson: LineString coordinates must be arrays"},
+		{`{"type":"Polygon","coordinates":[[[0,0],[1,95]]]}`, "invalid geojson: latitude 95 must be between -90 and 90"},
+		{`{"type":"GeometryCollection","geometries":[null]}`, "invalid geojson: geometry collections cannot contain null geometries"},
+	}
+
+	for i, c := range cases {
+		_, err := ParseGeoJSON([]byte(c.input))
+		if !(err == nil && c.err == "" || err != nil && err.Error() == c.err) {
+			t.Errorf("case %d error mismatch. expected: '%s', got: '%s'", i, c.err, err)
+			continue
+		}
+	}
+}
+
+func TestGeometryBBox(t *testing.T) {
+	cases := []struct {
+		input  string
+		expect BBox
+		ok     bool
+	}{
+		{`{"type":"Point","coordinates":[-74.006,40.7128]}`, BBox{-74.006, 40.7128, -74.006, 40.7128}, true},
+		{`{"type":"LineString","coordinates":[[0,5],[-10,2],[3,-1]]}`, BBox{-10, -1, 3, 5}, true},
+		{`{"type":"Polygon","coordinates":[[[0,0],[1,0],[1,1],[0,0]]]}`, BBox{0, 0, 1, 1}, true},
+		{`{"type":"GeometryCollection","geometries":[{"type":"Point","coordinates":[0,0]},{"type":"Point","coordinates":[10,-10]}]}`, BBox{0, -10, 10, 0}, true},
+		{`{"type":"GeometryCollection","geometries":[]}`, BBox{}, false},
+		{`{"type":"MultiPoint","coordinates":[]}`, BBox{}, false},
+	}
+
+	for i, c := range cases {
+		g, err := ParseGeoJSON([]byte(c.input))
+		if err != nil {
+			t.Errorf("case %d unexpected error: %s", i, err.Error())
+			continue
+		}
+		got, ok := g.BBox()
+		if ok != c.ok {
+			t.Errorf("case %d ok mismatch. expected: %t, got: %t", i, c.ok, ok)
+			continue
+		}
+		if got != c.expect {
+			t.Errorf("case %d mismatch. expected: %v, got: %v", i, c.expect, got)
+		}
+	}
+}
+
+func TestBBoxUnion(t *testing.T) {
+	cases := []struct {
+		a, b, expect BBox
+	}{
+		{BBox{0, 0, 1, 1}, BBox{0, 0, 1, 1}, BBox{0, 0, 1, 1}},
+		{BBox{0, 0, 1, 1}, BBox{-1, -1, 0.5, 0.5}, BBox{-1, -1, 1, 1}},
+		{BBox{0, 0, 1, 1}, BBox{2, 2, 3, 3}, BBox{0, 0, 3, 3}},
+		{Point{Lat: 10, Lon: 20}.BBox(), Point{Lat: -10, Lon: -20}.BBox(), BBox{-20, -10, 20, 10}},
+	}
+
+	for i, c := range cases {
+		if got := c.a.Union(c.b); got != c.expect {
+			t.Errorf("case %d mismatch. expected: %v, got: %v", i, c.expect, got)
+		}
+	}
+}
@@ -0,0 +1,232 @@
+package datatypes
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+)
+
+// Element describes a value & the values nested within it, so array &
+// object values can be checked against element schemas. dataset.Field
+// implements Element
+type Element interface {
+	// ElementName gives the name of the element, the empty string for
+	// array items
+	ElementName() string
+	// ElementType gives the datatype of the element
+	ElementType() Type
+	// ElementFormat gives the format string of the element
+	ElementFormat() string
+	// ElementItems gives the schema of array elements, nil if elements
+	// can be any value
+	ElementItems() Element
+	// ElementProperties gives the schemas of object properties. properties
+	// without a schema can be any value
+	ElementProperties() []Element
+}
+
+// ElementError is an error found at a path within a nested value. paths
+// use dots for object properties & brackets for array indexes,
+// eg: "addresses[2].zip"
+type ElementError struct {
+	Path string
+	Err  error
+}
+
+// Error implements the error interface
+func (e *ElementError) Error() string {
+	if e.Path == "" {
+		return e.Err.Error()
+	}
+	return e.Path + ": " + e.Err.Error()
+}
+
+// ParseArray converts raw bytes to a []interface{} value
+func ParseArray(value []byte) ([]interface{}, error) {
+	value = bytes.TrimSpace(value)
+	if len(value) == 0 || value[0] != '[' {
+		return nil, fmt.Errorf("invalid array: %s", string(value))
+	}
+	arr := []interface{}{}
+	if err := json.Unmarshal(value, &arr); err != nil {
+		return nil, fmt.Errorf("invalid array: %s", err.Error())
+	}
+	return arr, nil
+}
+
+// ParseObject converts raw bytes to a map[string]interface{} value
+func ParseObject(value []byte) (map[string]interface{}, error) {
+	value = bytes.TrimSpace(value)
+	if len(value) == 0 || value[0] != '{' {
+		return nil, fmt.Errorf("invalid object: %s", string(value))
+	}
+	obj := map[string]interface{}{}
+	if err := json.Unmarshal(value, &obj); err != nil {
+		return nil, fmt.Errorf("invalid object: %s", err.Error())
+	}
+	return obj, nil
+}
+
+// ValidateElement checks a raw json value against an element schema,
+// returning an error for each nested value that doesn't match. error paths
+// start with the element's name. null & empty values match any schema
+func ValidateElement(el Element, value []byte) []*ElementError {
+	if len(bytes.TrimSpace(value)) == 0 {
+		return nil
+	}
+	if !json.Valid(value) {
+		return []*ElementError{{Path: el.ElementName(), Err: fmt.Errorf("invalid json: %s", string(value))}}
+	}
+	errs := []*ElementError{}
+	validateElement(el, el.ElementName(), value, &errs)
+	return errs
+}
+
+func validateElement(el Element, path string, value []byte, errs *[]*ElementError) {
+	value = bytes.TrimSpace(value)
+	kind := jsonKind(value)
+	if kind == "null" {
+		return
+	}
+
+	switch el.ElementType() {
+	case Array:
+		if kind != "array" {
+			*errs = append(*errs, &ElementError{Path: path, Err: fmt.Errorf("expected array, got %s", kind)})
+			return
+		}
+		items := []json.RawMessage{}
+		if err := json.Unmarshal(value, &items); err != nil {
+			*errs = append(*errs, &ElementError{Path: path, Err: err})
+			return
+		}
+		if it := el.ElementItems(); it != nil {
+			for i, item := range items {
+				validateElement(it, path+"["+strconv.Itoa(i)+"]", item, errs)
+			}
+		}
+	case Object:
+		if kind != "object" {
+			*errs = append(*errs, &ElementError{Path: path, Err: fmt.Errorf("expected object, got %s", kind)})
+			return
+		}
+		props := map[string]json.RawMessage{}
+		if err := json.Unmarshal(value, &props); err != nil {
+			*errs = append(*errs, &ElementError{Path: path, Err: err})
+			return
+		}
+		for _, p := range el.ElementProperties() {
+			if v, ok := props[p.ElementName()]; ok {
+				validateElement(p, propertyPath(path, p.ElementName()), v, errs)
+			}
+		}
+	default:
+		if err := checkElementValue(el.ElementType(), el.ElementFormat(), value, kind); err != nil {
+			*errs = append(*errs, &ElementError{Path: path, Err: err})
+		}
+	}
+}
+
+// propertyPath joins an object path & property name
+func propertyPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+// checkElementValue checks a scalar json value of the given kind is a
+// valid value of type t. strings are parsed according to format for time
+// types
+func checkElementValue(t Type, format string, value []byte, kind string) (err error) {
+	switch t {
+	case String, URL, Date, Time, DateTime, Duration:
+		if kind != "string" {
+			return fmt.Errorf("expected %s, got %s", t.String(), kind)
+		}
+		var str string
+		if err = json.Unmarshal(value, &str); err != nil {
+			return err
+		}
+		switch t {
+		case URL:
+			_, err = ParseURL([]byte(str))
+		case Date, Time, DateTime:
+			var tf TimeFormat
+			if tf, err = ParseTimeFormat(t, format); err != nil {
+				return err
+			}
+			_, err = tf.ParseValue([]byte(str))
+		case Duration:
+			_, err = ParseDuration([]byte(str))
+		}
+	case Integer:
+		if kind != "number" {
+			return fmt.Errorf("expected %s, got %s", t.String(), kind)
+		}
+		if _, e := ParseInteger(value); e != nil {
+			return fmt.Errorf("invalid integer: %s", string(value))
+		}
+	case Float:
+		if kind != "number" {
+			return fmt.Errorf("expected %s, got %s", t.String(), kind)
+		}
+	case Decimal:
+		if kind == "string" {
+			var str string
+			if err = json.Unmarshal(value, &str); err != nil {
+				return err
+			}
+			value = []byte(str)
+		} else if kind != "number" {
+			return fmt.Errorf("expected %s, got %s", t.String(), kind)
+		}
+		_, err = ParseDecimal(value)
+	case Boolean:
+		if kind != "boolean" {
+			return fmt.Errorf("expected %s, got %s", t.String(), kind)
+		}
+	case GeoPoint:
+		switch kind {
+		case "string":
+			var str string
+			if err = json.Unmarshal(value, &str); err != nil {
+				return err
+			}
+			_, err = ParseGeoPoint([]byte(str))
+		case "array", "object":
+			_, err = ParseGeoPoint(value)
+		default:
+			return fmt.Errorf("expected %s, got %s", t.String(), kind)
+		}
+	case GeoJSON:
+		if kind != "object" {
+			return fmt.Errorf("expected %s, got %s", t.String(), kind)
+		}
+		_, err = ParseGeoJSON(value)
+	}
+	return err
+}
+
+// jsonKind gives the kind of a raw json value: one of "null", "boolean",
+// "number", "string", "array" or "object"
+func jsonKind(value []byte) string {
+	if len(value) == 0 {
+		return "null"
+	}
+	switch value[0] {
+	case 'n':
+		return "null"
+	case 't', 'f':
+		return "boolean"
+	case '"':
+		return "string"
+	case '[':
+		return "array"
+	case '{':
+		return "object"
+	default:
+		return "number"
+	}
+}
@@ -0,0 +1,130 @@
+package datatypes
+
+import (
+	"strings"
+	"testing"
+)
+
+// testElement is an Element for testing, standing in for dataset.Field
+type testElement struct {
+	name   string
+	t      Type
+	format string
+	items  *testElement
+	props  []*testElement
+}
+
+func (e *testElement) ElementName() string   { return e.name }
+func (e *testElement) ElementType() Type     { return e.t }
+func (e *testElement) ElementFormat() string { return e.format }
+func (e *testElement) ElementItems() Element {
+	if e.items == nil {
+		return nil
+	}
+	return e.items
+}
+func (e *testElement) ElementProperties() []Element {
+	props := make([]Element, len(e.props))
+	for i, p := range e.props {
+		props[i] = p
+	}
+	return props
+}
+
+func TestParseArray(t *testing.T) {
+	cases := []struct {
+		input  string
+		length int
+		err    string
+	}{
+		{"[]", 0, ""},
+		{" [1,\"a\",null] ", 3, ""},
+		{"{}", 0, "invalid array: {}"},
+		{"", 0, "invalid array: "},
+		{"[1,", 0, "invalid array: unexpected end of JSON input"},
+	}
+
+	for i, c := range cases {
+		got, err := ParseArray([]byte(c.input))
+		if !(err == nil && c.err == "" || err != nil && err.Error() == c.err) {
+			t.Errorf("case %d error mismatch. expected: '%s', got: '%s'", i, c.err, err)
+			continue
+		}
+		if len(got) != c.length {
+			t.Errorf("case %d length mismatch. expected: %d, got: %d", i, c.length, len(got))
+		}
+	}
+}
+
+func TestParseObject(t *testing.T) {
+	cases := []struct {
+		input  string
+		length int
+		err    string
+	}{
+		{"{}", 0, ""},
+		{`{"a":1,"b":[2]}`, 2, ""},
+		{"[]", 0, "invalid object: []"},
+		{`{"a":`, 0, "invalid object: unexpected end of JSON input"},
+	}
+
+	for i, c := range cases {
+		got, err := ParseObject([]byte(c.input))
+		if !(err == nil && c.err == "" || err != nil && err.Error() == c.err) {
+			t.Errorf("case %d error mismatch. expected: '%s', got: '%s'", i, c.err, err)
+			continue
+		}
+		if len(got) != c.length {
+			t.Errorf("case %d length mismatch. expected: %d, got: %d", i, c.length, len(got))
+		}
+	}
+}
+
+func TestValidateElement(t *testing.T) {
+	scores := &testElement{name: "scores", t: Array, items: &testElement{t: Integer}}
+	person := &testElement{name: "person", t: Object, props: []*testElement{
+		{name: "name", t: String},
+		{name: "born", t: Date, format: "%d/%m/%Y"},
+		{name: "tags", t: Array, items: &testElement{t: String}},
+		{name: "address", t: Object, props: []*testElement{
+			{name: "zip", t: Integer},
+			{name: "location", t: GeoPoint},
+		}},
+	}}
+	untyped := &testElement{name: "untyped", t: Array}
+
+	cases := []struct {
+		el     *testElement
+		input  string
+		expect []string
+	}{
+		{scores, "", nil},
+		{scores, "null", nil},
+		{scores, "[]", nil},
+		{scores, "[1,2,null,3]", nil},
+		{scores, `[1,"two",3.5]`, []string{"scores[1]: expected integer, got string", "scores[2]: invalid integer: 3.5"}},
+		{scores, `{"a":1}`, []string{"scores: expected array, got object"}},
+		{scores, "[1,", []string{"scores: invalid json: [1,"}},
+		{untyped, `[1,"a",{"b":[]}]`, nil},
+		{person, `{"name":"ana","born":"15/03/1990","tags":["a"],"address":{"zip":10001,"location":"40.7,-74"},"extra":true}`, nil},
+		{person, `{"name":1,"born":"1990-03-15","tags":["a",2],"address":{"zip":"10001","location":[0,100]}}`, []string{
+			"person.name: expected string, got number",
+			"person.born: invalid date: 1990-03-15. expected format '%d/%m/%Y'",
+			"person.tags[1]: expected string, got number",
+			"person.address.zip: expected integer, got string",
+			"person.address.location: invalid geopoint: [0,100]. latitude 100 must be between -90 and 90",
+		}},
+		{&testElement{t: Array, items: &testElement{t: Object, props: []*testElement{{name: "ok", t: Boolean}}}}, `[{"ok":true},{"ok":"yes"}]`, []string{"[1].ok: expected boolean, got string"}},
+	}
+
+	for i, c := range cases {
+		errs := ValidateElement(c.el, []byte(c.input))
+		got := make([]string, len(errs))
+		for j, e := range errs {
+			got[j] = e.Error()
+		}
+		if strings.Join(got, "\n") != strings.Join(c.expect, "\n") {
+			t.Errorf("case %d mismatch. expected:\n%s\ngot:\n%s", i, strings.Join(c.expect, "\n"), strings.Join(got, "\n"))
+		}
+	}
+}
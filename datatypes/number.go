@@ -0,0 +1,341 @@
+package datatypes
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// NumberFormat describes how integer, float & decimal values are written.
+// the zero value reads plain numbers like "1234.56"
+type NumberFormat struct {
+	// DecimalChar separates the integer & fractional parts of numbers,
+	// "." if empty
+	DecimalChar string
+	// GroupChar separates groups of digits like thousands. numbers are
+	// ungrouped if empty. a space group char matches any kind of space
+	GroupChar string
+	// Symbols permits leading & trailing non-numeric characters like
+	// currency symbols & percent signs, which are removed when parsing.
+	// this is the inverse of a field's bareNumber
+	Symbols bool
+}
+
+// IsPlain reports weather the format reads plain numbers only
+func (f NumberFormat) IsPlain() bool {
+	return (f.DecimalChar == "" || f.DecimalChar == ".") && f.GroupChar == "" && !f.Symbols
+}
+
+// localeNumberChars maps locales to their decimal & group characters.
+// locales are matched by full tag first, then by language
+var localeNumberChars = map[string][2]string{
+	"en":    {".", ","},
+	"ja":    {".", ","},
+	"ko":    {".", ","},
+	"zh":    {".", ","},
+	"th":    {".", ","},
+	"he":    {".", ","},
+	"ms":    {".", ","},
+	"de":    {",", "."},
+	"es":    {",", "."},
+	"it":    {",", "."},
+	"nl":    {",", "."},
+	"pt":    {",", "."},
+	"id":    {",", "."},
+	"tr":    {",", "."},
+	"da":    {",", "."},
+	"el":    {",", "."},
+	"ro":    {",", "."},
+	"hr":    {",", "."},
+	"sl":    {",", "."},
+	"sr":    {",", "."},
+	"vi":    {",", "."},
+	"fr":    {",", " "},
+	"ru":    {",", " "},
+	"pl":    {",", " "},
+	"cs":    {",", " "},
+	"sk":    {",", " "},
+	"sv":    {",", " "},
+	"fi":    {",", " "},
+	"nb":    {",", " "},
+	"no":    {",", " "},
+	"uk":    {",", " "},
+	"hu":    {",", " "},
+	"bg":    {",", " "},
+	"lt":    {",", " "},
+	"lv":    {",", " "},
+	"et":    {",", " "},
+	"de-CH": {".", "'"},
+	"de-LI": {".", "'"},
+	"it-CH": {".", "'"},
+	"fr-CH": {".", " "},
+	"es-MX": {".", ","},
+	"es-US": {".", ","},
+	"pt-PT": {",", " "},
+}
+
+// LocaleNumberFormat gives the number format of a locale, specified as a
+// BCP 47 language tag like "de-DE"
+func LocaleNumberFormat(locale string) (NumberFormat, error) {
+	tag := strings.Replace(strings.TrimSpace(locale), "_", "-", -1)
+	parts := strings.SplitN(tag, "-", 2)
+	lang := strings.ToLower(parts[0])
+	if len(parts) == 2 {
+		if chars, ok := localeNumberChars[lang+"-"+strings.ToUpper(parts[1])]; ok {
+			return NumberFormat{DecimalChar: chars[0], GroupChar: chars[1]}, nil
+		}
+	}
+	if chars, ok := localeNumberChars[lang]; ok {
+		return NumberFormat{DecimalChar: chars[0], GroupChar: chars[1]}, nil
+	}
+	return NumberFormat{}, fmt.Errorf("unknown locale: '%s'", locale)
+}
+
+// Normalize converts a number written in the format to a plain number
+// like "-1234.56", removing group characters & any symbols. plain formats
+// return value unchanged
+func (f NumberFormat) Normalize(value []byte) ([]byte, error) {
+	if f.IsPlain() {
+		return value, nil
+	}
+	str := strings.TrimSpace(string(value))
+	dec := f.DecimalChar
+	if dec == "" {
+		dec = "."
+	}
+
+	sign := ""
+	if f.Symbols {
+		var prefix, suffix string
+		str, prefix, suffix = splitNumberAffixes(str, dec)
+		if strings.Count(prefix, "-")+strings.Count(prefix, "+") > 1 || strings.IndexFunc(suffix, unicode.IsDigit) >= 0 {
+			return nil, fmt.Errorf("invalid number: %s", string(value))
+		}
+		if strings.Contains(prefix, "-") {
+			sign = "-"
+		}
+	}
+	if strings.HasPrefix(str, "-") || strings.HasPrefix(str, "+") {
+		if sign != "" {
+			return nil, fmt.Errorf("invalid number: %s", string(value))
+		}
+		sign, str = strings.TrimPrefix(str[:1], "+"), str[1:]
+	}
+
+	out := &bytes.Buffer{}
+	out.WriteString(sign)
+	digits, point, group := 0, false, false
+	for i := 0; i < len(str); {
+		r, size := utf8.DecodeRuneInString(str[i:])
+		switch {
+		case r >= '0' && r <= '9':
+			out.WriteRune(r)
+			digits++
+			group = false
+		case !point && strings.HasPrefix(str[i:], dec):
+			if group {
+				return nil, fmt.Errorf("invalid number: %s", string(value))
+			}
+			out.WriteByte('.')
+			point, size = true, len(dec)
+		case !point && digits > 0 && !group && f.isGroupChar(str[i:]) > 0:
+			group, size = true, f.isGroupChar(str[i:])
+		case (r == 'e' || r == 'E') && digits > 0 && !group:
+			out.WriteString(str[i:])
+			size = len(str) - i
+		default:
+			return nil, fmt.Errorf("invalid number: %s", string(value))
+		}
+		i += size
+	}
+	if digits == 0 || group {
+		return nil, fmt.Errorf("invalid number: %s", string(value))
+	}
+	return out.Bytes(), nil
+}
+
+// isGroupChar gives the byte length of the group character at the start
+// of str, zero if str doesn't start with a group character
+func (f NumberFormat) isGroupChar(str string) int {
+	if f.GroupChar == "" {
+		return 0
+	}
+	if strings.HasPrefix(str, f.GroupChar) {
+		return len(f.GroupChar)
+	}
+	r, size := utf8.DecodeRuneInString(str)
+	if strings.TrimSpace(f.GroupChar) == "" && unicode.IsSpace(r) {
+		return size
+	}
+	return 0
+}
+
+// splitNumberAffixes splits leading & trailing non-numeric characters
+// from a number string
+func splitNumberAffixes(str, dec string) (number, prefix, suffix string) {
+	start := -1
+	for i := range str {
+		if str[i] >= '0' && str[i] <= '9' || strings.HasPrefix(str[i:], dec) {
+			start = i
+			break
+		}
+	}
+	if start < 0 {
+		return "", str, ""
+	}
+	end := strings.LastIndexFunc(str, func(r rune) bool { return r >= '0' && r <= '9' }) + 1
+	if end < start {
+		end = start
+	}
+	return str[start:end], strings.TrimSpace(str[:start]), strings.TrimSpace(str[end:])
+}
+
+// FormatValue writes a plain number like "-1234.56" in the format,
+// grouping integer digits in threes. symbols aren't written
+func (f NumberFormat) FormatValue(plain string) string {
+	if f.IsPlain() || strings.ContainsAny(plain, "eE") {
+		return plain
+	}
+
+	sign := ""
+	if strings.HasPrefix(plain, "-") {
+		sign, plain = "-", plain[1:]
+	}
+	parts := strings.SplitN(plain, ".", 2)
+	integer := parts[0]
+	if f.GroupChar != "" && len(integer) > 3 {
+		buf := &bytes.Buffer{}
+		for i := range integer {
+			if i > 0 && (len(integer)-i)%3 == 0 {
+				buf.WriteString(f.GroupChar)
+			}
+			buf.WriteByte(integer[i])
+		}
+		integer = buf.String()
+	}
+	if len(parts) == 1 {
+		return sign + integer
+	}
+	dec := f.DecimalChar
+	if dec == "" {
+		dec = "."
+	}
+	return sign + integer + dec + parts[1]
+}
+
+// numberSymbols are the leading & trailing symbols DetectNumberFormat
+// recognizes
+var numberSymbols = map[string]bool{
+	"$": true, "€": true, "£": true, "¥": true, "₹": true, "%": true,
+	"USD": true, "EUR": true, "GBP": true, "JPY": true, "CAD": true, "AUD": true, "CHF": true,
+}
+
+// detectNumberFormats are the formats DetectNumberFormat tries, in order
+// of preference
+var detectNumberFormats = []NumberFormat{
+	{},
+	{GroupChar: ","},
+	{DecimalChar: ",", GroupChar: "."},
+	{DecimalChar: ",", GroupChar: " "},
+	{GroupChar: "'"},
+}
+
+// DetectNumberFormat finds a number format that reads all values, which
+// must include some that aren't plain numbers. values with group characters
+// must be grouped in threes, & only common currency symbols & percent signs
+// are recognized. the type of values is returned, either Integer or Float
+func DetectNumberFormat(values [][]byte) (NumberFormat, Type, bool) {
+	for _, f := range detectNumberFormats {
+		if nf, t, ok := f.detect(values); ok {
+			return nf, t, true
+		}
+	}
+	return NumberFormat{}, Unknown, false
+}
+
+// detect checks all values match a candidate format, giving the format
+// with only the features values use
+func (f NumberFormat) detect(values [][]byte) (NumberFormat, Type, bool) {
+	dec := f.DecimalChar
+	if dec == "" {
+		dec = "."
+	}
+	found := NumberFormat{}
+	t := Integer
+
+	for _, v := range values {
+		str := strings.TrimSpace(string(v))
+		number, prefix, suffix := splitNumberAffixes(str, dec)
+		prefix = strings.TrimSpace(strings.TrimLeft(prefix, "-+"))
+		if prefix != "" && !numberSymbols[prefix] || suffix != "" && !numberSymbols[suffix] {
+			return NumberFormat{}, Unknown, false
+		}
+		if prefix != "" || suffix != "" {
+			found.Symbols = true
+		}
+
+		integer, fraction, grouped, ok := f.splitNumber(strings.TrimLeft(number, "-+"), dec)
+		if !ok || integer == "" && fraction == "" {
+			return NumberFormat{}, Unknown, false
+		}
+		if grouped {
+			found.GroupChar = f.GroupChar
+		}
+		if fraction != "" || strings.Contains(number, dec) {
+			t = Float
+			if dec != "." {
+				found.DecimalChar = dec
+			}
+		}
+	}
+
+	if found.IsPlain() {
+		return NumberFormat{}, Unknown, false
+	}
+	return found, t, true
+}
+
+// splitNumber splits a unsigned number string into integer & fractional
+// digits, checking group characters separate groups of three digits
+func (f NumberFormat) splitNumber(str, dec string) (integer, fraction string, grouped, ok bool) {
+	parts := strings.SplitN(str, dec, 2)
+	if len(parts) == 2 {
+		fraction = parts[1]
+		if !allDigits(fraction) {
+			return "", "", false, false
+		}
+	}
+
+	groups := []string{}
+	start := 0
+	for i := 0; i < len(parts[0]); {
+		if size := f.isGroupChar(parts[0][i:]); size > 0 {
+			groups = append(groups, parts[0][start:i])
+			i += size
+			start = i
+			continue
+		}
+		i++
+	}
+	groups = append(groups, parts[0][start:])
+	grouped = len(groups) > 1
+
+	for i, g := range groups {
+		if !allDigits(g) || grouped && (i == 0 && (len(g) == 0 || len(g) > 3) || i > 0 && len(g) != 3) {
+			return "", "", false, false
+		}
+		integer += g
+	}
+	return integer, fraction, grouped, true
+}
+
+func allDigits(str string) bool {
+	for i := 0; i < len(str); i++ {
+		if str[i] < '0' || str[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,138 @@
+package datatypes
+
+import (
+	"testing"
+)
+
+func TestLocaleNumberFormat(t *testing.T) {
+	cases := []struct {
+		locale string
+		expect NumberFormat
+		err    string
+	}{
+		{"en", NumberFormat{DecimalChar: ".", GroupChar: ","}, ""},
+		{"en-US", NumberFormat{DecimalChar: ".", GroupChar: ","}, ""},
+		{"de-DE", NumberFormat{DecimalChar: ",", GroupChar: "."}, ""},
+		{"de_at", NumberFormat{DecimalChar: ",", GroupChar: "."}, ""},
+		{"de-CH", NumberFormat{DecimalChar: ".", GroupChar: "'"}, ""},
+		{"FR", NumberFormat{DecimalChar: ",", GroupChar: " "}, ""},
+		{"", NumberFormat{}, "unknown locale: ''"},
+		{"xx-YY", NumberFormat{}, "unknown locale: 'xx-YY'"},
+	}
+
+	for i, c := range cases {
+		got, err := LocaleNumberFormat(c.locale)
+		if !(err == nil && c.err == "" || err != nil && err.Error() == c.err) {
+			t.Errorf("case %d error mismatch. expected: '%s', got: '%s'", i, c.err, err)
+			continue
+		}
+		if got != c.expect {
+			t.Errorf("case %d mismatch. expected: %v, got: %v", i, c.expect, got)
+		}
+	}
+}
+
+func TestNumberFormatNormalize(t *testing.T) {
+	en := NumberFormat{GroupChar: ","}
+	de := NumberFormat{DecimalChar: ",", GroupChar: "."}
+	fr := NumberFormat{DecimalChar: ",", GroupChar: " "}
+	cases := []struct {
+		format NumberFormat
+		input  string
+		expect string
+		err    string
+	}{
+		{NumberFormat{}, "1,234", "1,234", ""},
+		{en, "1,234", "1234", ""},
+		{en, "-1,234,567.89", "-1234567.89", ""},
+		{en, "1234", "1234", ""},
+		{en, "1.5e3", "1.5e3", ""},
+		{en, ",123", "", "invalid number: ,123"},
+		{en, "1,,234", "", "invalid number: 1,,234"},
+		{en, "1,234,", "", "invalid number: 1,234,"},
+		{en, "$1,234", "", "invalid number: $1,234"},
+		{de, "1.234,5", "1234.5", ""},
+		{de, "+12,75", "12.75", ""},
+		{de, "1,2,3", "", "invalid number: 1,2,3"},
+		{fr, "1 234,5", "1234.5", ""},
+		{fr, "1 234,5", "1234.5", ""},
+		{fr, "1 234 567", "1234567", ""},
+		{NumberFormat{Symbols: true}, "45%", "45", ""},
+		{NumberFormat{Symbols: true}, "$12.50", "12.50", ""},
+		{NumberFormat{Symbols: true}, "-$12.50", "-12.50", ""},
+		{NumberFormat{Symbols: true}, "EUR 12", "12", ""},
+		{NumberFormat{Symbols: true}, "$", "", "invalid number: $"},
+		{NumberFormat{Symbols: true}, "-$-12", "", "invalid number: -$-12"},
+		{NumberFormat{Symbols: true}, "1 of 2", "", "invalid number: 1 of 2"},
+		{NumberFormat{DecimalChar: ",", GroupChar: ".", Symbols: true}, "1.234,50 €", "1234.50", ""},
+	}
+
+	for i, c := range cases {
+		got, err := c.format.Normalize([]byte(c.input))
+		if !(err == nil && c.err == "" || err != nil && err.Error() == c.err) {
+			t.Errorf("case %d error mismatch. expected: '%s', got: '%s'", i, c.err, err)
+			continue
+		}
+		if string(got) != c.expect {
+			t.Errorf("case %d mismatch. expected: '%s', got: '%s'", i, c.expect, string(got))
+		}
+	}
+}
+
+func TestNumberFormatFormatValue(t *testing.T) {
+	cases := []struct {
+		format NumberFormat
+		input  string
+		expect string
+	}{
+		{NumberFormat{}, "1234567.5", "1234567.5"},
+		{NumberFormat{GroupChar: ","}, "1234567.5", "1,234,567.5"},
+		{NumberFormat{GroupChar: ","}, "-123", "-123"},
+		{NumberFormat{GroupChar: ","}, "-1234", "-1,234"},
+		{NumberFormat{DecimalChar: ",", GroupChar: "."}, "1234.5", "1.234,5"},
+		{NumberFormat{DecimalChar: ","}, "1234.5", "1234,5"},
+		{NumberFormat{DecimalChar: ",", GroupChar: " "}, "1234567", "1 234 567"},
+		{NumberFormat{GroupChar: ","}, "1.5e10", "1.5e10"},
+	}
+
+	for i, c := range cases {
+		if got := c.format.FormatValue(c.input); got != c.expect {
+			t.Errorf("case %d mismatch. expected: '%s', got: '%s'", i, c.expect, got)
+		}
+	}
+}
+
+func TestDetectNumberFormat(t *testing.T) {
+	cases := []struct {
+		values []string
+		format NumberFormat
+		typ    Type
+		ok     bool
+	}{
+		{[]string{"1", "2", "3"}, NumberFormat{}, Unknown, false},
+		{[]string{"1,234", "56", "7,890,123"}, NumberFormat{GroupChar: ","}, Integer, true},
+		{[]string{"1,234.5", "56"}, NumberFormat{GroupChar: ","}, Float, true},
+		{[]string{"55,5", "44,4"}, NumberFormat{DecimalChar: ","}, Float, true},
+		{[]string{"1.234,5", "0,25"}, NumberFormat{DecimalChar: ",", GroupChar: "."}, Float, true},
+		{[]string{"1 234,5", "12 345"}, NumberFormat{DecimalChar: ",", GroupChar: " "}, Float, true},
+		{[]string{"1'234.50", "12"}, NumberFormat{GroupChar: "'"}, Float, true},
+		{[]string{"$1,234.00", "$12"}, NumberFormat{GroupChar: ",", Symbols: true}, Float, true},
+		{[]string{"45%", "-3%"}, NumberFormat{Symbols: true}, Integer, true},
+		{[]string{"12,34", "1,234"}, NumberFormat{DecimalChar: ","}, Float, true},
+		{[]string{"12,3456"}, NumberFormat{DecimalChar: ","}, Float, true},
+		{[]string{"1,23,456"}, NumberFormat{}, Unknown, false},
+		{[]string{"#12"}, NumberFormat{}, Unknown, false},
+		{[]string{"1,234", "apples"}, NumberFormat{}, Unknown, false},
+	}
+
+	for i, c := range cases {
+		values := make([][]byte, len(c.values))
+		for j, v := range c.values {
+			values[j] = []byte(v)
+		}
+		format, typ, ok := DetectNumberFormat(values)
+		if ok != c.ok || format != c.format || typ != c.typ {
+			t.Errorf("case %d mismatch. expected: %v %s %t, got: %v %s %t", i, c.format, c.typ, c.ok, format, typ, ok)
+		}
+	}
+}
@@ -0,0 +1,437 @@
+package datatypes
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// datePatterns are the date formats "any" accepts, in order of preference.
+// ambiguous day & month orders favour month first
+var datePatterns = []string{
+	"%Y-%m-%d",
+	"%Y/%m/%d",
+	"%m/%d/%Y",
+	"%d/%m/%Y",
+	"%m-%d-%Y",
+	"%d-%m-%Y",
+	"%d.%m.%Y",
+	"%m/%d/%y",
+	"%d/%m/%y",
+	"%d %b %Y",
+	"%d-%b-%Y",
+	"%d %B %Y",
+	"%b %d, %Y",
+	"%B %d, %Y",
+}
+
+// timePatterns are the time formats "any" accepts, in order of preference
+var timePatterns = []string{
+	"%H:%M:%S",
+	"%H:%M",
+	"%I:%M:%S %p",
+	"%I:%M %p",
+	"%I:%M%p",
+}
+
+// dateTimePatterns are the datetime formats "any" accepts in addition to
+// the default layouts, in order of preference
+var dateTimePatterns = []string{
+	"%Y-%m-%dT%H:%M:%S",
+	"%Y-%m-%d %H:%M:%S",
+	"%Y-%m-%d %H:%M:%S%z",
+	"%Y-%m-%d %H:%M",
+	"%m/%d/%Y %H:%M:%S",
+	"%m/%d/%Y %H:%M",
+	"%d/%m/%Y %H:%M:%S",
+	"%d/%m/%Y %H:%M",
+	"%m/%d/%Y %I:%M:%S %p",
+	"%m/%d/%Y %I:%M %p",
+	"%d.%m.%Y %H:%M:%S",
+	"%d.%m.%Y %H:%M",
+}
+
+// default go layouts for each type. values are written using the first
+// layout. datetime defaults include the layouts ParseDate accepted before
+// dates & datetimes were separate types
+var (
+	dateLayouts     = []string{"2006-01-02"}
+	timeLayouts     = []string{"15:04:05.999999999", "15:04:05.999999999Z07:00"}
+	dateTimeLayouts = []string{
+		time.RFC3339Nano,
+		time.ANSIC,
+		time.UnixDate,
+		time.RubyDate,
+		time.RFC822,
+		time.RFC822Z,
+		time.RFC850,
+		time.RFC1123,
+		time.RFC1123Z,
+	}
+)
+
+// formats that accept values in any known format, used when parsing
+// values without a field
+var (
+	anyDate, _     = ParseTimeFormat(Date, "any")
+	anyTime, _     = ParseTimeFormat(Time, "any")
+	anyDateTime, _ = ParseTimeFormat(DateTime, "any")
+)
+
+// TimeFormat is a format for date, time & datetime values read from a
+// field's Format. formats are a strftime-style pattern like "%d/%m/%Y",
+// "default" for ISO 8601 values, or "any" to accept any known pattern.
+// the empty string is the same as "any". all formats write values as
+// ISO 8601 unless they specify a pattern.
+//
+// a format can end with a ";tz=" option setting the time zone of values
+// that don't specify one, either an IANA name or a UTC offset, like
+// "%Y-%m-%d %H:%M;tz=America/New_York" or "tz=+05:30"
+type TimeFormat struct {
+	// Type is the datatype of values, one of Date, Time or DateTime
+	Type Type
+	// Pattern is the format string this format was read from, without
+	// the time zone option
+	Pattern string
+	// Location is the time zone of values that don't specify one, set by
+	// the format's tz option. UTC if nil. date values are always UTC
+	Location *time.Location
+
+	// go layouts tried in order when parsing
+	layouts []string
+	// go layout for writing values, empty for the type's default
+	layout string
+}
+
+// ParseTimeFormat reads the format of values of type t from a field
+// format string
+func ParseTimeFormat(t Type, format string) (TimeFormat, error) {
+	format, tz := splitTimeZone(format)
+	f := TimeFormat{Type: t, Pattern: format}
+
+	var defaults, patterns []string
+	switch t {
+	case Date:
+		defaults, patterns = dateLayouts, datePatterns
+	case Time:
+		defaults, patterns = timeLayouts, timePatterns
+	case DateTime:
+		defaults, patterns = dateTimeLayouts, dateTimePatterns
+	default:
+		return f, fmt.Errorf("%s values have no time format", t.String())
+	}
+
+	if tz != "" {
+		loc, err := parseTimeZone(tz)
+		if err != nil {
+			return f, err
+		}
+		f.Location = loc
+	}
+
+	switch strings.TrimSpace(format) {
+	case "default":
+		f.layouts = defaults
+	case "", "any":
+		f.layouts = append([]string{}, defaults...)
+		if t == Date {
+			// dates used to be points in time, so full timestamps are
+			// accepted & truncated to their date
+			f.layouts = append(f.layouts, dateTimeLayouts...)
+		}
+		for _, p := range patterns {
+			layout, err := strftimeLayout(p, true)
+			if err != nil {
+				return f, err
+			}
+			f.layouts = append(f.layouts, layout)
+		}
+	default:
+		layout, err := strftimeLayout(format, true)
+		if err != nil {
+			return f, err
+		}
+		if f.layout, err = strftimeLayout(format, false); err != nil {
+			return f, err
+		}
+		f.layouts = []string{layout}
+	}
+	return f, nil
+}
+
+// ParseValue converts raw bytes to a time.Time value using the format
+func (f TimeFormat) ParseValue(value []byte) (time.Time, error) {
+	loc := f.Location
+	if loc == nil || f.Type == Date {
+		loc = time.UTC
+	}
+	str := strings.TrimSpace(string(value))
+	for _, layout := range f.layouts {
+		if t, err := time.ParseInLocation(layout, str, loc); err == nil {
+			if f.Type == Date {
+				t = time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+			}
+			return t, nil
+		}
+	}
+	if f.layout != "" {
+		return time.Time{}, fmt.Errorf("invalid %s: %s. expected format '%s'", f.Type.String(), string(value), f.Pattern)
+	}
+	return time.Time{}, fmt.Errorf("invalid %s: %s", f.Type.String(), string(value))
+}
+
+// FormatValue encodes a time.Time value using the format
+func (f TimeFormat) FormatValue(t time.Time) string {
+	if f.Location != nil && f.Type == DateTime {
+		t = t.In(f.Location)
+	}
+	if f.layout != "" {
+		return t.Format(f.layout)
+	}
+	switch f.Type {
+	case Date:
+		return t.Format(dateLayouts[0])
+	case Time:
+		// times only include a zone if one was specified
+		if t.Location() == time.UTC {
+			return t.Format(timeLayouts[0])
+		}
+		return t.Format(timeLayouts[1])
+	default:
+		return t.Format(dateTimeLayouts[0])
+	}
+}
+
+// splitTimeZone separates a format's pattern from its tz option
+func splitTimeZone(format string) (pattern, tz string) {
+	if strings.HasPrefix(format, "tz=") {
+		return "", strings.TrimSpace(format[3:])
+	}
+	if i := strings.LastIndex(format, ";tz="); i >= 0 {
+		return format[:i], strings.TrimSpace(format[i+4:])
+	}
+	return format, ""
+}
+
+// parseTimeZone loads a time zone from an IANA name like "Europe/Berlin",
+// or a fixed UTC offset like "+05:30" or "-0800"
+func parseTimeZone(tz string) (*time.Location, error) {
+	if len(tz) == 5 || len(tz) == 6 {
+		if tz[0] == '+' || tz[0] == '-' {
+			if t, err := time.Parse("-0700", strings.Replace(tz, ":", "", 1)); err == nil {
+				_, offset := t.Zone()
+				return time.FixedZone("", offset), nil
+			}
+		}
+	}
+	loc, err := time.LoadLocation(tz)
+	if err != nil {
+		return nil, fmt.Errorf("invalid time zone: '%s'", tz)
+	}
+	return loc, nil
+}
+
+// DetectTimeFormat finds a format pattern that parses all values of type
+// t, returning the empty string if values parse with the default format
+// or no known pattern matches every value
+func DetectTimeFormat(t Type, values [][]byte) string {
+	var patterns []string
+	switch t {
+	case Date:
+		patterns = datePatterns
+	case Time:
+		patterns = timePatterns
+	case DateTime:
+		patterns = dateTimePatterns
+	default:
+		return ""
+	}
+
+	if f, err := ParseTimeFormat(t, "default"); err == nil && f.parsesAll(values) {
+		return ""
+	}
+	for _, p := range patterns {
+		if f, err := ParseTimeFormat(t, p); err == nil && f.parsesAll(values) {
+			return p
+		}
+	}
+	return ""
+}
+
+// parsesAll reports weather every value can be read with the format
+func (f TimeFormat) parsesAll(values [][]byte) bool {
+	for _, v := range values {
+		if _, err := f.ParseValue(v); err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// strftimeLayout converts a strftime pattern to a go time layout. parse
+// layouts accept unpadded numbers for zero-padded directives
+func strftimeLayout(pattern string, parse bool) (string, error) {
+	layout := &bytes.Buffer{}
+	for i := 0; i < len(pattern); i++ {
+		c := pattern[i]
+		if c != '%' {
+			if c >= '0' && c <= '9' {
+				return "", fmt.Errorf("invalid time format: '%s'. patterns cannot contain literal digits", pattern)
+			}
+			layout.WriteByte(c)
+			continue
+		}
+
+		i++
+		if i == len(pattern) {
+			return "", fmt.Errorf("invalid time format: '%s'. pattern ends with '%%'", pattern)
+		}
+		d, ok := strftimeDirectives[pattern[i]]
+		if !ok {
+			return "", fmt.Errorf("invalid time format: '%s'. unsupported directive '%%%c'", pattern, pattern[i])
+		}
+		if p, ok := strftimeParseDirectives[pattern[i]]; ok && parse {
+			d = p
+		}
+		layout.WriteString(d)
+	}
+	return layout.String(), nil
+}
+
+// strftimeDirectives maps strftime directives to go layout elements
+var strftimeDirectives = map[byte]string{
+	'Y': "2006",
+	'y': "06",
+	'm': "01",
+	'd': "02",
+	'e': "_2",
+	'b': "Jan",
+	'h': "Jan",
+	'B': "January",
+	'a': "Mon",
+	'A': "Monday",
+	'H': "15",
+	'I': "03",
+	'M': "04",
+	'S': "05",
+	'f': "000000",
+	'p': "PM",
+	'z': "-0700",
+	'Z': "MST",
+	'F': "2006-01-02",
+	'T': "15:04:05",
+	'D': "01/02/06",
+	'%': "%",
+}
+
+// strftimeParseDirectives are layout elements that parse values with or
+// without zero padding
+var strftimeParseDirectives = map[byte]string{
+	'm': "1",
+	'd': "2",
+	'I': "3",
+	'F': "2006-1-2",
+	'D': "1/2/06",
+}
+
+// units of ISO 8601 durations. years & months have no fixed length,
+// and are approximated as 365 & 30 days
+var durationUnits = map[byte]time.Duration{
+	'Y': 365 * 24 * time.Hour,
+	'W': 7 * 24 * time.Hour,
+	'D': 24 * time.Hour,
+	'H': time.Hour,
+	'S': time.Second,
+}
+
+// ParseDuration converts raw bytes to a time.Duration value. values are
+// ISO 8601 durations like "P1DT2H30M", or go durations like "2h30m"
+func ParseDuration(value []byte) (time.Duration, error) {
+	str := strings.TrimSpace(string(value))
+	neg := strings.HasPrefix(str, "-")
+	iso := strings.TrimPrefix(str, "-")
+	if !strings.HasPrefix(iso, "P") {
+		return time.ParseDuration(str)
+	}
+
+	var d time.Duration
+	inTime, components := false, 0
+	for rest := iso[1:]; rest != ""; {
+		if rest[0] == 'T' {
+			if inTime {
+				return 0, fmt.Errorf("invalid duration: %s", str)
+			}
+			inTime, rest = true, rest[1:]
+			continue
+		}
+
+		end := strings.IndexFunc(rest, func(r rune) bool { return (r < '0' || r > '9') && r != '.' })
+		if end <= 0 {
+			return 0, fmt.Errorf("invalid duration: %s", str)
+		}
+		num, err := strconv.ParseFloat(rest[:end], 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid duration: %s", str)
+		}
+
+		unit, ok := durationUnits[rest[end]]
+		switch {
+		case rest[end] == 'M' && inTime:
+			unit, ok = time.Minute, true
+		case rest[end] == 'M':
+			unit, ok = 30*24*time.Hour, true
+		case inTime != (rest[end] == 'H' || rest[end] == 'S'):
+			ok = false
+		}
+		if !ok {
+			return 0, fmt.Errorf("invalid duration: %s", str)
+		}
+		d += time.Duration(num * float64(unit))
+		components++
+		rest = rest[end+1:]
+	}
+	if components == 0 || strings.HasSuffix(iso, "T") {
+		return 0, fmt.Errorf("invalid duration: %s", str)
+	}
+
+	if neg {
+		d = -d
+	}
+	return d, nil
+}
+
+// isISODuration reports weather value is an ISO 8601 duration. go
+// durations like "1h" are too easily confused with other text to detect
+func isISODuration(value []byte) bool {
+	if !bytes.HasPrefix(bytes.TrimPrefix(value, []byte("-")), []byte("P")) {
+		return false
+	}
+	_, err := ParseDuration(value)
+	return err == nil
+}
+
+// durationString writes a duration in ISO 8601 format, using hours as
+// the largest unit
+func durationString(d time.Duration) string {
+	if d == 0 {
+		return "PT0S"
+	}
+	str := "PT"
+	if d < 0 {
+		str, d = "-PT", -d
+	}
+	if h := d / time.Hour; h > 0 {
+		str += strconv.FormatInt(int64(h), 10) + "H"
+		d -= h * time.Hour
+	}
+	if m := d / time.Minute; m > 0 {
+		str += strconv.FormatInt(int64(m), 10) + "M"
+		d -= m * time.Minute
+	}
+	if d > 0 {
+		str += strconv.FormatFloat(d.Seconds(), 'f', -1, 64) + "S"
+	}
+	return str
+}
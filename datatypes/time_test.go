@@ -0,0 +1,157 @@
+package datatypes
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTimeFormat(t *testing.T) {
+	est := time.FixedZone("", -5*3600)
+	cases := []struct {
+		t      Type
+		format string
+		input  string
+		expect time.Time
+		output string
+		err    string
+	}{
+		{Date, "", "2021-03-15", time.Date(2021, 3, 15, 0, 0, 0, 0, time.UTC), "2021-03-15", ""},
+		{Date, "", "03/15/2021", time.Date(2021, 3, 15, 0, 0, 0, 0, time.UTC), "2021-03-15", ""},
+		{Date, "any", "15 Mar 2021", time.Date(2021, 3, 15, 0, 0, 0, 0, time.UTC), "2021-03-15", ""},
+		{Date, "", "2015-09-03T13:27:52Z", time.Date(2015, 9, 3, 0, 0, 0, 0, time.UTC), "2015-09-03", ""},
+		{Date, "", "2015-09-03T23:27:52-05:00", time.Date(2015, 9, 3, 0, 0, 0, 0, time.UTC), "2015-09-03", ""},
+		{Date, "", "Thu Sep  3 13:27:52 2015", time.Date(2015, 9, 3, 0, 0, 0, 0, time.UTC), "2015-09-03", ""},
+		{Date, "default", "2021-03-15", time.Date(2021, 3, 15, 0, 0, 0, 0, time.UTC), "2021-03-15", ""},
+		{Date, "default", "03/15/2021", time.Time{}, "", "invalid date: 03/15/2021"},
+		{Date, "%d/%m/%Y", "15/03/2021", time.Date(2021, 3, 15, 0, 0, 0, 0, time.UTC), "15/03/2021", ""},
+		{Date, "%d/%m/%Y", "5/3/2021", time.Date(2021, 3, 5, 0, 0, 0, 0, time.UTC), "05/03/2021", ""},
+		{Date, "%d/%m/%Y", "2021-03-15", time.Time{}, "", "invalid date: 2021-03-15. expected format '%d/%m/%Y'"},
+		{Date, "%B %e, %Y", "March 5, 2021", time.Date(2021, 3, 5, 0, 0, 0, 0, time.UTC), "March  5, 2021", ""},
+		{Time, "", "13:27:52", time.Date(0, 1, 1, 13, 27, 52, 0, time.UTC), "13:27:52", ""},
+		{Time, "", "13:27:52.5-05:00", time.Date(0, 1, 1, 13, 27, 52, 500000000, est), "13:27:52.5-05:00", ""},
+		{Time, "", "1:27 PM", time.Date(0, 1, 1, 13, 27, 0, 0, time.UTC), "13:27:00", ""},
+		{Time, "%I:%M %p", "01:27 PM", time.Date(0, 1, 1, 13, 27, 0, 0, time.UTC), "01:27 PM", ""},
+		{DateTime, "", "2021-03-15T13:27:52Z", time.Date(2021, 3, 15, 13, 27, 52, 0, time.UTC), "2021-03-15T13:27:52Z", ""},
+		{DateTime, "", "2021-03-15T13:27:52-05:00", time.Date(2021, 3, 15, 13, 27, 52, 0, est), "2021-03-15T13:27:52-05:00", ""},
+		{DateTime, "", "2021-03-15 13:27", time.Date(2021, 3, 15, 13, 27, 0, 0, time.UTC), "2021-03-15T13:27:00Z", ""},
+		{DateTime, "", "Mon Jan  2 15:04:05 2006", time.Date(2006, 1, 2, 15, 4, 5, 0, time.UTC), "2006-01-02T15:04:05Z", ""},
+		{DateTime, "%Y-%m-%d %H:%M:%S%z", "2021-03-15 13:27:52-0500", time.Date(2021, 3, 15, 13, 27, 52, 0, est), "2021-03-15 13:27:52-0500", ""},
+		{DateTime, "%d/%m/%Y %H:%M:%S.%f", "15/03/2021 13:27:52.250000", time.Date(2021, 3, 15, 13, 27, 52, 250000000, time.UTC), "15/03/2021 13:27:52.250000", ""},
+		{DateTime, "%Y-%m-%d %H:%M", "2021-03-15", time.Time{}, "", "invalid datetime: 2021-03-15. expected format '%Y-%m-%d %H:%M'"},
+		{DateTime, "%Y-%m-%d %H:%M;tz=-05:00", "2021-03-15 17:00", time.Date(2021, 3, 15, 22, 0, 0, 0, time.UTC), "2021-03-15 17:00", ""},
+		{DateTime, "tz=+09:00", "2021-03-15 09:00", time.Date(2021, 3, 15, 0, 0, 0, 0, time.UTC), "2021-03-15T09:00:00+09:00", ""},
+		{DateTime, "tz=+0900", "2021-03-15T09:00:00Z", time.Date(2021, 3, 15, 9, 0, 0, 0, time.UTC), "2021-03-15T18:00:00+09:00", ""},
+		{DateTime, "any;tz=America/New_York", "2021-07-01 12:00", time.Date(2021, 7, 1, 16, 0, 0, 0, time.UTC), "2021-07-01T12:00:00-04:00", ""},
+		{DateTime, "%Y;tz=Mars/Olympus", "", time.Time{}, "", "invalid time zone: 'Mars/Olympus'"},
+		{Date, "%Y-%q", "", time.Time{}, "", "invalid time format: '%Y-%q'. unsupported directive '%q'"},
+		{Date, "%Y-%", "", time.Time{}, "", "invalid time format: '%Y-%'. pattern ends with '%'"},
+		{Date, "%Y-01", "", time.Time{}, "", "invalid time format: '%Y-01'. patterns cannot contain literal digits"},
+		{Integer, "%Y", "", time.Time{}, "", "integer values have no time format"},
+	}
+
+	for i, c := range cases {
+		f, err := ParseTimeFormat(c.t, c.format)
+		if err == nil {
+			var got time.Time
+			if got, err = f.ParseValue([]byte(c.input)); err == nil {
+				if !got.Equal(c.expect) {
+					t.Errorf("case %d value mismatch. expected: %s, got: %s", i, c.expect, got)
+				}
+				if out := f.FormatValue(got); out != c.output {
+					t.Errorf("case %d output mismatch. expected: '%s', got: '%s'", i, c.output, out)
+				}
+			}
+		}
+		if !(err == nil && c.err == "" || err != nil && err.Error() == c.err) {
+			t.Errorf("case %d error mismatch. expected: '%s', got: '%s'", i, c.err, err)
+		}
+	}
+}
+
+func TestTimeFormatLocation(t *testing.T) {
+	f, err := ParseTimeFormat(DateTime, "%Y-%m-%d %H:%M;tz=+09:00")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.Pattern != "%Y-%m-%d %H:%M" || f.Location == nil {
+		t.Errorf("format mismatch. expected pattern & location, got: '%s' %v", f.Pattern, f.Location)
+	}
+	got, err := f.ParseValue([]byte("2021-03-15 09:00"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expect := time.Date(2021, 3, 15, 0, 0, 0, 0, time.UTC); !got.Equal(expect) {
+		t.Errorf("value mismatch. expected: %s, got: %s", expect, got)
+	}
+}
+
+func TestDetectTimeFormat(t *testing.T) {
+	cases := []struct {
+		t      Type
+		values []string
+		expect string
+	}{
+		{Date, []string{"2021-03-15", "2021-04-01"}, ""},
+		{Date, []string{"03/15/2021", "04/01/2021"}, "%m/%d/%Y"},
+		{Date, []string{"04/01/2021", "15/03/2021"}, "%d/%m/%Y"},
+		{Date, []string{"15.03.2021"}, "%d.%m.%Y"},
+		{Date, []string{"03/15/2021", "15/03/2021"}, ""},
+		{Time, []string{"13:27:52"}, ""},
+		{Time, []string{"13:27"}, "%H:%M"},
+		{Time, []string{"1:27 PM", "11:00 AM"}, "%I:%M %p"},
+		{DateTime, []string{"2021-03-15T13:27:52Z"}, ""},
+		{DateTime, []string{"2021-03-15 13:27:52"}, "%Y-%m-%d %H:%M:%S"},
+		{String, []string{"2021-03-15"}, ""},
+	}
+
+	for i, c := range cases {
+		values := make([][]byte, len(c.values))
+		for j, v := range c.values {
+			values[j] = []byte(v)
+		}
+		if got := DetectTimeFormat(c.t, values); got != c.expect {
+			t.Errorf("case %d mismatch. expected: '%s', got: '%s'", i, c.expect, got)
+		}
+	}
+}
+
+func TestParseDuration(t *testing.T) {
+	cases := []struct {
+		input  string
+		expect time.Duration
+		output string
+		err    string
+	}{
+		{"PT0S", 0, "PT0S", ""},
+		{"PT1H30M", 90 * time.Minute, "PT1H30M", ""},
+		{"P1DT2H", 26 * time.Hour, "PT26H", ""},
+		{"P1W", 7 * 24 * time.Hour, "PT168H", ""},
+		{"PT0.5S", 500 * time.Millisecond, "PT0.5S", ""},
+		{"-PT90S", -90 * time.Second, "-PT1M30S", ""},
+		{"P1M", 30 * 24 * time.Hour, "PT720H", ""},
+		{"1h30m", 90 * time.Minute, "PT1H30M", ""},
+		{"P", 0, "", "invalid duration: P"},
+		{"PT", 0, "", "invalid duration: PT"},
+		{"P1H", 0, "", "invalid duration: P1H"},
+		{"PT1D", 0, "", "invalid duration: PT1D"},
+		{"P1DT", 0, "", "invalid duration: P1DT"},
+		{"PTH", 0, "", "invalid duration: PTH"},
+		{"P1", 0, "", "invalid duration: P1"},
+	}
+
+	for i, c := range cases {
+		got, err := ParseDuration([]byte(c.input))
+		if !(err == nil && c.err == "" || err != nil && err.Error() == c.err) {
+			t.Errorf("case %d error mismatch. expected: '%s', got: '%s'", i, c.err, err)
+			continue
+		}
+		if got != c.expect {
+			t.Errorf("case %d value mismatch. expected: %s, got: %s", i, c.expect, got)
+		}
+		if c.err == "" {
+			if out := durationString(got); out != c.output {
+				t.Errorf("case %d output mismatch. expected: '%s', got: '%s'", i, c.output, out)
+			}
+		}
+	}
+}
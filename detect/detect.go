@@ -1,6 +1,7 @@
 package detect
 
 import (
+	"bufio"
 	"errors"
 	"fmt"
 	"io"
@@ -10,6 +11,8 @@ import (
 	"strings"
 
 	"github.com/qri-io/dataset"
+	"github.com/qri-io/dataset/charset"
+	"github.com/qri-io/dataset/compression"
 )
 
 var (
@@ -19,14 +22,15 @@ var (
 )
 
 // FromFile takes a filepath & tries to work out the corresponding dataset
-// for the sake of speed, it only works with files that have a recognized extension
+// for the sake of speed, it only works with files that have a recognized extension.
+// compressed files are detected by extension (eg: data.csv.gz) or by sniffing
+// the start of the file
 func FromFile(path string) (ds *dataset.Structure, err error) {
 	// if filepath.Base(path) == dataset.Filename {
 	// 	return nil, fmt.Errorf("cannot determine schema of a %s file", dataset.Filename)
 	// }
 
-	format, err := ExtensionDataFormat(path)
-	if err != nil {
+	if _, err := ExtensionDataFormat(trimCompressionExt(path)); err != nil {
 		return nil, err
 	}
 
@@ -34,17 +38,83 @@ func FromFile(path string) (ds *dataset.Structure, err error) {
 	if err != nil {
 		return nil, err
 	}
+	defer f.Close()
 
-	return Structure(format, f)
+	return FromReader(path, f)
 }
 
 // FromReader is a shorthand for a path/filename and reader
 func FromReader(path string, data io.Reader) (ds *dataset.Structure, err error) {
-	format, err := ExtensionDataFormat(path)
+	format, err := ExtensionDataFormat(trimCompressionExt(path))
+	if err != nil {
+		return nil, err
+	}
+
+	comp, data, err := compression.Sniff(data)
 	if err != nil {
 		return nil, err
 	}
-	return Structure(format, data)
+	if ext := compression.ExtensionType(filepath.Ext(path)); ext != compression.None {
+		comp = ext
+	}
+
+	rc, err := compression.NewReader(comp, data)
+	if err != nil {
+		return nil, fmt.Errorf("error reading %s data: %s", comp.String(), err.Error())
+	}
+	defer rc.Close()
+
+	var (
+		text io.Reader = rc
+		enc  string
+	)
+	if format != dataset.XLSDataFormat && format != dataset.ParquetDataFormat {
+		if text, enc, err = Encoding(rc); err != nil {
+			return nil, err
+		}
+	}
+
+	// field detection is given the sniffed encoding, so readers know text
+	// has already been transcoded to utf-8
+	ds = &dataset.Structure{
+		Format:      format,
+		Compression: comp,
+		Encoding:    enc,
+		Schema:      &dataset.Schema{},
+	}
+	ds.Schema.Fields, err = Fields(ds, text)
+	return ds, err
+}
+
+// Encoding guesses the character encoding of a reader from byte order marks
+// & the content of the start of the stream, returning a reader of the data
+// transcoded to utf-8. the returned encoding name is empty for utf-8 data
+// without a byte order mark
+func Encoding(data io.Reader) (text io.Reader, encoding string, err error) {
+	br := bufio.NewReader(data)
+	header, err := br.Peek(4)
+	if err != nil && err != io.EOF {
+		return nil, "", err
+	}
+
+	encoding, sniffed, err := charset.Sniff(br)
+	if err != nil {
+		return nil, "", err
+	}
+	if encoding == charset.UTF8 && charset.SniffBOM(header) == "" {
+		encoding = ""
+	}
+
+	text, err = charset.NewReader(encoding, sniffed)
+	return text, encoding, err
+}
+
+// trimCompressionExt removes compression extensions from a path
+func trimCompressionExt(path string) string {
+	if compression.ExtensionType(filepath.Ext(path)) != compression.None {
+		return strings.TrimSuffix(path, filepath.Ext(path))
+	}
+	return path
 }
 
 // Structure attemptes to extract a reader based on a given format and data reader
@@ -109,8 +179,14 @@ func ExtensionDataFormat(path string) (format dataset.DataFormat, err error) {
 		return dataset.JSONDataFormat, nil
 	case ".xml":
 		return dataset.XMLDataFormat, nil
-	case ".xls":
+	case ".xls", ".xlsx":
 		return dataset.XLSDataFormat, nil
+	case ".ndjson", ".jsonl":
+		return dataset.NDJSONDataFormat, nil
+	case ".parquet":
+		return dataset.ParquetDataFormat, nil
+	case ".geojson":
+		return dataset.GeoJSONDataFormat, nil
 	case "":
 		return dataset.UnknownDataFormat, errors.New("no file extension provided")
 	default:
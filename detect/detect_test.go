@@ -3,9 +3,14 @@ package detect
 import (
 	"encoding/json"
 	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/qri-io/dataset"
+	"github.com/qri-io/dataset/compression"
+	"github.com/qri-io/dataset/dsio"
 )
 
 func TestFromFile(t *testing.T) {
@@ -17,6 +22,9 @@ func TestFromFile(t *testing.T) {
 		{"testdata/hours.csv", "testdata/hours.resource.json", nil},
 		{"testdata/spelling.csv", "testdata/spelling.resource.json", nil},
 		{"testdata/daily_wind_2011.csv", "testdata/daily_wind_2011.resource.json", nil},
+		{"testdata/cities.xml", "testdata/cities.resource.json", nil},
+		{"testdata/city_events.ndjson", "testdata/city_events.resource.json", nil},
+		{"testdata/city_counts.jsonl", "testdata/city_counts.resource.json", nil},
 	}
 
 	for i, c := range cases {
@@ -66,6 +74,112 @@ func TestFromFile(t *testing.T) {
 	}
 }
 
+func TestFromFileCompressed(t *testing.T) {
+	cases := []struct {
+		inpath, dspath string
+		compression    compression.Type
+	}{
+		{"testdata/hours.csv.gz", "testdata/hours.resource.json", compression.Gzip},
+		{"testdata/hours-with-header.csv.zst", "testdata/hours-with-header.resource.json", compression.Zstd},
+		{"testdata/spelling.csv.bz2", "testdata/spelling.resource.json", compression.Bzip2},
+	}
+
+	for i, c := range cases {
+		data, err := ioutil.ReadFile(c.dspath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		expect := &dataset.Structure{}
+		if err := json.Unmarshal(data, expect); err != nil {
+			t.Fatal(err)
+		}
+
+		ds, err := FromFile(c.inpath)
+		if err != nil {
+			t.Errorf("case %d unexpected error: %s", i, err.Error())
+			continue
+		}
+		if ds.Compression != c.compression {
+			t.Errorf("case %d compression mismatch. expected: %s, got: %s", i, c.compression, ds.Compression)
+		}
+		if err := dataset.CompareSchemas(expect.Schema, ds.Schema); err != nil {
+			t.Errorf("case %d schema mismatch: %s", i, err.Error())
+		}
+
+		// compression should also be sniffed without a compression extension
+		f, err := os.Open(c.inpath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ds, err = FromReader(strings.TrimSuffix(c.inpath, filepath.Ext(c.inpath)), f)
+		f.Close()
+		if err != nil {
+			t.Errorf("case %d unexpected error sniffing compression: %s", i, err.Error())
+			continue
+		}
+		if ds.Compression != c.compression {
+			t.Errorf("case %d sniffed compression mismatch. expected: %s, got: %s", i, c.compression, ds.Compression)
+		}
+	}
+}
+
+func TestFromFileEncoding(t *testing.T) {
+	cases := []struct {
+		inpath   string
+		encoding string
+	}{
+		{"testdata/cafes.csv", "utf-8"},
+		{"testdata/cafes_latin1.csv", "windows-1252"},
+		{"testdata/cafes_utf16.csv", "utf-16le"},
+		{"testdata/cafes_latin1.xml", "windows-1252"},
+		{"testdata/spelling.csv", ""},
+	}
+
+	for i, c := range cases {
+		ds, err := FromFile(c.inpath)
+		if err != nil {
+			t.Errorf("case %d unexpected error: %s", i, err.Error())
+			continue
+		}
+		if ds.Encoding != c.encoding {
+			t.Errorf("case %d encoding mismatch. expected: '%s', got: '%s'", i, c.encoding, ds.Encoding)
+		}
+		if c.encoding == "" {
+			continue
+		}
+
+		data, err := ioutil.ReadFile("testdata/cafes.resource.json")
+		if err != nil {
+			t.Fatal(err)
+		}
+		expect := &dataset.Structure{}
+		if err := json.Unmarshal(data, expect); err != nil {
+			t.Fatal(err)
+		}
+		if err := dataset.CompareSchemas(expect.Schema, ds.Schema); err != nil {
+			t.Errorf("case %d schema mismatch: %s", i, err.Error())
+		}
+
+		f, err := os.Open(c.inpath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		rr, err := dsio.NewRowReader(ds, f)
+		if err != nil {
+			t.Fatal(err)
+		}
+		row, err := rr.ReadRow()
+		f.Close()
+		if err != nil {
+			t.Errorf("case %d error reading row: %s", i, err.Error())
+			continue
+		}
+		if string(row[0]) != "Café Zoë" || string(row[1]) != "Montréal" {
+			t.Errorf("case %d transcoded row mismatch. got: '%s', '%s'", i, row[0], row[1])
+		}
+	}
+}
+
 func TestCamelize(t *testing.T) {
 	cases := []struct {
 		in, out string
@@ -1,7 +1,9 @@
 package detect
 
 import (
-	"encoding/csv"
+	"bufio"
+	"bytes"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
@@ -9,11 +11,17 @@ import (
 	"strings"
 
 	"github.com/qri-io/dataset"
+	"github.com/qri-io/dataset/charset"
 	"github.com/qri-io/dataset/datatypes"
+	"github.com/qri-io/dataset/dsio"
 )
 
 var (
 	startsWithNumberRegex = regexp.MustCompile(`^[0-9]`)
+	// nullMarkers are values commonly used for missing data in tables. they
+	// are skipped when tallying types, and become a field's MissingValue if
+	// the field has a non-string type
+	nullMarkers = []string{"NA", "N/A", "n/a", "na", "null", "NULL", "None", "-"}
 )
 
 // Fields determines the fields of a given reader, for a given structure
@@ -29,23 +37,116 @@ func Fields(r *dataset.Structure, data io.Reader) (fields []*dataset.Field, err
 		return JSONFields(r, data)
 	case dataset.XMLDataFormat:
 		return XMLFields(r, data)
+	case dataset.XLSDataFormat:
+		return XLSFields(r, data)
+	case dataset.NDJSONDataFormat:
+		return NDJSONFields(r, data)
+	case dataset.ParquetDataFormat:
+		return ParquetFields(r, data)
+	case dataset.GeoJSONDataFormat:
+		return GeoJSONFields(r, data)
 	}
 
 	return nil, fmt.Errorf("'%s' is not supported for field detection", r.Format.String())
 }
 
-// CSVFields determines the field names and types of an io.Reader of CSV-formatted data
+// CSVFields determines the field names and types of an io.Reader of CSV-formatted data.
+// if resource is configured with CSVOptions, data is read with that dialect
 func CSVFields(resource *dataset.Structure, data io.Reader) (fields []*dataset.Field, err error) {
-	r := csv.NewReader(data)
+	opts := &dataset.CSVOptions{}
+	if o, ok := resource.FormatConfig.(*dataset.CSVOptions); ok && o != nil {
+		*opts = *o
+	}
+
+	dialect := *opts
+	dialect.TrimLeadingSpace = true
+	r := dsio.NewCSVRecordReader(&dialect, data)
 	r.FieldsPerRecord = -1
-	r.TrimLeadingSpace = true
 	header, err := r.Read()
 	if err != nil {
 		return nil, err
 	}
 
+	fields, headerRow, err := rowFields(header, r.Read)
+	if headerRow || resource.FormatConfig != nil {
+		opts.HeaderRow = headerRow
+		resource.FormatConfig = opts
+	}
+	return fields, err
+}
+
+// XLSFields determines the field names and types of an io.Reader of excel workbook data.
+// if resource is configured with XLSOptions the configured sheet & cell range is read,
+// otherwise the first sheet
+func XLSFields(resource *dataset.Structure, data io.Reader) (fields []*dataset.Field, err error) {
+	opts := &dataset.XLSOptions{}
+	if o, ok := resource.FormatConfig.(*dataset.XLSOptions); ok && o != nil {
+		*opts = *o
+	}
+
+	// read the header row as data to guess weather it has field names
+	sel := *opts
+	sel.HeaderRow = false
+	rr := dsio.NewXLSXReader(&dataset.Structure{Format: dataset.XLSDataFormat, FormatConfig: &sel}, data)
+	next := func() ([]string, error) {
+		row, err := rr.ReadRow()
+		if err != nil {
+			return nil, err
+		}
+		rec := make([]string, len(row))
+		for i, cell := range row {
+			rec[i] = string(cell)
+		}
+		return rec, nil
+	}
+
+	header, err := next()
+	if err != nil {
+		return nil, err
+	}
+
+	fields, headerRow, err := rowFields(header, next)
+	if headerRow || resource.FormatConfig != nil {
+		opts.HeaderRow = headerRow
+		resource.FormatConfig = opts
+	}
+	return fields, err
+}
+
+// ParquetFields determines the field names and types of an io.Reader of
+// parquet data from the schema stored in the file footer
+func ParquetFields(resource *dataset.Structure, data io.Reader) (fields []*dataset.Field, err error) {
+	return dsio.NewParquetReader(&dataset.Structure{Format: dataset.ParquetDataFormat}, data).Fields()
+}
+
+// rowFields tallies the most common datatype of each column in a table,
+// reading up to 2000 rows from next. header is the first row of the table, and
+// is used to name fields if it looks like a header row
+func rowFields(header []string, next func() ([]string, error)) (fields []*dataset.Field, headerRow bool, err error) {
 	fields = make([]*dataset.Field, len(header))
 	types := make([]map[datatypes.Type]int, len(header))
+	// time values are kept to infer their format
+	times := make([]map[datatypes.Type][][]byte, len(header))
+	// string & number values are kept to detect locale number formats
+	numbers := make([][][]byte, len(header))
+	// null markers seen in each column, in order of appearance
+	nulls := make([][]string, len(header))
+	tally := func(i int, cell string) {
+		if isNullMarker(cell) {
+			if !containsString(nulls[i], cell) {
+				nulls[i] = append(nulls[i], cell)
+			}
+			return
+		}
+		typ := datatypes.ParseDatatype([]byte(cell))
+		types[i][typ]++
+		if typ == datatypes.Date || typ == datatypes.Time || typ == datatypes.DateTime {
+			times[i][typ] = append(times[i][typ], []byte(cell))
+		}
+		if (typ == datatypes.String || typ == datatypes.Integer || typ == datatypes.Float) && strings.TrimSpace(cell) != "" {
+			numbers[i] = append(numbers[i], []byte(cell))
+		}
+	}
 
 	for i := range fields {
 		fields[i] = &dataset.Field{
@@ -53,6 +154,7 @@ func CSVFields(resource *dataset.Structure, data io.Reader) (fields []*dataset.F
 			Type: datatypes.Any,
 		}
 		types[i] = map[datatypes.Type]int{}
+		times[i] = map[datatypes.Type][][]byte{}
 	}
 
 	if possibleCsvHeaderRow(header) {
@@ -60,20 +162,16 @@ func CSVFields(resource *dataset.Structure, data io.Reader) (fields []*dataset.F
 			f.Name = Camelize(header[i])
 			f.Type = datatypes.Any
 		}
-		resource.FormatConfig = &dataset.CSVOptions{
-			HeaderRow: true,
-		}
-		// ds.HeaderRow = true
+		headerRow = true
 	} else {
 		for i, cell := range header {
-			types[i][datatypes.ParseDatatype([]byte(cell))]++
+			tally(i, cell)
 		}
 	}
 
 	count := 0
 	for {
-		rec, err := r.Read()
-		// fmt.Println(rec)
+		rec, err := next()
 		if count > 2000 {
 			break
 		}
@@ -81,16 +179,336 @@ func CSVFields(resource *dataset.Structure, data io.Reader) (fields []*dataset.F
 			if err.Error() == "EOF" {
 				break
 			}
-			return fields, err
+			return fields, headerRow, err
 		}
 
 		for i, cell := range rec {
-			types[i][datatypes.ParseDatatype([]byte(cell))]++
+			if i < len(types) {
+				tally(i, cell)
+			}
 		}
 
 		count++
 	}
 
+	for i, counts := range types {
+		// columns of only null markers are strings that happen to look
+		// like markers
+		if len(counts) == 0 && len(nulls[i]) > 0 {
+			fields[i].Type = datatypes.String
+			continue
+		}
+		for typ, count := range counts {
+			if count > counts[fields[i].Type] {
+				fields[i].Type = typ
+			}
+		}
+		fields[i].Format = datatypes.DetectTimeFormat(fields[i].Type, times[i][fields[i].Type])
+		if counts[datatypes.String] > 0 {
+			numberField(fields[i], numbers[i])
+		}
+		if len(nulls[i]) > 0 && fields[i].Type != datatypes.String && fields[i].Type != datatypes.Any {
+			if len(nulls[i]) == 1 {
+				fields[i].MissingValue = nulls[i][0]
+			} else {
+				markers := make([]interface{}, len(nulls[i]))
+				for j, m := range nulls[i] {
+					markers[j] = m
+				}
+				fields[i].MissingValue = markers
+			}
+		}
+	}
+
+	return fields, headerRow, nil
+}
+
+// isNullMarker reports weather a cell is one of the common null markers
+func isNullMarker(cell string) bool {
+	return containsString(nullMarkers, cell)
+}
+
+// containsString reports weather s contains str
+func containsString(s []string, str string) bool {
+	for _, v := range s {
+		if v == str {
+			return true
+		}
+	}
+	return false
+}
+
+// numberField checks if the string values of a field are numbers written
+// with group characters, currency symbols or decimal commas, setting the
+// field's type & number format if so
+func numberField(f *dataset.Field, values [][]byte) {
+	if f.Type != datatypes.String && f.Type != datatypes.Integer && f.Type != datatypes.Float {
+		return
+	}
+	nf, typ, ok := datatypes.DetectNumberFormat(values)
+	if !ok {
+		return
+	}
+	f.Type = typ
+	f.DecimalChar = nf.DecimalChar
+	f.GroupChar = nf.GroupChar
+	if nf.Symbols {
+		bare := false
+		f.BareNumber = &bare
+	}
+}
+
+// JSONFields determines the field names and types of a given io.Reader of JSON-formatted data.
+// entries of the top level array are objects or arrays, like NDJSONFields. values that are
+// themselves arrays or objects give fields with Items & Properties schemas
+func JSONFields(ds *dataset.Structure, data io.Reader) (fields []*dataset.Field, err error) {
+	dec := json.NewDecoder(data)
+	if tok, err := dec.Token(); err != nil {
+		return nil, fmt.Errorf("invalid json: %s", err.Error())
+	} else if tok != json.Delim('[') {
+		return nil, errors.New("json top level must be an array")
+	}
+
+	tallies := []*jsonTally{}
+	index := map[string]int{}
+	arrayEntries := false
+
+	for count := 0; count <= 2000 && dec.More(); count++ {
+		var entry json.RawMessage
+		if err := dec.Decode(&entry); err != nil {
+			return nil, fmt.Errorf("entry %d: %s", count, err.Error())
+		}
+		keys, values, isArray, err := jsonEntries(entry)
+		if err != nil {
+			return nil, fmt.Errorf("entry %d: %s", count, err.Error())
+		}
+		if count == 0 {
+			arrayEntries = isArray
+		} else if isArray != arrayEntries {
+			return nil, fmt.Errorf("entry %d: json entries must all be objects or all be arrays", count)
+		}
+
+		for i, val := range values {
+			name := fmt.Sprintf("field_%d", i+1)
+			if !isArray {
+				name = keys[i]
+			}
+			j, ok := index[name]
+			if !ok {
+				j = len(tallies)
+				index[name] = j
+				tallies = append(tallies, newJSONTally(name))
+			}
+			if err := tallies[j].add(val, 0); err != nil {
+				return nil, fmt.Errorf("entry %d: %s", count, err.Error())
+			}
+		}
+	}
+
+	if len(tallies) == 0 {
+		return nil, errors.New("no json entries found")
+	}
+
+	fields = make([]*dataset.Field, len(tallies))
+	for i, t := range tallies {
+		fields[i] = t.field()
+	}
+
+	if arrayEntries {
+		ds.FormatConfig = &dataset.JSONOptions{
+			ArrayEntries: true,
+		}
+	}
+	return fields, nil
+}
+
+// maxNestedDepth limits how deeply nested arrays & objects are inspected
+// to determine element schemas. values below this depth are json
+const maxNestedDepth = 32
+
+// jsonTally counts the types of values seen for a field, with tallies for
+// the elements of array values & properties of object values
+type jsonTally struct {
+	name  string
+	types map[datatypes.Type]int
+	items *jsonTally
+	props []*jsonTally
+	index map[string]int
+}
+
+func newJSONTally(name string) *jsonTally {
+	return &jsonTally{
+		name:  name,
+		types: map[datatypes.Type]int{},
+		index: map[string]int{},
+	}
+}
+
+// add tallies a raw json value, descending into arrays & objects
+func (t *jsonTally) add(val json.RawMessage, depth int) error {
+	typ := jsonValueType(val)
+	if typ == datatypes.Unknown {
+		return nil
+	}
+
+	if typ == datatypes.JSON && depth < maxNestedDepth {
+		keys, values, isArray, err := jsonEntries(val)
+		if err != nil {
+			return err
+		}
+		if isArray {
+			typ = datatypes.Array
+			if t.items == nil {
+				t.items = newJSONTally("")
+			}
+			for _, v := range values {
+				if err := t.items.add(v, depth+1); err != nil {
+					return err
+				}
+			}
+		} else {
+			typ = datatypes.Object
+			for i, v := range values {
+				j, ok := t.index[keys[i]]
+				if !ok {
+					j = len(t.props)
+					t.index[keys[i]] = j
+					t.props = append(t.props, newJSONTally(keys[i]))
+				}
+				if err := t.props[j].add(v, depth+1); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	t.types[typ]++
+	return nil
+}
+
+// field gives a field of the most common type tallied, with element
+// schemas for arrays & objects
+func (t *jsonTally) field() *dataset.Field {
+	f := &dataset.Field{Name: t.name, Type: datatypes.Any}
+	for typ, count := range t.types {
+		if count > t.types[f.Type] {
+			f.Type = typ
+		}
+	}
+
+	switch f.Type {
+	case datatypes.Array:
+		if t.items != nil && len(t.items.types) > 0 {
+			f.Items = t.items.field()
+		}
+	case datatypes.Object:
+		for _, p := range t.props {
+			f.Properties = append(f.Properties, p.field())
+		}
+	}
+	return f
+}
+
+// XMLFields determines the field names and types of a given io.Reader of XML-formatted data.
+// field names are the union of attribute & child element names of row elements, in order of
+// first appearance. if ds names a non-utf-8 encoding data must already be transcoded to utf-8,
+// as FromReader does
+func XMLFields(ds *dataset.Structure, data io.Reader) (fields []*dataset.Field, err error) {
+	rr := dsio.NewXMLReader(ds, data, func(cfg *dsio.XMLReaderCfg) { cfg.Transcoded = !charset.IsUTF8(ds.Encoding) })
+	types := map[string]map[datatypes.Type]int{}
+
+	for count := 0; count <= 2000; count++ {
+		rec, err := rr.ReadRecord()
+		if err != nil {
+			if err == io.EOF {
+				break
+			}
+			return nil, err
+		}
+
+		for _, name := range rec.Names {
+			if types[name] == nil {
+				types[name] = map[datatypes.Type]int{}
+				fields = append(fields, &dataset.Field{Name: name, Type: datatypes.Any})
+			}
+			types[name][datatypes.ParseDatatype(rec.Values[name])]++
+		}
+	}
+
+	if len(fields) == 0 {
+		return nil, errors.New("no xml row elements found")
+	}
+
+	for _, f := range fields {
+		tally := types[f.Name]
+		for typ, count := range tally {
+			if count > tally[f.Type] {
+				f.Type = typ
+			}
+		}
+	}
+
+	return fields, nil
+}
+
+// NDJSONFields determines the field names and types of a given io.Reader of
+// newline-delimited JSON. object keys name fields in order of first appearance,
+// lines of arrays give positional fields
+func NDJSONFields(resource *dataset.Structure, data io.Reader) (fields []*dataset.Field, err error) {
+	rd := bufio.NewReader(data)
+	types := []map[datatypes.Type]int{}
+	index := map[string]int{}
+	arrayEntries := false
+
+	for lineNum, count := 1, 0; count <= 2000; lineNum++ {
+		line, err := rd.ReadBytes('\n')
+		if err != nil && err != io.EOF {
+			return nil, err
+		}
+		if line = bytes.TrimSpace(line); len(line) == 0 {
+			if err == io.EOF {
+				break
+			}
+			continue
+		}
+
+		keys, values, isArray, e := jsonEntries(line)
+		if e != nil {
+			return nil, fmt.Errorf("line %d: %s", lineNum, e.Error())
+		}
+		if count == 0 {
+			arrayEntries = isArray
+		} else if isArray != arrayEntries {
+			return nil, fmt.Errorf("line %d: ndjson lines must all be objects or all be arrays", lineNum)
+		}
+		count++
+
+		for i, val := range values {
+			name := fmt.Sprintf("field_%d", i+1)
+			if !isArray {
+				name = keys[i]
+			}
+			j, ok := index[name]
+			if !ok {
+				j = len(fields)
+				index[name] = j
+				fields = append(fields, &dataset.Field{Name: name, Type: datatypes.Any})
+				types = append(types, map[datatypes.Type]int{})
+			}
+			if typ := jsonValueType(val); typ != datatypes.Unknown {
+				types[j][typ]++
+			}
+		}
+
+		if err == io.EOF {
+			break
+		}
+	}
+
+	if len(fields) == 0 {
+		return nil, errors.New("no ndjson entries found")
+	}
+
 	for i, tally := range types {
 		for typ, count := range tally {
 			if count > tally[fields[i].Type] {
@@ -99,19 +517,139 @@ func CSVFields(resource *dataset.Structure, data io.Reader) (fields []*dataset.F
 		}
 	}
 
+	if arrayEntries {
+		resource.FormatConfig = &dataset.NDJSONOptions{
+			ArrayEntries: true,
+		}
+	}
 	return fields, nil
 }
 
-// JSONFields determines the field names and types of a given io.Reader of JSON-formatted data
-func JSONFields(ds *dataset.Structure, data io.Reader) (fields []*dataset.Field, err error) {
-	// TODO
-	return nil, errors.New("json field detection not yet implemented")
+// GeoJSONFields determines the field names and types of a GeoJSON feature
+// collection. the first field holds feature geometries, followed by
+// feature properties in order of first appearance
+func GeoJSONFields(resource *dataset.Structure, data io.Reader) (fields []*dataset.Field, err error) {
+	doc := struct {
+		Type     string `json:"type"`
+		Features []struct {
+			Geometry   json.RawMessage `json:"geometry"`
+			Properties json.RawMessage `json:"properties"`
+		} `json:"features"`
+	}{}
+	if err := json.NewDecoder(data).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("invalid geojson: %s", err.Error())
+	}
+	if doc.Type != "FeatureCollection" {
+		return nil, fmt.Errorf("invalid geojson: expected type 'FeatureCollection', got '%s'", doc.Type)
+	}
+
+	geom := &dataset.Field{Name: "geometry", Type: datatypes.GeoJSON}
+	fields = []*dataset.Field{geom}
+	types := []map[datatypes.Type]int{{}}
+	index := map[string]int{geom.Name: 0}
+
+	for i, feat := range doc.Features {
+		if i >= 2000 {
+			break
+		}
+		if len(feat.Properties) == 0 || feat.Properties[0] != '{' {
+			continue
+		}
+		keys, values, _, err := jsonEntries(feat.Properties)
+		if err != nil {
+			return nil, fmt.Errorf("feature %d: %s", i, err.Error())
+		}
+		for j, val := range values {
+			k, ok := index[keys[j]]
+			if !ok {
+				k = len(fields)
+				index[keys[j]] = k
+				fields = append(fields, &dataset.Field{Name: keys[j], Type: datatypes.Any})
+				types = append(types, map[datatypes.Type]int{})
+			}
+			if typ := jsonValueType(val); typ != datatypes.Unknown && k != 0 {
+				types[k][typ]++
+			}
+		}
+	}
+
+	for i, tally := range types {
+		for typ, count := range tally {
+			if count > tally[fields[i].Type] {
+				fields[i].Type = typ
+			}
+		}
+	}
+	return fields, nil
 }
 
-// XMLFields determines the field names and types of a given io.Reader of XML-formatted data
-func XMLFields(ds *dataset.Structure, data io.Reader) (fields []*dataset.Field, err error) {
-	// TODO
-	return nil, errors.New("xml field detection not yet implemented")
+// jsonEntries splits a json object or array into it's raw values, returning
+// keys in document order for objects
+func jsonEntries(data []byte) (keys []string, values []json.RawMessage, isArray bool, err error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, nil, false, err
+	}
+
+	switch tok {
+	case json.Delim('['):
+		isArray = true
+	case json.Delim('{'):
+	default:
+		return nil, nil, false, errors.New("entries must be json objects or arrays")
+	}
+
+	for dec.More() {
+		if !isArray {
+			key, err := dec.Token()
+			if err != nil {
+				return nil, nil, false, err
+			}
+			keys = append(keys, key.(string))
+		}
+		var val json.RawMessage
+		if err := dec.Decode(&val); err != nil {
+			return nil, nil, false, err
+		}
+		values = append(values, val)
+	}
+
+	return keys, values, isArray, nil
+}
+
+// jsonValueType gives the datatype of a raw json value, with null values
+// giving an Unknown type. strings are only ever String or time values, numbers
+// are never read as strings
+func jsonValueType(val json.RawMessage) datatypes.Type {
+	switch val[0] {
+	case 'n':
+		return datatypes.Unknown
+	case 't', 'f':
+		return datatypes.Boolean
+	case '{':
+		switch t := datatypes.ParseDatatype(val); t {
+		case datatypes.GeoJSON, datatypes.GeoPoint:
+			return t
+		}
+		return datatypes.JSON
+	case '[':
+		return datatypes.JSON
+	case '"':
+		var s string
+		if err := json.Unmarshal(val, &s); err == nil {
+			switch t := datatypes.ParseDatatype([]byte(s)); t {
+			case datatypes.Date, datatypes.Time, datatypes.DateTime, datatypes.Duration, datatypes.GeoPoint:
+				return t
+			}
+		}
+		return datatypes.String
+	}
+	switch t := datatypes.ParseDatatype(val); t {
+	case datatypes.Integer, datatypes.Decimal:
+		return t
+	}
+	return datatypes.Float
 }
 
 // PossibleHeaderRow makes an educated guess about weather or not this csv file has a header row.
@@ -1,5 +1,342 @@
 package detect
 
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/dataset/datatypes"
+	"github.com/qri-io/dataset/dsio"
+)
+
+func TestCSVFieldsDialect(t *testing.T) {
+	data := []byte("# exported from a spreadsheet\ncity;pop;avg_age\ntoronto;40000000;55,5\n'new; york';8500000;44,4\n")
+	st := &dataset.Structure{
+		Format:       dataset.CSVDataFormat,
+		FormatConfig: &dataset.CSVOptions{Delimiter: ";", Quote: "'", Comment: "#"},
+	}
+
+	fields, err := CSVFields(st, bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+
+	expect := []*dataset.Field{
+		{Name: "city", Type: datatypes.String},
+		{Name: "pop", Type: datatypes.Integer},
+		{Name: "avg_age", Type: datatypes.Float, DecimalChar: ","},
+	}
+	if len(fields) != len(expect) {
+		t.Fatalf("field length mismatch. expected: %d, got: %d", len(expect), len(fields))
+	}
+	for i, f := range expect {
+		if err := dataset.CompareFields(f, fields[i]); err != nil {
+			t.Errorf("field %d mismatch: %s", i, err.Error())
+		}
+	}
+
+	opts, ok := st.FormatConfig.(*dataset.CSVOptions)
+	if !ok {
+		t.Fatalf("expected FormatConfig to be CSVOptions")
+	}
+	if !opts.HeaderRow || opts.Delimiter != ";" || opts.Quote != "'" || opts.Comment != "#" {
+		t.Errorf("expected dialect to be preserved with a header row, got: %v", opts.Map())
+	}
+}
+
+func TestCSVFieldsTimeFormats(t *testing.T) {
+	data := []byte(`employee,date,start,length,clocked_in,updated
+ana,15/03/2021,9:00 AM,PT8H,03/15/2021 09:02,2021-03-15T17:00:00Z
+bo,16/3/2021,12:30 PM,PT7H30M,03/16/2021 12:28,2021-03-16T20:00:00Z
+cy,04/01/2021,1:00 PM,P1DT2H,04/01/2021 13:05,2021-04-02T15:00:00Z
+`)
+
+	fields, err := CSVFields(&dataset.Structure{Format: dataset.CSVDataFormat}, bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+
+	expect := []*dataset.Field{
+		{Name: "employee", Type: datatypes.String},
+		{Name: "date", Type: datatypes.Date, Format: "%d/%m/%Y"},
+		{Name: "start", Type: datatypes.Time, Format: "%I:%M %p"},
+		{Name: "length", Type: datatypes.Duration},
+		{Name: "clocked_in", Type: datatypes.DateTime, Format: "%m/%d/%Y %H:%M"},
+		{Name: "updated", Type: datatypes.DateTime},
+	}
+	if len(fields) != len(expect) {
+		t.Fatalf("field length mismatch. expected: %d, got: %d", len(expect), len(fields))
+	}
+	for i, f := range expect {
+		if f.Name != fields[i].Name || f.Type != fields[i].Type || f.Format != fields[i].Format {
+			t.Errorf("field %d mismatch. expected: %s %s '%s', got: %s %s '%s'", i, f.Name, f.Type, f.Format, fields[i].Name, fields[i].Type, fields[i].Format)
+		}
+	}
+}
+
+func TestCSVFieldsNumberFormats(t *testing.T) {
+	data := []byte(`item,units,price,revenue,share,eu_price,code
+widget,"1,200",$4.50,"$5,400.00",45%,"1.234,50",A-1
+gadget,950,$12,"$11,400.00",38.5%,"12,00",B-2
+doohickey,"12,300",$0.25,"$3,075.00",16.5%,"0,25",1-2
+`)
+
+	fields, err := CSVFields(&dataset.Structure{Format: dataset.CSVDataFormat}, bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+
+	bare := false
+	expect := []*dataset.Field{
+		{Name: "item", Type: datatypes.String},
+		{Name: "units", Type: datatypes.Integer, GroupChar: ","},
+		{Name: "price", Type: datatypes.Float, BareNumber: &bare},
+		{Name: "revenue", Type: datatypes.Float, GroupChar: ",", BareNumber: &bare},
+		{Name: "share", Type: datatypes.Float, BareNumber: &bare},
+		{Name: "eu_price", Type: datatypes.Float, DecimalChar: ",", GroupChar: "."},
+		{Name: "code", Type: datatypes.String},
+	}
+	if len(fields) != len(expect) {
+		t.Fatalf("field length mismatch. expected: %d, got: %d", len(expect), len(fields))
+	}
+	for i, f := range expect {
+		if err := dataset.CompareFields(f, fields[i]); err != nil {
+			t.Errorf("field %d mismatch: %s", i, err.Error())
+		}
+	}
+}
+
+func TestCSVFieldsMissingValues(t *testing.T) {
+	data := []byte(`city,pop,avg_age,status
+toronto,40000000,55.5,None
+new york,NA,N/A,None
+chatham,35000,NA,None
+`)
+
+	fields, err := CSVFields(&dataset.Structure{Format: dataset.CSVDataFormat}, bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+
+	expect := []*dataset.Field{
+		{Name: "city", Type: datatypes.String},
+		{Name: "pop", Type: datatypes.Integer, MissingValue: "NA"},
+		{Name: "avg_age", Type: datatypes.Float, MissingValue: []interface{}{"N/A", "NA"}},
+		{Name: "status", Type: datatypes.String},
+	}
+	if len(fields) != len(expect) {
+		t.Fatalf("field length mismatch. expected: %d, got: %d", len(expect), len(fields))
+	}
+	for i, f := range expect {
+		if err := dataset.CompareFields(f, fields[i]); err != nil {
+			t.Errorf("field %d mismatch: %s", i, err.Error())
+		}
+	}
+}
+
+func TestJSONFields(t *testing.T) {
+	data := []byte(`[
+{"city":"toronto","pop":40000000,"tags":["big","cold"],"mayor":{"name":"john","elected":"2014-10-27","terms":[1,2]}},
+{"city":"chatham","pop":35000,"tags":[],"mayor":{"name":"darrin","elected":"2018-10-22","terms":null},"ratings":[[4.5,3.5],[5]]},
+{"city":"atlantis","pop":null,"tags":null,"mayor":null}
+]`)
+
+	st := &dataset.Structure{Format: dataset.JSONDataFormat}
+	fields, err := JSONFields(st, bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+
+	expect := []*dataset.Field{
+		{Name: "city", Type: datatypes.String},
+		{Name: "pop", Type: datatypes.Integer},
+		{Name: "tags", Type: datatypes.Array, Items: &dataset.Field{Type: datatypes.String}},
+		{Name: "mayor", Type: datatypes.Object, Properties: []*dataset.Field{
+			{Name: "name", Type: datatypes.String},
+			{Name: "elected", Type: datatypes.Date},
+			{Name: "terms", Type: datatypes.Array, Items: &dataset.Field{Type: datatypes.Integer}},
+		}},
+		{Name: "ratings", Type: datatypes.Array, Items: &dataset.Field{Type: datatypes.Array, Items: &dataset.Field{Type: datatypes.Float}}},
+	}
+	if len(fields) != len(expect) {
+		t.Fatalf("field length mismatch. expected: %d, got: %d", len(expect), len(fields))
+	}
+	for i, f := range expect {
+		if err := dataset.CompareFields(f, fields[i]); err != nil {
+			t.Errorf("field %d mismatch: %s", i, err.Error())
+		}
+	}
+	if st.FormatConfig != nil {
+		t.Errorf("expected object entries to leave format config unset")
+	}
+
+	st = &dataset.Structure{Format: dataset.JSONDataFormat}
+	fields, err = JSONFields(st, bytes.NewReader([]byte(`[["a",[1,2]],["b",[3]]]`)))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+	if len(fields) != 2 || fields[0].Name != "field_1" || fields[1].Type != datatypes.Array || fields[1].Items == nil || fields[1].Items.Type != datatypes.Integer {
+		t.Errorf("array entry fields mismatch. got: %v", fields)
+	}
+	if opts, ok := st.FormatConfig.(*dataset.JSONOptions); !ok || !opts.ArrayEntries {
+		t.Errorf("expected array entries format config, got: %v", st.FormatConfig)
+	}
+
+	errCases := []struct {
+		data string
+		err  string
+	}{
+		{`{"a":1}`, "json top level must be an array"},
+		{`[]`, "no json entries found"},
+		{`[{"a":1},[1]]`, "entry 1: json entries must all be objects or all be arrays"},
+		{`[1]`, "entry 0: entries must be json objects or arrays"},
+	}
+	for i, c := range errCases {
+		_, err := JSONFields(&dataset.Structure{Format: dataset.JSONDataFormat}, strings.NewReader(c.data))
+		if err == nil || err.Error() != c.err {
+			t.Errorf("case %d error mismatch. expected: '%s', got: '%v'", i, c.err, err)
+		}
+	}
+}
+
+func TestGeoJSONFields(t *testing.T) {
+	data := []byte(`{"type":"FeatureCollection","features":[
+{"type":"Feature","geometry":{"type":"Point","coordinates":[-74.006,40.7128]},"properties":{"city":"new york","pop":8500000}},
+{"type":"Feature","geometry":null,"properties":{"pop":2800000,"city":"toronto","capital":false,"hq":"43.6532,-79.3832"}}
+]}`)
+
+	fields, err := GeoJSONFields(&dataset.Structure{Format: dataset.GeoJSONDataFormat}, bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+
+	expect := []*dataset.Field{
+		{Name: "geometry", Type: datatypes.GeoJSON},
+		{Name: "city", Type: datatypes.String},
+		{Name: "pop", Type: datatypes.Integer},
+		{Name: "capital", Type: datatypes.Boolean},
+		{Name: "hq", Type: datatypes.GeoPoint},
+	}
+	if len(fields) != len(expect) {
+		t.Fatalf("field length mismatch. expected: %d, got: %d", len(expect), len(fields))
+	}
+	for i, f := range expect {
+		if f.Name != fields[i].Name || f.Type != fields[i].Type {
+			t.Errorf("field %d mismatch. expected: %s %s, got: %s %s", i, f.Name, f.Type, fields[i].Name, fields[i].Type)
+		}
+	}
+
+	_, err = GeoJSONFields(&dataset.Structure{Format: dataset.GeoJSONDataFormat}, bytes.NewReader([]byte(`{"type":"Feature"}`)))
+	expectErr := "invalid geojson: expected type 'FeatureCollection', got 'Feature'"
+	if err == nil || err.Error() != expectErr {
+		t.Errorf("error mismatch. expected: '%s', got: '%v'", expectErr, err)
+	}
+}
+
+func TestXLSFieldsOptions(t *testing.T) {
+	fields := []*dataset.Field{
+		{Name: "city", Type: datatypes.String},
+		{Name: "pop", Type: datatypes.Integer},
+		{Name: "avg_age", Type: datatypes.Float},
+	}
+	buf := &bytes.Buffer{}
+	w := dsio.NewXLSXWriter(&dataset.Structure{
+		Format:       dataset.XLSDataFormat,
+		FormatConfig: &dataset.XLSOptions{SheetName: "cities", HeaderRow: true},
+		Schema:       &dataset.Schema{Fields: fields},
+	}, buf)
+	for _, row := range [][]string{{"toronto", "40000000", "55.5"}, {"new york", "8500000", "44.4"}, {"chicago", "300000", "44.4"}} {
+		if err := w.WriteRow([][]byte{[]byte(row[0]), []byte(row[1]), []byte(row[2])}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	data := buf.Bytes()
+
+	cases := []struct {
+		opts   *dataset.XLSOptions
+		expect []string
+		err    string
+	}{
+		{nil, []string{"city:string", "pop:integer", "avg_age:float"}, ""},
+		{&dataset.XLSOptions{SheetName: "cities"}, []string{"city:string", "pop:integer", "avg_age:float"}, ""},
+		{&dataset.XLSOptions{SheetName: "cities", HeaderRow: true, CellRange: "B1:C4"}, []string{"pop:integer", "avg_age:float"}, ""},
+		{&dataset.XLSOptions{CellRange: "B2"}, []string{"field_1:integer", "field_2:float"}, ""},
+		{&dataset.XLSOptions{SheetName: "nope"}, nil, "sheet 'nope' not found in workbook"},
+	}
+	for i, c := range cases {
+		st := &dataset.Structure{Format: dataset.XLSDataFormat}
+		if c.opts != nil {
+			st.FormatConfig = c.opts
+		}
+		got, err := XLSFields(st, bytes.NewReader(data))
+		if !(err == nil && c.err == "" || err != nil && err.Error() == c.err) {
+			t.Errorf("case %d error mismatch. expected: '%s', got: '%s'", i, c.err, err)
+			continue
+		}
+		if c.err != "" {
+			continue
+		}
+		if len(got) != len(c.expect) {
+			t.Errorf("case %d field count mismatch. expected: %d, got: %d", i, len(c.expect), len(got))
+			continue
+		}
+		for j, f := range got {
+			if str := f.Name + ":" + f.Type.String(); str != c.expect[j] {
+				t.Errorf("case %d field %d mismatch. expected: %s, got: %s", i, j, c.expect[j], str)
+			}
+		}
+		opts, ok := st.FormatConfig.(*dataset.XLSOptions)
+		if !ok || opts.HeaderRow != (c.expect[0] != "field_1:integer") {
+			t.Errorf("case %d expected header row to be detected, got: %v", i, st.FormatConfig)
+			continue
+		}
+		if c.opts != nil && (opts.SheetName != c.opts.SheetName || opts.CellRange != c.opts.CellRange) {
+			t.Errorf("case %d expected sheet & range options to be kept, got: %v", i, opts)
+		}
+	}
+}
+
+func TestParquetFields(t *testing.T) {
+	expect := []*dataset.Field{
+		{Name: "city", Type: datatypes.String},
+		{Name: "pop", Type: datatypes.Integer},
+		{Name: "avg_age", Type: datatypes.Float},
+		{Name: "in_usa", Type: datatypes.Boolean},
+		{Name: "founded", Type: datatypes.Date},
+		{Name: "updated", Type: datatypes.DateTime},
+	}
+
+	buf := &bytes.Buffer{}
+	w := dsio.NewParquetWriter(&dataset.Structure{Format: dataset.ParquetDataFormat, Schema: &dataset.Schema{Fields: expect}}, buf)
+	if err := w.WriteRow([][]byte{[]byte("toronto"), []byte("40000000"), []byte("55.5"), []byte("false"), []byte("1834-03-06"), []byte("2018-03-06T12:30:00Z")}); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	st, err := FromReader("cities.parquet", buf)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+	if st.Format != dataset.ParquetDataFormat {
+		t.Errorf("format mismatch. expected: %s, got: %s", dataset.ParquetDataFormat, st.Format)
+	}
+	fields := st.Schema.Fields
+	if len(fields) != len(expect) {
+		t.Fatalf("field length mismatch. expected: %d, got: %d", len(expect), len(fields))
+	}
+	for i, f := range expect {
+		if f.Name != fields[i].Name || f.Type != fields[i].Type {
+			t.Errorf("field %d mismatch. expected: %s %s, got: %s %s", i, f.Name, f.Type, fields[i].Name, fields[i].Type)
+		}
+	}
+}
+
 var (
 	egCorruptCsvData = []byte(`
 		"""fhkajslfnakjlcdnajcl ashklj asdhcjklads ch,,,\dagfd
@@ -7,6 +7,7 @@ import (
 	"github.com/ipfs/go-datastore"
 	"github.com/qri-io/cafs"
 	"github.com/qri-io/dataset"
+	"github.com/qri-io/dataset/compression"
 	"github.com/qri-io/dataset/dsio"
 )
 
@@ -15,7 +16,8 @@ func LoadData(store cafs.Filestore, ds *dataset.Dataset) (cafs.File, error) {
 	return store.Get(datastore.NewKey(ds.Data))
 }
 
-// LoadRows loads a slice of raw bytes inside a limit/offset row range
+// LoadRows loads a slice of raw bytes inside a limit/offset row range.
+// compressed data is decompressed, rows are always returned uncompressed
 func LoadRows(store cafs.Filestore, ds *dataset.Dataset, limit, offset int) ([]byte, error) {
 
 	datafile, err := LoadData(store, ds)
@@ -24,7 +26,10 @@ func LoadRows(store cafs.Filestore, ds *dataset.Dataset, limit, offset int) ([]b
 	}
 
 	added := 0
-	buf, err := dsio.NewStructuredBuffer(ds.Structure)
+	bufst := &dataset.Structure{}
+	bufst.Assign(ds.Structure)
+	bufst.Compression = compression.None
+	buf, err := dsio.NewStructuredBuffer(bufst)
 	if err != nil {
 		return nil, fmt.Errorf("error loading dataset data: %s", err.Error())
 	}
@@ -2,22 +2,25 @@ package dsio
 
 import (
 	"encoding/csv"
-	"github.com/qri-io/dataset"
 	"io"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/qri-io/dataset"
 )
 
 // CSVReader implements the RowReader interface for the CSV data format
 type CSVReader struct {
 	st         *dataset.Structure
 	readHeader bool
-	r          *csv.Reader
+	r          *CSVRecordReader
 }
 
 // NewCSVReader creates a reader from a structure and read source
 func NewCSVReader(st *dataset.Structure, r io.Reader) *CSVReader {
 	return &CSVReader{
 		st: st,
-		r:  csv.NewReader(r),
+		r:  NewCSVRecordReader(csvOptions(st), r),
 	}
 }
 
@@ -61,27 +64,95 @@ func HasHeaderRow(st *dataset.Structure) bool {
 	return false
 }
 
+// csvOptions returns the structure's csv configuration, falling
+// back to defaults if none is provided
+func csvOptions(st *dataset.Structure) *dataset.CSVOptions {
+	if opts, ok := st.FormatConfig.(*dataset.CSVOptions); ok && opts != nil {
+		return opts
+	}
+	return &dataset.CSVOptions{}
+}
+
+// CSVRecordReader reads string records from csv data with the dialect
+// described by CSVOptions. The embedded csv.Reader can be used to further
+// configure reading, eg: setting FieldsPerRecord.
+// encoding/csv only understands double quotes, so other quote characters
+// are read by swapping them with double quotes in the underlying stream,
+// and swapping them back in each field
+type CSVRecordReader struct {
+	*csv.Reader
+	swap *csvQuoteSwap
+}
+
+// NewCSVRecordReader creates a record reader for a csv dialect
+func NewCSVRecordReader(opts *dataset.CSVOptions, r io.Reader) *CSVRecordReader {
+	if opts == nil {
+		opts = &dataset.CSVOptions{}
+	}
+	swap := newCSVQuoteSwap(opts.Quote)
+	if swap != nil {
+		r = &csvSwapReader{swap: swap, r: r}
+	}
+
+	cr := csv.NewReader(r)
+	if opts.Delimiter != "" {
+		cr.Comma = swap.rune(firstRune(opts.Delimiter))
+	}
+	if opts.Comment != "" {
+		cr.Comment = swap.rune(firstRune(opts.Comment))
+	}
+	cr.LazyQuotes = opts.LazyQuotes
+	cr.TrimLeadingSpace = opts.TrimLeadingSpace
+
+	return &CSVRecordReader{Reader: cr, swap: swap}
+}
+
+// Read reads one record
+func (r *CSVRecordReader) Read() ([]string, error) {
+	rec, err := r.Reader.Read()
+	if err != nil || r.swap == nil {
+		return rec, err
+	}
+	for i, field := range rec {
+		rec[i] = r.swap.string(field)
+	}
+	return rec, nil
+}
+
 // CSVWriter implements the RowWriter interface for
 // CSV-formatted data
 type CSVWriter struct {
 	rowsWritten int
 	w           *csv.Writer
+	swap        *csvQuoteSwap
 	st          *dataset.Structure
 }
 
 // NewCSVWriter creates a Writer from a structure and write destination
 func NewCSVWriter(st *dataset.Structure, w io.Writer) *CSVWriter {
+	opts := csvOptions(st)
+	swap := newCSVQuoteSwap(opts.Quote)
+	if swap != nil {
+		w = &csvSwapWriter{swap: swap, w: w}
+	}
+
 	writer := csv.NewWriter(w)
+	if opts.Delimiter != "" {
+		writer.Comma = swap.rune(firstRune(opts.Delimiter))
+	}
+	writer.UseCRLF = opts.LineTerminator == "\r\n"
+	// TODO - csv.Writer can't be forced to quote fields, so a first field
+	// that starts with opts.Comment will be read back as a comment line
+
 	wr := &CSVWriter{
-		st: st,
-		w:  writer,
+		st:   st,
+		w:    writer,
+		swap: swap,
 	}
 
-	if CSVOpts, ok := st.FormatConfig.(*dataset.CSVOptions); ok {
-		if CSVOpts.HeaderRow {
-			// TODO - capture error
-			writer.Write(st.Schema.FieldNames())
-		}
+	if opts.HeaderRow {
+		// TODO - capture error
+		wr.WriteRow(byteFields(st.Schema.FieldNames()))
 	}
 
 	return wr
@@ -96,7 +167,7 @@ func (w *CSVWriter) Structure() *dataset.Structure {
 func (w *CSVWriter) WriteRow(data [][]byte) error {
 	row := make([]string, len(data))
 	for i, d := range data {
-		row[i] = string(d)
+		row[i] = w.swap.string(string(d))
 	}
 	return w.w.Write(row)
 }
@@ -105,5 +176,91 @@ func (w *CSVWriter) WriteRow(data [][]byte) error {
 // will be written
 func (w *CSVWriter) Close() error {
 	w.w.Flush()
-	return nil
+	return w.w.Error()
+}
+
+func byteFields(strs []string) [][]byte {
+	b := make([][]byte, len(strs))
+	for i, s := range strs {
+		b[i] = []byte(s)
+	}
+	return b
+}
+
+func firstRune(s string) rune {
+	r, _ := utf8.DecodeRuneInString(s)
+	return r
+}
+
+// csvQuoteSwap exchanges a quote character with double quotes. a nil
+// *csvQuoteSwap is valid & performs no swapping
+type csvQuoteSwap struct {
+	quote byte
+}
+
+// newCSVQuoteSwap returns nil if quote is the default double quote
+func newCSVQuoteSwap(quote string) *csvQuoteSwap {
+	if quote == "" || quote == `"` {
+		return nil
+	}
+	return &csvQuoteSwap{quote: quote[0]}
+}
+
+func (s *csvQuoteSwap) byte(b byte) byte {
+	if s != nil {
+		switch b {
+		case s.quote:
+			return '"'
+		case '"':
+			return s.quote
+		}
+	}
+	return b
+}
+
+func (s *csvQuoteSwap) rune(r rune) rune {
+	if r < utf8.RuneSelf {
+		return rune(s.byte(byte(r)))
+	}
+	return r
+}
+
+func (s *csvQuoteSwap) string(str string) string {
+	if s == nil || !strings.ContainsAny(str, string([]byte{'"', s.quote})) {
+		return str
+	}
+	b := []byte(str)
+	for i, c := range b {
+		b[i] = s.byte(c)
+	}
+	return string(b)
+}
+
+// csvSwapReader swaps quote characters in the underlying stream
+type csvSwapReader struct {
+	swap *csvQuoteSwap
+	r    io.Reader
+}
+
+func (r *csvSwapReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	for i := 0; i < n; i++ {
+		p[i] = r.swap.byte(p[i])
+	}
+	return n, err
+}
+
+// csvSwapWriter swaps quote characters before writing to the
+// underlying stream
+type csvSwapWriter struct {
+	swap *csvQuoteSwap
+	w    io.Writer
+}
+
+func (w *csvSwapWriter) Write(p []byte) (int, error) {
+	b := make([]byte, len(p))
+	for i, c := range p {
+		b[i] = w.swap.byte(c)
+	}
+	return w.w.Write(b)
 }
@@ -2,6 +2,8 @@ package dsio
 
 import (
 	"bytes"
+	"io"
+	"strings"
 	"testing"
 
 	"github.com/qri-io/dataset"
@@ -95,3 +97,95 @@ func TestCSVWriter(t *testing.T) {
 		t.Errorf("output mismatch. %s != %s", buf.String(), csvData)
 	}
 }
+
+func TestCSVDialects(t *testing.T) {
+	fields := []*dataset.Field{
+		{Name: "name", Type: datatypes.String},
+		{Name: "note", Type: datatypes.String},
+	}
+	data := [][][]byte{
+		{[]byte("toronto"), []byte("it's \"big\"")},
+		{[]byte("new york"), []byte("a;b,c\td|e")},
+		{[]byte(" chatham"), []byte("line\nbreak")},
+	}
+
+	cases := []struct {
+		opts  *dataset.CSVOptions
+		write string
+	}{
+		{&dataset.CSVOptions{}, "toronto,\"it's \"\"big\"\"\"\nnew york,\"a;b,c\td|e\"\n\" chatham\",\"line\nbreak\"\n"},
+		{&dataset.CSVOptions{HeaderRow: true, Delimiter: "\t"}, "name\tnote\ntoronto\t\"it's \"\"big\"\"\"\nnew york\t\"a;b,c\td|e\"\n\" chatham\"\t\"line\nbreak\"\n"},
+		{&dataset.CSVOptions{Delimiter: ";", LineTerminator: "\r\n"}, "toronto;\"it's \"\"big\"\"\"\r\nnew york;\"a;b,c\td|e\"\r\n\" chatham\";\"line\r\nbreak\"\r\n"},
+		{&dataset.CSVOptions{Quote: "'"}, "toronto,'it''s \"big\"'\nnew york,'a;b,c\td|e'\n' chatham','line\nbreak'\n"},
+		{&dataset.CSVOptions{Delimiter: "|", Quote: "'", Comment: "#"}, "toronto|'it''s \"big\"'\nnew york|'a;b,c\td|e'\n' chatham'|'line\nbreak'\n"},
+	}
+
+	for i, c := range cases {
+		st := &dataset.Structure{Format: dataset.CSVDataFormat, FormatConfig: c.opts, Schema: &dataset.Schema{Fields: fields}}
+		buf := &bytes.Buffer{}
+		w := NewCSVWriter(st, buf)
+		for _, row := range data {
+			if err := w.WriteRow(row); err != nil {
+				t.Errorf("case %d error writing row: %s", i, err.Error())
+			}
+		}
+		if err := w.Close(); err != nil {
+			t.Errorf("case %d error closing writer: %s", i, err.Error())
+			continue
+		}
+		if buf.String() != c.write {
+			t.Errorf("case %d output mismatch. expected:\n%q\ngot:\n%q", i, c.write, buf.String())
+		}
+
+		r := NewCSVReader(st, buf)
+		for j, expect := range data {
+			row, err := r.ReadRow()
+			if err != nil {
+				t.Errorf("case %d row %d read error: %s", i, j, err.Error())
+				break
+			}
+			for k, cell := range row {
+				if !bytes.Equal(cell, expect[k]) {
+					t.Errorf("case %d row %d cell %d mismatch. expected: %q, got: %q", i, j, k, expect[k], cell)
+				}
+			}
+		}
+	}
+}
+
+func TestCSVReaderDialects(t *testing.T) {
+	cases := []struct {
+		opts   *dataset.CSVOptions
+		data   string
+		expect [][]string
+	}{
+		{&dataset.CSVOptions{Comment: "#"}, "# comment\na,b\n#,c\n", [][]string{{"a", "b"}}},
+		{&dataset.CSVOptions{TrimLeadingSpace: true}, "a,  b\n", [][]string{{"a", "b"}}},
+		{&dataset.CSVOptions{LazyQuotes: true}, "a,b\"c\n", [][]string{{"a", "b\"c"}}},
+		{&dataset.CSVOptions{Delimiter: ";"}, "a;b\r\nc;d\r\n", [][]string{{"a", "b"}, {"c", "d"}}},
+		{&dataset.CSVOptions{Delimiter: "\"", Quote: "'"}, "a\"'b\"c'\n", [][]string{{"a", "b\"c"}}},
+	}
+
+	for i, c := range cases {
+		r := NewCSVReader(&dataset.Structure{Format: dataset.CSVDataFormat, FormatConfig: c.opts}, strings.NewReader(c.data))
+		for j, expect := range c.expect {
+			row, err := r.ReadRow()
+			if err != nil {
+				t.Errorf("case %d row %d read error: %s", i, j, err.Error())
+				break
+			}
+			if len(row) != len(expect) {
+				t.Errorf("case %d row %d length mismatch. expected: %d, got: %d", i, j, len(expect), len(row))
+				continue
+			}
+			for k, cell := range row {
+				if string(cell) != expect[k] {
+					t.Errorf("case %d row %d cell %d mismatch. expected: %q, got: %q", i, j, k, expect[k], cell)
+				}
+			}
+		}
+		if _, err := r.ReadRow(); err != io.EOF {
+			t.Errorf("case %d expected EOF, got: %v", i, err)
+		}
+	}
+}
@@ -6,6 +6,8 @@ import (
 	"io"
 
 	"github.com/qri-io/dataset"
+	"github.com/qri-io/dataset/charset"
+	"github.com/qri-io/dataset/compression"
 )
 
 // RowWriter is a generalized interface for writing structured data
@@ -42,8 +44,19 @@ type RowReadWriter interface {
 	Bytes() []byte
 }
 
-// NewRowReader allocates a RowReader based on a given structure
+// NewRowReader allocates a RowReader based on a given structure.
+// compressed data is decompressed according to st.Compression, if
+// st.Compression is None compression is detected from the data itself.
+// text formats are transcoded to utf-8 from st.Encoding
 func NewRowReader(st *dataset.Structure, r io.Reader) (RowReader, error) {
+	r = &decompressReader{t: st.Compression, src: r}
+	if isTextFormat(st.Format) {
+		var err error
+		if r, err = charset.NewReader(st.Encoding, r); err != nil {
+			return nil, err
+		}
+	}
+
 	switch st.Format {
 	case dataset.CSVDataFormat:
 		return NewCSVReader(st, r), nil
@@ -51,6 +64,17 @@ func NewRowReader(st *dataset.Structure, r io.Reader) (RowReader, error) {
 		return NewJSONReader(st, r), nil
 	case dataset.CDXJDataFormat:
 		return NewCDXJReader(st, r), nil
+	case dataset.XLSDataFormat:
+		return NewXLSXReader(st, r), nil
+	case dataset.XMLDataFormat:
+		// data in a non-utf-8 structure encoding has been transcoded above
+		return NewXMLReader(st, r, func(cfg *XMLReaderCfg) { cfg.Transcoded = !charset.IsUTF8(st.Encoding) }), nil
+	case dataset.NDJSONDataFormat:
+		return NewNDJSONReader(st, r), nil
+	case dataset.ParquetDataFormat:
+		return NewParquetReader(st, r), nil
+	case dataset.GeoJSONDataFormat:
+		return NewGeoJSONReader(st, r), nil
 	case dataset.UnknownDataFormat:
 		return nil, fmt.Errorf("structure must have a data format")
 	default:
@@ -58,8 +82,49 @@ func NewRowReader(st *dataset.Structure, r io.Reader) (RowReader, error) {
 	}
 }
 
-// NewRowWriter allocates a RowWriter based on a given structure
+// NewRowWriter allocates a RowWriter based on a given structure.
+// text formats are transcoded from utf-8 to st.Encoding, and data is
+// compressed according to st.Compression
 func NewRowWriter(st *dataset.Structure, w io.Writer) (RowWriter, error) {
+	if st.Compression == compression.None {
+		return newEncodedRowWriter(st, w)
+	}
+
+	cw, err := compression.NewWriter(st.Compression, w)
+	if err != nil {
+		return nil, fmt.Errorf("error creating %s writer: %s", st.Compression.String(), err.Error())
+	}
+	rw, err := newEncodedRowWriter(st, cw)
+	if err != nil {
+		return nil, err
+	}
+	return &compressedRowWriter{RowWriter: rw, cw: cw}, nil
+}
+
+// newEncodedRowWriter transcodes writes to text formats to st.Encoding
+func newEncodedRowWriter(st *dataset.Structure, w io.Writer) (RowWriter, error) {
+	if !isTextFormat(st.Format) || charset.IsUTF8(st.Encoding) {
+		return newRowWriter(st, w)
+	}
+
+	ew, err := charset.NewWriter(st.Encoding, w)
+	if err != nil {
+		return nil, err
+	}
+	rw, err := newRowWriter(st, ew)
+	if err != nil {
+		return nil, err
+	}
+	return &encodedRowWriter{RowWriter: rw, ew: ew}, nil
+}
+
+// isTextFormat reports weather a data format is character data that can be
+// transcoded. binary formats define their own text encodings
+func isTextFormat(df dataset.DataFormat) bool {
+	return df != dataset.XLSDataFormat && df != dataset.ParquetDataFormat
+}
+
+func newRowWriter(st *dataset.Structure, w io.Writer) (RowWriter, error) {
 	switch st.Format {
 	case dataset.CSVDataFormat:
 		return NewCSVWriter(st, w), nil
@@ -67,9 +132,87 @@ func NewRowWriter(st *dataset.Structure, w io.Writer) (RowWriter, error) {
 		return NewJSONWriter(st, w), nil
 	case dataset.CDXJDataFormat:
 		return NewCDXJWriter(st, w), nil
+	case dataset.XLSDataFormat:
+		return NewXLSXWriter(st, w), nil
+	case dataset.XMLDataFormat:
+		return NewXMLWriter(st, w), nil
+	case dataset.NDJSONDataFormat:
+		return NewNDJSONWriter(st, w), nil
+	case dataset.ParquetDataFormat:
+		return NewParquetWriter(st, w), nil
+	case dataset.GeoJSONDataFormat:
+		return NewGeoJSONWriter(st, w), nil
+	case dataset.SQLDataFormat:
+		return NewSQLWriter(st, w), nil
 	case dataset.UnknownDataFormat:
 		return nil, fmt.Errorf("structure must have a data format")
 	default:
 		return nil, fmt.Errorf("invalid format to create writer: %s", st.Format.String())
 	}
 }
+
+// decompressReader wraps it's source in a decompressor on the first call to
+// Read, so readers can be created before any data is available, which
+// StructuredBuffer relies on. Compression type None sniffs for compressed data
+type decompressReader struct {
+	t   compression.Type
+	src io.Reader
+	r   io.Reader
+}
+
+func (d *decompressReader) Read(p []byte) (int, error) {
+	if d.r == nil {
+		t, src := d.t, d.src
+		if t == compression.None {
+			var err error
+			if t, src, err = compression.Sniff(d.src); err != nil {
+				return 0, err
+			}
+		}
+
+		rc, err := compression.NewReader(t, src)
+		if err == io.EOF {
+			// no data to read yet
+			d.src = src
+			return 0, io.EOF
+		} else if err != nil {
+			return 0, fmt.Errorf("error reading %s data: %s", t.String(), err.Error())
+		}
+		d.r = rc
+	}
+	return d.r.Read(p)
+}
+
+// compressedRowWriter closes the compressing writer after it's RowWriter,
+// flushing any buffered compressed data
+type compressedRowWriter struct {
+	RowWriter
+	cw io.WriteCloser
+}
+
+func (w *compressedRowWriter) Close() error {
+	if err := w.RowWriter.Close(); err != nil {
+		return err
+	}
+	if err := w.cw.Close(); err != nil {
+		return fmt.Errorf("error closing %s writer: %s", w.Structure().Compression.String(), err.Error())
+	}
+	return nil
+}
+
+// encodedRowWriter closes the transcoding writer after it's RowWriter,
+// flushing any buffered encoded data
+type encodedRowWriter struct {
+	RowWriter
+	ew io.WriteCloser
+}
+
+func (w *encodedRowWriter) Close() error {
+	if err := w.RowWriter.Close(); err != nil {
+		return err
+	}
+	if err := w.ew.Close(); err != nil {
+		return fmt.Errorf("error closing %s writer: %s", w.Structure().Encoding, err.Error())
+	}
+	return nil
+}
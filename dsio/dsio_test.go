@@ -2,8 +2,13 @@ package dsio
 
 import (
 	"bytes"
-	"github.com/qri-io/dataset"
+	"io"
+	"strings"
 	"testing"
+
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/dataset/compression"
+	"github.com/qri-io/dataset/datatypes"
 )
 
 func TestNewRowReader(t *testing.T) {
@@ -39,3 +44,188 @@ func TestNewRowWriter(t *testing.T) {
 		}
 	}
 }
+
+func TestCompressedReadWrite(t *testing.T) {
+	cases := []struct {
+		write, read compression.Type
+		err         string
+	}{
+		{compression.None, compression.None, ""},
+		{compression.Gzip, compression.Gzip, ""},
+		{compression.Zstd, compression.Zstd, ""},
+		// compressed data is sniffed when no compression is specified
+		{compression.Gzip, compression.None, ""},
+		{compression.Zstd, compression.None, ""},
+		{compression.Bzip2, compression.None, "error creating bzip2 writer: bzip2 compression is read-only"},
+	}
+
+	for i, c := range cases {
+		st := &dataset.Structure{Format: dataset.CSVDataFormat, Compression: c.write, Schema: &dataset.Schema{Fields: xlsxFields}}
+		buf := &bytes.Buffer{}
+		w, err := NewRowWriter(st, buf)
+		if !(err == nil && c.err == "" || err != nil && err.Error() == c.err) {
+			t.Errorf("case %d error mismatch. expected: '%s', got: '%s'", i, c.err, err)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		for _, row := range rows["cities"] {
+			if err := w.WriteRow(row); err != nil {
+				t.Errorf("case %d error writing row: %s", i, err.Error())
+			}
+		}
+		if err := w.Close(); err != nil {
+			t.Errorf("case %d error closing writer: %s", i, err.Error())
+			continue
+		}
+		if got := compression.SniffType(buf.Bytes()); got != c.write {
+			t.Errorf("case %d expected written data to be %s compressed, sniffed: '%s'", i, c.write, got)
+		}
+
+		r, err := NewRowReader(&dataset.Structure{Format: dataset.CSVDataFormat, Compression: c.read, Schema: st.Schema}, buf)
+		if err != nil {
+			t.Errorf("case %d error allocating reader: %s", i, err.Error())
+			continue
+		}
+		for j, expect := range rows["cities"] {
+			row, err := r.ReadRow()
+			if err != nil {
+				t.Errorf("case %d row %d read error: %s", i, j, err.Error())
+				break
+			}
+			for k, cell := range row {
+				if !bytes.Equal(cell, expect[k]) {
+					t.Errorf("case %d row %d cell %d mismatch. expected: '%s', got: '%s'", i, j, k, expect[k], cell)
+				}
+			}
+		}
+		if _, err := r.ReadRow(); err != io.EOF {
+			t.Errorf("case %d expected EOF, got: %v", i, err)
+		}
+	}
+}
+
+func TestUncompressedBzip2Prefix(t *testing.T) {
+	// plain text that starts with bzip2 magic isn't read as bzip2
+	st := &dataset.Structure{Format: dataset.CSVDataFormat, FormatConfig: &dataset.CSVOptions{HeaderRow: true}, Schema: &dataset.Schema{Fields: []*dataset.Field{
+		{Name: "BZh1", Type: datatypes.String},
+		{Name: "b", Type: datatypes.String},
+	}}}
+	r, err := NewRowReader(st, strings.NewReader("BZh1,b\nx,y\n"))
+	if err != nil {
+		t.Fatalf("error allocating reader: %s", err.Error())
+	}
+	row, err := r.ReadRow()
+	if err != nil {
+		t.Fatalf("error reading row: %s", err.Error())
+	}
+	if string(row[0]) != "x" || string(row[1]) != "y" {
+		t.Errorf("row mismatch. got: %s", row)
+	}
+}
+
+func TestCompressedStructuredBuffer(t *testing.T) {
+	st := &dataset.Structure{Format: dataset.JSONDataFormat, Compression: compression.Gzip, Schema: &dataset.Schema{Fields: xlsxFields}}
+	buf, err := NewStructuredBuffer(st)
+	if err != nil {
+		t.Fatalf("error allocating buffer: %s", err.Error())
+	}
+	for _, row := range rows["cities"] {
+		if err := buf.WriteRow(row); err != nil {
+			t.Errorf("error writing row: %s", err.Error())
+		}
+	}
+	if err := buf.Close(); err != nil {
+		t.Fatalf("error closing buffer: %s", err.Error())
+	}
+
+	count := 0
+	if err := EachRow(buf, func(i int, row [][]byte, err error) error {
+		count++
+		return err
+	}); err != nil {
+		t.Errorf("error reading rows: %s", err.Error())
+	}
+	if count != len(rows["cities"]) {
+		t.Errorf("row count mismatch. expected: %d, got: %d", len(rows["cities"]), count)
+	}
+}
+
+func TestEncodedReadWrite(t *testing.T) {
+	fields := []*dataset.Field{
+		{Name: "name", Type: datatypes.String},
+		{Name: "city", Type: datatypes.String},
+	}
+	data := [][][]byte{
+		{[]byte("Café Zoë"), []byte("Montréal")},
+		{[]byte("Göteborg Kaffe"), []byte("Göteborg")},
+	}
+
+	cases := []struct {
+		format      dataset.DataFormat
+		encoding    string
+		compression compression.Type
+		contains    string
+		err         string
+	}{
+		{dataset.CSVDataFormat, "windows-1252", compression.None, "Caf\xe9 Zo\xeb", ""},
+		{dataset.CSVDataFormat, "latin1", compression.None, "Caf\xe9 Zo\xeb", ""},
+		{dataset.CSVDataFormat, "utf-16", compression.None, "C\x00a\x00f\x00\xe9\x00", ""},
+		{dataset.CSVDataFormat, "windows-1252", compression.Gzip, "", ""},
+		{dataset.JSONDataFormat, "utf-16be", compression.None, "\x00C\x00a\x00f\x00\xe9", ""},
+		{dataset.XMLDataFormat, "windows-1252", compression.None, `encoding="windows-1252"`, ""},
+		{dataset.CSVDataFormat, "klingon", compression.None, "", "unsupported encoding: 'klingon'"},
+	}
+
+	for i, c := range cases {
+		st := &dataset.Structure{
+			Format:      c.format,
+			Encoding:    c.encoding,
+			Compression: c.compression,
+			Schema:      &dataset.Schema{Fields: fields},
+		}
+		buf := &bytes.Buffer{}
+		w, err := NewRowWriter(st, buf)
+		if !(err == nil && c.err == "" || err != nil && err.Error() == c.err) {
+			t.Errorf("case %d error mismatch. expected: '%s', got: '%s'", i, c.err, err)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		for _, row := range data {
+			if err := w.WriteRow(row); err != nil {
+				t.Errorf("case %d error writing row: %s", i, err.Error())
+			}
+		}
+		if err := w.Close(); err != nil {
+			t.Errorf("case %d error closing writer: %s", i, err.Error())
+			continue
+		}
+		if !bytes.Contains(buf.Bytes(), []byte(c.contains)) {
+			t.Errorf("case %d expected encoded data to contain %q, got: %q", i, c.contains, buf.Bytes())
+		}
+
+		r, err := NewRowReader(st, buf)
+		if err != nil {
+			t.Errorf("case %d error allocating reader: %s", i, err.Error())
+			continue
+		}
+		for j, expect := range data {
+			row, err := r.ReadRow()
+			if err != nil {
+				t.Errorf("case %d row %d read error: %s", i, j, err.Error())
+				break
+			}
+			for k, cell := range row {
+				if !bytes.Equal(cell, expect[k]) {
+					t.Errorf("case %d row %d cell %d mismatch. expected: '%s', got: '%s'", i, j, k, expect[k], cell)
+				}
+			}
+		}
+		if _, err := r.ReadRow(); err != io.EOF {
+			t.Errorf("case %d expected EOF, got: %v", i, err)
+		}
+	}
+}
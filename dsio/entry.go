@@ -0,0 +1,271 @@
+package dsio
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/dataset/datatypes"
+)
+
+// Entry is a single row of parsed values. Values hold go types as
+// returned by datatypes.Type.Parse, in schema field order. missing
+// values are nil
+type Entry struct {
+	// Index is the zero-indexed position of the row this entry was read from
+	Index int
+	// Values of the entry, one per schema field
+	Values []interface{}
+}
+
+// EntryError is an error parsing or encoding a single cell of an entry
+type EntryError struct {
+	// Row is the zero-indexed row the error occurred in
+	Row int
+	// Column is the zero-indexed column the error occurred in,
+	// -1 for errors that apply to the entire row
+	Column int
+	// Field is the name of the schema field for the column, if any
+	Field string
+	// Err is the underlying error
+	Err error
+}
+
+// Error implements the error interface
+func (e *EntryError) Error() string {
+	if e.Column < 0 {
+		return fmt.Sprintf("row %d: %s", e.Row, e.Err.Error())
+	}
+	return fmt.Sprintf("row %d, column %d (%s): %s", e.Row, e.Column, e.Field, e.Err.Error())
+}
+
+// EntryReader reads typed entries from a RowReader, parsing each cell
+// according to the matching schema field. Works with any RowReader
+type EntryReader struct {
+	rr  RowReader
+	idx int
+}
+
+// NewEntryReader wraps a RowReader to read entries
+func NewEntryReader(rr RowReader) *EntryReader {
+	return &EntryReader{rr: rr}
+}
+
+// Structure gives this reader's structure
+func (r *EntryReader) Structure() *dataset.Structure {
+	return r.rr.Structure()
+}
+
+// ReadEntry reads and parses one row from the underlying reader.
+// empty cells and cells matching a field's null values are nil.
+// parse errors are returned as *EntryError, io.EOF is returned
+// unmodified when no rows remain
+func (r *EntryReader) ReadEntry() (Entry, error) {
+	ent := Entry{Index: r.idx}
+	row, err := r.rr.ReadRow()
+	if err != nil {
+		return ent, err
+	}
+	r.idx++
+
+	st := r.rr.Structure()
+	if st.Schema == nil {
+		return ent, &EntryError{Row: ent.Index, Column: -1, Err: fmt.Errorf("structure must have a schema to read entries")}
+	}
+	fields := st.Schema.Fields
+	if len(row) != len(fields) {
+		return ent, &EntryError{Row: ent.Index, Column: -1, Err: fmt.Errorf("row has %d values, schema has %d fields", len(row), len(fields))}
+	}
+
+	ent.Values = make([]interface{}, len(row))
+	for i, f := range fields {
+		if st.IsNull(f, row[i]) {
+			continue
+		}
+		val, err := parseEntryValue(f, row[i])
+		if err != nil {
+			return ent, &EntryError{Row: ent.Index, Column: i, Field: f.Name, Err: err}
+		}
+		ent.Values[i] = val
+	}
+	return ent, nil
+}
+
+// parseEntryValue parses a cell with the field's Parse method, detecting
+// the type of Any values
+func parseEntryValue(f *dataset.Field, c []byte) (interface{}, error) {
+	t := f.Type
+	if t == datatypes.Any {
+		t = datatypes.ParseDatatype(c)
+	}
+
+	if (t == datatypes.Array || t == datatypes.Object) && len(c) > 0 {
+		if errs := datatypes.ValidateElement(f, c); len(errs) > 0 {
+			return nil, errs[0]
+		}
+	}
+
+	if t != f.Type {
+		cp := *f
+		cp.Type = t
+		f = &cp
+	}
+	return f.Parse(c)
+}
+
+// EntryWriter writes typed entries to a RowWriter, encoding each value
+// according to the matching schema field. Works with any RowWriter
+type EntryWriter struct {
+	rw  RowWriter
+	idx int
+}
+
+// NewEntryWriter wraps a RowWriter to write entries
+func NewEntryWriter(rw RowWriter) *EntryWriter {
+	return &EntryWriter{rw: rw}
+}
+
+// Structure gives this writer's structure
+func (w *EntryWriter) Structure() *dataset.Structure {
+	return w.rw.Structure()
+}
+
+// WriteEntry encodes and writes one entry to the underlying writer.
+// nil values are written as the field's first null value, or as an empty
+// cell if the field has no null values. formats with a native null
+// value always write nil as an empty cell
+func (w *EntryWriter) WriteEntry(ent Entry) error {
+	st := w.rw.Structure()
+	if st.Schema == nil {
+		return &EntryError{Row: w.idx, Column: -1, Err: fmt.Errorf("structure must have a schema to write entries")}
+	}
+	fields := st.Schema.Fields
+	if len(ent.Values) != len(fields) {
+		return &EntryError{Row: w.idx, Column: -1, Err: fmt.Errorf("entry has %d values, schema has %d fields", len(ent.Values), len(fields))}
+	}
+
+	row := make([][]byte, len(fields))
+	for i, f := range fields {
+		if ent.Values[i] == nil {
+			if nativeNull(st.Format) {
+				row[i] = []byte{}
+			} else {
+				row[i] = []byte(nullValue(st, f))
+			}
+			continue
+		}
+		data, err := formatEntryValue(f, ent.Values[i])
+		if err != nil {
+			return &EntryError{Row: w.idx, Column: i, Field: f.Name, Err: err}
+		}
+		row[i] = data
+	}
+
+	if err := w.rw.WriteRow(row); err != nil {
+		return err
+	}
+	w.idx++
+	return nil
+}
+
+// formatEntryValue encodes a value, writing dates & times with the field's
+// format, rounding decimals to the precision & scale of the field's
+// format, if any, and writing numbers with the field's number format
+func formatEntryValue(f *dataset.Field, val interface{}) ([]byte, error) {
+	if t, ok := val.(time.Time); ok && isTimeType(f.Type) && f.Format != "" {
+		tf, err := datatypes.ParseTimeFormat(f.Type, f.Format)
+		if err != nil {
+			return nil, err
+		}
+		return []byte(tf.FormatValue(t)), nil
+	}
+
+	data, err := f.Type.ValueToBytes(val)
+	if err != nil || !isNumberType(f.Type) {
+		return data, err
+	}
+	nf, err := f.NumberFormat()
+	if err != nil {
+		return nil, err
+	}
+	if f.Type != datatypes.Decimal || f.Format == "" {
+		return []byte(nf.FormatValue(string(data))), nil
+	}
+
+	df, err := datatypes.ParseDecimalFormat(f.Format)
+	if err != nil {
+		return nil, err
+	}
+	r, err := datatypes.ParseDecimal(data)
+	if err != nil {
+		return nil, err
+	}
+	str, err := df.FormatValue(r)
+	if err != nil {
+		return nil, err
+	}
+	return []byte(nf.FormatValue(str)), nil
+}
+
+// isNumberType reports weather values of a datatype are read & written
+// with a number format
+func isNumberType(t datatypes.Type) bool {
+	return t == datatypes.Integer || t == datatypes.Float || t == datatypes.Decimal
+}
+
+// plainNumber normalizes a numeric cell written in the field's number
+// format to a plain number
+func plainNumber(f *dataset.Field, c []byte) ([]byte, error) {
+	nf, err := f.NumberFormat()
+	if err != nil {
+		return nil, err
+	}
+	return nf.Normalize(c)
+}
+
+// isTimeType reports weather values of a datatype are read & written
+// with a time format
+func isTimeType(t datatypes.Type) bool {
+	return t == datatypes.Date || t == datatypes.Time || t == datatypes.DateTime
+}
+
+// Close finalizes the underlying writer
+func (w *EntryWriter) Close() error {
+	return w.rw.Close()
+}
+
+// nativeNull reports weather writers for a data format encode empty
+// cells as an explicit null value
+func nativeNull(df dataset.DataFormat) bool {
+	return df == dataset.JSONDataFormat || df == dataset.NDJSONDataFormat || df == dataset.GeoJSONDataFormat || df == dataset.SQLDataFormat
+}
+
+// nullValue gives the raw value written for nulls in a field, the first of
+// the field's null values, or the empty string if it has none
+func nullValue(st *dataset.Structure, f *dataset.Field) string {
+	if vals := st.NullValues(f); len(vals) > 0 {
+		return vals[0]
+	}
+	return ""
+}
+
+// nullCells gives a row with null values of each field replaced by empty
+// cells, for writers of formats with a native null. row is only copied if
+// a cell is replaced
+func nullCells(st *dataset.Structure, row [][]byte) [][]byte {
+	if st.Schema == nil {
+		return row
+	}
+	copied := false
+	for i, c := range row {
+		if i >= len(st.Schema.Fields) || len(c) == 0 || !st.IsNull(st.Schema.Fields[i], c) {
+			continue
+		}
+		if !copied {
+			row = append([][]byte{}, row...)
+			copied = true
+		}
+		row[i] = nil
+	}
+	return row
+}
@@ -0,0 +1,362 @@
+package dsio
+
+import (
+	"bytes"
+	"io"
+	"math/big"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/dataset/datatypes"
+)
+
+var entryFields = []*dataset.Field{
+	{Name: "city", Type: datatypes.String},
+	{Name: "pop", Type: datatypes.Integer, MissingValue: "NA"},
+	{Name: "avg_age", Type: datatypes.Float},
+	{Name: "in_usa", Type: datatypes.Boolean},
+}
+
+var entries = []Entry{
+	{Index: 0, Values: []interface{}{"toronto", int64(40000000), 55.5, false}},
+	{Index: 1, Values: []interface{}{"new york", nil, 44.4, true}},
+	{Index: 2, Values: []interface{}{"chatham", int64(35000), nil, true}},
+}
+
+func TestEntryReadWrite(t *testing.T) {
+	formats := []dataset.DataFormat{
+		dataset.CSVDataFormat,
+		dataset.JSONDataFormat,
+		dataset.NDJSONDataFormat,
+		dataset.XMLDataFormat,
+	}
+
+	for _, df := range formats {
+		st := &dataset.Structure{Format: df, Schema: &dataset.Schema{Fields: entryFields}}
+		buf := &bytes.Buffer{}
+		rw, err := NewRowWriter(st, buf)
+		if err != nil {
+			t.Errorf("%s error allocating writer: %s", df, err.Error())
+			continue
+		}
+		w := NewEntryWriter(rw)
+		for _, ent := range entries {
+			if err := w.WriteEntry(ent); err != nil {
+				t.Errorf("%s error writing entry: %s", df, err.Error())
+			}
+		}
+		if err := w.Close(); err != nil {
+			t.Errorf("%s error closing writer: %s", df, err.Error())
+			continue
+		}
+
+		rr, err := NewRowReader(st, buf)
+		if err != nil {
+			t.Errorf("%s error allocating reader: %s", df, err.Error())
+			continue
+		}
+		r := NewEntryReader(rr)
+		got := []Entry{}
+		for {
+			ent, err := r.ReadEntry()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				t.Errorf("%s error reading entry: %s", df, err.Error())
+				break
+			}
+			got = append(got, ent)
+		}
+
+		if !reflect.DeepEqual(entries, got) {
+			t.Errorf("%s entry mismatch. expected: %v, got: %v", df, entries, got)
+		}
+	}
+}
+
+func TestEntryDecimalFormat(t *testing.T) {
+	fields := []*dataset.Field{
+		{Name: "item", Type: datatypes.String},
+		{Name: "price", Type: datatypes.Decimal, Format: "6,2"},
+	}
+
+	cases := []struct {
+		value  interface{}
+		expect string
+		err    string
+	}{
+		{big.NewRat(3, 2), "widget,1.50\n", ""},
+		{big.NewRat(10005, 1000), "widget,10.01\n", ""},
+		{int64(42), "widget,42.00\n", ""},
+		{big.NewRat(123456, 1), "", "row 0, column 1 (price): 123456.00 exceeds decimal precision 6, scale 2"},
+	}
+
+	for i, c := range cases {
+		st := &dataset.Structure{Format: dataset.CSVDataFormat, Schema: &dataset.Schema{Fields: fields}}
+		buf := &bytes.Buffer{}
+		rw, err := NewRowWriter(st, buf)
+		if err != nil {
+			t.Errorf("case %d error allocating writer: %s", i, err.Error())
+			continue
+		}
+		w := NewEntryWriter(rw)
+		err = w.WriteEntry(Entry{Values: []interface{}{"widget", c.value}})
+		if !(err == nil && c.err == "" || err != nil && err.Error() == c.err) {
+			t.Errorf("case %d error mismatch. expected: '%s', got: '%s'", i, c.err, err)
+			continue
+		}
+		if err := w.Close(); err != nil {
+			t.Errorf("case %d error closing writer: %s", i, err.Error())
+			continue
+		}
+		if buf.String() != c.expect {
+			t.Errorf("case %d output mismatch. expected: %q, got: %q", i, c.expect, buf.String())
+		}
+	}
+
+	st := &dataset.Structure{Format: dataset.CSVDataFormat, Schema: &dataset.Schema{Fields: fields}}
+	rr, err := NewRowReader(st, bytes.NewBufferString("widget,12345678.9\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := "row 0, column 1 (price): 12345678.90 exceeds decimal precision 6, scale 2"
+	if _, err := NewEntryReader(rr).ReadEntry(); err == nil || err.Error() != expect {
+		t.Errorf("error mismatch. expected: '%s', got: '%v'", expect, err)
+	}
+}
+
+func TestEntryTimeFormat(t *testing.T) {
+	fields := []*dataset.Field{
+		{Name: "date", Type: datatypes.Date, Format: "%d/%m/%Y"},
+		{Name: "start", Type: datatypes.Time, Format: "%I:%M %p"},
+		{Name: "updated", Type: datatypes.DateTime},
+		{Name: "length", Type: datatypes.Duration},
+		{Name: "local", Type: datatypes.DateTime, Format: "%Y-%m-%d %H:%M;tz=-05:00"},
+	}
+	data := "15/03/2021,01:30 PM,2021-03-15T17:00:00-05:00,PT1H30M,2021-03-15 17:00\n"
+
+	st := &dataset.Structure{Format: dataset.CSVDataFormat, Schema: &dataset.Schema{Fields: fields}}
+	rr, err := NewRowReader(st, bytes.NewBufferString(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ent, err := NewEntryReader(rr).ReadEntry()
+	if err != nil {
+		t.Fatalf("error reading entry: %s", err.Error())
+	}
+
+	expect := []interface{}{
+		time.Date(2021, 3, 15, 0, 0, 0, 0, time.UTC),
+		time.Date(0, 1, 1, 13, 30, 0, 0, time.UTC),
+		time.Date(2021, 3, 15, 22, 0, 0, 0, time.UTC),
+		90 * time.Minute,
+		time.Date(2021, 3, 15, 22, 0, 0, 0, time.UTC),
+	}
+	for i, v := range expect {
+		if tv, ok := v.(time.Time); ok {
+			if got, ok := ent.Values[i].(time.Time); !ok || !got.Equal(tv) {
+				t.Errorf("value %d mismatch. expected: %s, got: %v", i, tv, ent.Values[i])
+			}
+		} else if ent.Values[i] != v {
+			t.Errorf("value %d mismatch. expected: %v, got: %v", i, v, ent.Values[i])
+		}
+	}
+
+	buf := &bytes.Buffer{}
+	rw, err := NewRowWriter(st, buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := NewEntryWriter(rw)
+	if err := w.WriteEntry(ent); err != nil {
+		t.Fatalf("error writing entry: %s", err.Error())
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != data {
+		t.Errorf("output mismatch. expected: %q, got: %q", data, buf.String())
+	}
+
+	rr, err = NewRowReader(st, bytes.NewBufferString("2021-03-15,01:30 PM,,,\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectErr := "row 0, column 0 (date): invalid date: 2021-03-15. expected format '%d/%m/%Y'"
+	if _, err := NewEntryReader(rr).ReadEntry(); err == nil || err.Error() != expectErr {
+		t.Errorf("error mismatch. expected: '%s', got: '%v'", expectErr, err)
+	}
+}
+
+func TestEntryReaderErrors(t *testing.T) {
+	cases := []struct {
+		data string
+		err  string
+	}{
+		{"toronto,40000000,55.5,false\n", ""},
+		{"toronto,NA,55.5,false\n", ""},
+		{"toronto,forty,55.5,false\n", "row 0, column 1 (pop): strconv.ParseInt: parsing \"forty\": invalid syntax"},
+		{"toronto,40000000,55.5,false\nchatham,35000,44.4,maybe\n", "row 1, column 3 (in_usa): strconv.ParseBool: parsing \"maybe\": invalid syntax"},
+		{"toronto,40000000,55.5\n", "row 0: row has 3 values, schema has 4 fields"},
+	}
+
+	for i, c := range cases {
+		st := &dataset.Structure{Format: dataset.CSVDataFormat, Schema: &dataset.Schema{Fields: entryFields}}
+		rr, err := NewRowReader(st, bytes.NewBufferString(c.data))
+		if err != nil {
+			t.Errorf("case %d error allocating reader: %s", i, err.Error())
+			continue
+		}
+		r := NewEntryReader(rr)
+		for {
+			_, err = r.ReadEntry()
+			if err != nil {
+				break
+			}
+		}
+		if err == io.EOF {
+			err = nil
+		}
+		if !(err == nil && c.err == "" || err != nil && err.Error() == c.err) {
+			t.Errorf("case %d error mismatch. expected: '%s', got: '%s'", i, c.err, err)
+		}
+	}
+}
+
+func TestEntryWriterErrors(t *testing.T) {
+	cases := []struct {
+		ent Entry
+		err string
+	}{
+		{Entry{Values: []interface{}{"toronto", int64(40000000), 55.5, false}}, ""},
+		{Entry{Values: []interface{}{"toronto", "forty", 55.5, false}}, "row 0, column 1 (pop): forty is not an integer value"},
+		{Entry{Values: []interface{}{"toronto"}}, "row 0: entry has 1 values, schema has 4 fields"},
+	}
+
+	for i, c := range cases {
+		st := &dataset.Structure{Format: dataset.CSVDataFormat, Schema: &dataset.Schema{Fields: entryFields}}
+		rw, err := NewRowWriter(st, &bytes.Buffer{})
+		if err != nil {
+			t.Errorf("case %d error allocating writer: %s", i, err.Error())
+			continue
+		}
+		err = NewEntryWriter(rw).WriteEntry(c.ent)
+		if !(err == nil && c.err == "" || err != nil && err.Error() == c.err) {
+			t.Errorf("case %d error mismatch. expected: '%s', got: '%s'", i, c.err, err)
+		}
+	}
+}
+
+func TestEntryNested(t *testing.T) {
+	fields := []*dataset.Field{
+		{Name: "city", Type: datatypes.String},
+		{Name: "mayor", Type: datatypes.Object, Properties: []*dataset.Field{
+			{Name: "terms", Type: datatypes.Array, Items: &dataset.Field{Type: datatypes.Integer}},
+		}},
+	}
+	data := `[
+{"city":"toronto","mayor":{"name":"john","terms":[1,2]}},
+{"city":"chatham","mayor":{"terms":[1,"two"]}}
+]`
+
+	st := &dataset.Structure{Format: dataset.JSONDataFormat, Schema: &dataset.Schema{Fields: fields}}
+	rr, err := NewRowReader(st, bytes.NewBufferString(data))
+	if err != nil {
+		t.Fatalf("error allocating reader: %s", err.Error())
+	}
+	r := NewEntryReader(rr)
+
+	ent, err := r.ReadEntry()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+	expect := map[string]interface{}{"name": "john", "terms": []interface{}{float64(1), float64(2)}}
+	if !reflect.DeepEqual(ent.Values[1], expect) {
+		t.Errorf("value mismatch. expected: %v, got: %v", expect, ent.Values[1])
+	}
+
+	_, err = r.ReadEntry()
+	expectErr := "row 1, column 1 (mayor): mayor.terms[1]: expected integer, got string"
+	if err == nil || err.Error() != expectErr {
+		t.Errorf("error mismatch. expected: '%s', got: '%v'", expectErr, err)
+	}
+}
+
+func TestNullValues(t *testing.T) {
+	fields := []*dataset.Field{
+		{Name: "city", Type: datatypes.String},
+		{Name: "pop", Type: datatypes.Integer},
+		{Name: "rank", Type: datatypes.Integer, MissingValue: []interface{}{"-", "?"}},
+	}
+	data := "toronto,NA,1\nchatham,35000,?\natlantis,N/A,-\n"
+	st := &dataset.Structure{
+		Format:        dataset.CSVDataFormat,
+		MissingValues: []string{"NA", "N/A"},
+		Schema:        &dataset.Schema{Fields: fields},
+	}
+
+	rr, err := NewRowReader(st, bytes.NewBufferString(data))
+	if err != nil {
+		t.Fatalf("error allocating reader: %s", err.Error())
+	}
+	r := NewEntryReader(rr)
+	expect := [][]interface{}{
+		{"toronto", nil, int64(1)},
+		{"chatham", int64(35000), nil},
+		{"atlantis", nil, nil},
+	}
+	for i, e := range expect {
+		ent, err := r.ReadEntry()
+		if err != nil {
+			t.Fatalf("row %d unexpected error: %s", i, err.Error())
+		}
+		if !reflect.DeepEqual(ent.Values, e) {
+			t.Errorf("row %d mismatch. expected: %v, got: %v", i, e, ent.Values)
+		}
+	}
+
+	// null values are written as native nulls
+	rr, err = NewRowReader(st, bytes.NewBufferString(data))
+	if err != nil {
+		t.Fatalf("error allocating reader: %s", err.Error())
+	}
+	buf := &bytes.Buffer{}
+	jst := &dataset.Structure{Format: dataset.JSONDataFormat, MissingValues: st.MissingValues, Schema: st.Schema}
+	w := NewJSONWriter(jst, buf)
+	err = EachRow(rr, func(num int, row [][]byte, err error) error {
+		if err != nil {
+			return err
+		}
+		return w.WriteRow(row)
+	})
+	if err != nil {
+		t.Fatalf("error copying rows: %s", err.Error())
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("error closing writer: %s", err.Error())
+	}
+	expectJSON := `[
+{"city":"toronto","pop":null,"rank":1},
+{"city":"chatham","pop":35000,"rank":null},
+{"city":"atlantis","pop":null,"rank":null}
+]`
+	if buf.String() != expectJSON {
+		t.Errorf("json mismatch. expected:\n%s\ngot:\n%s", expectJSON, buf.String())
+	}
+
+	// nil values are written as the first null value
+	buf = &bytes.Buffer{}
+	ew := NewEntryWriter(NewCSVWriter(st, buf))
+	if err := ew.WriteEntry(Entry{Values: []interface{}{"atlantis", nil, nil}}); err != nil {
+		t.Fatalf("error writing entry: %s", err.Error())
+	}
+	if err := ew.Close(); err != nil {
+		t.Fatalf("error closing writer: %s", err.Error())
+	}
+	if buf.String() != "atlantis,NA,-\n" {
+		t.Errorf("csv mismatch. got: %q", buf.String())
+	}
+}
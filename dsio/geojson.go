@@ -0,0 +1,334 @@
+package dsio
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/dataset/datatypes"
+)
+
+// geoJSONFeature is a single feature of a feature collection
+type geoJSONFeature struct {
+	Type       string                     `json:"type"`
+	Geometry   json.RawMessage            `json:"geometry"`
+	Properties map[string]json.RawMessage `json:"properties"`
+}
+
+// GeoJSONReader implements the RowReader interface for GeoJSON feature
+// collections. Each feature is a row, with the feature's geometry read
+// into the structure's geometry field & properties mapped to other schema
+// fields by name. features are decoded one at a time, so large collections
+// can be streamed
+type GeoJSONReader struct {
+	st      *dataset.Structure
+	dec     *json.Decoder
+	geomIdx int
+	started bool
+	done    bool
+	count   int
+}
+
+// NewGeoJSONReader creates a reader from a structure and read source
+func NewGeoJSONReader(st *dataset.Structure, r io.Reader) *GeoJSONReader {
+	return &GeoJSONReader{
+		st:      st,
+		dec:     json.NewDecoder(r),
+		geomIdx: geometryFieldIndex(st),
+	}
+}
+
+// Structure gives this reader's structure
+func (r *GeoJSONReader) Structure() *dataset.Structure {
+	return r.st
+}
+
+// ReadRow reads one feature from the collection
+func (r *GeoJSONReader) ReadRow() ([][]byte, error) {
+	if r.done {
+		return nil, io.EOF
+	}
+	if r.st.Schema == nil {
+		return nil, fmt.Errorf("structure must have a schema to read geojson")
+	}
+	if !r.started {
+		if err := r.readToFeatures(); err != nil {
+			return nil, err
+		}
+		r.started = true
+	}
+
+	if !r.dec.More() {
+		r.done = true
+		return nil, io.EOF
+	}
+
+	feat := &geoJSONFeature{}
+	if err := r.dec.Decode(feat); err != nil {
+		return nil, fmt.Errorf("feature %d: %s", r.count, err.Error())
+	}
+	if feat.Type != "Feature" {
+		return nil, fmt.Errorf("feature %d: expected type 'Feature', got '%s'", r.count, feat.Type)
+	}
+
+	row := make([][]byte, len(r.st.Schema.Fields))
+	for i, f := range r.st.Schema.Fields {
+		val := feat.Properties[f.Name]
+		if i == r.geomIdx {
+			val = feat.Geometry
+		}
+		if len(val) == 0 {
+			row[i] = []byte{}
+			continue
+		}
+
+		c, err := jsonCell(f, val)
+		if err != nil {
+			return nil, fmt.Errorf("feature %d: %s", r.count, err.Error())
+		}
+		if i == r.geomIdx && f.Type == datatypes.GeoPoint && len(c) > 0 {
+			if c, err = geometryPoint(c); err != nil {
+				return nil, fmt.Errorf("feature %d: %s", r.count, err.Error())
+			}
+		}
+		row[i] = c
+	}
+	r.count++
+	return row, nil
+}
+
+// readToFeatures advances the decoder to the start of the features array
+func (r *GeoJSONReader) readToFeatures() error {
+	if tok, err := r.dec.Token(); err != nil {
+		return fmt.Errorf("invalid geojson: %s", err.Error())
+	} else if tok != json.Delim('{') {
+		return fmt.Errorf("invalid geojson: expected a FeatureCollection object")
+	}
+
+	for r.dec.More() {
+		tok, err := r.dec.Token()
+		if err != nil {
+			return fmt.Errorf("invalid geojson: %s", err.Error())
+		}
+		switch tok {
+		case "features":
+			if tok, err := r.dec.Token(); err != nil {
+				return fmt.Errorf("invalid geojson: %s", err.Error())
+			} else if tok != json.Delim('[') {
+				return fmt.Errorf("invalid geojson: features must be an array")
+			}
+			return nil
+		case "type":
+			var typ string
+			if err := r.dec.Decode(&typ); err != nil || typ != "FeatureCollection" {
+				return fmt.Errorf("invalid geojson: expected type 'FeatureCollection'")
+			}
+		default:
+			var skip json.RawMessage
+			if err := r.dec.Decode(&skip); err != nil {
+				return fmt.Errorf("invalid geojson: %s", err.Error())
+			}
+		}
+	}
+	return fmt.Errorf("invalid geojson: missing features")
+}
+
+// geometryPoint converts a Point geometry to a geopoint cell
+func geometryPoint(geom []byte) ([]byte, error) {
+	g, err := datatypes.ParseGeoJSON(geom)
+	if err != nil {
+		return nil, err
+	}
+	if g.Type != "Point" {
+		return nil, fmt.Errorf("geopoint geometry must be a Point, got '%s'", g.Type)
+	}
+	pos := g.Coordinates.([]interface{})
+	return []byte(datatypes.Point{Lon: pos[0].(float64), Lat: pos[1].(float64)}.String()), nil
+}
+
+// geometryFieldIndex finds the index of a structure's geometry field,
+// returning -1 if the schema has no geometry field
+func geometryFieldIndex(st *dataset.Structure) int {
+	if st.Schema == nil {
+		return -1
+	}
+	name := "geometry"
+	if opts, ok := st.FormatConfig.(*dataset.GeoJSONOptions); ok && opts != nil && opts.GeometryField != "" {
+		name = opts.GeometryField
+	}
+	for i, f := range st.Schema.Fields {
+		if f.Name == name {
+			return i
+		}
+	}
+	for i, f := range st.Schema.Fields {
+		if f.Type == datatypes.GeoJSON || f.Type == datatypes.GeoPoint {
+			return i
+		}
+	}
+	return -1
+}
+
+// GeoJSONWriter implements the RowWriter interface for GeoJSON feature
+// collections. The geometry field of each row is written as the feature's
+// geometry, with all other fields written as properties. The collection's
+// bounding box is written when Close is called
+type GeoJSONWriter struct {
+	st          *dataset.Structure
+	wr          io.Writer
+	geomIdx     int
+	rowsWritten int
+	bbox        *datatypes.BBox
+}
+
+// NewGeoJSONWriter creates a Writer from a structure and write destination
+func NewGeoJSONWriter(st *dataset.Structure, w io.Writer) *GeoJSONWriter {
+	return &GeoJSONWriter{
+		st:      st,
+		wr:      w,
+		geomIdx: geometryFieldIndex(st),
+	}
+}
+
+// Structure gives this writer's structure
+func (w *GeoJSONWriter) Structure() *dataset.Structure {
+	return w.st
+}
+
+// WriteRow writes one row to the writer as a feature
+func (w *GeoJSONWriter) WriteRow(row [][]byte) error {
+	if w.st.Schema == nil {
+		return fmt.Errorf("structure must have a schema to write geojson")
+	}
+	fields := w.st.Schema.Fields
+	row = nullCells(w.st, row)
+
+	geom := []byte("null")
+	if w.geomIdx >= 0 && w.geomIdx < len(row) && len(row[w.geomIdx]) > 0 {
+		var err error
+		if geom, err = w.geometry(fields[w.geomIdx], row[w.geomIdx]); err != nil {
+			return fmt.Errorf("row %d: %s", w.rowsWritten, err.Error())
+		}
+	}
+
+	buf := &bytes.Buffer{}
+	if w.rowsWritten == 0 {
+		buf.WriteString(`{"type":"FeatureCollection","features":[` + "\n")
+	} else {
+		buf.WriteString(",\n")
+	}
+	buf.WriteString(`{"type":"Feature","geometry":`)
+	buf.Write(geom)
+	buf.WriteString(`,"properties":{`)
+	written := 0
+	for i, c := range row {
+		if i == w.geomIdx || i >= len(fields) {
+			continue
+		}
+		if written > 0 {
+			buf.WriteByte(',')
+		}
+		buf.WriteString(strconv.Quote(fields[i].Name) + ":")
+		buf.Write(jsonValue(fields[i], c, nil))
+		written++
+	}
+	buf.WriteString("}}")
+
+	if _, err := w.wr.Write(buf.Bytes()); err != nil {
+		return fmt.Errorf("error writing geojson feature: %s", err.Error())
+	}
+	w.rowsWritten++
+	return nil
+}
+
+// geometry encodes a geometry field cell as a GeoJSON geometry, extending
+// the collection's bounding box. geopoints are written as Point geometries
+func (w *GeoJSONWriter) geometry(f *dataset.Field, c []byte) ([]byte, error) {
+	if f.Type == datatypes.GeoPoint {
+		p, err := datatypes.ParseGeoPoint(c)
+		if err != nil {
+			return nil, err
+		}
+		w.extend(p)
+		return json.Marshal(&datatypes.Geometry{Type: "Point", Coordinates: []float64{p.Lon, p.Lat}})
+	}
+
+	g, err := datatypes.ParseGeoJSON(c)
+	if err != nil {
+		return nil, err
+	}
+	w.extend(g)
+	return json.Marshal(g)
+}
+
+// extend grows the collection's bounding box to include a geo value
+func (w *GeoJSONWriter) extend(val interface{}) {
+	b, ok := datatypes.ValueBBox(val)
+	if !ok {
+		return
+	}
+	if w.bbox == nil {
+		w.bbox = &b
+		return
+	}
+	*w.bbox = w.bbox.Union(b)
+}
+
+// Close finalizes the writer, indicating no more records
+// will be written
+func (w *GeoJSONWriter) Close() error {
+	end := "\n]}"
+	if w.rowsWritten == 0 {
+		end = `{"type":"FeatureCollection","features":[]}`
+	} else if w.bbox != nil {
+		data, err := json.Marshal(w.bbox)
+		if err != nil {
+			return err
+		}
+		end = "\n],\"bbox\":" + string(data) + "}"
+	}
+	if _, err := w.wr.Write([]byte(end)); err != nil {
+		return fmt.Errorf("error closing geojson writer: %s", err.Error())
+	}
+	return nil
+}
+
+// GeoBounds reads all rows from a reader, giving the bounding box of all
+// geopoint & geojson values in the schema's fields. null values are
+// skipped, a nil box is returned if the data has no geographic values
+func GeoBounds(rr RowReader) (*datatypes.BBox, error) {
+	st := rr.Structure()
+	if st.Schema == nil {
+		return nil, fmt.Errorf("structure must have a schema to compute bounds")
+	}
+
+	var bbox *datatypes.BBox
+	for i := 0; ; i++ {
+		row, err := rr.ReadRow()
+		if err == io.EOF {
+			return bbox, nil
+		} else if err != nil {
+			return nil, err
+		}
+
+		for j, f := range st.Schema.Fields {
+			if j >= len(row) || st.IsNull(f, row[j]) || (f.Type != datatypes.GeoPoint && f.Type != datatypes.GeoJSON) {
+				continue
+			}
+			val, err := f.Type.Parse(row[j])
+			if err != nil {
+				return nil, fmt.Errorf("row %d, column %d (%s): %s", i, j, f.Name, err.Error())
+			}
+			if b, ok := datatypes.ValueBBox(val); ok {
+				if bbox == nil {
+					bbox = &b
+				} else {
+					*bbox = bbox.Union(b)
+				}
+			}
+		}
+	}
+}
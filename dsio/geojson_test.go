@@ -0,0 +1,221 @@
+package dsio
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/dataset/datatypes"
+)
+
+const geoJSONCities = `{"type":"FeatureCollection","features":[
+{"type":"Feature","geometry":{"type":"Point","coordinates":[-74.006,40.7128]},"properties":{"city":"new york","pop":8500000}},
+{"type":"Feature","geometry":{"type":"Point","coordinates":[-79.3832,43.6532]},"properties":{"pop":2800000,"city":"toronto"}},
+{"type":"Feature","geometry":null,"properties":{"city":"atlantis"}}
+]}`
+
+func TestGeoJSONReader(t *testing.T) {
+	fields := []*dataset.Field{
+		{Name: "geometry", Type: datatypes.GeoJSON},
+		{Name: "city", Type: datatypes.String},
+		{Name: "pop", Type: datatypes.Integer},
+	}
+	pointFields := []*dataset.Field{
+		{Name: "city", Type: datatypes.String},
+		{Name: "location", Type: datatypes.GeoPoint},
+	}
+
+	cases := []struct {
+		schema *dataset.Schema
+		data   string
+		expect [][]string
+		err    string
+	}{
+		{&dataset.Schema{Fields: fields}, geoJSONCities, [][]string{
+			{`{"type":"Point","coordinates":[-74.006,40.7128]}`, "new york", "8500000"},
+			{`{"type":"Point","coordinates":[-79.3832,43.6532]}`, "toronto", "2800000"},
+			{"", "atlantis", ""},
+		}, ""},
+		{&dataset.Schema{Fields: pointFields}, geoJSONCities, [][]string{
+			{"new york", "40.7128,-74.006"},
+			{"toronto", "43.6532,-79.3832"},
+			{"atlantis", ""},
+		}, ""},
+		{&dataset.Schema{Fields: fields}, `{"bbox":[0,0,1,1],"features":[],"type":"FeatureCollection"}`, [][]string{}, ""},
+		{&dataset.Schema{Fields: fields}, `{"type":"Feature"}`, nil, "invalid geojson: expected type 'FeatureCollection'"},
+		{&dataset.Schema{Fields: fields}, `{"type":"FeatureCollection"}`, nil, "invalid geojson: missing features"},
+		{&dataset.Schema{Fields: fields}, `[]`, nil, "invalid geojson: expected a FeatureCollection object"},
+		{&dataset.Schema{Fields: fields}, `{"type":"FeatureCollection","features":[{"type":"Point"}]}`, nil, "feature 0: expected type 'Feature', got 'Point'"},
+		{&dataset.Schema{Fields: pointFields}, `{"type":"FeatureCollection","features":[{"type":"Feature","geometry":{"type":"LineString","coordinates":[[0,0],[1,1]]}}]}`, nil, "feature 0: geopoint geometry must be a Point, got 'LineString'"},
+		{nil, geoJSONCities, nil, "structure must have a schema to read geojson"},
+	}
+
+	for i, c := range cases {
+		st := &dataset.Structure{Format: dataset.GeoJSONDataFormat, Schema: c.schema}
+		r := NewGeoJSONReader(st, strings.NewReader(c.data))
+		got := [][]string{}
+		var err error
+		for {
+			var row [][]byte
+			row, err = r.ReadRow()
+			if err != nil {
+				break
+			}
+			strs := make([]string, len(row))
+			for j, cell := range row {
+				strs[j] = string(cell)
+			}
+			got = append(got, strs)
+		}
+		if err == io.EOF {
+			err = nil
+		}
+		if !(err == nil && c.err == "" || err != nil && err.Error() == c.err) {
+			t.Errorf("case %d error mismatch. expected: '%s', got: '%s'", i, c.err, err)
+			continue
+		}
+		if c.expect == nil {
+			continue
+		}
+
+		if len(got) != len(c.expect) {
+			t.Errorf("case %d row count mismatch. expected: %d, got: %d", i, len(c.expect), len(got))
+			continue
+		}
+		for j, row := range got {
+			if strings.Join(row, "|") != strings.Join(c.expect[j], "|") {
+				t.Errorf("case %d row %d mismatch. expected: %v, got: %v", i, j, c.expect[j], row)
+			}
+		}
+	}
+}
+
+func TestGeoJSONWriter(t *testing.T) {
+	cases := []struct {
+		st     *dataset.Structure
+		rows   [][][]byte
+		expect string
+		err    string
+	}{
+		{&dataset.Structure{Schema: &dataset.Schema{Fields: []*dataset.Field{{Name: "geometry", Type: datatypes.GeoJSON}}}}, nil, `{"type":"FeatureCollection","features":[]}`, ""},
+		{&dataset.Structure{Schema: &dataset.Schema{Fields: []*dataset.Field{{Name: "city", Type: datatypes.String}, {Name: "location", Type: datatypes.GeoPoint}}}}, [][][]byte{
+			{[]byte("new york"), []byte("40.7128,-74.006")},
+			{[]byte("toronto"), []byte("43.6532,-79.3832")},
+		}, `{"type":"FeatureCollection","features":[
+{"type":"Feature","geometry":{"type":"Point","coordinates":[-74.006,40.7128]},"properties":{"city":"new york"}},
+{"type":"Feature","geometry":{"type":"Point","coordinates":[-79.3832,43.6532]},"properties":{"city":"toronto"}}
+],"bbox":[-79.3832,40.7128,-74.006,43.6532]}`, ""},
+		{&dataset.Structure{Schema: &dataset.Schema{Fields: []*dataset.Field{{Name: "shape", Type: datatypes.GeoJSON}, {Name: "n", Type: datatypes.Integer}}}}, [][][]byte{
+			{[]byte(`{"type":"LineString","coordinates":[[0,0],[1,2]]}`), []byte("1")},
+			{[]byte(""), []byte("2")},
+		}, `{"type":"FeatureCollection","features":[
+{"type":"Feature","geometry":{"type":"LineString","coordinates":[[0,0],[1,2]]},"properties":{"n":1}},
+{"type":"Feature","geometry":null,"properties":{"n":2}}
+],"bbox":[0,0,1,2]}`, ""},
+		{&dataset.Structure{Schema: &dataset.Schema{Fields: []*dataset.Field{{Name: "n", Type: datatypes.Integer}}}}, [][][]byte{
+			{[]byte("1")},
+		}, `{"type":"FeatureCollection","features":[
+{"type":"Feature","geometry":null,"properties":{"n":1}}
+]}`, ""},
+		{&dataset.Structure{Schema: &dataset.Schema{Fields: []*dataset.Field{{Name: "location", Type: datatypes.GeoPoint}}}}, [][][]byte{
+			{[]byte("100,0")},
+		}, "", "row 0: invalid geopoint: 100,0. latitude 100 must be between -90 and 90"},
+		{&dataset.Structure{}, [][][]byte{{[]byte("1")}}, "", "structure must have a schema to write geojson"},
+	}
+
+	for i, c := range cases {
+		buf := &bytes.Buffer{}
+		w := NewGeoJSONWriter(c.st, buf)
+		var err error
+		for _, row := range c.rows {
+			if err = w.WriteRow(row); err != nil {
+				break
+			}
+		}
+		if !(err == nil && c.err == "" || err != nil && err.Error() == c.err) {
+			t.Errorf("case %d error mismatch. expected: '%s', got: '%s'", i, c.err, err)
+			continue
+		}
+		if c.err != "" {
+			continue
+		}
+		if err := w.Close(); err != nil {
+			t.Errorf("case %d error closing writer: %s", i, err.Error())
+			continue
+		}
+		if buf.String() != c.expect {
+			t.Errorf("case %d output mismatch. expected:\n%s\ngot:\n%s", i, c.expect, buf.String())
+		}
+	}
+}
+
+func TestGeoJSONReadWrite(t *testing.T) {
+	st := &dataset.Structure{
+		Format:       dataset.GeoJSONDataFormat,
+		FormatConfig: &dataset.GeoJSONOptions{GeometryField: "shape"},
+		Schema: &dataset.Schema{Fields: []*dataset.Field{
+			{Name: "city", Type: datatypes.String},
+			{Name: "shape", Type: datatypes.GeoJSON},
+			{Name: "pop", Type: datatypes.Integer},
+			{Name: "note", Type: datatypes.JSON},
+		}},
+	}
+
+	buf := &bytes.Buffer{}
+	w := NewGeoJSONWriter(st, buf)
+	rows := [][][]byte{
+		{[]byte("new york"), []byte(`{"type":"Point","coordinates":[-74.006,40.7128]}`), []byte("8500000"), []byte(`"big apple"`)},
+		{[]byte("toronto"), []byte(`{"type":"Point","coordinates":[-79.3832,43.6532]}`), []byte("2800000"), []byte(`{"a":1}`)},
+	}
+	for _, row := range rows {
+		if err := w.WriteRow(row); err != nil {
+			t.Fatalf("error writing row: %s", err.Error())
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("error closing writer: %s", err.Error())
+	}
+
+	r := NewGeoJSONReader(st, buf)
+	for i, expect := range rows {
+		got, err := r.ReadRow()
+		if err != nil {
+			t.Fatalf("row %d error: %s", i, err.Error())
+		}
+		if string(bytes.Join(got, []byte("|"))) != string(bytes.Join(expect, []byte("|"))) {
+			t.Errorf("row %d mismatch. expected: %s, got: %s", i, bytes.Join(expect, []byte("|")), bytes.Join(got, []byte("|")))
+		}
+	}
+	if _, err := r.ReadRow(); err != io.EOF {
+		t.Errorf("expected EOF after all rows, got: %v", err)
+	}
+}
+
+func TestGeoBounds(t *testing.T) {
+	cases := []struct {
+		fields []*dataset.Field
+		data   string
+		expect *datatypes.BBox
+		err    string
+	}{
+		{[]*dataset.Field{{Name: "city", Type: datatypes.String}, {Name: "location", Type: datatypes.GeoPoint}}, "new york,\"40.7128,-74.006\"\ntoronto,\"43.6532,-79.3832\"\natlantis,\n", &datatypes.BBox{-79.3832, 40.7128, -74.006, 43.6532}, ""},
+		{[]*dataset.Field{{Name: "a", Type: datatypes.GeoPoint}, {Name: "b", Type: datatypes.GeoJSON}}, "\"0,0\",\"{\"\"type\"\":\"\"LineString\"\",\"\"coordinates\"\":[[10,-5],[20,5]]}\"\n", &datatypes.BBox{0, -5, 20, 5}, ""},
+		{[]*dataset.Field{{Name: "city", Type: datatypes.String}}, "new york\n", nil, ""},
+		{[]*dataset.Field{{Name: "city", Type: datatypes.String}, {Name: "location", Type: datatypes.GeoPoint, MissingValue: "NA"}}, "new york,\"40.7128,-74.006\"\natlantis,NA\n", &datatypes.BBox{-74.006, 40.7128, -74.006, 40.7128}, ""},
+		{[]*dataset.Field{{Name: "city", Type: datatypes.String}, {Name: "location", Type: datatypes.GeoPoint}}, "new york,north\n", nil, "row 0, column 1 (location): invalid geopoint: north"},
+	}
+
+	for i, c := range cases {
+		st := &dataset.Structure{Format: dataset.CSVDataFormat, Schema: &dataset.Schema{Fields: c.fields}}
+		got, err := GeoBounds(NewCSVReader(st, strings.NewReader(c.data)))
+		if !(err == nil && c.err == "" || err != nil && err.Error() == c.err) {
+			t.Errorf("case %d error mismatch. expected: '%s', got: '%s'", i, c.err, err)
+			continue
+		}
+		if (got == nil) != (c.expect == nil) || got != nil && *got != *c.expect {
+			t.Errorf("case %d mismatch. expected: %v, got: %v", i, c.expect, got)
+		}
+	}
+}
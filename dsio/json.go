@@ -3,6 +3,7 @@ package dsio
 import (
 	"bufio"
 	"bytes"
+	"encoding/json"
 	"fmt"
 	"io"
 	"strconv"
@@ -11,23 +12,29 @@ import (
 	"github.com/qri-io/dataset/datatypes"
 )
 
-// JSONReader implements the RowReader interface for the JSON data format
+// JSONReader implements the RowReader interface for the JSON data format.
+// Entries of the top level array are tokenized as a stream, so rows of any
+// size can be read without loading the entire document.
+// Object entries are split into cells by matching keys to schema field names,
+// array entries are split by position, scalar entries are read as a single value
 type JSONReader struct {
-	rowsRead    int
-	initialized bool
-	st          *dataset.Structure
-	sc          *bufio.Scanner
+	rowsRead int
+	st       *dataset.Structure
+	opts     *dataset.JSONOptions
+	sc       *jsonScanner
 }
 
 // NewJSONReader creates a reader from a structure and read source
 func NewJSONReader(st *dataset.Structure, r io.Reader) *JSONReader {
-	sc := bufio.NewScanner(r)
-	jr := &JSONReader{
-		st: st,
-		sc: sc,
+	opts, ok := st.FormatConfig.(*dataset.JSONOptions)
+	if !ok || opts == nil {
+		opts = &dataset.JSONOptions{}
+	}
+	return &JSONReader{
+		st:   st,
+		opts: opts,
+		sc:   &jsonScanner{rd: bufio.NewReader(r)},
 	}
-	sc.Split(jr.scanJSONRow)
-	return jr
 }
 
 // Structure gives this writer's structure
@@ -37,87 +44,341 @@ func (r *JSONReader) Structure() *dataset.Structure {
 
 // ReadRow reads one JSON record from the reader
 func (r *JSONReader) ReadRow() ([][]byte, error) {
-	more := r.sc.Scan()
-	if !more {
-		return nil, fmt.Errorf("EOF")
+	entry, offset, err := r.sc.nextEntry()
+	if err != nil {
+		return nil, err
 	}
 	r.rowsRead++
 
-	return [][]byte{r.sc.Bytes()}, r.sc.Err()
+	row, err := jsonEntryCells(r.st.Schema, entry, r.opts.DisallowMissingKeys, r.opts.DisallowExtraKeys)
+	if err != nil {
+		return nil, fmt.Errorf("entry %d at byte offset %d: %s", r.rowsRead-1, offset, err.Error())
+	}
+	return row, nil
+}
+
+// jsonScanner tokenizes the entries of a top level json array, one byte at
+// a time. It understands enough json to find the bounds of each entry:
+// string literals, escapes & nested closures. Validating the contents of
+// an entry is left to encoding/json
+type jsonScanner struct {
+	rd *bufio.Reader
+	// count of bytes consumed from rd
+	offset  int64
+	entries int
+	started bool
+	done    bool
 }
 
-// initialIndex sets the scanner up to read data, advancing until the first
-// entry in the top level array & setting the scanner split func to scan objects
-func initialIndex(data []byte) (skip int, err error) {
-	typ, err := datatypes.JSONArrayOrObject(data)
+// nextEntry reads the next entry in the top level array, returning the raw
+// entry & byte offset it starts at. io.EOF is returned after the closing bracket
+func (s *jsonScanner) nextEntry() (entry []byte, offset int64, err error) {
+	if s.done {
+		return nil, 0, io.EOF
+	}
+
+	if !s.started {
+		b, err := s.skipSpace()
+		if err == io.EOF {
+			s.done = true
+			return nil, 0, io.EOF
+		} else if err != nil {
+			return nil, 0, err
+		}
+		if b != '[' {
+			return nil, 0, s.errorf("json top level must be an array, found '%c'", b)
+		}
+		s.started = true
+	}
+
+	b, err := s.skipSpace()
 	if err != nil {
-		// might not have initial closure, request more data
-		return -1, err
+		return nil, 0, s.eofError(err)
 	}
-	if typ == "object" {
-		return 0, fmt.Errorf("jsonReader top level must be an array")
+	if b == ']' {
+		s.done = true
+		return nil, 0, s.checkTrailing()
+	}
+
+	if s.entries > 0 {
+		if b != ',' {
+			return nil, 0, s.errorf("expected ',' or ']' after array entry, found '%c'", b)
+		}
+		if b, err = s.skipSpace(); err != nil {
+			return nil, 0, s.eofError(err)
+		}
 	}
 
-	// grab first opening bracked index to advance past
-	// initial array closure
-	idx := bytes.IndexByte(data, '[')
-	return idx + 1, nil
+	offset = s.offset - 1
+	if entry, err = s.readValue(b); err != nil {
+		return nil, offset, err
+	}
+	s.entries++
+	return entry, offset, nil
 }
 
-// scanJSONRow scans according to json closures ([] and {})
-func (r *JSONReader) scanJSONRow(data []byte, atEOF bool) (advance int, token []byte, err error) {
-	if atEOF && len(data) == 0 {
-		return 0, nil, nil
+// readValue reads a complete json value that starts with b
+func (s *jsonScanner) readValue(b byte) ([]byte, error) {
+	buf := []byte{b}
+
+	switch b {
+	case '"':
+		return s.readString(buf)
+	case '{', '[':
+		closers := []byte{jsonCloser(b)}
+		for len(closers) > 0 {
+			c, err := s.readByte()
+			if err != nil {
+				return nil, s.eofError(err)
+			}
+			buf = append(buf, c)
+
+			switch c {
+			case '"':
+				if buf, err = s.readString(buf); err != nil {
+					return nil, err
+				}
+			case '{', '[':
+				closers = append(closers, jsonCloser(c))
+			case '}', ']':
+				if c != closers[len(closers)-1] {
+					return nil, s.errorf("unexpected '%c', expected '%c'", c, closers[len(closers)-1])
+				}
+				closers = closers[:len(closers)-1]
+			}
+		}
+		return buf, nil
+	case ',', ':', '}', ']':
+		return nil, s.errorf("unexpected '%c'", b)
 	}
 
-	depth := 0
-	starti := -1
-	stopi := -1
+	// literal values (numbers, true, false, null) run until a delimiter
+	for {
+		c, err := s.readByte()
+		if err == io.EOF {
+			return buf, nil
+		} else if err != nil {
+			return nil, err
+		}
+		if isJSONSpace(c) || c == ',' || c == ']' || c == '}' {
+			s.unreadByte()
+			return buf, nil
+		}
+		buf = append(buf, c)
+	}
+}
+
+// readString reads the remainder of a string literal who's opening quote
+// is the last byte of buf, skipping escaped characters
+func (s *jsonScanner) readString(buf []byte) ([]byte, error) {
+	start := s.offset - 1
+	for {
+		c, err := s.readByte()
+		if err == io.EOF {
+			return nil, fmt.Errorf("unterminated string starting at byte offset %d", start)
+		} else if err != nil {
+			return nil, err
+		}
+		buf = append(buf, c)
 
-	if !r.initialized {
-		skip, err := initialIndex(data)
+		switch c {
+		case '\\':
+			esc, err := s.readByte()
+			if err == io.EOF {
+				return nil, fmt.Errorf("unterminated string starting at byte offset %d", start)
+			} else if err != nil {
+				return nil, err
+			}
+			buf = append(buf, esc)
+		case '"':
+			return buf, nil
+		}
+	}
+}
+
+// checkTrailing ensures nothing but whitespace follows the top level array
+func (s *jsonScanner) checkTrailing() error {
+	b, err := s.skipSpace()
+	if err == io.EOF {
+		return io.EOF
+	} else if err != nil {
+		return err
+	}
+	return s.errorf("unexpected '%c' after top level array", b)
+}
+
+// skipSpace reads until the first non-whitespace byte
+func (s *jsonScanner) skipSpace() (byte, error) {
+	for {
+		b, err := s.readByte()
 		if err != nil {
-			return 0, nil, err
+			return 0, err
+		}
+		if !isJSONSpace(b) {
+			return b, nil
 		}
-		if skip > 0 {
-			r.initialized = true
-			data = data[skip:]
+	}
+}
+
+func (s *jsonScanner) readByte() (byte, error) {
+	b, err := s.rd.ReadByte()
+	if err == nil {
+		s.offset++
+	}
+	return b, err
+}
+
+func (s *jsonScanner) unreadByte() {
+	if err := s.rd.UnreadByte(); err == nil {
+		s.offset--
+	}
+}
+
+// errorf creates an error positioned at the last byte read
+func (s *jsonScanner) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("%s at byte offset %d", fmt.Sprintf(format, args...), s.offset-1)
+}
+
+// eofError converts io.EOF to an error for input that ends mid-array
+func (s *jsonScanner) eofError(err error) error {
+	if err == io.EOF {
+		return fmt.Errorf("unexpected end of json input at byte offset %d", s.offset)
+	}
+	return err
+}
+
+func jsonCloser(b byte) byte {
+	if b == '{' {
+		return '}'
+	}
+	return ']'
+}
+
+func isJSONSpace(b byte) bool {
+	return b == ' ' || b == '\t' || b == '\n' || b == '\r'
+}
+
+// jsonEntryCells splits a json object or array into cells ordered by schema
+// fields. fields missing from the entry are read as empty cells unless
+// disallowMissing is set, values that don't match a field are dropped unless
+// disallowExtra is set. Without a schema, values are given in the order they
+// occur in the entry. scalar entries are read as an array of one value.
+// values are converted to cells with jsonCell
+func jsonEntryCells(sch *dataset.Schema, entry []byte, disallowMissing, disallowExtra bool) ([][]byte, error) {
+	var (
+		keys   []string
+		values []json.RawMessage
+	)
+
+	dec := json.NewDecoder(bytes.NewReader(entry))
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	isArray, isScalar := false, false
+	switch tok {
+	case json.Delim('['):
+		isArray = true
+	case json.Delim('{'):
+	default:
+		var val json.RawMessage
+		if err := json.Unmarshal(entry, &val); err != nil {
+			return nil, err
 		}
+		values = append(values, val)
+		isArray, isScalar = true, true
 	}
 
-LOOP:
-	for i, b := range data {
-		switch b {
-		case '{', '[':
-			if depth == 0 {
-				starti = i
+	for !isScalar && dec.More() {
+		if !isArray {
+			key, err := dec.Token()
+			if err != nil {
+				return nil, err
 			}
-			depth++
-		case '}', ']':
-			depth--
-			if depth == 0 {
-				stopi = i + 1
-				break LOOP
-			} else if depth < 0 {
-				// if we encounter a closing bracket
-				// before any depth, it's the end of the line
-				return len(data), nil, nil
+			keys = append(keys, key.(string))
+		}
+		var val json.RawMessage
+		if err := dec.Decode(&val); err != nil {
+			return nil, err
+		}
+		values = append(values, val)
+	}
+
+	if sch == nil {
+		row := make([][]byte, len(values))
+		for i, val := range values {
+			if row[i], err = jsonCell(nil, val); err != nil {
+				return nil, err
 			}
 		}
+		return row, nil
 	}
+	fields := sch.Fields
 
-	if stopi == -1 || starti == -1 {
-		return 0, nil, nil
+	if isArray {
+		if len(values) > len(fields) && disallowExtra {
+			return nil, fmt.Errorf("entry has %d values, schema only has %d fields", len(values), len(fields))
+		}
+		if len(values) < len(fields) && disallowMissing {
+			return nil, fmt.Errorf("entry has %d values, schema has %d fields", len(values), len(fields))
+		}
+		row := make([][]byte, len(fields))
+		for i := range row {
+			if i >= len(values) {
+				row[i] = []byte{}
+			} else if row[i], err = jsonCell(fields[i], values[i]); err != nil {
+				return nil, err
+			}
+		}
+		return row, nil
 	}
 
-	// return sliced data
-	if starti < stopi {
-		// TODO - this should semantically advance past a comma or something...
-		return stopi + 1, data[starti:stopi], nil
+	idx := make(map[string]int, len(fields))
+	for i, f := range fields {
+		idx[f.Name] = i
+	}
+
+	row := make([][]byte, len(fields))
+	for i, key := range keys {
+		j, ok := idx[key]
+		if !ok {
+			if disallowExtra {
+				return nil, fmt.Errorf("unexpected key: '%s'", key)
+			}
+			continue
+		}
+		if row[j], err = jsonCell(fields[j], values[i]); err != nil {
+			return nil, err
+		}
+	}
+	for i, c := range row {
+		if c == nil {
+			if disallowMissing {
+				return nil, fmt.Errorf("missing key: '%s'", fields[i].Name)
+			}
+			row[i] = []byte{}
+		}
 	}
+	return row, nil
+}
 
-	// Request more data.
-	return 0, nil, nil
+// jsonCell converts a raw json value of a field to cell bytes. nulls are
+// empty, strings are unquoted unless the field holds json, all other values
+// are left as raw json. f is nil for values without a schema
+func jsonCell(f *dataset.Field, val json.RawMessage) ([]byte, error) {
+	switch {
+	case val[0] == 'n':
+		return []byte{}, nil
+	case val[0] == '"' && (f == nil || f.Type != datatypes.JSON):
+		var s string
+		if err := json.Unmarshal(val, &s); err != nil {
+			return nil, err
+		}
+		return []byte(s), nil
+	default:
+		return []byte(val), nil
+	}
 }
 
 // JSONWriter implements the RowWriter interface for
@@ -155,6 +416,7 @@ func (w *JSONWriter) WriteRow(row [][]byte) error {
 		}
 	}
 
+	row = nullCells(w.st, row)
 	if w.writeObjects {
 		return w.writeObjectRow(row)
 	}
@@ -162,43 +424,11 @@ func (w *JSONWriter) WriteRow(row [][]byte) error {
 }
 
 func (w *JSONWriter) writeObjectRow(row [][]byte) error {
-	enc := []byte{',', '\n', '{'}
+	enc := []byte{',', '\n'}
 	if w.rowsWritten == 0 {
 		enc = enc[1:]
 	}
-	for i, c := range row {
-		f := w.st.Schema.Fields[i]
-		ent := []byte(",\"" + f.Name + "\":")
-		if i == 0 {
-			ent = ent[1:]
-		}
-		if c == nil || len(c) == 0 {
-			ent = append(ent, []byte("null")...)
-		} else {
-			switch f.Type {
-			case datatypes.String:
-				ent = append(ent, []byte(strconv.Quote(string(c)))...)
-			case datatypes.Float, datatypes.Integer:
-				// if len(c) == 0 {
-				// 	ent = append(ent, []byte("null")...)
-				// } else {
-				// 	ent = append(ent, c...)
-				// }
-				ent = append(ent, c...)
-			case datatypes.Boolean:
-				// TODO - coerce to true & false specifically
-				ent = append(ent, c...)
-			case datatypes.JSON:
-				ent = append(ent, c...)
-			default:
-				ent = append(ent, []byte(strconv.Quote(string(c)))...)
-			}
-		}
-
-		enc = append(enc, ent...)
-	}
-
-	enc = append(enc, '}')
+	enc = append(enc, jsonObjectRow(w.st.Schema.Fields, row)...)
 	if _, err := w.wr.Write(enc); err != nil {
 		return fmt.Errorf("error writing json object row to writer: %s", err.Error())
 	}
@@ -208,52 +438,100 @@ func (w *JSONWriter) writeObjectRow(row [][]byte) error {
 }
 
 func (w *JSONWriter) writeArrayRow(row [][]byte) error {
-	enc := []byte{',', '\n', '['}
+	enc := []byte{',', '\n'}
 	if w.rowsWritten == 0 {
 		enc = enc[1:]
 	}
+	enc = append(enc, jsonArrayRow(w.st.Schema.Fields, row)...)
+	if _, err := w.wr.Write(enc); err != nil {
+		return fmt.Errorf("error writing closing `]`: %s", err.Error())
+	}
+
+	w.rowsWritten++
+	return nil
+}
+
+// jsonObjectRow encodes a row as a json object, keyed by field name
+func jsonObjectRow(fields []*dataset.Field, row [][]byte) []byte {
+	enc := []byte{'{'}
 	for i, c := range row {
-		f := w.st.Schema.Fields[i]
-		ent := []byte(",")
+		f := fields[i]
+		ent := []byte(",\"" + f.Name + "\":")
 		if i == 0 {
 			ent = ent[1:]
 		}
-		if c == nil || len(c) == 0 {
-			ent = append(ent, []byte("null")...)
-		} else {
-			switch f.Type {
-			case datatypes.String:
-				ent = append(ent, []byte(strconv.Quote(string(c)))...)
-			case datatypes.Float, datatypes.Integer:
-				// TODO - decide on weather or not to supply default values
-				// if len(c) == 0 {
-				// ent = append(ent, []byte("0")...)
-				// } else {
-				ent = append(ent, c...)
-				// }
-			case datatypes.Boolean:
-				// TODO - coerce to true & false specifically
-				// if len(c) == 0 {
-				// ent = append(ent, []byte("false")...)
-				// }
-				ent = append(ent, c...)
-			case datatypes.JSON:
-				ent = append(ent, c...)
-			default:
-				ent = append(ent, []byte(strconv.Quote(string(c)))...)
-			}
+		enc = append(enc, jsonValue(f, c, ent)...)
+	}
+	return append(enc, '}')
+}
+
+// jsonArrayRow encodes a row as a json array of values
+func jsonArrayRow(fields []*dataset.Field, row [][]byte) []byte {
+	enc := []byte{'['}
+	for i, c := range row {
+		ent := []byte(",")
+		if i == 0 {
+			ent = ent[1:]
 		}
+		enc = append(enc, jsonValue(fields[i], c, ent)...)
+	}
+	return append(enc, ']')
+}
 
-		enc = append(enc, ent...)
+// jsonValue appends the json encoding of a single cell to ent,
+// writing empty cells as null
+func jsonValue(f *dataset.Field, c []byte, ent []byte) []byte {
+	if c == nil || len(c) == 0 {
+		return append(ent, []byte("null")...)
 	}
 
-	enc = append(enc, ']')
-	if _, err := w.wr.Write(enc); err != nil {
-		return fmt.Errorf("error writing closing `]`: %s", err.Error())
+	if isNumberType(f.Type) {
+		// numbers written with group characters, symbols or decimal commas
+		// are normalized, values that can't be read are kept as strings
+		n, err := plainNumber(f, c)
+		if err != nil {
+			return append(ent, []byte(strconv.Quote(string(c)))...)
+		}
+		c = n
 	}
 
-	w.rowsWritten++
-	return nil
+	switch f.Type {
+	case datatypes.String:
+		return append(ent, []byte(strconv.Quote(string(c)))...)
+	case datatypes.Float, datatypes.Integer:
+		// TODO - decide on weather or not to supply default values
+		return append(ent, c...)
+	case datatypes.Decimal:
+		// decimals are written as numbers to keep full precision, normalizing
+		// values like "+1.5" that aren't valid json
+		if (c[0] == '-' || c[0] >= '0' && c[0] <= '9') && json.Valid(c) {
+			return append(ent, c...)
+		}
+		if r, err := datatypes.ParseDecimal(c); err == nil {
+			str, _ := datatypes.Decimal.ValueToString(r)
+			return append(ent, []byte(str)...)
+		}
+		return append(ent, []byte(strconv.Quote(string(c)))...)
+	case datatypes.Boolean:
+		// TODO - coerce to true & false specifically
+		return append(ent, c...)
+	case datatypes.JSON, datatypes.GeoJSON, datatypes.Array, datatypes.Object:
+		// cells that aren't valid json, like text read from csv, are
+		// written as strings
+		if !json.Valid(c) {
+			return append(ent, []byte(strconv.Quote(string(c)))...)
+		}
+		return append(ent, c...)
+	case datatypes.Any:
+		// untyped values read from json are kept as written, strings are
+		// unquoted when read & quoted again here
+		if c[0] != '"' && json.Valid(c) {
+			return append(ent, c...)
+		}
+		return append(ent, []byte(strconv.Quote(string(c)))...)
+	default:
+		return append(ent, []byte(strconv.Quote(string(c)))...)
+	}
 }
 
 // Close finalizes the writer, indicating no more records
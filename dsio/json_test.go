@@ -3,7 +3,9 @@ package dsio
 import (
 	"bytes"
 	"encoding/json"
+	"io"
 	"os"
+	"strings"
 	"testing"
 
 	"github.com/qri-io/dataset"
@@ -78,7 +80,213 @@ func TestJSONReader(t *testing.T) {
 	}
 }
 
+func TestJSONReaderTokenize(t *testing.T) {
+	long := strings.Repeat("x", 200000)
+
+	cases := []struct {
+		data   string
+		expect [][]string
+		err    string
+	}{
+		{"", [][]string{}, ""},
+		{"[]", [][]string{}, ""},
+		{" \n[ \t]\n ", [][]string{}, ""},
+		{`[{"a":"a]b"},{"a":"}{"}]`, [][]string{{"a]b"}, {"}{"}}, ""},
+		{`[{"a":"esc\"]\\"}]`, [][]string{{`esc"]\`}}, ""},
+		{"[\n  [ 1 , [2,{\"b\":[3]}] ] ,\n\t{ \"a\" : { } }\n]", [][]string{{"1", `[2,{"b":[3]}]`}, {"{ }"}}, ""},
+		{`[1,"two",true,null,-2.5e3]`, [][]string{{"1"}, {"two"}, {"true"}, {""}, {"-2.5e3"}}, ""},
+		{`[{"a":"` + long + `"}]`, [][]string{{long}}, ""},
+		{`{"a":1}`, nil, "json top level must be an array, found '{' at byte offset 0"},
+		{`  "a"`, nil, "json top level must be an array, found '\"' at byte offset 2"},
+		{`[{"a":1}{"a":2}]`, nil, "expected ',' or ']' after array entry, found '{' at byte offset 8"},
+		{`[{"a":[1}]`, nil, "unexpected '}', expected ']' at byte offset 8"},
+		{`[{"a":"b}]`, nil, "unterminated string starting at byte offset 6"},
+		{`[{"a":1},`, nil, "unexpected end of json input at byte offset 9"},
+		{`[1,,2]`, nil, "unexpected ',' at byte offset 3"},
+		{`[1] x`, nil, "unexpected 'x' after top level array at byte offset 4"},
+		{`[1, {"a" 1}]`, nil, "entry 1 at byte offset 4: invalid character '1' after object key"},
+	}
+
+	for i, c := range cases {
+		r := NewJSONReader(&dataset.Structure{Format: dataset.JSONDataFormat}, strings.NewReader(c.data))
+		got := [][]string{}
+		var err error
+		for {
+			var row [][]byte
+			if row, err = r.ReadRow(); err != nil {
+				break
+			}
+			strs := make([]string, len(row))
+			for j, cell := range row {
+				strs[j] = string(cell)
+			}
+			got = append(got, strs)
+		}
+		if err == io.EOF {
+			err = nil
+		}
+		if !(err == nil && c.err == "" || err != nil && err.Error() == c.err) {
+			t.Errorf("case %d error mismatch. expected: '%s', got: '%s'", i, c.err, err)
+			continue
+		}
+		if c.expect == nil {
+			continue
+		}
+
+		if len(got) != len(c.expect) {
+			t.Errorf("case %d row count mismatch. expected: %d, got: %d", i, len(c.expect), len(got))
+			continue
+		}
+		for j, row := range got {
+			if strings.Join(row, "|") != strings.Join(c.expect[j], "|") {
+				t.Errorf("case %d row %d mismatch. expected: %v, got: %v", i, j, c.expect[j], row)
+			}
+		}
+	}
+}
+
+func TestJSONReaderFields(t *testing.T) {
+	fields := []*dataset.Field{
+		{Name: "city", Type: datatypes.String},
+		{Name: "pop", Type: datatypes.Integer},
+		{Name: "meta", Type: datatypes.JSON},
+	}
+
+	cases := []struct {
+		opts   *dataset.JSONOptions
+		data   string
+		expect [][]string
+		err    string
+	}{
+		{nil, `[{"pop":40000000,"city":"toronto","meta":{"a":[1]}}]`, [][]string{{"toronto", "40000000", `{"a":[1]}`}}, ""},
+		{nil, `[{"city":"new\nyork","extra":false}]`, [][]string{{"new\nyork", "", ""}}, ""},
+		{nil, `[{"city":null,"pop":null,"meta":null}]`, [][]string{{"", "", ""}}, ""},
+		{nil, `[{"city":"oslo","meta":"hello"}]`, [][]string{{"oslo", "", `"hello"`}}, ""},
+		{&dataset.JSONOptions{ArrayEntries: true}, `[["chicago",300000],["raleigh",250000,[],"extra"]]`, [][]string{{"chicago", "300000", ""}, {"raleigh", "250000", "[]"}}, ""},
+		{&dataset.JSONOptions{DisallowMissingKeys: true}, `[{"city":"chatham","pop":35000}]`, nil, "entry 0 at byte offset 1: missing key: 'meta'"},
+		{&dataset.JSONOptions{DisallowExtraKeys: true}, `[{"city":"chatham"},{"state":"ON"}]`, nil, "entry 1 at byte offset 20: unexpected key: 'state'"},
+		{&dataset.JSONOptions{ArrayEntries: true, DisallowExtraKeys: true}, `[["a",1,{},2]]`, nil, "entry 0 at byte offset 1: entry has 4 values, schema only has 3 fields"},
+		{&dataset.JSONOptions{ArrayEntries: true, DisallowMissingKeys: true}, `[["a"]]`, nil, "entry 0 at byte offset 1: entry has 1 values, schema has 3 fields"},
+	}
+
+	for i, c := range cases {
+		st := &dataset.Structure{Format: dataset.JSONDataFormat, Schema: &dataset.Schema{Fields: fields}}
+		if c.opts != nil {
+			st.FormatConfig = c.opts
+		}
+		r := NewJSONReader(st, strings.NewReader(c.data))
+
+		got := [][]string{}
+		var err error
+		for {
+			var row [][]byte
+			if row, err = r.ReadRow(); err != nil {
+				break
+			}
+			strs := make([]string, len(row))
+			for j, cell := range row {
+				strs[j] = string(cell)
+			}
+			got = append(got, strs)
+		}
+		if err != nil && err.Error() == "EOF" {
+			err = nil
+		}
+		if !(err == nil && c.err == "" || err != nil && err.Error() == c.err) {
+			t.Errorf("case %d error mismatch. expected: '%s', got: '%s'", i, c.err, err)
+			continue
+		}
+		if c.expect == nil {
+			continue
+		}
+
+		if len(got) != len(c.expect) {
+			t.Errorf("case %d row count mismatch. expected: %d, got: %d", i, len(c.expect), len(got))
+			continue
+		}
+		for j, row := range got {
+			if strings.Join(row, "|") != strings.Join(c.expect[j], "|") {
+				t.Errorf("case %d row %d mismatch. expected: %v, got: %v", i, j, c.expect[j], row)
+			}
+		}
+	}
+}
+
+func TestJSONReadWrite(t *testing.T) {
+	for _, arrayEntries := range []bool{false, true} {
+		st := &dataset.Structure{
+			Format:       dataset.JSONDataFormat,
+			FormatConfig: &dataset.JSONOptions{ArrayEntries: arrayEntries},
+			Schema:       &dataset.Schema{Fields: xlsxFields},
+		}
+
+		buf := &bytes.Buffer{}
+		w := NewJSONWriter(st, buf)
+		for _, row := range rows["cities"] {
+			if err := w.WriteRow(row); err != nil {
+				t.Errorf("arrayEntries %t WriteRow error: %s", arrayEntries, err.Error())
+			}
+		}
+		if err := w.Close(); err != nil {
+			t.Errorf("arrayEntries %t Close error: %s", arrayEntries, err.Error())
+			continue
+		}
+
+		r := NewJSONReader(st, buf)
+		for j, expect := range rows["cities"] {
+			row, err := r.ReadRow()
+			if err != nil {
+				t.Errorf("arrayEntries %t row %d read error: %s", arrayEntries, j, err.Error())
+				break
+			}
+			for k, cell := range row {
+				if !bytes.Equal(cell, expect[k]) {
+					t.Errorf("arrayEntries %t row %d cell %d mismatch. expected: '%s', got: '%s'", arrayEntries, j, k, expect[k], cell)
+				}
+			}
+		}
+	}
+}
+
+func TestJSONReadWriteStrings(t *testing.T) {
+	st := &dataset.Structure{
+		Format: dataset.JSONDataFormat,
+		Schema: &dataset.Schema{Fields: []*dataset.Field{
+			{Name: "meta", Type: datatypes.JSON},
+			{Name: "other", Type: datatypes.Any},
+		}},
+	}
+	data := "[\n{\"meta\":\"hello\",\"other\":\"world\"},\n{\"meta\":\"true\",\"other\":[1]},\n{\"meta\":{\"a\":1},\"other\":2.5},\n{\"meta\":null,\"other\":false}\n]"
+
+	r := NewJSONReader(st, strings.NewReader(data))
+	buf := &bytes.Buffer{}
+	w := NewJSONWriter(st, buf)
+	for {
+		row, err := r.ReadRow()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatalf("read error: %s", err.Error())
+		}
+		if err := w.WriteRow(row); err != nil {
+			t.Fatalf("write error: %s", err.Error())
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("close error: %s", err.Error())
+	}
+
+	expect := data
+	if buf.String() != expect {
+		t.Errorf("output mismatch. expected:\n%s\ngot:\n%s", expect, buf.String())
+	}
+	if !json.Valid(buf.Bytes()) {
+		t.Errorf("output is not valid json: %s", buf.String())
+	}
+}
+
 func TestJSONWriter(t *testing.T) {
+	notBare := false
 	cases := []struct {
 		structure *dataset.Structure
 		entries   [][][]byte
@@ -99,6 +307,25 @@ func TestJSONWriter(t *testing.T) {
 			{[]byte("hello\n?")},
 			{[]byte("world")},
 		}, "[\n[\"hello\\n?\"],\n[\"world\"]\n]"},
+		{&dataset.Structure{Schema: &dataset.Schema{Fields: []*dataset.Field{{Name: "a", Type: datatypes.Decimal}}}, FormatConfig: &dataset.JSONOptions{ArrayEntries: true}}, [][][]byte{
+			{[]byte("12345678901234567890.12")},
+			{[]byte("+1.50")},
+			{[]byte("NA")},
+			{[]byte{}},
+		}, "[\n[12345678901234567890.12],\n[1.5],\n[\"NA\"],\n[null]\n]"},
+		{&dataset.Structure{Schema: &dataset.Schema{Fields: []*dataset.Field{
+			{Name: "a", Type: datatypes.Integer, GroupChar: ","},
+			{Name: "b", Type: datatypes.Float, Locale: "de-DE", BareNumber: &notBare},
+		}}, FormatConfig: &dataset.JSONOptions{ArrayEntries: true}}, [][][]byte{
+			{[]byte("1,234"), []byte("1.234,50 €")},
+			{[]byte("56"), []byte("-0,5")},
+			{[]byte("NA"), []byte("lots")},
+		}, "[\n[1234,1234.50],\n[56,-0.5],\n[\"NA\",\"lots\"]\n]"},
+		{&dataset.Structure{Schema: &dataset.Schema{Fields: []*dataset.Field{{Name: "a", Type: datatypes.JSON}}}, FormatConfig: &dataset.JSONOptions{ArrayEntries: true}}, [][][]byte{
+			{[]byte(`{"b":1}`)},
+			{[]byte(`"hello"`)},
+			{[]byte("hello")},
+		}, "[\n[{\"b\":1}],\n[\"hello\"],\n[\"hello\"]\n]"},
 		{&dataset.Structure{Schema: &dataset.Schema{
 			Fields: []*dataset.Field{
 				{Name: "ident", Type: datatypes.String},
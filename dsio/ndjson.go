@@ -0,0 +1,120 @@
+package dsio
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+
+	"github.com/qri-io/dataset"
+)
+
+// NDJSONReader implements the RowReader interface for newline-delimited
+// JSON. Each non-blank line is read as a row, only buffering one line at
+// a time so arbitrarily large files can be streamed. Lines are split into
+// cells the same way JSONReader splits entries
+type NDJSONReader struct {
+	lineNum int
+	st      *dataset.Structure
+	opts    *dataset.NDJSONOptions
+	rd      *bufio.Reader
+}
+
+// NewNDJSONReader creates a reader from a structure and read source
+func NewNDJSONReader(st *dataset.Structure, r io.Reader) *NDJSONReader {
+	opts, ok := st.FormatConfig.(*dataset.NDJSONOptions)
+	if !ok || opts == nil {
+		opts = &dataset.NDJSONOptions{}
+	}
+	return &NDJSONReader{
+		st:   st,
+		opts: opts,
+		rd:   bufio.NewReader(r),
+	}
+}
+
+// Structure gives this reader's structure
+func (r *NDJSONReader) Structure() *dataset.Structure {
+	return r.st
+}
+
+// ReadRow reads one line of JSON from the reader, skipping blank lines
+func (r *NDJSONReader) ReadRow() ([][]byte, error) {
+	for {
+		line, err := r.rd.ReadBytes('\n')
+		if err != nil && err != io.EOF {
+			return nil, fmt.Errorf("error reading line %d: %s", r.lineNum+1, err.Error())
+		}
+		r.lineNum++
+
+		line = bytes.TrimSpace(line)
+		if len(line) > 0 {
+			row, err := jsonEntryCells(r.st.Schema, line, r.opts.DisallowMissingKeys, r.opts.DisallowExtraKeys)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %s", r.lineNum, err.Error())
+			}
+			return row, nil
+		}
+		if err == io.EOF {
+			return nil, io.EOF
+		}
+	}
+}
+
+// NDJSONWriter implements the RowWriter interface for
+// newline-delimited JSON
+type NDJSONWriter struct {
+	writeObjects bool
+	rowsWritten  int
+	st           *dataset.Structure
+	wr           io.Writer
+}
+
+// NewNDJSONWriter creates a Writer from a structure and write destination
+func NewNDJSONWriter(st *dataset.Structure, w io.Writer) *NDJSONWriter {
+	writeObjects := true
+	if opt, ok := st.FormatConfig.(*dataset.NDJSONOptions); ok {
+		writeObjects = !opt.ArrayEntries
+	}
+	return &NDJSONWriter{
+		writeObjects: writeObjects,
+		st:           st,
+		wr:           w,
+	}
+}
+
+// Structure gives this writer's structure
+func (w *NDJSONWriter) Structure() *dataset.Structure {
+	return w.st
+}
+
+// WriteRow writes one JSON record to the writer, followed by a newline
+func (w *NDJSONWriter) WriteRow(row [][]byte) error {
+	if w.st.Schema == nil {
+		return fmt.Errorf("structure must have a schema to write ndjson")
+	}
+	if len(row) > len(w.st.Schema.Fields) {
+		return fmt.Errorf("row has %d values, schema only has %d fields", len(row), len(w.st.Schema.Fields))
+	}
+	row = nullCells(w.st, row)
+
+	var enc []byte
+	if w.writeObjects {
+		enc = jsonObjectRow(w.st.Schema.Fields, row)
+	} else {
+		enc = jsonArrayRow(w.st.Schema.Fields, row)
+	}
+
+	if _, err := w.wr.Write(append(enc, '\n')); err != nil {
+		return fmt.Errorf("error writing ndjson row: %s", err.Error())
+	}
+	w.rowsWritten++
+	return nil
+}
+
+// Close finalizes the writer, indicating no more records
+// will be written. ndjson has no closing delimiter, so this
+// is a no-op
+func (w *NDJSONWriter) Close() error {
+	return nil
+}
@@ -0,0 +1,129 @@
+package dsio
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/dataset/datatypes"
+)
+
+func TestNDJSONReader(t *testing.T) {
+	fields := []*dataset.Field{
+		{Name: "city", Type: datatypes.String},
+		{Name: "pop", Type: datatypes.Integer},
+	}
+	long := strings.Repeat("x", 100000)
+
+	cases := []struct {
+		schema *dataset.Schema
+		opts   *dataset.NDJSONOptions
+		data   string
+		expect [][]string
+		err    string
+	}{
+		{nil, nil, "", [][]string{}, ""},
+		{nil, nil, "{\"a\":1}", [][]string{{"1"}}, ""},
+		{nil, nil, "{\"a\":1}\n{\"a\":2}\n", [][]string{{"1"}, {"2"}}, ""},
+		{nil, nil, "\n{\"a\":\"]\"}\r\n\n  [1,[2]]  \n", [][]string{{"]"}, {"1", "[2]"}}, ""},
+		{nil, nil, "{\"a\":\"" + long + "\"}\n", [][]string{{long}}, ""},
+		{&dataset.Schema{Fields: fields}, nil, "{\"pop\":35000,\"city\":\"chatham\",\"extra\":true}\n{\"city\":null}", [][]string{{"chatham", "35000"}, {"", ""}}, ""},
+		{&dataset.Schema{Fields: fields}, nil, "[\"toronto\",40000000]", [][]string{{"toronto", "40000000"}}, ""},
+		{&dataset.Schema{Fields: fields}, &dataset.NDJSONOptions{DisallowExtraKeys: true}, "{\"city\":\"a\"}\n\n{\"extra\":true}", [][]string{{"a", ""}}, "line 3: unexpected key: 'extra'"},
+		{&dataset.Schema{Fields: fields}, &dataset.NDJSONOptions{DisallowMissingKeys: true}, "{\"city\":\"a\"}", nil, "line 1: missing key: 'pop'"},
+		{nil, nil, "{\"a\":", nil, "line 1: unexpected EOF"},
+	}
+
+	for i, c := range cases {
+		st := &dataset.Structure{Format: dataset.NDJSONDataFormat, Schema: c.schema}
+		if c.opts != nil {
+			st.FormatConfig = c.opts
+		}
+		r := NewNDJSONReader(st, strings.NewReader(c.data))
+		got := [][]string{}
+		var err error
+		for {
+			var row [][]byte
+			row, err = r.ReadRow()
+			if err != nil {
+				break
+			}
+			strs := make([]string, len(row))
+			for j, cell := range row {
+				strs[j] = string(cell)
+			}
+			got = append(got, strs)
+		}
+		if err == io.EOF {
+			err = nil
+		}
+		if !(err == nil && c.err == "" || err != nil && err.Error() == c.err) {
+			t.Errorf("case %d error mismatch. expected: '%s', got: '%s'", i, c.err, err)
+			continue
+		}
+		if c.expect == nil {
+			continue
+		}
+
+		if len(got) != len(c.expect) {
+			t.Errorf("case %d row count mismatch. expected: %d, got: %d", i, len(c.expect), len(got))
+			continue
+		}
+		for j, row := range got {
+			if strings.Join(row, "|") != strings.Join(c.expect[j], "|") {
+				t.Errorf("case %d row %d mismatch. expected: %v, got: %v", i, j, c.expect[j], row)
+			}
+		}
+	}
+}
+
+func TestNDJSONWriter(t *testing.T) {
+	fields := []*dataset.Field{
+		{Name: "city", Type: datatypes.String},
+		{Name: "pop", Type: datatypes.Integer},
+	}
+
+	cases := []struct {
+		structure *dataset.Structure
+		entries   [][][]byte
+		out       string
+		err       string
+	}{
+		{&dataset.Structure{Schema: &dataset.Schema{Fields: fields}}, [][][]byte{}, "", ""},
+		{&dataset.Structure{Schema: &dataset.Schema{Fields: fields}}, [][][]byte{
+			{[]byte("toronto"), []byte("40000000")},
+			{[]byte("new\nyork"), []byte{}},
+		}, "{\"city\":\"toronto\",\"pop\":40000000}\n{\"city\":\"new\\nyork\",\"pop\":null}\n", ""},
+		{&dataset.Structure{Schema: &dataset.Schema{Fields: fields}, FormatConfig: &dataset.NDJSONOptions{ArrayEntries: true}}, [][][]byte{
+			{[]byte("toronto"), []byte("40000000")},
+		}, "[\"toronto\",40000000]\n", ""},
+		{&dataset.Structure{}, [][][]byte{{[]byte("toronto")}}, "", "structure must have a schema to write ndjson"},
+		{&dataset.Structure{Schema: &dataset.Schema{Fields: fields}}, [][][]byte{
+			{[]byte("a"), []byte("1"), []byte("2")},
+		}, "", "row has 3 values, schema only has 2 fields"},
+	}
+
+	for i, c := range cases {
+		buf := &bytes.Buffer{}
+		w := NewNDJSONWriter(c.structure, buf)
+		var err error
+		for _, ent := range c.entries {
+			if err = w.WriteRow(ent); err != nil {
+				break
+			}
+		}
+		if !(err == nil && c.err == "" || err != nil && err.Error() == c.err) {
+			t.Errorf("case %d error mismatch. expected: '%s', got: '%s'", i, c.err, err)
+			continue
+		}
+		if err := w.Close(); err != nil {
+			t.Errorf("case %d Close error: %s", i, err.Error())
+			continue
+		}
+		if buf.String() != c.out {
+			t.Errorf("case %d result mismatch. expected:\n%s\ngot:\n%s", i, c.out, buf.String())
+		}
+	}
+}
@@ -0,0 +1,525 @@
+package dsio
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/dataset/datatypes"
+	"github.com/xitongsys/parquet-go-source/buffer"
+	"github.com/xitongsys/parquet-go-source/writerfile"
+	"github.com/xitongsys/parquet-go/parquet"
+	"github.com/xitongsys/parquet-go/reader"
+	"github.com/xitongsys/parquet-go/types"
+	"github.com/xitongsys/parquet-go/writer"
+)
+
+// parquetBatchSize is the number of rows read from each column at a time
+const parquetBatchSize = 1024
+
+// ParquetReader implements the RowReader interface for apache parquet files.
+// parquet metadata lives in a footer at the end of the file, so the
+// entire file is read into memory on the first call to ReadRow.
+// only flat schemas of primitive columns are supported
+type ParquetReader struct {
+	st     *dataset.Structure
+	r      io.Reader
+	pr     *reader.ParquetReader
+	cols   []*parquet.SchemaElement
+	loaded bool
+	// index of the parquet column for each schema field, -1 if missing
+	colIdx []int
+	// rows of the current batch
+	rows [][][]byte
+	idx  int
+	// number of rows read from the file so far
+	read int64
+}
+
+// NewParquetReader creates a reader from a structure and read source
+func NewParquetReader(st *dataset.Structure, r io.Reader) *ParquetReader {
+	return &ParquetReader{
+		st: st,
+		r:  r,
+	}
+}
+
+// Structure gives this reader's structure
+func (r *ParquetReader) Structure() *dataset.Structure {
+	return r.st
+}
+
+// Fields gives schema fields for the columns of the parquet file,
+// with datatypes mapped from column physical & logical types.
+// decimal fields are formatted with the column's precision & scale
+func (r *ParquetReader) Fields() ([]*dataset.Field, error) {
+	if err := r.load(); err != nil {
+		return nil, err
+	}
+	fields := make([]*dataset.Field, len(r.cols))
+	for i, col := range r.cols {
+		fields[i] = &dataset.Field{
+			Name: col.GetName(),
+			Type: parquetDatatype(col),
+		}
+		if fields[i].Type == datatypes.Decimal {
+			fields[i].Format = fmt.Sprintf("%d,%d", col.GetPrecision(), col.GetScale())
+		}
+	}
+	return fields, nil
+}
+
+// ReadRow reads one row from the parquet file. If the structure has a
+// schema, values are ordered by schema fields matched to columns by
+// name, fields with no matching column are empty. otherwise values are
+// given in column order
+func (r *ParquetReader) ReadRow() ([][]byte, error) {
+	if err := r.load(); err != nil {
+		return nil, err
+	}
+
+	if r.idx >= len(r.rows) {
+		if err := r.readBatch(); err != nil {
+			return nil, err
+		}
+	}
+
+	row := r.rows[r.idx]
+	r.idx++
+	return row, nil
+}
+
+// load reads the file footer, matching columns to schema fields
+func (r *ParquetReader) load() error {
+	if r.loaded {
+		return nil
+	}
+
+	data, err := ioutil.ReadAll(r.r)
+	if err != nil {
+		return fmt.Errorf("error reading parquet data: %s", err.Error())
+	}
+	if len(data) < 12 || string(data[:4]) != "PAR1" || string(data[len(data)-4:]) != "PAR1" {
+		return fmt.Errorf("invalid parquet data: missing PAR1 magic bytes")
+	}
+
+	pf, err := buffer.NewBufferFile(data)
+	if err != nil {
+		return fmt.Errorf("error reading parquet data: %s", err.Error())
+	}
+	pr, err := reader.NewParquetColumnReader(pf, 1)
+	if err != nil {
+		return fmt.Errorf("error reading parquet footer: %s", err.Error())
+	}
+
+	// the first schema element is the root of the file schema
+	elements := pr.SchemaHandler.SchemaElements
+	cols := make([]*parquet.SchemaElement, 0, len(elements))
+	names := map[string]int{}
+	for i, el := range elements[1:] {
+		name := pr.SchemaHandler.Infos[i+1].ExName
+		if el.GetNumChildren() > 0 || el.GetRepetitionType() == parquet.FieldRepetitionType_REPEATED {
+			return fmt.Errorf("nested parquet column '%s' is not supported", name)
+		}
+		// column readers rename elements, keep external names
+		col := *el
+		col.Name = name
+		names[name] = len(cols)
+		cols = append(cols, &col)
+	}
+
+	r.pr = pr
+	r.cols = cols
+	if r.st.Schema != nil {
+		r.colIdx = make([]int, len(r.st.Schema.Fields))
+		for i, f := range r.st.Schema.Fields {
+			if j, ok := names[f.Name]; ok {
+				r.colIdx[i] = j
+			} else {
+				r.colIdx[i] = -1
+			}
+		}
+	} else {
+		r.colIdx = make([]int, len(cols))
+		for i := range cols {
+			r.colIdx[i] = i
+		}
+	}
+	r.loaded = true
+	return nil
+}
+
+// readBatch reads the next set of rows from each column
+func (r *ParquetReader) readBatch() error {
+	remain := r.pr.GetNumRows() - r.read
+	if remain <= 0 {
+		return io.EOF
+	}
+	n := int64(parquetBatchSize)
+	if remain < n {
+		n = remain
+	}
+
+	values := make([][]interface{}, len(r.cols))
+	for i, col := range r.cols {
+		vals, _, _, err := r.pr.ReadColumnByIndex(int64(i), n)
+		if err != nil {
+			return fmt.Errorf("error reading parquet column '%s': %s", col.GetName(), err.Error())
+		}
+		if int64(len(vals)) != n {
+			return fmt.Errorf("error reading parquet column '%s': expected %d values, got %d", col.GetName(), n, len(vals))
+		}
+		values[i] = vals
+	}
+
+	r.rows = make([][][]byte, n)
+	for i := range r.rows {
+		row := make([][]byte, len(r.colIdx))
+		for j, idx := range r.colIdx {
+			if idx < 0 {
+				row[j] = []byte{}
+				continue
+			}
+			row[j] = parquetCell(r.cols[idx], values[idx][i])
+		}
+		r.rows[i] = row
+	}
+	r.idx = 0
+	r.read += n
+	return nil
+}
+
+// parquetDatatype maps a parquet column to the closest datatype
+func parquetDatatype(col *parquet.SchemaElement) datatypes.Type {
+	if col.IsSetConvertedType() {
+		switch col.GetConvertedType() {
+		case parquet.ConvertedType_UTF8, parquet.ConvertedType_ENUM:
+			return datatypes.String
+		case parquet.ConvertedType_JSON:
+			return datatypes.JSON
+		case parquet.ConvertedType_DATE:
+			return datatypes.Date
+		case parquet.ConvertedType_TIME_MILLIS, parquet.ConvertedType_TIME_MICROS:
+			return datatypes.Time
+		case parquet.ConvertedType_TIMESTAMP_MILLIS, parquet.ConvertedType_TIMESTAMP_MICROS:
+			return datatypes.DateTime
+		case parquet.ConvertedType_DECIMAL:
+			return datatypes.Decimal
+		}
+	}
+
+	switch col.GetType() {
+	case parquet.Type_BOOLEAN:
+		return datatypes.Boolean
+	case parquet.Type_INT32, parquet.Type_INT64:
+		return datatypes.Integer
+	case parquet.Type_INT96:
+		return datatypes.DateTime
+	case parquet.Type_FLOAT, parquet.Type_DOUBLE:
+		return datatypes.Float
+	default:
+		return datatypes.String
+	}
+}
+
+// parquetCell encodes a value read from a parquet column as raw bytes
+func parquetCell(col *parquet.SchemaElement, val interface{}) []byte {
+	ct := parquet.ConvertedType(-1)
+	if col.IsSetConvertedType() {
+		ct = col.GetConvertedType()
+	}
+
+	switch v := val.(type) {
+	case nil:
+		return []byte{}
+	case bool:
+		return []byte(strconv.FormatBool(v))
+	case int32:
+		switch ct {
+		case parquet.ConvertedType_DATE:
+			return []byte(time.Unix(int64(v)*86400, 0).UTC().Format("2006-01-02"))
+		case parquet.ConvertedType_TIME_MILLIS:
+			return []byte(time.Unix(0, 0).UTC().Add(time.Duration(v) * time.Millisecond).Format("15:04:05.999999999"))
+		case parquet.ConvertedType_DECIMAL:
+			return []byte(parquetDecimal(big.NewInt(int64(v)), col.GetScale()))
+		}
+		return []byte(strconv.FormatInt(int64(v), 10))
+	case int64:
+		switch ct {
+		case parquet.ConvertedType_TIMESTAMP_MILLIS:
+			return []byte(types.TIMESTAMP_MILLISToTime(v, true).Format(time.RFC3339Nano))
+		case parquet.ConvertedType_TIMESTAMP_MICROS:
+			return []byte(types.TIMESTAMP_MICROSToTime(v, true).Format(time.RFC3339Nano))
+		case parquet.ConvertedType_TIME_MICROS:
+			return []byte(time.Unix(0, 0).UTC().Add(time.Duration(v) * time.Microsecond).Format("15:04:05.999999999"))
+		case parquet.ConvertedType_DECIMAL:
+			return []byte(parquetDecimal(big.NewInt(v), col.GetScale()))
+		}
+		return []byte(strconv.FormatInt(v, 10))
+	case float32:
+		return []byte(strconv.FormatFloat(float64(v), 'g', -1, 32))
+	case float64:
+		return []byte(strconv.FormatFloat(v, 'g', -1, 64))
+	case string:
+		if col.GetType() == parquet.Type_INT96 {
+			return []byte(types.INT96ToTime(v).Format(time.RFC3339Nano))
+		}
+		if ct == parquet.ConvertedType_DECIMAL {
+			return []byte(parquetDecimal(fromTwosComplement([]byte(v)), col.GetScale()))
+		}
+		return []byte(v)
+	default:
+		return []byte(fmt.Sprint(v))
+	}
+}
+
+// parquetDecimal formats an unscaled decimal integer
+func parquetDecimal(n *big.Int, scale int32) string {
+	r := new(big.Rat).SetFrac(n, new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(scale)), nil))
+	return r.FloatString(int(scale))
+}
+
+// twosComplement encodes an integer as big-endian two's complement bytes,
+// the encoding of byte array decimals
+func twosComplement(n *big.Int) []byte {
+	if n.Sign() >= 0 {
+		b := n.Bytes()
+		if len(b) == 0 || b[0]&0x80 != 0 {
+			b = append([]byte{0}, b...)
+		}
+		return b
+	}
+	// negative values are 2^(8*len) + n, with room for the sign bit
+	size := len(n.Bytes()) + 1
+	b := new(big.Int).Add(new(big.Int).Lsh(big.NewInt(1), uint(size*8)), n).Bytes()
+	for len(b) < size {
+		b = append([]byte{0xff}, b...)
+	}
+	return b
+}
+
+// fromTwosComplement decodes big-endian two's complement bytes
+func fromTwosComplement(b []byte) *big.Int {
+	n := new(big.Int).SetBytes(b)
+	if len(b) > 0 && b[0]&0x80 != 0 {
+		n.Sub(n, new(big.Int).Lsh(big.NewInt(1), uint(len(b)*8)))
+	}
+	return n
+}
+
+// ParquetWriter implements the RowWriter interface for apache parquet files.
+// rows are buffered into row groups, the file footer is written when
+// Close is called
+type ParquetWriter struct {
+	st *dataset.Structure
+	pw *writer.CSVWriter
+	// error allocating the writer, reported on the first write
+	err error
+}
+
+// NewParquetWriter creates a Writer from a structure and write destination.
+// Structures must have a schema to write parquet data
+func NewParquetWriter(st *dataset.Structure, w io.Writer) *ParquetWriter {
+	wr := &ParquetWriter{st: st}
+	if st.Schema == nil {
+		wr.err = fmt.Errorf("structure must have a schema to write parquet")
+		return wr
+	}
+
+	md := make([]string, len(st.Schema.Fields))
+	for i, f := range st.Schema.Fields {
+		if strings.ContainsAny(f.Name, ",=") {
+			wr.err = fmt.Errorf("invalid parquet field name: '%s'. names cannot contain ',' or '='", f.Name)
+			return wr
+		}
+		ct, err := parquetColumnType(f)
+		if err != nil {
+			wr.err = fmt.Errorf("invalid parquet field '%s': %s", f.Name, err.Error())
+			return wr
+		}
+		md[i] = fmt.Sprintf("name=%s, %s, repetitiontype=OPTIONAL", f.Name, ct)
+	}
+
+	pw, err := writer.NewCSVWriter(md, writerfile.NewWriterFile(w), 1)
+	if err != nil {
+		wr.err = fmt.Errorf("error creating parquet writer: %s", err.Error())
+		return wr
+	}
+
+	if opts, ok := st.FormatConfig.(*dataset.ParquetOptions); ok && opts != nil {
+		if opts.Compression != "" {
+			codec, err := parquet.CompressionCodecFromString(strings.ToUpper(opts.Compression))
+			if err != nil {
+				wr.err = fmt.Errorf("invalid parquet compression: '%s'", opts.Compression)
+				return wr
+			}
+			pw.CompressionType = codec
+		}
+		if opts.RowGroupSize > 0 {
+			pw.RowGroupSize = opts.RowGroupSize
+		}
+	}
+
+	wr.pw = pw
+	return wr
+}
+
+// parquetColumnType gives parquet type metadata for a field.
+// decimals are stored with the precision & scale of the field's format,
+// in the smallest physical type that holds them. decimals without a
+// precision, and datatypes without a parquet equivalent are stored as utf8 strings
+func parquetColumnType(f *dataset.Field) (string, error) {
+	switch f.Type {
+	case datatypes.Integer:
+		return "type=INT64", nil
+	case datatypes.Float:
+		return "type=DOUBLE", nil
+	case datatypes.Boolean:
+		return "type=BOOLEAN", nil
+	case datatypes.Decimal:
+		df, err := datatypes.ParseDecimalFormat(f.Format)
+		if err != nil {
+			return "", err
+		}
+		if df.Precision == 0 {
+			return "type=BYTE_ARRAY, convertedtype=UTF8", nil
+		}
+		return fmt.Sprintf("type=%s, convertedtype=DECIMAL, precision=%d, scale=%d", parquetDecimalType(df.Precision), df.Precision, df.Scale), nil
+	case datatypes.Date:
+		return "type=INT32, convertedtype=DATE", nil
+	case datatypes.Time:
+		return "type=INT32, convertedtype=TIME_MILLIS", nil
+	case datatypes.DateTime:
+		return "type=INT64, convertedtype=TIMESTAMP_MILLIS", nil
+	case datatypes.JSON, datatypes.Array, datatypes.Object:
+		return "type=BYTE_ARRAY, convertedtype=JSON", nil
+	default:
+		return "type=BYTE_ARRAY, convertedtype=UTF8", nil
+	}
+}
+
+// parquetDecimalType gives the physical type for decimals of a precision
+func parquetDecimalType(precision int) string {
+	switch {
+	case precision <= 9:
+		return "INT32"
+	case precision <= 18:
+		return "INT64"
+	default:
+		return "BYTE_ARRAY"
+	}
+}
+
+// Structure gives this writer's structure
+func (w *ParquetWriter) Structure() *dataset.Structure {
+	return w.st
+}
+
+// WriteRow writes one row to the writer. empty cells are written as nulls
+func (w *ParquetWriter) WriteRow(row [][]byte) error {
+	if w.err != nil {
+		return w.err
+	}
+	fields := w.st.Schema.Fields
+	if len(row) > len(fields) {
+		return fmt.Errorf("row has %d values, schema only has %d fields", len(row), len(fields))
+	}
+	row = nullCells(w.st, row)
+
+	rec := make([]interface{}, len(fields))
+	for i, c := range row {
+		val, err := parquetValue(fields[i], c)
+		if err != nil {
+			return fmt.Errorf("invalid value for field '%s': %s", fields[i].Name, err.Error())
+		}
+		rec[i] = val
+	}
+
+	if err := w.pw.Write(rec); err != nil {
+		return fmt.Errorf("error writing parquet row: %s", err.Error())
+	}
+	return nil
+}
+
+// parquetValue converts raw bytes to the go type parquet stores for a
+// field's type, reading dates & datetimes with the field's format
+func parquetValue(f *dataset.Field, c []byte) (interface{}, error) {
+	if len(c) == 0 {
+		return nil, nil
+	}
+	if isNumberType(f.Type) {
+		var err error
+		if c, err = plainNumber(f, c); err != nil {
+			return nil, err
+		}
+	}
+	switch f.Type {
+	case datatypes.Integer:
+		return datatypes.ParseInteger(c)
+	case datatypes.Float:
+		return datatypes.ParseFloat(c)
+	case datatypes.Boolean:
+		return datatypes.ParseBoolean(c)
+	case datatypes.Decimal:
+		df, err := datatypes.ParseDecimalFormat(f.Format)
+		if err != nil {
+			return nil, err
+		}
+		if df.Precision == 0 {
+			return string(c), nil
+		}
+		r, err := datatypes.ParseDecimal(c)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := df.FormatValue(r); err != nil {
+			return nil, err
+		}
+		// decimals are stored as unscaled integers, rounded to the scale
+		str := r.Mul(r, new(big.Rat).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(df.Scale)), nil))).FloatString(0)
+		n, _ := new(big.Int).SetString(str, 10)
+		switch parquetDecimalType(df.Precision) {
+		case "INT32":
+			return int32(n.Int64()), nil
+		case "INT64":
+			return n.Int64(), nil
+		default:
+			return string(twosComplement(n)), nil
+		}
+	case datatypes.Date, datatypes.Time, datatypes.DateTime:
+		tf, err := datatypes.ParseTimeFormat(f.Type, f.Format)
+		if err != nil {
+			return nil, err
+		}
+		d, err := tf.ParseValue(c)
+		if err != nil {
+			return nil, err
+		}
+		switch f.Type {
+		case datatypes.Date:
+			return int32(d.Unix() / 86400), nil
+		case datatypes.Time:
+			return int32(types.TimeToTIME_MILLIS(d, true)), nil
+		}
+		return types.TimeToTIMESTAMP_MILLIS(d, true), nil
+	default:
+		return string(c), nil
+	}
+}
+
+// Close finalizes the writer, flushing buffered rows & writing
+// the file footer
+func (w *ParquetWriter) Close() error {
+	if w.err != nil {
+		return w.err
+	}
+	if err := w.pw.WriteStop(); err != nil {
+		return fmt.Errorf("error writing parquet data: %s", err.Error())
+	}
+	return nil
+}
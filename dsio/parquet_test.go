@@ -0,0 +1,199 @@
+package dsio
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/dataset/datatypes"
+)
+
+func TestParquetReadWrite(t *testing.T) {
+	dateFields := []*dataset.Field{
+		{Name: "city", Type: datatypes.String},
+		{Name: "founded", Type: datatypes.Date},
+		{Name: "updated", Type: datatypes.DateTime},
+	}
+	formattedFields := []*dataset.Field{
+		{Name: "city", Type: datatypes.String},
+		{Name: "founded", Type: datatypes.Date, Format: "%d/%m/%Y"},
+		{Name: "updated", Type: datatypes.DateTime, Format: "%Y-%m-%d %H:%M"},
+	}
+
+	numberFields := []*dataset.Field{
+		{Name: "small", Type: datatypes.Decimal, Format: "5,2"},
+		{Name: "medium", Type: datatypes.Decimal, Format: "15,3"},
+		{Name: "large", Type: datatypes.Decimal, Format: "30,4"},
+		{Name: "opens", Type: datatypes.Time},
+		{Name: "closes", Type: datatypes.Time, Format: "%H:%M"},
+	}
+	readNumberFields := []*dataset.Field{
+		{Name: "small", Type: datatypes.Decimal},
+		{Name: "medium", Type: datatypes.Decimal},
+		{Name: "large", Type: datatypes.Decimal},
+		{Name: "opens", Type: datatypes.Time},
+		{Name: "closes", Type: datatypes.Time},
+	}
+
+	cases := []struct {
+		write, read *dataset.Schema
+		opts        *dataset.ParquetOptions
+		input       [][][]byte
+		expect      [][][]byte
+		err         string
+	}{
+		{&dataset.Schema{Fields: xlsxFields}, &dataset.Schema{Fields: xlsxFields}, nil, rows["cities"], rows["cities"], ""},
+		{&dataset.Schema{Fields: xlsxFields}, &dataset.Schema{Fields: xlsxFields}, &dataset.ParquetOptions{Compression: "gzip", RowGroupSize: 64}, rows["cities"], rows["cities"], ""},
+		{&dataset.Schema{Fields: xlsxFields}, &dataset.Schema{Fields: xlsxFields}, &dataset.ParquetOptions{Compression: "uncompressed"}, rows["cities"], rows["cities"], ""},
+		// no read schema gives columns in file order
+		{&dataset.Schema{Fields: xlsxFields}, nil, nil, rows["cities"], rows["cities"], ""},
+		// read schema fields are matched to columns by name
+		{&dataset.Schema{Fields: xlsxFields}, &dataset.Schema{Fields: []*dataset.Field{
+			{Name: "in_usa", Type: datatypes.Boolean},
+			{Name: "nope", Type: datatypes.String},
+			{Name: "city", Type: datatypes.String},
+		}}, nil, rows["cities"][:2], [][][]byte{
+			{[]byte("false"), []byte{}, []byte("toronto")},
+			{[]byte("false"), []byte{}, []byte("toronto")},
+		}, ""},
+		// empty cells are nulls
+		{&dataset.Schema{Fields: xlsxFields}, &dataset.Schema{Fields: xlsxFields}, nil, [][][]byte{
+			{[]byte("toronto"), []byte{}, []byte("55.5"), []byte{}},
+		}, [][][]byte{
+			{[]byte("toronto"), []byte{}, []byte("55.5"), []byte{}},
+		}, ""},
+		{&dataset.Schema{Fields: dateFields}, &dataset.Schema{Fields: dateFields}, nil, [][][]byte{
+			{[]byte("toronto"), []byte("1834-03-06"), []byte("2018-03-06T12:30:00Z")},
+		}, [][][]byte{
+			{[]byte("toronto"), []byte("1834-03-06"), []byte("2018-03-06T12:30:00Z")},
+		}, ""},
+		// formatted values are stored as dates & timestamps
+		{&dataset.Schema{Fields: formattedFields}, &dataset.Schema{Fields: dateFields}, nil, [][][]byte{
+			{[]byte("toronto"), []byte("6/3/1834"), []byte("2018-03-06 12:30")},
+		}, [][][]byte{
+			{[]byte("toronto"), []byte("1834-03-06"), []byte("2018-03-06T12:30:00Z")},
+		}, ""},
+		// decimals are stored as scaled integers, times as milliseconds
+		{&dataset.Schema{Fields: numberFields}, &dataset.Schema{Fields: readNumberFields}, nil, [][][]byte{
+			{[]byte("1.05"), []byte("-123456789012.345"), []byte("12345678901234567890123456.7891"), []byte("12:30:05.25"), []byte("08:15")},
+			{[]byte("-1.5"), []byte("0.0005"), []byte("-0.00005"), []byte("00:00:00"), []byte{}},
+		}, [][][]byte{
+			{[]byte("1.05"), []byte("-123456789012.345"), []byte("12345678901234567890123456.7891"), []byte("12:30:05.25"), []byte("08:15:00")},
+			{[]byte("-1.50"), []byte("0.001"), []byte("-0.0001"), []byte("00:00:00"), []byte{}},
+		}, ""},
+		{&dataset.Schema{Fields: numberFields}, nil, nil, [][][]byte{
+			{[]byte("1234.5")},
+		}, nil, "invalid value for field 'small': 1234.50 exceeds decimal precision 5, scale 2"},
+		{&dataset.Schema{Fields: []*dataset.Field{{Name: "n", Type: datatypes.Decimal, Format: "2,5"}}}, nil, nil, nil, nil,
+			"invalid parquet field 'n': invalid decimal format: '2,5'. scale must be between 0 and precision"},
+		{&dataset.Schema{Fields: xlsxFields}, &dataset.Schema{Fields: xlsxFields}, nil, [][][]byte{
+			{[]byte("toronto"), []byte("forty")},
+		}, nil, "invalid value for field 'pop': strconv.ParseInt: parsing \"forty\": invalid syntax"},
+		{nil, nil, nil, rows["cities"], nil, "structure must have a schema to write parquet"},
+	}
+
+	for i, c := range cases {
+		st := &dataset.Structure{Format: dataset.ParquetDataFormat, Schema: c.write}
+		if c.opts != nil {
+			st.FormatConfig = c.opts
+		}
+		buf := &bytes.Buffer{}
+		w, err := NewRowWriter(st, buf)
+		if err != nil {
+			t.Errorf("case %d error allocating writer: %s", i, err.Error())
+			continue
+		}
+		for _, row := range c.input {
+			if err = w.WriteRow(row); err != nil {
+				break
+			}
+		}
+		if err == nil {
+			err = w.Close()
+		}
+		if !(err == nil && c.err == "" || err != nil && err.Error() == c.err) {
+			t.Errorf("case %d error mismatch. expected: '%s', got: '%s'", i, c.err, err)
+			continue
+		}
+		if c.err != "" {
+			continue
+		}
+
+		r, err := NewRowReader(&dataset.Structure{Format: dataset.ParquetDataFormat, Schema: c.read}, buf)
+		if err != nil {
+			t.Errorf("case %d error allocating reader: %s", i, err.Error())
+			continue
+		}
+		got := [][][]byte{}
+		for {
+			row, err := r.ReadRow()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				t.Errorf("case %d error reading row: %s", i, err.Error())
+				break
+			}
+			got = append(got, row)
+		}
+
+		if len(got) != len(c.expect) {
+			t.Errorf("case %d row count mismatch. expected: %d, got: %d", i, len(c.expect), len(got))
+			continue
+		}
+		for j, row := range got {
+			if len(row) != len(c.expect[j]) {
+				t.Errorf("case %d row %d length mismatch. expected: %d, got: %d", i, j, len(c.expect[j]), len(row))
+				continue
+			}
+			for k, cell := range row {
+				if !bytes.Equal(cell, c.expect[j][k]) {
+					t.Errorf("case %d row %d cell %d mismatch. expected: '%s', got: '%s'", i, j, k, c.expect[j][k], cell)
+				}
+			}
+		}
+	}
+}
+
+func TestParquetReaderFields(t *testing.T) {
+	fields := []*dataset.Field{
+		{Name: "city", Type: datatypes.String},
+		{Name: "pop", Type: datatypes.Integer},
+		{Name: "avg_age", Type: datatypes.Float},
+		{Name: "in_usa", Type: datatypes.Boolean},
+		{Name: "founded", Type: datatypes.Date},
+		{Name: "updated", Type: datatypes.DateTime},
+		{Name: "opens", Type: datatypes.Time},
+		{Name: "price", Type: datatypes.Decimal, Format: "10,2"},
+		{Name: "meta", Type: datatypes.JSON},
+		{Name: "link", Type: datatypes.URL},
+	}
+	buf := &bytes.Buffer{}
+	w := NewParquetWriter(&dataset.Structure{Format: dataset.ParquetDataFormat, Schema: &dataset.Schema{Fields: fields}}, buf)
+	if err := w.Close(); err != nil {
+		t.Fatalf("error writing parquet data: %s", err.Error())
+	}
+
+	got, err := NewParquetReader(&dataset.Structure{Format: dataset.ParquetDataFormat}, buf).Fields()
+	if err != nil {
+		t.Fatalf("error reading fields: %s", err.Error())
+	}
+	if len(got) != len(fields) {
+		t.Fatalf("field count mismatch. expected: %d, got: %d", len(fields), len(got))
+	}
+	for i, f := range got {
+		expect := fields[i].Type
+		// urls have no parquet equivalent, and are stored as strings
+		if expect == datatypes.URL {
+			expect = datatypes.String
+		}
+		if f.Name != fields[i].Name || f.Type != expect || f.Format != fields[i].Format {
+			t.Errorf("field %d mismatch. expected: %s %s '%s', got: %s %s '%s'", i, fields[i].Name, expect, fields[i].Format, f.Name, f.Type, f.Format)
+		}
+	}
+
+	if _, err := NewParquetReader(&dataset.Structure{}, bytes.NewBufferString("city,pop")).Fields(); err == nil || err.Error() != "invalid parquet data: missing PAR1 magic bytes" {
+		t.Errorf("expected missing magic bytes error, got: %v", err)
+	}
+}
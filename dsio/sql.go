@@ -0,0 +1,315 @@
+package dsio
+
+import (
+	"bytes"
+	"database/sql"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/dataset/datatypes"
+)
+
+const (
+	// defaultSQLTable is the table name used when SQLOptions.Table is empty
+	defaultSQLTable = "data"
+	// defaultSQLBatchSize is the number of rows per INSERT statement used
+	// when SQLOptions.BatchSize is zero
+	defaultSQLBatchSize = 100
+)
+
+// CreateTableSQL generates a CREATE TABLE statement for a structure's schema
+// in the given dialect, one of dataset.SQLDialects. column types are chosen
+// from field datatypes, required fields are NOT NULL, unique fields are
+// UNIQUE, and the schema's primary key becomes the table's PRIMARY KEY
+func CreateTableSQL(st *dataset.Structure, table, dialect string) (string, error) {
+	if st.Schema == nil || len(st.Schema.Fields) == 0 {
+		return "", fmt.Errorf("structure must have a schema to write sql")
+	}
+
+	buf := &bytes.Buffer{}
+	buf.WriteString("CREATE TABLE " + sqlIdentifier(table) + " (\n")
+	for i, f := range st.Schema.Fields {
+		if f.Name == "" {
+			return "", fmt.Errorf("field %d has no name", i)
+		}
+		typ, err := sqlColumnType(f, dialect)
+		if err != nil {
+			return "", fmt.Errorf("field %s: %s", f.Name, err.Error())
+		}
+		if i > 0 {
+			buf.WriteString(",\n")
+		}
+		buf.WriteString("  " + sqlIdentifier(f.Name) + " " + typ)
+		if c := f.Constraints; c != nil {
+			if c.Required != nil && *c.Required {
+				buf.WriteString(" NOT NULL")
+			}
+			if c.Unique != nil && *c.Unique {
+				buf.WriteString(" UNIQUE")
+			}
+		}
+	}
+	if key := st.Schema.PrimaryKey; len(key) > 0 {
+		if _, err := key.Indexes(st.Schema); err != nil {
+			return "", fmt.Errorf("primary key: %s", err.Error())
+		}
+		buf.WriteString(",\n  PRIMARY KEY (" + sqlIdentifiers(key) + ")")
+	}
+	buf.WriteString("\n);")
+	return buf.String(), nil
+}
+
+// sqlColumnType gives the column type for a field in a dialect. sqlite
+// has no date, time or json types, storing those values as text. sqlite
+// NUMERIC columns store fractional values as 8-byte floats, so decimals
+// are also stored as text to keep their precision
+func sqlColumnType(f *dataset.Field, dialect string) (string, error) {
+	sqlite := dialect == "sqlite"
+	switch f.Type {
+	case datatypes.Integer:
+		if sqlite {
+			return "INTEGER", nil
+		}
+		return "BIGINT", nil
+	case datatypes.Float:
+		if sqlite {
+			return "REAL", nil
+		}
+		return "DOUBLE PRECISION", nil
+	case datatypes.Decimal:
+		df, err := datatypes.ParseDecimalFormat(f.Format)
+		if err != nil {
+			return "", err
+		}
+		if sqlite {
+			return "TEXT", nil
+		}
+		if df.Precision == 0 {
+			return "NUMERIC", nil
+		}
+		return fmt.Sprintf("NUMERIC(%d,%d)", df.Precision, df.Scale), nil
+	case datatypes.Boolean:
+		if sqlite {
+			return "INTEGER", nil
+		}
+		return "BOOLEAN", nil
+	}
+
+	if sqlite {
+		return "TEXT", nil
+	}
+	switch f.Type {
+	case datatypes.Date:
+		return "DATE", nil
+	case datatypes.Time:
+		return "TIME", nil
+	case datatypes.DateTime:
+		return "TIMESTAMPTZ", nil
+	case datatypes.Duration:
+		return "INTERVAL", nil
+	case datatypes.JSON, datatypes.Array, datatypes.Object, datatypes.GeoJSON:
+		return "JSONB", nil
+	default:
+		return "TEXT", nil
+	}
+}
+
+// SQLExecer executes SQL statements. *sql.DB & *sql.Tx both satisfy SQLExecer
+type SQLExecer interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+}
+
+// SQLWriter implements the RowWriter interface, writing rows as batched
+// INSERT statements configured by the structure's SQLOptions. statements
+// are either written as text or executed against a database, which can be
+// used to fill a SQLite file opened with any database/sql driver
+type SQLWriter struct {
+	st          *dataset.Structure
+	wr          io.Writer
+	db          SQLExecer
+	opts        dataset.SQLOptions
+	batch       []string
+	started     bool
+	rowsWritten int
+}
+
+// NewSQLWriter creates a Writer that writes SQL statements to a destination
+func NewSQLWriter(st *dataset.Structure, w io.Writer) *SQLWriter {
+	return newSQLWriter(st, w, nil)
+}
+
+// NewSQLDBWriter creates a Writer that executes SQL statements against a
+// database. Closing the writer does not close the database
+func NewSQLDBWriter(st *dataset.Structure, db SQLExecer) *SQLWriter {
+	return newSQLWriter(st, nil, db)
+}
+
+func newSQLWriter(st *dataset.Structure, w io.Writer, db SQLExecer) *SQLWriter {
+	opts := dataset.SQLOptions{}
+	if o, ok := st.FormatConfig.(*dataset.SQLOptions); ok && o != nil {
+		opts = *o
+	}
+	if opts.Dialect == "" {
+		opts.Dialect = "postgres"
+	}
+	if opts.Table == "" {
+		opts.Table = defaultSQLTable
+	}
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = defaultSQLBatchSize
+	}
+	return &SQLWriter{st: st, wr: w, db: db, opts: opts}
+}
+
+// Structure gives this writer's structure
+func (w *SQLWriter) Structure() *dataset.Structure {
+	return w.st
+}
+
+// WriteRow writes one row to the writer, emitting an INSERT statement each
+// time a batch of rows fills
+func (w *SQLWriter) WriteRow(row [][]byte) error {
+	if err := w.start(); err != nil {
+		return err
+	}
+	fields := w.st.Schema.Fields
+	if len(row) > len(fields) {
+		return fmt.Errorf("row %d: row has %d values, schema has %d fields", w.rowsWritten, len(row), len(fields))
+	}
+
+	vals := make([]string, len(fields))
+	for i, f := range fields {
+		var c []byte
+		if i < len(row) {
+			c = row[i]
+		}
+		vals[i] = sqlLiteral(w.st, f, c, w.opts.Dialect)
+	}
+	w.batch = append(w.batch, "("+strings.Join(vals, ", ")+")")
+	w.rowsWritten++
+
+	if len(w.batch) >= w.opts.BatchSize {
+		return w.flush()
+	}
+	return nil
+}
+
+// start writes the CREATE TABLE statement if one is configured
+func (w *SQLWriter) start() error {
+	if w.started {
+		return nil
+	}
+	if w.st.Schema == nil || len(w.st.Schema.Fields) == 0 {
+		return fmt.Errorf("structure must have a schema to write sql")
+	}
+	w.started = true
+	if !w.opts.CreateTable {
+		return nil
+	}
+	stmt, err := CreateTableSQL(w.st, w.opts.Table, w.opts.Dialect)
+	if err != nil {
+		return err
+	}
+	return w.exec(stmt)
+}
+
+// flush writes an INSERT statement for all buffered rows
+func (w *SQLWriter) flush() error {
+	if len(w.batch) == 0 {
+		return nil
+	}
+	names := make([]string, len(w.st.Schema.Fields))
+	for i, f := range w.st.Schema.Fields {
+		names[i] = f.Name
+	}
+	stmt := "INSERT INTO " + sqlIdentifier(w.opts.Table) + " (" + sqlIdentifiers(names) + ") VALUES\n  " +
+		strings.Join(w.batch, ",\n  ") + ";"
+	w.batch = w.batch[:0]
+	return w.exec(stmt)
+}
+
+// exec sends a single statement to the writer's destination
+func (w *SQLWriter) exec(stmt string) error {
+	if w.db != nil {
+		if _, err := w.db.Exec(stmt); err != nil {
+			return fmt.Errorf("error executing sql: %s", err.Error())
+		}
+		return nil
+	}
+	if _, err := w.wr.Write([]byte(stmt + "\n")); err != nil {
+		return fmt.Errorf("error writing sql: %s", err.Error())
+	}
+	return nil
+}
+
+// Close finalizes the writer, writing any buffered rows
+func (w *SQLWriter) Close() error {
+	if err := w.start(); err != nil {
+		return err
+	}
+	return w.flush()
+}
+
+// sqlLiteral encodes a raw cell as a SQL literal. missing values are NULL,
+// numbers & booleans are written bare, and dates & times are converted to
+// ISO strings. sqlite decimals are quoted to match their text columns.
+// cells that don't parse as their field type are quoted as-is
+func sqlLiteral(st *dataset.Structure, f *dataset.Field, c []byte, dialect string) string {
+	if st.IsNull(f, c) {
+		return "NULL"
+	}
+
+	switch {
+	case isNumberType(f.Type):
+		if num, err := plainNumber(f, c); err == nil {
+			if _, err := datatypes.ParseDecimal(num); err == nil {
+				if f.Type == datatypes.Decimal && dialect == "sqlite" {
+					return sqlString(string(num))
+				}
+				return string(num)
+			}
+		}
+	case f.Type == datatypes.Boolean:
+		if b, err := datatypes.ParseBoolean(c); err == nil {
+			if dialect == "sqlite" {
+				if b {
+					return "1"
+				}
+				return "0"
+			}
+			if b {
+				return "TRUE"
+			}
+			return "FALSE"
+		}
+	case isTimeType(f.Type):
+		if tf, err := datatypes.ParseTimeFormat(f.Type, f.Format); err == nil {
+			if t, err := tf.ParseValue(c); err == nil {
+				iso, _ := datatypes.ParseTimeFormat(f.Type, "default")
+				return sqlString(iso.FormatValue(t))
+			}
+		}
+	}
+	return sqlString(string(c))
+}
+
+// sqlString quotes a string literal, doubling single quotes
+func sqlString(s string) string {
+	return "'" + strings.Replace(s, "'", "''", -1) + "'"
+}
+
+// sqlIdentifier quotes a table or column name, doubling double quotes
+func sqlIdentifier(name string) string {
+	return `"` + strings.Replace(name, `"`, `""`, -1) + `"`
+}
+
+// sqlIdentifiers quotes & joins a list of names
+func sqlIdentifiers(names []string) string {
+	quoted := make([]string, len(names))
+	for i, name := range names {
+		quoted[i] = sqlIdentifier(name)
+	}
+	return strings.Join(quoted, ", ")
+}
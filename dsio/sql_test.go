@@ -0,0 +1,227 @@
+package dsio
+
+import (
+	"bytes"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"testing"
+
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/dataset/datatypes"
+)
+
+func sqlTestStructure(opts *dataset.SQLOptions) *dataset.Structure {
+	yes := true
+	return &dataset.Structure{
+		Format:       dataset.SQLDataFormat,
+		FormatConfig: opts,
+		Schema: &dataset.Schema{
+			Fields: []*dataset.Field{
+				{Name: "id", Type: datatypes.Integer, Constraints: &dataset.FieldConstraints{Required: &yes}},
+				{Name: "name", Type: datatypes.String, Constraints: &dataset.FieldConstraints{Unique: &yes}},
+				{Name: "price", Type: datatypes.Decimal, Format: "10,2"},
+				{Name: "active", Type: datatypes.Boolean},
+				{Name: "born", Type: datatypes.Date, Format: "%d/%m/%Y"},
+				{Name: "tags", Type: datatypes.Array},
+			},
+			PrimaryKey: dataset.FieldKey{"id"},
+		},
+	}
+}
+
+func TestCreateTableSQL(t *testing.T) {
+	cases := []struct {
+		dialect, expect string
+	}{
+		{"postgres", `CREATE TABLE "items" (
+  "id" BIGINT NOT NULL,
+  "name" TEXT UNIQUE,
+  "price" NUMERIC(10,2),
+  "active" BOOLEAN,
+  "born" DATE,
+  "tags" JSONB,
+  PRIMARY KEY ("id")
+);`},
+		{"sqlite", `CREATE TABLE "items" (
+  "id" INTEGER NOT NULL,
+  "name" TEXT UNIQUE,
+  "price" TEXT,
+  "active" INTEGER,
+  "born" TEXT,
+  "tags" TEXT,
+  PRIMARY KEY ("id")
+);`},
+	}
+
+	for i, c := range cases {
+		got, err := CreateTableSQL(sqlTestStructure(nil), "items", c.dialect)
+		if err != nil {
+			t.Errorf("case %d unexpected error: %s", i, err.Error())
+			continue
+		}
+		if got != c.expect {
+			t.Errorf("case %d mismatch. expected:\n%s\ngot:\n%s", i, c.expect, got)
+		}
+	}
+
+	errs := []struct {
+		st  *dataset.Structure
+		err string
+	}{
+		{&dataset.Structure{}, "structure must have a schema to write sql"},
+		{&dataset.Structure{Schema: &dataset.Schema{Fields: []*dataset.Field{{Type: datatypes.String}}}}, "field 0 has no name"},
+		{&dataset.Structure{Schema: &dataset.Schema{Fields: []*dataset.Field{{Name: "a", Type: datatypes.Decimal, Format: "x"}}}}, "field a: invalid decimal format: 'x'"},
+		{&dataset.Structure{Schema: &dataset.Schema{Fields: []*dataset.Field{{Name: "a"}}, PrimaryKey: dataset.FieldKey{"b"}}}, "primary key: field 'b' not found"},
+	}
+	for i, c := range errs {
+		_, err := CreateTableSQL(c.st, "t", "postgres")
+		if !(err == nil && c.err == "" || err != nil && err.Error() == c.err) {
+			t.Errorf("case %d error mismatch. expected: '%s', got: '%s'", i, c.err, err)
+		}
+	}
+}
+
+var sqlTestRows = [][][]byte{
+	{[]byte("1"), []byte("bob's"), []byte("12.50"), []byte("true"), []byte("02/01/2006"), []byte(`["a"]`)},
+	{[]byte("2"), []byte(""), []byte(""), []byte("false"), []byte(""), []byte("")},
+	{[]byte("3"), []byte("carl")},
+}
+
+func TestSQLWriter(t *testing.T) {
+	cases := []struct {
+		opts   *dataset.SQLOptions
+		rows   [][][]byte
+		expect string
+	}{
+		{nil, sqlTestRows, `INSERT INTO "data" ("id", "name", "price", "active", "born", "tags") VALUES
+  (1, 'bob''s', 12.50, TRUE, '2006-01-02', '["a"]'),
+  (2, NULL, NULL, FALSE, NULL, NULL),
+  (3, 'carl', NULL, NULL, NULL, NULL);
+`},
+		{&dataset.SQLOptions{Dialect: "sqlite", Table: "items", BatchSize: 2}, sqlTestRows, `INSERT INTO "items" ("id", "name", "price", "active", "born", "tags") VALUES
+  (1, 'bob''s', '12.50', 1, '2006-01-02', '["a"]'),
+  (2, NULL, NULL, 0, NULL, NULL);
+INSERT INTO "items" ("id", "name", "price", "active", "born", "tags") VALUES
+  (3, 'carl', NULL, NULL, NULL, NULL);
+`},
+		{&dataset.SQLOptions{Table: "t", CreateTable: true}, nil, `CREATE TABLE "t" (
+  "id" BIGINT NOT NULL,
+  "name" TEXT UNIQUE,
+  "price" NUMERIC(10,2),
+  "active" BOOLEAN,
+  "born" DATE,
+  "tags" JSONB,
+  PRIMARY KEY ("id")
+);
+`},
+	}
+
+	for i, c := range cases {
+		buf := &bytes.Buffer{}
+		w, err := NewRowWriter(sqlTestStructure(c.opts), buf)
+		if err != nil {
+			t.Fatalf("case %d error creating writer: %s", i, err.Error())
+		}
+		for _, row := range c.rows {
+			if err := w.WriteRow(row); err != nil {
+				t.Fatalf("case %d error writing row: %s", i, err.Error())
+			}
+		}
+		if err := w.Close(); err != nil {
+			t.Fatalf("case %d error closing writer: %s", i, err.Error())
+		}
+		if buf.String() != c.expect {
+			t.Errorf("case %d output mismatch. expected:\n%s\ngot:\n%s", i, c.expect, buf.String())
+		}
+	}
+
+	w := NewSQLWriter(sqlTestStructure(nil), &bytes.Buffer{})
+	if err := w.WriteRow([][]byte{{}, {}, {}, {}, {}, {}, {}}); err == nil || err.Error() != "row 0: row has 7 values, schema has 6 fields" {
+		t.Errorf("expected row length error, got: %v", err)
+	}
+	w = NewSQLWriter(&dataset.Structure{Format: dataset.SQLDataFormat}, &bytes.Buffer{})
+	if err := w.Close(); err == nil || err.Error() != "structure must have a schema to write sql" {
+		t.Errorf("expected missing schema error, got: %v", err)
+	}
+}
+
+// testSQLDriver records statements executed against it
+type testSQLDriver struct {
+	stmts []string
+	fail  bool
+}
+
+func (d *testSQLDriver) Open(name string) (driver.Conn, error) { return &testSQLConn{d}, nil }
+
+type testSQLConn struct{ d *testSQLDriver }
+
+func (c *testSQLConn) Prepare(query string) (driver.Stmt, error) {
+	return &testSQLStmt{c.d, query}, nil
+}
+func (c *testSQLConn) Close() error { return nil }
+func (c *testSQLConn) Begin() (driver.Tx, error) {
+	return nil, fmt.Errorf("transactions not supported")
+}
+
+type testSQLStmt struct {
+	d     *testSQLDriver
+	query string
+}
+
+func (s *testSQLStmt) Close() error  { return nil }
+func (s *testSQLStmt) NumInput() int { return -1 }
+func (s *testSQLStmt) Exec(args []driver.Value) (driver.Result, error) {
+	if s.d.fail {
+		return nil, fmt.Errorf("disk full")
+	}
+	s.d.stmts = append(s.d.stmts, s.query)
+	return driver.RowsAffected(1), nil
+}
+func (s *testSQLStmt) Query(args []driver.Value) (driver.Rows, error) {
+	return nil, fmt.Errorf("queries not supported")
+}
+
+var testDriver = &testSQLDriver{}
+
+func init() {
+	sql.Register("dsio_test", testDriver)
+}
+
+func TestSQLDBWriter(t *testing.T) {
+	db, err := sql.Open("dsio_test", "")
+	if err != nil {
+		t.Fatalf("error opening database: %s", err.Error())
+	}
+	defer db.Close()
+
+	testDriver.stmts = nil
+	w := NewSQLDBWriter(sqlTestStructure(&dataset.SQLOptions{Dialect: "sqlite", CreateTable: true, BatchSize: 2}), db)
+	for _, row := range sqlTestRows {
+		if err := w.WriteRow(row); err != nil {
+			t.Fatalf("error writing row: %s", err.Error())
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("error closing writer: %s", err.Error())
+	}
+
+	if len(testDriver.stmts) != 3 {
+		t.Fatalf("expected 3 statements, got: %d", len(testDriver.stmts))
+	}
+	expect := `INSERT INTO "data" ("id", "name", "price", "active", "born", "tags") VALUES
+  (3, 'carl', NULL, NULL, NULL, NULL);`
+	if testDriver.stmts[2] != expect {
+		t.Errorf("statement mismatch. expected:\n%s\ngot:\n%s", expect, testDriver.stmts[2])
+	}
+
+	testDriver.fail = true
+	defer func() { testDriver.fail = false }()
+	w = NewSQLDBWriter(sqlTestStructure(nil), db)
+	if err := w.WriteRow(sqlTestRows[0]); err != nil {
+		t.Fatalf("error writing row: %s", err.Error())
+	}
+	if err := w.Close(); err == nil || err.Error() != "error executing sql: disk full" {
+		t.Errorf("expected exec error, got: %v", err)
+	}
+}
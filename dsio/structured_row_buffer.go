@@ -17,8 +17,11 @@ type StructuredRowBuffer struct {
 	st     *dataset.Structure
 	rows   [][][]byte
 	less   *func(i, j int) bool
+	desc   bool
 	unique bool
 	err    error
+	// first error comparing values while sorting
+	sortErr error
 }
 
 // StructuredRowBufferCfg encapsulates configuration for StructuredRowBuffer
@@ -27,6 +30,9 @@ type StructuredRowBufferCfg struct {
 	OrderBy []*dataset.Field
 	// OrderByDesc reverses the order given
 	OrderByDesc bool
+	// NullOrder specifies where empty values are placed in the order.
+	// the default treats nulls as the lowest of all values
+	NullOrder datatypes.NullOrder
 	// Unique silently rejects writing rows
 	// already present in the buffer
 	Unique bool
@@ -43,6 +49,7 @@ func NewStructuredRowBuffer(st *dataset.Structure, configs ...func(o *Structured
 
 	rb := &StructuredRowBuffer{
 		st:     st,
+		desc:   cfg.OrderByDesc,
 		unique: cfg.Unique,
 	}
 	rb.less, rb.err = rb.makeLessFunc(st, cfg)
@@ -70,13 +77,23 @@ func (rb *StructuredRowBuffer) WriteRow(row [][]byte) error {
 }
 
 // Close closes the writer portion of the buffer, which will affect
-// underlying contents.
+// underlying contents. Rows are sorted in ascending order & reversed for
+// descending orders, so rows with equal values keep their written order
+// when ascending. Values that can't be compared are an error
 func (rb *StructuredRowBuffer) Close() error {
 	if rb.err != nil {
 		return rb.err
 	}
 	if rb.less != nil {
-		sort.Sort(rb)
+		sort.Stable(rb)
+		if rb.sortErr != nil {
+			return rb.sortErr
+		}
+		if rb.desc {
+			for i, j := 0, len(rb.rows)-1; i < j; i, j = i+1, j-1 {
+				rb.rows[i], rb.rows[j] = rb.rows[j], rb.rows[i]
+			}
+		}
 	}
 	return nil
 }
@@ -140,8 +157,8 @@ func (rb *StructuredRowBuffer) makeLessFunc(st *dataset.Structure, cfg *Structur
 
 	type order struct {
 		idx  int
-		desc bool
-		dt   datatypes.Type
+		name string
+		cmp  *datatypes.Comparer
 	}
 
 	if st.Schema == nil {
@@ -162,19 +179,45 @@ func (rb *StructuredRowBuffer) makeLessFunc(st *dataset.Structure, cfg *Structur
 			return nil, fmt.Errorf("couldn't find sort field: %s", o.Name)
 		}
 
+		// rows are sorted ascending & reversed for descending orders, so
+		// explicit null placement is flipped for the ascending sort
+		nulls := cfg.NullOrder
+		if cfg.OrderByDesc && nulls == datatypes.NullsFirst {
+			nulls = datatypes.NullsLast
+		} else if cfg.OrderByDesc && nulls == datatypes.NullsLast {
+			nulls = datatypes.NullsFirst
+		}
+
+		f := st.Schema.Fields[idx]
+		cmp, err := datatypes.NewComparer(f.Type, f.Format, nulls)
+		if err != nil {
+			return nil, fmt.Errorf("sort field %s: %s", f.Name, err.Error())
+		}
+		if cmp.Number, err = f.NumberFormat(); err != nil {
+			return nil, fmt.Errorf("sort field %s: %s", f.Name, err.Error())
+		}
+		cmp.Missing = st.NullValues(f)
 		orders = append(orders, order{
 			idx:  idx,
-			desc: cfg.OrderByDesc,
-			dt:   st.Schema.Fields[idx].Type,
+			name: f.Name,
+			cmp:  cmp,
 		})
 	}
 
 	less := func(i, j int) bool {
 		for _, o := range orders {
-			l, err := datatypes.CompareTypeBytes(rb.rows[i][o.idx], rb.rows[j][o.idx], o.dt)
+			if o.idx >= len(rb.rows[i]) || o.idx >= len(rb.rows[j]) {
+				if rb.sortErr == nil {
+					rb.sortErr = fmt.Errorf("error sorting by %s: row is missing column %d", o.name, o.idx)
+				}
+				return false
+			}
+			l, err := o.cmp.Compare(rb.rows[i][o.idx], rb.rows[j][o.idx])
 			if err != nil {
-				// TODO - wut
-				continue
+				if rb.sortErr == nil {
+					rb.sortErr = fmt.Errorf("error sorting by %s: %s", o.name, err.Error())
+				}
+				return false
 			}
 			if l == 0 {
 				continue
@@ -184,10 +227,5 @@ func (rb *StructuredRowBuffer) makeLessFunc(st *dataset.Structure, cfg *Structur
 		return false
 	}
 
-	if cfg.OrderByDesc {
-		opposite := func(i, j int) bool { return !less(i, j) }
-		return &opposite, nil
-	}
-
 	return &less, nil
 }
@@ -5,9 +5,11 @@ import (
 	"fmt"
 	"io/ioutil"
 	"os"
+	"strings"
 	"testing"
 
 	"github.com/qri-io/dataset"
+	"github.com/qri-io/dataset/datatypes"
 	dmp "github.com/sergi/go-diff/diffmatchpatch"
 )
 
@@ -136,3 +138,96 @@ func TestStructuredRowBuffer(t *testing.T) {
 	// 	return
 	// }
 }
+
+func TestStructuredRowBufferOrder(t *testing.T) {
+	st := &dataset.Structure{
+		Format: dataset.CSVDataFormat,
+		Schema: &dataset.Schema{
+			Fields: []*dataset.Field{
+				{Name: "name", Type: datatypes.String},
+				{Name: "joined", Type: datatypes.Date, Format: "%d/%m/%Y"},
+				{Name: "active", Type: datatypes.Boolean},
+			},
+		},
+	}
+	rows := [][][]byte{
+		{[]byte("ana"), []byte("02/10/2017"), []byte("true")},
+		{[]byte("bo"), []byte(""), []byte("false")},
+		{[]byte("cy"), []byte("10/01/2017"), []byte("")},
+		{[]byte("di"), []byte("10/01/2017"), []byte("true")},
+	}
+
+	cases := []struct {
+		orderBy []string
+		desc    bool
+		nulls   datatypes.NullOrder
+		expect  string
+		err     string
+	}{
+		{[]string{"joined"}, false, datatypes.NullsLow, "bo,cy,di,ana", ""},
+		{[]string{"joined"}, true, datatypes.NullsLow, "ana,di,cy,bo", ""},
+		{[]string{"joined"}, false, datatypes.NullsLast, "cy,di,ana,bo", ""},
+		{[]string{"joined"}, true, datatypes.NullsFirst, "bo,ana,di,cy", ""},
+		{[]string{"joined"}, true, datatypes.NullsLast, "ana,di,cy,bo", ""},
+		{[]string{"active", "name"}, false, datatypes.NullsLow, "cy,bo,ana,di", ""},
+		{[]string{"active", "name"}, false, datatypes.NullsLast, "bo,ana,di,cy", ""},
+		{[]string{"missing"}, false, datatypes.NullsLow, "", "couldn't find sort field: missing"},
+	}
+
+	for i, c := range cases {
+		buf, err := NewStructuredRowBuffer(st, func(cfg *StructuredRowBufferCfg) {
+			for _, name := range c.orderBy {
+				cfg.OrderBy = append(cfg.OrderBy, &dataset.Field{Name: name})
+			}
+			cfg.OrderByDesc = c.desc
+			cfg.NullOrder = c.nulls
+		})
+		if err != nil {
+			t.Errorf("case %d error allocating buffer: %s", i, err.Error())
+			continue
+		}
+		for _, row := range rows {
+			buf.WriteRow(row)
+		}
+		err = buf.Close()
+		if !(err == nil && c.err == "" || err != nil && err.Error() == c.err) {
+			t.Errorf("case %d error mismatch. expected: '%s', got: '%s'", i, c.err, err)
+			continue
+		}
+		if c.err != "" {
+			continue
+		}
+
+		names := make([]string, len(buf.rows))
+		for j, row := range buf.rows {
+			names[j] = string(row[0])
+		}
+		if got := strings.Join(names, ","); got != c.expect {
+			t.Errorf("case %d order mismatch. expected: %s, got: %s", i, c.expect, got)
+		}
+	}
+}
+
+func TestStructuredRowBufferCompareError(t *testing.T) {
+	st := &dataset.Structure{
+		Format: dataset.CSVDataFormat,
+		Schema: &dataset.Schema{
+			Fields: []*dataset.Field{
+				{Name: "pop", Type: datatypes.Integer},
+			},
+		},
+	}
+	buf, err := NewStructuredRowBuffer(st, func(cfg *StructuredRowBufferCfg) {
+		cfg.OrderBy = []*dataset.Field{{Name: "pop"}}
+	})
+	if err != nil {
+		t.Fatalf("error allocating buffer: %s", err.Error())
+	}
+	buf.WriteRow([][]byte{[]byte("35000")})
+	buf.WriteRow([][]byte{[]byte("lots")})
+
+	expect := `error sorting by pop: strconv.ParseInt: parsing "lots": invalid syntax`
+	if err := buf.Close(); err == nil || err.Error() != expect {
+		t.Errorf("error mismatch. expected: '%s', got: '%v'", expect, err)
+	}
+}
@@ -0,0 +1,304 @@
+package dsio
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/360EntSecGroup-Skylar/excelize"
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/dataset/datatypes"
+)
+
+// XLSXReader implements the RowReader interface for excel workbooks.
+// schema fields describe the columns of the configured cell range.
+// xlsx files are zip archives that can't be decoded incrementally,
+// so the entire workbook is read into memory on the first call to ReadRow
+type XLSXReader struct {
+	st     *dataset.Structure
+	r      io.Reader
+	loaded bool
+	rows   [][]string
+	idx    int
+}
+
+// NewXLSXReader creates a reader from a structure and read source
+func NewXLSXReader(st *dataset.Structure, r io.Reader) *XLSXReader {
+	return &XLSXReader{
+		st: st,
+		r:  r,
+	}
+}
+
+// Structure gives this reader's structure
+func (r *XLSXReader) Structure() *dataset.Structure {
+	return r.st
+}
+
+// ReadRow reads one row of the configured worksheet
+func (r *XLSXReader) ReadRow() ([][]byte, error) {
+	if !r.loaded {
+		if err := r.load(); err != nil {
+			return nil, err
+		}
+		r.loaded = true
+	}
+
+	if r.idx >= len(r.rows) {
+		return nil, io.EOF
+	}
+
+	data := r.rows[r.idx]
+	r.idx++
+
+	row := make([][]byte, len(data))
+	for i, d := range data {
+		if r.st.Schema != nil && i < len(r.st.Schema.Fields) && r.st.Schema.Fields[i].Type == datatypes.Boolean {
+			// excel stores boolean cells as 1 & 0
+			switch d {
+			case "1":
+				d = "true"
+			case "0":
+				d = "false"
+			}
+		}
+		row[i] = []byte(d)
+	}
+	return row, nil
+}
+
+// load reads the workbook, selecting rows from the configured sheet & range
+func (r *XLSXReader) load() error {
+	opts := xlsxOptions(r.st)
+
+	f, err := excelize.OpenReader(r.r)
+	if err != nil {
+		return fmt.Errorf("error reading xlsx data: %s", err.Error())
+	}
+
+	sheet, err := xlsxSheetName(f, opts)
+	if err != nil {
+		return err
+	}
+
+	rng, err := parseXLSXCellRange(opts.CellRange)
+	if err != nil {
+		return err
+	}
+
+	rows := rng.slice(f.GetRows(sheet))
+	if opts.HeaderRow && len(rows) > 0 {
+		rows = rows[1:]
+	}
+	r.rows = rows
+	return nil
+}
+
+// xlsxOptions returns the structure's excel configuration, falling
+// back to defaults if none is provided
+func xlsxOptions(st *dataset.Structure) *dataset.XLSOptions {
+	if opts, ok := st.FormatConfig.(*dataset.XLSOptions); ok && opts != nil {
+		return opts
+	}
+	return &dataset.XLSOptions{}
+}
+
+// xlsxSheetName resolves the name of the sheet specified by opts
+func xlsxSheetName(f *excelize.File, opts *dataset.XLSOptions) (string, error) {
+	if opts.SheetName != "" {
+		if f.GetSheetIndex(opts.SheetName) == 0 {
+			return "", fmt.Errorf("sheet '%s' not found in workbook", opts.SheetName)
+		}
+		return opts.SheetName, nil
+	}
+
+	// excelize sheet indexes start at one
+	name := f.GetSheetName(opts.SheetIndex + 1)
+	if name == "" {
+		return "", fmt.Errorf("sheet index %d not found in workbook", opts.SheetIndex)
+	}
+	return name, nil
+}
+
+// xlsxCellRange is a zero-indexed, inclusive rectangle of cells.
+// negative end values leave the range unbounded in that direction
+type xlsxCellRange struct {
+	startCol, startRow int
+	endCol, endRow     int
+}
+
+// parseXLSXCellRange reads A1-notation ranges like "B2:F100".
+// a single cell reference selects everything from that cell onward,
+// the empty string selects all cells
+func parseXLSXCellRange(s string) (rng xlsxCellRange, err error) {
+	rng = xlsxCellRange{endCol: -1, endRow: -1}
+	if s == "" {
+		return
+	}
+
+	cells := strings.Split(s, ":")
+	if len(cells) > 2 {
+		return rng, fmt.Errorf("invalid cell range: '%s'", s)
+	}
+
+	if rng.startCol, rng.startRow, err = parseXLSXCell(cells[0]); err != nil {
+		return rng, fmt.Errorf("invalid cell range: '%s': %s", s, err.Error())
+	}
+
+	if len(cells) == 2 {
+		if rng.endCol, rng.endRow, err = parseXLSXCell(cells[1]); err != nil {
+			return rng, fmt.Errorf("invalid cell range: '%s': %s", s, err.Error())
+		}
+		if rng.endCol < rng.startCol || rng.endRow < rng.startRow {
+			return rng, fmt.Errorf("invalid cell range: '%s': range end must come after start", s)
+		}
+	}
+
+	return
+}
+
+// parseXLSXCell converts a cell reference like "AB12" to zero-indexed
+// column & row positions
+func parseXLSXCell(s string) (col, row int, err error) {
+	s = strings.ToUpper(strings.TrimSpace(s))
+	i := strings.IndexFunc(s, func(r rune) bool { return r < 'A' || r > 'Z' })
+	if i <= 0 {
+		return 0, 0, fmt.Errorf("cell '%s' must start with a column letter", s)
+	}
+
+	n, err := strconv.Atoi(s[i:])
+	if err != nil || n < 1 {
+		return 0, 0, fmt.Errorf("cell '%s' must end with a row number greater than zero", s)
+	}
+
+	return excelize.TitleToNumber(s[:i]), n - 1, nil
+}
+
+// slice trims a set of rows to the bounds of the range. rows that
+// fall short of the range width are padded with empty cells, rows that end
+// before the range starts are empty
+func (rng xlsxCellRange) slice(rows [][]string) [][]string {
+	if rng.startRow >= len(rows) {
+		return nil
+	}
+	if rng.endRow >= 0 && rng.endRow < len(rows) {
+		rows = rows[:rng.endRow+1]
+	}
+	rows = rows[rng.startRow:]
+
+	sliced := make([][]string, len(rows))
+	for i, row := range rows {
+		end := len(row)
+		if rng.endCol >= 0 {
+			end = rng.endCol + 1
+		}
+		if end < rng.startCol {
+			end = rng.startCol
+		}
+		cells := make([]string, 0, end-rng.startCol)
+		for j := rng.startCol; j < end; j++ {
+			if j < len(row) {
+				cells = append(cells, row[j])
+			} else {
+				cells = append(cells, "")
+			}
+		}
+		sliced[i] = cells
+	}
+	return sliced
+}
+
+// XLSXWriter implements the RowWriter interface for excel workbooks.
+// Like XLSXReader, the workbook is held in memory, only writing to the
+// underlying writer when Close is called
+type XLSXWriter struct {
+	rowsWritten int
+	sheet       string
+	st          *dataset.Structure
+	f           *excelize.File
+	w           io.Writer
+}
+
+// NewXLSXWriter creates a Writer from a structure and write destination
+func NewXLSXWriter(st *dataset.Structure, w io.Writer) *XLSXWriter {
+	opts := xlsxOptions(st)
+	f := excelize.NewFile()
+	sheet := "Sheet1"
+	if opts.SheetName != "" {
+		f.SetSheetName(sheet, opts.SheetName)
+		sheet = opts.SheetName
+	}
+
+	wr := &XLSXWriter{
+		sheet: sheet,
+		st:    st,
+		f:     f,
+		w:     w,
+	}
+
+	if opts.HeaderRow && st.Schema != nil {
+		for i, name := range st.Schema.FieldNames() {
+			f.SetCellStr(sheet, xlsxAxis(i, 0), name)
+		}
+		wr.rowsWritten++
+	}
+
+	return wr
+}
+
+// Structure gives this writer's structure
+func (w *XLSXWriter) Structure() *dataset.Structure {
+	return w.st
+}
+
+// WriteRow writes one row to the workbook
+func (w *XLSXWriter) WriteRow(row [][]byte) error {
+	row = nullCells(w.st, row)
+	for i, c := range row {
+		t := datatypes.String
+		if w.st.Schema != nil && i < len(w.st.Schema.Fields) {
+			t = w.st.Schema.Fields[i].Type
+		}
+		w.f.SetCellValue(w.sheet, xlsxAxis(i, w.rowsWritten), xlsxCellValue(t, c))
+	}
+	w.rowsWritten++
+	return nil
+}
+
+// Close finalizes the writer, writing the workbook to the
+// underlying writer
+func (w *XLSXWriter) Close() error {
+	if err := w.f.Write(w.w); err != nil {
+		return fmt.Errorf("error writing xlsx data: %s", err.Error())
+	}
+	return nil
+}
+
+// xlsxAxis gives the A1-notation name of a zero-indexed cell position
+func xlsxAxis(col, row int) string {
+	return excelize.ToAlphaString(col) + strconv.Itoa(row+1)
+}
+
+// xlsxCellValue converts raw bytes to a value excelize will store with
+// the cell type matching t, falling back to string values
+func xlsxCellValue(t datatypes.Type, c []byte) interface{} {
+	if len(c) == 0 {
+		return nil
+	}
+	switch t {
+	case datatypes.Integer:
+		if i, err := datatypes.ParseInteger(c); err == nil {
+			return i
+		}
+	case datatypes.Float:
+		if f, err := datatypes.ParseFloat(c); err == nil {
+			return f
+		}
+	case datatypes.Boolean:
+		if b, err := datatypes.ParseBoolean(c); err == nil {
+			return b
+		}
+	}
+	return string(c)
+}
@@ -0,0 +1,101 @@
+package dsio
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/dataset/datatypes"
+)
+
+var xlsxFields = []*dataset.Field{
+	{Name: "city", Type: datatypes.String},
+	{Name: "pop", Type: datatypes.Integer},
+	{Name: "avg_age", Type: datatypes.Float},
+	{Name: "in_usa", Type: datatypes.Boolean},
+}
+
+func TestXLSXReadWrite(t *testing.T) {
+	cases := []struct {
+		write, read *dataset.XLSOptions
+		expect      [][][]byte
+		err         string
+	}{
+		{&dataset.XLSOptions{}, &dataset.XLSOptions{}, rows["cities"], ""},
+		{&dataset.XLSOptions{HeaderRow: true}, &dataset.XLSOptions{HeaderRow: true}, rows["cities"], ""},
+		{&dataset.XLSOptions{SheetName: "cities"}, &dataset.XLSOptions{SheetName: "cities"}, rows["cities"], ""},
+		{&dataset.XLSOptions{SheetName: "cities"}, &dataset.XLSOptions{SheetName: "nope"}, nil, "sheet 'nope' not found in workbook"},
+		{&dataset.XLSOptions{}, &dataset.XLSOptions{SheetIndex: 3}, nil, "sheet index 3 not found in workbook"},
+		{&dataset.XLSOptions{HeaderRow: true}, &dataset.XLSOptions{CellRange: "A2:B3"}, [][][]byte{
+			{[]byte("toronto"), []byte("40000000")},
+			{[]byte("toronto"), []byte("40000000")},
+		}, ""},
+		{&dataset.XLSOptions{}, &dataset.XLSOptions{CellRange: "B5"}, [][][]byte{
+			{[]byte("35000"), []byte("65.25"), []byte("1")},
+			{[]byte("250000"), []byte("50.65"), []byte("1")},
+		}, ""},
+		// range starts past the last column of data
+		{&dataset.XLSOptions{}, &dataset.XLSOptions{CellRange: "F5"}, [][][]byte{{}, {}}, ""},
+		{&dataset.XLSOptions{}, &dataset.XLSOptions{CellRange: "C6:B2"}, nil, "invalid cell range: 'C6:B2': range end must come after start"},
+	}
+
+	for i, c := range cases {
+		buf := &bytes.Buffer{}
+		w, err := NewRowWriter(&dataset.Structure{Format: dataset.XLSDataFormat, FormatConfig: c.write, Schema: &dataset.Schema{Fields: xlsxFields}}, buf)
+		if err != nil {
+			t.Errorf("case %d error allocating writer: %s", i, err.Error())
+			continue
+		}
+		for _, row := range rows["cities"] {
+			if err := w.WriteRow(row); err != nil {
+				t.Errorf("case %d error writing row: %s", i, err.Error())
+			}
+		}
+		if err := w.Close(); err != nil {
+			t.Errorf("case %d error closing writer: %s", i, err.Error())
+			continue
+		}
+
+		r, err := NewRowReader(&dataset.Structure{Format: dataset.XLSDataFormat, FormatConfig: c.read, Schema: &dataset.Schema{Fields: xlsxFields}}, buf)
+		if err != nil {
+			t.Errorf("case %d error allocating reader: %s", i, err.Error())
+			continue
+		}
+
+		got := [][][]byte{}
+		for {
+			row, err := r.ReadRow()
+			if err == io.EOF {
+				break
+			}
+			if !(err == nil && c.err == "" || err != nil && err.Error() == c.err) {
+				t.Errorf("case %d error mismatch. expected: '%s', got: '%s'", i, c.err, err)
+			}
+			if err != nil {
+				break
+			}
+			got = append(got, row)
+		}
+
+		if c.err != "" {
+			continue
+		}
+
+		if len(got) != len(c.expect) {
+			t.Errorf("case %d row count mismatch. expected: %d, got: %d", i, len(c.expect), len(got))
+			continue
+		}
+		for j, row := range got {
+			if len(row) != len(c.expect[j]) {
+				t.Errorf("case %d row %d length mismatch. expected: %d, got: %d", i, j, len(c.expect[j]), len(row))
+				continue
+			}
+			for k, cell := range row {
+				if !bytes.Equal(cell, c.expect[j][k]) {
+					t.Errorf("case %d row %d cell %d mismatch. expected: '%s', got: '%s'", i, j, k, c.expect[j][k], cell)
+				}
+			}
+		}
+	}
+}
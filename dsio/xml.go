@@ -0,0 +1,394 @@
+package dsio
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/dataset/charset"
+)
+
+// XMLReader implements the RowReader interface for the XML data format.
+// Each element matching the configured row path is read as a row, with
+// field values drawn from attributes & child elements who's names match
+// schema field names
+type XMLReader struct {
+	st   *dataset.Structure
+	opts *dataset.XMLOptions
+	d    *xml.Decoder
+	// path of the row element, split on "/"
+	rowPath []string
+	// anchor row path matching to the document root
+	anchored bool
+	// stack of element names leading to the current token
+	stack []string
+}
+
+// XMLReaderCfg configures an XMLReader
+type XMLReaderCfg struct {
+	// Transcoded marks input as already transcoded to utf-8, the encoding
+	// a document declares is ignored
+	Transcoded bool
+}
+
+// NewXMLReader creates a reader from a structure and read source.
+// documents that declare a non-utf-8 encoding are transcoded to utf-8
+// unless the reader is configured with already-transcoded input
+func NewXMLReader(st *dataset.Structure, r io.Reader, options ...func(cfg *XMLReaderCfg)) *XMLReader {
+	cfg := &XMLReaderCfg{}
+	for _, opt := range options {
+		opt(cfg)
+	}
+
+	opts := xmlOptions(st)
+	rowPath, anchored := xmlRowPath(opts.RowElement)
+	d := xml.NewDecoder(r)
+	d.CharsetReader = func(label string, input io.Reader) (io.Reader, error) {
+		if cfg.Transcoded {
+			return input, nil
+		}
+		return charset.NewReader(label, input)
+	}
+	return &XMLReader{
+		st:       st,
+		opts:     opts,
+		d:        d,
+		rowPath:  rowPath,
+		anchored: anchored,
+	}
+}
+
+// Structure gives this reader's structure
+func (r *XMLReader) Structure() *dataset.Structure {
+	return r.st
+}
+
+// ReadRow reads one row element from the reader, ordering values
+// by schema fields. fields missing from the element are empty.
+// If the structure has no schema, values are given in the order they
+// occur in the document
+func (r *XMLReader) ReadRow() ([][]byte, error) {
+	rec, err := r.ReadRecord()
+	if err != nil {
+		return nil, err
+	}
+
+	if r.st.Schema == nil {
+		row := make([][]byte, len(rec.Names))
+		for i, name := range rec.Names {
+			row[i] = rec.Values[name]
+		}
+		return row, nil
+	}
+
+	row := make([][]byte, len(r.st.Schema.Fields))
+	for i, f := range r.st.Schema.Fields {
+		if val, ok := rec.Values[f.Name]; ok {
+			row[i] = val
+		} else {
+			row[i] = []byte{}
+		}
+	}
+	return row, nil
+}
+
+// XMLRecord is the set of named values read from a single row element
+type XMLRecord struct {
+	// Names lists value names in document order, attributes first
+	Names []string
+	// Values maps names to raw values
+	Values map[string][]byte
+}
+
+// ReadRecord reads the next row element from the reader
+func (r *XMLReader) ReadRecord() (*XMLRecord, error) {
+	for {
+		tok, err := r.d.Token()
+		if err != nil {
+			return nil, err
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			r.stack = append(r.stack, t.Name.Local)
+			if r.isRow() {
+				return r.readRecord(t)
+			}
+		case xml.EndElement:
+			r.stack = r.stack[:len(r.stack)-1]
+		}
+	}
+}
+
+// isRow checks the current element stack against the row path
+func (r *XMLReader) isRow() bool {
+	if len(r.rowPath) == 0 {
+		// default to direct children of the root element
+		return len(r.stack) == 2
+	}
+	if len(r.stack) < len(r.rowPath) || r.anchored && len(r.stack) != len(r.rowPath) {
+		return false
+	}
+	offset := len(r.stack) - len(r.rowPath)
+	for i, name := range r.rowPath {
+		if r.stack[offset+i] != name {
+			return false
+		}
+	}
+	return true
+}
+
+// readRecord consumes tokens until the end of the row element start
+func (r *XMLReader) readRecord(start xml.StartElement) (*XMLRecord, error) {
+	rec := &XMLRecord{Values: map[string][]byte{}}
+	fromAttr := map[string]bool{}
+	for _, attr := range start.Attr {
+		if _, ok := rec.Values[attr.Name.Local]; !ok {
+			rec.Names = append(rec.Names, attr.Name.Local)
+		}
+		rec.Values[attr.Name.Local] = []byte(attr.Value)
+		fromAttr[attr.Name.Local] = true
+	}
+
+	var (
+		depth int
+		child string
+		text  []byte
+	)
+
+	for {
+		tok, err := r.d.Token()
+		if err != nil {
+			return nil, err
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			if depth == 0 {
+				child = t.Name.Local
+				text = []byte{}
+			}
+			depth++
+		case xml.CharData:
+			if depth > 0 {
+				text = append(text, t...)
+			}
+		case xml.EndElement:
+			if depth == 0 {
+				r.stack = r.stack[:len(r.stack)-1]
+				return rec, nil
+			}
+			depth--
+			if depth == 0 {
+				if _, ok := rec.Values[child]; !ok {
+					rec.Names = append(rec.Names, child)
+				} else if fromAttr[child] && r.opts.AttributeFields {
+					continue
+				}
+				rec.Values[child] = text
+			}
+		}
+	}
+}
+
+// xmlOptions returns the structure's xml configuration, falling
+// back to defaults if none is provided
+func xmlOptions(st *dataset.Structure) *dataset.XMLOptions {
+	if opts, ok := st.FormatConfig.(*dataset.XMLOptions); ok && opts != nil {
+		return opts
+	}
+	return &dataset.XMLOptions{}
+}
+
+// xmlRowPath splits a row element path into element names, reporting
+// weather the path is anchored to the document root
+func xmlRowPath(rowElement string) (path []string, anchored bool) {
+	anchored = strings.HasPrefix(rowElement, "/")
+	for _, name := range strings.Split(strings.Trim(rowElement, "/"), "/") {
+		if name != "" {
+			path = append(path, name)
+		}
+	}
+	return
+}
+
+// XMLWriter implements the RowWriter interface for
+// XML-formatted data
+type XMLWriter struct {
+	rowsWritten int
+	st          *dataset.Structure
+	wr          io.Writer
+	attrs       bool
+	// elements enclosing rows, outermost first
+	parents []string
+	row     string
+	// invalid element or attribute name, reported on the first write
+	err error
+}
+
+// NewXMLWriter creates a Writer from a structure and write destination.
+// Rows are wrapped in the parent elements of the configured row path,
+// a single element name is wrapped in a "rows" root element.
+// row path & field names must be valid XML names
+func NewXMLWriter(st *dataset.Structure, w io.Writer) *XMLWriter {
+	opts := xmlOptions(st)
+	path, _ := xmlRowPath(opts.RowElement)
+	switch len(path) {
+	case 0:
+		path = []string{"rows", "row"}
+	case 1:
+		path = []string{"rows", path[0]}
+	}
+
+	wr := &XMLWriter{
+		st:      st,
+		wr:      w,
+		attrs:   opts.AttributeFields,
+		parents: path[:len(path)-1],
+		row:     path[len(path)-1],
+	}
+	for _, name := range path {
+		if !isXMLName(name) {
+			wr.err = fmt.Errorf("invalid xml element name: '%s'", name)
+			return wr
+		}
+	}
+	if st.Schema != nil {
+		for _, f := range st.Schema.Fields {
+			if !isXMLName(f.Name) {
+				wr.err = fmt.Errorf("field name '%s' is not a valid xml name", f.Name)
+				return wr
+			}
+		}
+	}
+	return wr
+}
+
+// isXMLName checks a name against the XML 1.0 Name production
+func isXMLName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for i, c := range name {
+		if !isXMLNameStartChar(c) && (i == 0 || !isXMLNameChar(c)) {
+			return false
+		}
+	}
+	return true
+}
+
+func isXMLNameStartChar(c rune) bool {
+	return c == ':' || c == '_' ||
+		c >= 'A' && c <= 'Z' || c >= 'a' && c <= 'z' ||
+		c >= 0xC0 && c <= 0xD6 || c >= 0xD8 && c <= 0xF6 ||
+		c >= 0xF8 && c <= 0x2FF || c >= 0x370 && c <= 0x37D ||
+		c >= 0x37F && c <= 0x1FFF || c >= 0x200C && c <= 0x200D ||
+		c >= 0x2070 && c <= 0x218F || c >= 0x2C00 && c <= 0x2FEF ||
+		c >= 0x3001 && c <= 0xD7FF || c >= 0xF900 && c <= 0xFDCF ||
+		c >= 0xFDF0 && c <= 0xFFFD || c >= 0x10000 && c <= 0xEFFFF
+}
+
+func isXMLNameChar(c rune) bool {
+	return c == '-' || c == '.' || c >= '0' && c <= '9' || c == 0xB7 ||
+		c >= 0x300 && c <= 0x36F || c >= 0x203F && c <= 0x2040
+}
+
+// Structure gives this writer's structure
+func (w *XMLWriter) Structure() *dataset.Structure {
+	return w.st
+}
+
+// WriteRow writes one row element to the writer.
+// empty values are omitted from the element
+func (w *XMLWriter) WriteRow(row [][]byte) error {
+	if w.err != nil {
+		return w.err
+	}
+	if w.st.Schema == nil {
+		return fmt.Errorf("structure must have a schema to write xml")
+	}
+	if len(row) > len(w.st.Schema.Fields) {
+		return fmt.Errorf("row has %d values, schema only has %d fields", len(row), len(w.st.Schema.Fields))
+	}
+	row = nullCells(w.st, row)
+
+	if w.rowsWritten == 0 {
+		if err := w.writeOpen(); err != nil {
+			return err
+		}
+	}
+
+	enc := &bytes.Buffer{}
+	enc.WriteString("<" + w.row)
+	if w.attrs {
+		for i, c := range row {
+			if len(c) == 0 {
+				continue
+			}
+			enc.WriteString(" " + w.st.Schema.Fields[i].Name + "=\"")
+			xml.EscapeText(enc, c)
+			enc.WriteString("\"")
+		}
+		enc.WriteString("/>\n")
+	} else {
+		enc.WriteString(">")
+		for i, c := range row {
+			if len(c) == 0 {
+				continue
+			}
+			name := w.st.Schema.Fields[i].Name
+			enc.WriteString("<" + name + ">")
+			xml.EscapeText(enc, c)
+			enc.WriteString("</" + name + ">")
+		}
+		enc.WriteString("</" + w.row + ">\n")
+	}
+
+	if _, err := w.wr.Write(enc.Bytes()); err != nil {
+		return fmt.Errorf("error writing xml row: %s", err.Error())
+	}
+	w.rowsWritten++
+	return nil
+}
+
+// writeOpen writes the xml header & opening parent elements
+func (w *XMLWriter) writeOpen() error {
+	enc := &bytes.Buffer{}
+	if name, _, err := charset.Lookup(w.st.Encoding); err == nil && name != charset.UTF8 {
+		enc.WriteString(`<?xml version="1.0" encoding="` + name + `"?>` + "\n")
+	} else {
+		enc.WriteString(xml.Header)
+	}
+	for _, p := range w.parents {
+		enc.WriteString("<" + p + ">\n")
+	}
+	if _, err := w.wr.Write(enc.Bytes()); err != nil {
+		return fmt.Errorf("error writing xml header: %s", err.Error())
+	}
+	return nil
+}
+
+// Close finalizes the writer, indicating no more records
+// will be written
+func (w *XMLWriter) Close() error {
+	if w.err != nil {
+		return w.err
+	}
+	if w.rowsWritten == 0 {
+		if err := w.writeOpen(); err != nil {
+			return err
+		}
+	}
+
+	enc := &bytes.Buffer{}
+	for i := len(w.parents) - 1; i >= 0; i-- {
+		enc.WriteString("</" + w.parents[i] + ">\n")
+	}
+	if _, err := w.wr.Write(enc.Bytes()); err != nil {
+		return fmt.Errorf("error closing writer: %s", err.Error())
+	}
+	return nil
+}
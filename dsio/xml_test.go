@@ -0,0 +1,198 @@
+package dsio
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/dataset/datatypes"
+)
+
+var xmlFields = []*dataset.Field{
+	{Name: "city", Type: datatypes.String},
+	{Name: "pop", Type: datatypes.Integer},
+	{Name: "in_usa", Type: datatypes.Boolean},
+}
+
+func TestXMLReader(t *testing.T) {
+	cases := []struct {
+		opts   *dataset.XMLOptions
+		data   string
+		expect [][][]byte
+		err    string
+	}{
+		{nil, `<rows><row><city>toronto</city><pop>40000000</pop><in_usa>false</in_usa></row></rows>`, [][][]byte{
+			{[]byte("toronto"), []byte("40000000"), []byte("false")},
+		}, ""},
+		{nil, `<rows>
+  <row city="toronto" pop="40000000"><in_usa>false</in_usa></row>
+  <row><pop>8500000</pop><city>new york</city><extra>ignored</extra></row>
+</rows>`, [][][]byte{
+			{[]byte("toronto"), []byte("40000000"), []byte("false")},
+			{[]byte("new york"), []byte("8500000"), []byte{}},
+		}, ""},
+		{&dataset.XMLOptions{RowElement: "cities/city_data"}, `<doc>
+  <meta><city_data city="ignored"/></meta>
+  <cities>
+    <city_data city="chicago" pop="300000" in_usa="true"/>
+    <city_data city="raleigh" pop="250000" in_usa="true"/>
+  </cities>
+</doc>`, [][][]byte{
+			{[]byte("chicago"), []byte("300000"), []byte("true")},
+			{[]byte("raleigh"), []byte("250000"), []byte("true")},
+		}, ""},
+		{&dataset.XMLOptions{RowElement: "/doc/city_data"}, `<doc><city_data city="chatham"/><nested><city_data city="nope"/></nested></doc>`, [][][]byte{
+			{[]byte("chatham"), []byte{}, []byte{}},
+		}, ""},
+		{&dataset.XMLOptions{AttributeFields: true}, `<rows><row city="attr"><city>child</city></row></rows>`, [][][]byte{
+			{[]byte("attr"), []byte{}, []byte{}},
+		}, ""},
+		{&dataset.XMLOptions{}, `<rows><row city="attr"><city>child</city></row></rows>`, [][][]byte{
+			{[]byte("child"), []byte{}, []byte{}},
+		}, ""},
+		{nil, `<rows><row><city>toronto</city>`, nil, "XML syntax error on line 1: unexpected EOF"},
+	}
+
+	for i, c := range cases {
+		st := &dataset.Structure{Format: dataset.XMLDataFormat, Schema: &dataset.Schema{Fields: xmlFields}}
+		if c.opts != nil {
+			st.FormatConfig = c.opts
+		}
+		r := NewXMLReader(st, strings.NewReader(c.data))
+
+		got := [][][]byte{}
+		var err error
+		for {
+			var row [][]byte
+			if row, err = r.ReadRow(); err != nil {
+				break
+			}
+			got = append(got, row)
+		}
+		if err == io.EOF {
+			err = nil
+		}
+		if !(err == nil && c.err == "" || err != nil && err.Error() == c.err) {
+			t.Errorf("case %d error mismatch. expected: '%s', got: '%s'", i, c.err, err)
+			continue
+		}
+		if c.err != "" {
+			continue
+		}
+
+		if len(got) != len(c.expect) {
+			t.Errorf("case %d row count mismatch. expected: %d, got: %d", i, len(c.expect), len(got))
+			continue
+		}
+		for j, row := range got {
+			for k, cell := range row {
+				if !bytes.Equal(cell, c.expect[j][k]) {
+					t.Errorf("case %d row %d cell %d mismatch. expected: '%s', got: '%s'", i, j, k, c.expect[j][k], cell)
+				}
+			}
+		}
+	}
+}
+
+func TestXMLWriter(t *testing.T) {
+	cases := []struct {
+		opts *dataset.XMLOptions
+		rows [][][]byte
+		out  string
+	}{
+		{nil, [][][]byte{}, "<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n<rows>\n</rows>\n"},
+		{nil, [][][]byte{
+			{[]byte("toronto"), []byte("40000000"), []byte("false")},
+			{[]byte("<&>"), {}, []byte("true")},
+		}, `<?xml version="1.0" encoding="UTF-8"?>
+<rows>
+<row><city>toronto</city><pop>40000000</pop><in_usa>false</in_usa></row>
+<row><city>&lt;&amp;&gt;</city><in_usa>true</in_usa></row>
+</rows>
+`},
+		{&dataset.XMLOptions{RowElement: "doc/cities/city", AttributeFields: true}, [][][]byte{
+			{[]byte("\"quoted\""), []byte("1"), {}},
+		}, `<?xml version="1.0" encoding="UTF-8"?>
+<doc>
+<cities>
+<city city="&#34;quoted&#34;" pop="1"/>
+</cities>
+</doc>
+`},
+	}
+
+	for i, c := range cases {
+		st := &dataset.Structure{Format: dataset.XMLDataFormat, Schema: &dataset.Schema{Fields: xmlFields}}
+		if c.opts != nil {
+			st.FormatConfig = c.opts
+		}
+		buf := &bytes.Buffer{}
+		w := NewXMLWriter(st, buf)
+		for _, row := range c.rows {
+			if err := w.WriteRow(row); err != nil {
+				t.Errorf("case %d WriteRow error: %s", i, err.Error())
+			}
+		}
+		if err := w.Close(); err != nil {
+			t.Errorf("case %d Close error: %s", i, err.Error())
+			continue
+		}
+		if buf.String() != c.out {
+			t.Errorf("case %d result mismatch. expected:\n%s\ngot:\n%s", i, c.out, buf.String())
+			continue
+		}
+
+		r := NewXMLReader(st, buf)
+		for j, expect := range c.rows {
+			row, err := r.ReadRow()
+			if err != nil {
+				t.Errorf("case %d row %d read error: %s", i, j, err.Error())
+				break
+			}
+			for k, cell := range row {
+				if !bytes.Equal(cell, expect[k]) {
+					t.Errorf("case %d row %d cell %d round trip mismatch. expected: '%s', got: '%s'", i, j, k, expect[k], cell)
+				}
+			}
+		}
+	}
+}
+
+func TestXMLWriterNames(t *testing.T) {
+	cases := []struct {
+		opts   *dataset.XMLOptions
+		fields []string
+		err    string
+	}{
+		{nil, []string{"city", "_pop", "avg.age", "in-usa", "café", "ns:x"}, ""},
+		{nil, []string{"a b"}, "field name 'a b' is not a valid xml name"},
+		{nil, []string{"1st"}, "field name '1st' is not a valid xml name"},
+		{nil, []string{"x<y"}, "field name 'x<y' is not a valid xml name"},
+		{nil, []string{""}, "field name '' is not a valid xml name"},
+		{&dataset.XMLOptions{RowElement: "doc/my rows/row"}, []string{"city"}, "invalid xml element name: 'my rows'"},
+	}
+
+	for i, c := range cases {
+		fields := make([]*dataset.Field, len(c.fields))
+		row := make([][]byte, len(c.fields))
+		for j, name := range c.fields {
+			fields[j] = &dataset.Field{Name: name, Type: datatypes.String}
+			row[j] = []byte("x")
+		}
+		st := &dataset.Structure{Format: dataset.XMLDataFormat, Schema: &dataset.Schema{Fields: fields}}
+		if c.opts != nil {
+			st.FormatConfig = c.opts
+		}
+		w := NewXMLWriter(st, &bytes.Buffer{})
+		err := w.WriteRow(row)
+		if !(err == nil && c.err == "" || err != nil && err.Error() == c.err) {
+			t.Errorf("case %d error mismatch. expected: '%s', got: '%s'", i, c.err, err)
+		}
+		err = w.Close()
+		if !(err == nil && c.err == "" || err != nil && err.Error() == c.err) {
+			t.Errorf("case %d close error mismatch. expected: '%s', got: '%s'", i, c.err, err)
+		}
+	}
+}
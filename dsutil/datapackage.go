@@ -0,0 +1,558 @@
+package dsutil
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/qri-io/cafs"
+	"github.com/qri-io/cafs/memfs"
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/dataset/datatypes"
+)
+
+// DataPackageFilename is the name of Data Package descriptor files
+const DataPackageFilename = "datapackage.json"
+
+// DataPackage is a Frictionless Data Package descriptor
+// see https://specs.frictionlessdata.io/data-package/
+type DataPackage struct {
+	Profile      string                    `json:"profile,omitempty"`
+	Name         string                    `json:"name,omitempty"`
+	Title        string                    `json:"title,omitempty"`
+	Description  string                    `json:"description,omitempty"`
+	Homepage     string                    `json:"homepage,omitempty"`
+	Version      string                    `json:"version,omitempty"`
+	Image        string                    `json:"image,omitempty"`
+	Keywords     []string                  `json:"keywords,omitempty"`
+	Created      string                    `json:"created,omitempty"`
+	Licenses     []*DataPackageLicense     `json:"licenses,omitempty"`
+	Contributors []*DataPackageContributor `json:"contributors,omitempty"`
+	Sources      []*DataPackageSource      `json:"sources,omitempty"`
+	Resources    []*DataPackageResource    `json:"resources"`
+}
+
+// DataPackageLicense is a license that applies to a package
+type DataPackageLicense struct {
+	Name  string `json:"name,omitempty"`
+	Path  string `json:"path,omitempty"`
+	Title string `json:"title,omitempty"`
+}
+
+// DataPackageContributor is a person or organization that contributed
+// to a package
+type DataPackageContributor struct {
+	Title string `json:"title"`
+	Email string `json:"email,omitempty"`
+	Path  string `json:"path,omitempty"`
+	Role  string `json:"role,omitempty"`
+}
+
+// DataPackageSource is a raw source of package data
+type DataPackageSource struct {
+	Title string `json:"title"`
+	Path  string `json:"path,omitempty"`
+	Email string `json:"email,omitempty"`
+}
+
+// DataPackageResource describes a single data file of a package
+type DataPackageResource struct {
+	Profile     string              `json:"profile,omitempty"`
+	Name        string              `json:"name"`
+	Path        string              `json:"path"`
+	Title       string              `json:"title,omitempty"`
+	Description string              `json:"description,omitempty"`
+	Format      string              `json:"format,omitempty"`
+	Mediatype   string              `json:"mediatype,omitempty"`
+	Encoding    string              `json:"encoding,omitempty"`
+	Bytes       int                 `json:"bytes,omitempty"`
+	Dialect     *DataPackageDialect `json:"dialect,omitempty"`
+	Schema      *TableSchema        `json:"schema,omitempty"`
+}
+
+// DataPackageDialect is a CSV Dialect descriptor
+// see https://specs.frictionlessdata.io/csv-dialect/
+type DataPackageDialect struct {
+	Delimiter        string `json:"delimiter,omitempty"`
+	DoubleQuote      *bool  `json:"doubleQuote,omitempty"`
+	EscapeChar       string `json:"escapeChar,omitempty"`
+	LineTerminator   string `json:"lineTerminator,omitempty"`
+	QuoteChar        string `json:"quoteChar,omitempty"`
+	SkipInitialSpace bool   `json:"skipInitialSpace,omitempty"`
+	Header           *bool  `json:"header,omitempty"`
+	CommentChar      string `json:"commentChar,omitempty"`
+}
+
+// TableSchema is a Frictionless Table Schema
+// see https://specs.frictionlessdata.io/table-schema/
+type TableSchema struct {
+	Fields []*TableSchemaField `json:"fields"`
+	// MissingValues are the raw values read as null in every field.
+	// nil means the spec's default of [""]
+	MissingValues []string                 `json:"missingValues,omitempty"`
+	PrimaryKey    dataset.FieldKey         `json:"primaryKey,omitempty"`
+	ForeignKeys   []*TableSchemaForeignKey `json:"foreignKeys,omitempty"`
+}
+
+// TableSchemaField describes a single column of a table
+type TableSchemaField struct {
+	Name        string                    `json:"name"`
+	Title       string                    `json:"title,omitempty"`
+	Description string                    `json:"description,omitempty"`
+	Type        string                    `json:"type,omitempty"`
+	Format      string                    `json:"format,omitempty"`
+	Constraints *dataset.FieldConstraints `json:"constraints,omitempty"`
+	DecimalChar string                    `json:"decimalChar,omitempty"`
+	GroupChar   string                    `json:"groupChar,omitempty"`
+	BareNumber  *bool                     `json:"bareNumber,omitempty"`
+	// Decimal marks numbers exported from decimal fields with the field's
+	// "precision,scale" format, or "default" for decimals without one. it
+	// isn't part of the table schema spec, other tools ignore it
+	Decimal string `json:"decimal,omitempty"`
+}
+
+// TableSchemaForeignKey references fields of the same or another resource
+type TableSchemaForeignKey struct {
+	Fields    dataset.FieldKey      `json:"fields"`
+	Reference *TableSchemaReference `json:"reference"`
+}
+
+// TableSchemaReference is the target of a foreign key. an empty Resource
+// references the resource the key belongs to
+type TableSchemaReference struct {
+	Resource string           `json:"resource"`
+	Fields   dataset.FieldKey `json:"fields"`
+}
+
+// tableSchemaTypes maps datatypes to table schema types
+var tableSchemaTypes = map[datatypes.Type]string{
+	datatypes.Any:      "any",
+	datatypes.String:   "string",
+	datatypes.Integer:  "integer",
+	datatypes.Float:    "number",
+	datatypes.Decimal:  "number",
+	datatypes.Boolean:  "boolean",
+	datatypes.Date:     "date",
+	datatypes.URL:      "string",
+	datatypes.JSON:     "any",
+	datatypes.Time:     "time",
+	datatypes.DateTime: "datetime",
+	datatypes.Duration: "duration",
+	datatypes.GeoPoint: "geopoint",
+	datatypes.GeoJSON:  "geojson",
+	datatypes.Array:    "array",
+	datatypes.Object:   "object",
+}
+
+// nonNameChars are characters not permitted in package & resource names
+var nonNameChars = regexp.MustCompile(`[^-a-z0-9._]+`)
+
+// NewDataPackage creates a Data Package descriptor for a dataset, with a
+// single resource for the dataset's data. the resource path is the data
+// filename WriteDir uses
+func NewDataPackage(ds *dataset.Dataset) (*DataPackage, error) {
+	if ds.Structure == nil {
+		return nil, fmt.Errorf("dataset must have a structure")
+	}
+
+	name := strings.Trim(nonNameChars.ReplaceAllString(strings.ToLower(ds.Title), "-"), "-")
+	if name == "" {
+		name = "dataset"
+	}
+	dp := &DataPackage{
+		Profile:     "data-package",
+		Name:        name,
+		Title:       ds.Title,
+		Description: ds.Description,
+		Homepage:    ds.Homepage,
+		Version:     ds.Version,
+		Image:       ds.Image,
+		Keywords:    ds.Keywords,
+	}
+	if !ds.Timestamp.IsZero() {
+		dp.Created = ds.Timestamp.UTC().Format(time.RFC3339)
+	}
+	if ds.License != nil {
+		dp.Licenses = []*DataPackageLicense{{Name: ds.License.Type, Path: ds.License.URL}}
+	}
+	if ds.Author != nil {
+		dp.Contributors = append(dp.Contributors, packageContributor(ds.Author, "author"))
+	}
+	for _, u := range ds.Contributors {
+		dp.Contributors = append(dp.Contributors, packageContributor(u, "contributor"))
+	}
+	for _, c := range ds.Citations {
+		dp.Sources = append(dp.Sources, &DataPackageSource{Title: c.Name, Path: c.URL, Email: c.Email})
+	}
+
+	st := ds.Structure
+	res := &DataPackageResource{
+		Profile:  "data-resource",
+		Name:     name,
+		Path:     dataFilename(ds),
+		Format:   st.Format.String(),
+		Encoding: st.Encoding,
+		Bytes:    ds.Length,
+	}
+	if st.Format == dataset.CSVDataFormat {
+		res.Mediatype = "text/csv"
+		res.Dialect = packageDialect(st.FormatConfig)
+	}
+	if st.Schema != nil {
+		ts, err := packageSchema(st)
+		if err != nil {
+			return nil, err
+		}
+		res.Schema = ts
+		// tabular resources are csv files with a table schema
+		if st.Format == dataset.CSVDataFormat {
+			dp.Profile, res.Profile = "tabular-data-package", "tabular-data-resource"
+		}
+	}
+	dp.Resources = []*DataPackageResource{res}
+	return dp, nil
+}
+
+// packageContributor converts a user to a package contributor
+func packageContributor(u *dataset.User, role string) *DataPackageContributor {
+	title := u.Fullname
+	if title == "" {
+		title = u.ID
+	}
+	return &DataPackageContributor{Title: title, Email: u.Email, Role: role}
+}
+
+// packageDialect converts csv options to a dialect. the default dialect
+// has a header row & "\r\n" line endings, so both are always written
+func packageDialect(fc dataset.FormatConfig) *DataPackageDialect {
+	opts, ok := fc.(*dataset.CSVOptions)
+	if !ok || opts == nil {
+		opts = &dataset.CSVOptions{}
+	}
+	header := opts.HeaderRow
+	d := &DataPackageDialect{
+		Delimiter:        opts.Delimiter,
+		QuoteChar:        opts.Quote,
+		CommentChar:      opts.Comment,
+		SkipInitialSpace: opts.TrimLeadingSpace,
+		LineTerminator:   opts.LineTerminator,
+		Header:           &header,
+	}
+	if d.LineTerminator == "" {
+		d.LineTerminator = "\n"
+	}
+	return d
+}
+
+// packageSchema converts a structure's schema to a table schema. table
+// schemas only have missing values for all fields, so the structure's &
+// each field's missing values are combined
+func packageSchema(st *dataset.Structure) (*TableSchema, error) {
+	sch := st.Schema
+	ts := &TableSchema{
+		Fields:     make([]*TableSchemaField, len(sch.Fields)),
+		PrimaryKey: sch.PrimaryKey,
+	}
+
+	missing := append([]string{""}, st.MissingValues...)
+	for i, f := range sch.Fields {
+		tf := &TableSchemaField{
+			Name:        f.Name,
+			Title:       f.Title,
+			Description: f.Description,
+			Type:        tableSchemaTypes[f.Type],
+			Constraints: f.Constraints,
+			BareNumber:  f.BareNumber,
+		}
+		switch f.Type {
+		case datatypes.Date, datatypes.Time, datatypes.DateTime:
+			tf.Format = f.Format
+		case datatypes.URL:
+			tf.Format = "uri"
+		case datatypes.Integer, datatypes.Float, datatypes.Decimal:
+			// table schemas have no locales, so locale number formats are
+			// written as explicit characters
+			nf, err := f.NumberFormat()
+			if err != nil {
+				return nil, fmt.Errorf("field %s: %s", f.Name, err.Error())
+			}
+			if nf.DecimalChar != "" && nf.DecimalChar != "." {
+				tf.DecimalChar = nf.DecimalChar
+			}
+			tf.GroupChar = nf.GroupChar
+			if f.Type == datatypes.Decimal {
+				tf.Decimal = "default"
+				if f.Format != "" {
+					tf.Decimal = f.Format
+				}
+			}
+		}
+		for _, mv := range f.MissingValues() {
+			if !containsString(missing, mv) {
+				missing = append(missing, mv)
+			}
+		}
+		ts.Fields[i] = tf
+	}
+	if len(missing) > 1 {
+		ts.MissingValues = missing
+	}
+
+	for _, fk := range sch.ForeignKeys {
+		tfk := &TableSchemaForeignKey{Fields: fk.Fields, Reference: &TableSchemaReference{}}
+		if fk.Reference != nil {
+			tfk.Reference.Resource = fk.Reference.Dataset
+			tfk.Reference.Fields = fk.Reference.Fields
+		}
+		ts.ForeignKeys = append(ts.ForeignKeys, tfk)
+	}
+	return ts, nil
+}
+
+// DataPackageDataset creates a dataset from a Data Package resource, named
+// by resource. the empty string selects the first resource. foreign keys
+// that reference other resources of the package keep the resource name as
+// their referenced dataset
+func DataPackageDataset(dp *DataPackage, resource string) (*dataset.Dataset, error) {
+	var res *DataPackageResource
+	for _, r := range dp.Resources {
+		if resource == "" || r.Name == resource {
+			res = r
+			break
+		}
+	}
+	if res == nil {
+		if resource == "" {
+			return nil, fmt.Errorf("data package has no resources")
+		}
+		return nil, fmt.Errorf("resource '%s' not found", resource)
+	}
+
+	ds := &dataset.Dataset{
+		Title:       dp.Title,
+		Description: dp.Description,
+		Homepage:    dp.Homepage,
+		Version:     dp.Version,
+		Image:       dp.Image,
+		Keywords:    dp.Keywords,
+	}
+	if ds.Title == "" {
+		ds.Title = dp.Name
+	}
+	if dp.Created != "" {
+		if t, err := time.Parse(time.RFC3339, dp.Created); err == nil {
+			ds.Timestamp = t
+		}
+	}
+	if len(dp.Licenses) > 0 {
+		l := dp.Licenses[0]
+		ds.License = &dataset.License{Type: l.Name, URL: l.Path}
+		if ds.License.Type == "" {
+			ds.License.Type = l.Title
+		}
+	}
+	for _, c := range dp.Contributors {
+		u := &dataset.User{Fullname: c.Title, Email: c.Email}
+		if c.Role == "author" && ds.Author == nil {
+			ds.Author = u
+		} else {
+			ds.Contributors = append(ds.Contributors, u)
+		}
+	}
+	for _, s := range dp.Sources {
+		ds.Citations = append(ds.Citations, &dataset.Citation{Name: s.Title, URL: s.Path, Email: s.Email})
+	}
+
+	st, err := resourceStructure(res)
+	if err != nil {
+		return nil, fmt.Errorf("resource %s: %s", res.Name, err.Error())
+	}
+	ds.Structure = st
+	ds.Length = res.Bytes
+	return ds, nil
+}
+
+// resourceStructure creates a structure from a resource's format, dialect
+// & schema
+func resourceStructure(res *DataPackageResource) (*dataset.Structure, error) {
+	format := res.Format
+	if format == "" {
+		format = filepath.Ext(res.Path)
+	}
+	df, err := dataset.ParseDataFormatString(strings.ToLower(format))
+	if err != nil {
+		return nil, err
+	}
+	st := &dataset.Structure{Format: df, Encoding: res.Encoding}
+
+	if df == dataset.CSVDataFormat {
+		opts := &dataset.CSVOptions{HeaderRow: true}
+		if d := res.Dialect; d != nil {
+			if d.DoubleQuote != nil && !*d.DoubleQuote || d.EscapeChar != "" {
+				return nil, fmt.Errorf("dialects with escape characters are not supported")
+			}
+			if d.Header != nil {
+				opts.HeaderRow = *d.Header
+			}
+			opts.Delimiter, opts.Quote, opts.Comment = d.Delimiter, d.QuoteChar, d.CommentChar
+			opts.TrimLeadingSpace = d.SkipInitialSpace
+			opts.LineTerminator = d.LineTerminator
+			if opts.Delimiter == "," {
+				opts.Delimiter = ""
+			}
+			if opts.Quote == `"` {
+				opts.Quote = ""
+			}
+			if err := opts.Validate(); err != nil {
+				return nil, err
+			}
+		}
+		st.FormatConfig = opts
+	}
+
+	if res.Schema != nil {
+		if st.Schema, err = tableSchemaSchema(res.Schema); err != nil {
+			return nil, err
+		}
+		// the empty string is always a missing value, so only other markers
+		// are kept
+		for _, mv := range res.Schema.MissingValues {
+			if mv != "" {
+				st.MissingValues = append(st.MissingValues, mv)
+			}
+		}
+	}
+	return st, nil
+}
+
+// tableSchemaSchema converts a table schema to a schema. numbers are read
+// as floats unless they're marked as decimals
+func tableSchemaSchema(ts *TableSchema) (*dataset.Schema, error) {
+	sch := &dataset.Schema{
+		Fields:     make([]*dataset.Field, len(ts.Fields)),
+		PrimaryKey: ts.PrimaryKey,
+	}
+
+	for i, tf := range ts.Fields {
+		f := &dataset.Field{
+			Name:        tf.Name,
+			Title:       tf.Title,
+			Description: tf.Description,
+			Constraints: tf.Constraints,
+			DecimalChar: tf.DecimalChar,
+			GroupChar:   tf.GroupChar,
+			BareNumber:  tf.BareNumber,
+		}
+		format := strings.TrimPrefix(tf.Format, "fmt:")
+		switch tf.Type {
+		case "", "string":
+			f.Type = datatypes.String
+			if format == "uri" {
+				f.Type = datatypes.URL
+			}
+		case "number":
+			// table schema numbers have arbitrary precision. only numbers
+			// exported from decimal fields are read as decimals, others
+			// are floats, which round values with more than 15 significant digits
+			f.Type = datatypes.Float
+			if tf.Decimal != "" {
+				f.Type = datatypes.Decimal
+				if tf.Decimal != "default" {
+					if _, err := datatypes.ParseDecimalFormat(tf.Decimal); err != nil {
+						return nil, fmt.Errorf("field %s: %s", tf.Name, err.Error())
+					}
+					f.Format = tf.Decimal
+				}
+			}
+		case "integer", "year":
+			f.Type = datatypes.Integer
+		case "yearmonth":
+			f.Type = datatypes.String
+		default:
+			f.Type = datatypes.TypeFromString(tf.Type)
+			if f.Type == datatypes.Unknown {
+				return nil, fmt.Errorf("field %s: unsupported type '%s'", tf.Name, tf.Type)
+			}
+		}
+		if (f.Type == datatypes.Date || f.Type == datatypes.Time || f.Type == datatypes.DateTime) && format != "default" {
+			f.Format = format
+		}
+		sch.Fields[i] = f
+	}
+
+	for _, tfk := range ts.ForeignKeys {
+		fk := &dataset.ForeignKey{Fields: tfk.Fields}
+		if tfk.Reference != nil {
+			fk.Reference = &dataset.ForeignKeyReference{Dataset: tfk.Reference.Resource, Fields: tfk.Reference.Fields}
+		}
+		sch.ForeignKeys = append(sch.ForeignKeys, fk)
+	}
+	return sch, nil
+}
+
+// ReadDataPackageDir reads a Data Package from a directory containing a
+// datapackage.json descriptor, adding the data of the first resource to
+// store. the returned dataset's Data is the stored data path
+func ReadDataPackageDir(store cafs.Filestore, path string) (*dataset.Dataset, error) {
+	data, err := ioutil.ReadFile(filepath.Join(path, DataPackageFilename))
+	if err != nil {
+		return nil, err
+	}
+	dp := &DataPackage{}
+	if err := json.Unmarshal(data, dp); err != nil {
+		return nil, fmt.Errorf("error reading %s: %s", DataPackageFilename, err.Error())
+	}
+	ds, err := DataPackageDataset(dp, "")
+	if err != nil {
+		return nil, err
+	}
+
+	res := dp.Resources[0]
+	if strings.Contains(res.Path, "://") || filepath.IsAbs(res.Path) || strings.HasPrefix(filepath.Clean(res.Path), "..") {
+		return nil, fmt.Errorf("resource path must be relative to the package: %s", res.Path)
+	}
+	raw, err := ioutil.ReadFile(filepath.Join(path, res.Path))
+	if err != nil {
+		return nil, err
+	}
+	key, err := store.Put(memfs.NewMemfileBytes(filepath.Base(res.Path), raw), false)
+	if err != nil {
+		return nil, fmt.Errorf("error adding data to store: %s", err.Error())
+	}
+	ds.Data = key.String()
+	ds.Length = len(raw)
+	return ds, nil
+}
+
+// writeDataPackage writes a Data Package descriptor for ds to a directory
+func writeDataPackage(ds *dataset.Dataset, path string) error {
+	dp, err := NewDataPackage(ds)
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(dp, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filepath.Join(path, DataPackageFilename), data, os.ModePerm)
+}
+
+// dataFilename gives the name data files are written to in packages
+func dataFilename(ds *dataset.Dataset) string {
+	return fmt.Sprintf("data.%s", ds.Structure.Format.String())
+}
+
+// containsString reports weather a slice contains str
+func containsString(s []string, str string) bool {
+	for _, v := range s {
+		if v == str {
+			return true
+		}
+	}
+	return false
+}
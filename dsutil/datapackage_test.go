@@ -0,0 +1,238 @@
+package dsutil
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/qri-io/cafs/memfs"
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/dataset/datatypes"
+	"github.com/qri-io/dataset/dsfs"
+)
+
+func TestNewDataPackage(t *testing.T) {
+	yes := true
+	ds := &dataset.Dataset{
+		Title:     "World Cities",
+		Timestamp: time.Date(2018, 1, 2, 3, 4, 5, 0, time.UTC),
+		Keywords:  []string{"cities"},
+		License:   &dataset.License{Type: "CC-BY-4.0"},
+		Author:    &dataset.User{Fullname: "ada", Email: "ada@example.com"},
+		Citations: []*dataset.Citation{{Name: "census", URL: "https://example.com/census"}},
+		Length:    42,
+		Structure: &dataset.Structure{
+			Format:       dataset.CSVDataFormat,
+			FormatConfig: &dataset.CSVOptions{HeaderRow: true, Delimiter: ";"},
+			Schema: &dataset.Schema{
+				Fields: []*dataset.Field{
+					{Name: "id", Type: datatypes.Integer, Constraints: &dataset.FieldConstraints{Required: &yes}},
+					{Name: "pop", Type: datatypes.Float, Locale: "de", MissingValue: "NA"},
+					{Name: "area", Type: datatypes.Decimal, Format: "30,10"},
+					{Name: "gdp", Type: datatypes.Decimal},
+					{Name: "founded", Type: datatypes.Date, Format: "%d/%m/%Y"},
+					{Name: "site", Type: datatypes.URL},
+					{Name: "country", Type: datatypes.String},
+				},
+				PrimaryKey: dataset.FieldKey{"id"},
+				ForeignKeys: []*dataset.ForeignKey{
+					{Fields: dataset.FieldKey{"country"}, Reference: &dataset.ForeignKeyReference{Dataset: "countries", Fields: dataset.FieldKey{"code"}}},
+				},
+			},
+		},
+	}
+
+	dp, err := NewDataPackage(ds)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+	got, err := json.Marshal(dp)
+	if err != nil {
+		t.Fatalf("error marshaling data package: %s", err.Error())
+	}
+	expect := `{"profile":"tabular-data-package","name":"world-cities","title":"World Cities","keywords":["cities"],"created":"2018-01-02T03:04:05Z",` +
+		`"licenses":[{"name":"CC-BY-4.0"}],"contributors":[{"title":"ada","email":"ada@example.com","role":"author"}],` +
+		`"sources":[{"title":"census","path":"https://example.com/census"}],` +
+		`"resources":[{"profile":"tabular-data-resource","name":"world-cities","path":"data.csv","format":"csv","mediatype":"text/csv","bytes":42,` +
+		`"dialect":{"delimiter":";","lineTerminator":"\n","header":true},` +
+		`"schema":{"fields":[` +
+		`{"name":"id","type":"integer","constraints":{"required":true}},` +
+		`{"name":"pop","type":"number","decimalChar":",","groupChar":"."},` +
+		`{"name":"area","type":"number","decimal":"30,10"},` +
+		`{"name":"gdp","type":"number","decimal":"default"},` +
+		`{"name":"founded","type":"date","format":"%d/%m/%Y"},` +
+		`{"name":"site","type":"string","format":"uri"},` +
+		`{"name":"country","type":"string"}],` +
+		`"missingValues":["","NA"],"primaryKey":["id"],` +
+		`"foreignKeys":[{"fields":["country"],"reference":{"resource":"countries","fields":["code"]}}]}}]}`
+	if string(got) != expect {
+		t.Errorf("data package mismatch. expected:\n%s\ngot:\n%s", expect, string(got))
+	}
+
+	// decimals survive a round trip, plain numbers are floats
+	data, err := json.Marshal(dp)
+	if err != nil {
+		t.Fatalf("error marshaling data package: %s", err.Error())
+	}
+	rdp := &DataPackage{}
+	if err := json.Unmarshal(data, rdp); err != nil {
+		t.Fatalf("error unmarshaling data package: %s", err.Error())
+	}
+	rds, err := DataPackageDataset(rdp, "")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+	for i, f := range ds.Structure.Schema.Fields[1:4] {
+		got := rds.Structure.Schema.Fields[i+1]
+		if got.Name != f.Name || got.Type != f.Type || got.Format != f.Format {
+			t.Errorf("field %d round trip mismatch. expected: %s %s '%s', got: %s %s '%s'", i+1, f.Name, f.Type, f.Format, got.Name, got.Type, got.Format)
+		}
+	}
+
+	if _, err := NewDataPackage(&dataset.Dataset{}); err == nil || err.Error() != "dataset must have a structure" {
+		t.Errorf("expected missing structure error, got: %v", err)
+	}
+}
+
+func TestDataPackageDataset(t *testing.T) {
+	doc := `{
+		"name": "gdp",
+		"licenses": [{"name": "ODC-PDDL-1.0", "path": "http://opendatacommons.org/licenses/pddl/"}],
+		"contributors": [{"title": "ada", "role": "author"}, {"title": "grace"}],
+		"resources": [{
+			"name": "gdp",
+			"path": "data/gdp.csv",
+			"dialect": {"delimiter": "\t", "header": false, "lineTerminator": "\r\n"},
+			"schema": {
+				"fields": [
+					{"name": "country", "type": "string"},
+					{"name": "year", "type": "year"},
+					{"name": "value", "type": "number", "decimalChar": ",", "bareNumber": false},
+					{"name": "updated", "type": "datetime", "format": "default"},
+					{"name": "day", "type": "date", "format": "fmt:%d/%m/%Y"},
+					{"name": "parent", "type": "string", "constraints": {"enum": ["a", "b"]}}
+				],
+				"missingValues": ["", "-", "n/a"],
+				"primaryKey": "country",
+				"foreignKeys": [{"fields": "parent", "reference": {"resource": "", "fields": "country"}}]
+			}
+		}]
+	}`
+	dp := &DataPackage{}
+	if err := json.Unmarshal([]byte(doc), dp); err != nil {
+		t.Fatalf("error unmarshaling data package: %s", err.Error())
+	}
+	ds, err := DataPackageDataset(dp, "gdp")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+
+	if ds.Title != "gdp" || ds.License.Type != "ODC-PDDL-1.0" || ds.Author.Fullname != "ada" || len(ds.Contributors) != 1 {
+		t.Errorf("metadata mismatch: %s %v %v %v", ds.Title, ds.License, ds.Author, ds.Contributors)
+	}
+	st := ds.Structure
+	opts, ok := st.FormatConfig.(*dataset.CSVOptions)
+	if st.Format != dataset.CSVDataFormat || !ok || opts.HeaderRow || opts.Delimiter != "\t" || opts.LineTerminator != "\r\n" {
+		t.Errorf("format mismatch: %s %v", st.Format, st.FormatConfig)
+	}
+
+	expect := []struct {
+		name   string
+		typ    datatypes.Type
+		format string
+	}{
+		{"country", datatypes.String, ""},
+		{"year", datatypes.Integer, ""},
+		{"value", datatypes.Float, ""},
+		{"updated", datatypes.DateTime, ""},
+		{"day", datatypes.Date, "%d/%m/%Y"},
+		{"parent", datatypes.String, ""},
+	}
+	for i, e := range expect {
+		f := st.Schema.Fields[i]
+		if f.Name != e.name || f.Type != e.typ || f.Format != e.format {
+			t.Errorf("field %d mismatch. expected: %s %s '%s', got: %s %s '%s'", i, e.name, e.typ, e.format, f.Name, f.Type, f.Format)
+		}
+	}
+	if mv := st.MissingValues; len(mv) != 2 || mv[0] != "-" || mv[1] != "n/a" {
+		t.Errorf("missing values mismatch: %v", mv)
+	}
+	if f := st.Schema.Fields[2]; f.DecimalChar != "," || f.BareNumber == nil || *f.BareNumber {
+		t.Errorf("number format mismatch: %s %v", f.DecimalChar, f.BareNumber)
+	}
+	if len(st.Schema.Fields[5].Constraints.Enum) != 2 {
+		t.Errorf("expected enum constraint")
+	}
+	if len(st.Schema.PrimaryKey) != 1 || st.Schema.PrimaryKey[0] != "country" {
+		t.Errorf("primary key mismatch: %v", st.Schema.PrimaryKey)
+	}
+	if fk := st.Schema.ForeignKeys[0]; fk.Fields[0] != "parent" || fk.Reference.Dataset != "" || fk.Reference.Fields[0] != "country" {
+		t.Errorf("foreign key mismatch: %v %v", fk.Fields, fk.Reference)
+	}
+
+	cases := []struct {
+		doc, resource, err string
+	}{
+		{`{"resources":[]}`, "", "data package has no resources"},
+		{`{"resources":[{"name":"a","path":"a.csv"}]}`, "b", "resource 'b' not found"},
+		{`{"resources":[{"name":"a","path":"a.csv","dialect":{"doubleQuote":false,"escapeChar":"\\"}}]}`, "", "resource a: dialects with escape characters are not supported"},
+		{`{"resources":[{"name":"a","path":"a.csv","schema":{"fields":[{"name":"x","type":"blob"}]}}]}`, "", "resource a: field x: unsupported type 'blob'"},
+		{`{"resources":[{"name":"a","path":"a.csv","schema":{"fields":[{"name":"x","type":"number","decimal":"2,5"}]}}]}`, "", "resource a: field x: invalid decimal format: '2,5'. scale must be between 0 and precision"},
+	}
+	for i, c := range cases {
+		dp := &DataPackage{}
+		if err := json.Unmarshal([]byte(c.doc), dp); err != nil {
+			t.Fatalf("case %d error unmarshaling: %s", i, err.Error())
+		}
+		_, err := DataPackageDataset(dp, c.resource)
+		if !(err == nil && c.err == "" || err != nil && err.Error() == c.err) {
+			t.Errorf("case %d error mismatch. expected: '%s', got: '%s'", i, c.err, err)
+		}
+	}
+}
+
+func TestDataPackageDir(t *testing.T) {
+	store, names, err := testStore()
+	if err != nil {
+		t.Fatalf("error creating store: %s", err.Error())
+	}
+	ds, err := dsfs.LoadDataset(store, names["movies"])
+	if err != nil {
+		t.Fatalf("error fetching movies dataset from store: %s", err.Error())
+	}
+	ds.Title = "movies"
+
+	dir, err := ioutil.TempDir("", "dsutil_test_data_package")
+	if err != nil {
+		t.Fatalf("error creating temp directory: %s", err.Error())
+	}
+	defer os.RemoveAll(dir)
+
+	if err = WriteDir(store, ds, dir, func(cfg *WriteDirCfg) { cfg.DataPackage = true }); err != nil {
+		t.Fatalf("error writing directory: %s", err.Error())
+	}
+	if _, err := os.Stat(filepath.Join(dir, DataPackageFilename)); err != nil {
+		t.Fatalf("expected %s to be written: %s", DataPackageFilename, err.Error())
+	}
+
+	got, err := ReadDataPackageDir(memfs.NewMapstore(), dir)
+	if err != nil {
+		t.Fatalf("error reading data package: %s", err.Error())
+	}
+	if got.Title != "movies" || got.Data == "" || got.Length != len("movie\nup\nthe incredibles") {
+		t.Errorf("dataset mismatch: %s %s %d", got.Title, got.Data, got.Length)
+	}
+	if err := dataset.CompareSchemas(ds.Structure.Schema, got.Structure.Schema); err != nil {
+		t.Errorf("schema mismatch: %s", err.Error())
+	}
+
+	if err := ioutil.WriteFile(filepath.Join(dir, DataPackageFilename), []byte(`{"resources":[{"name":"a","path":"../a.csv"}]}`), os.ModePerm); err != nil {
+		t.Fatal(err.Error())
+	}
+	if _, err := ReadDataPackageDir(memfs.NewMapstore(), dir); err == nil || err.Error() != "resource path must be relative to the package: ../a.csv" {
+		t.Errorf("expected resource path error, got: %v", err)
+	}
+}
@@ -61,8 +61,20 @@ func WriteZipArchive(store cafs.Filestore, ds *dataset.Dataset, w io.Writer) err
 	return zw.Close()
 }
 
+// WriteDirCfg configures WriteDir
+type WriteDirCfg struct {
+	// DataPackage writes a datapackage.json Data Package descriptor
+	// alongside the dataset
+	DataPackage bool
+}
+
 // WriteDir loads a dataset & writes all contents to a directory specified by path
-func WriteDir(store cafs.Filestore, ds *dataset.Dataset, path string) error {
+func WriteDir(store cafs.Filestore, ds *dataset.Dataset, path string, config ...func(cfg *WriteDirCfg)) error {
+	cfg := &WriteDirCfg{}
+	for _, opt := range config {
+		opt(cfg)
+	}
+
 	if err := os.MkdirAll(path, os.ModePerm); err != nil {
 		return err
 	}
@@ -81,13 +93,17 @@ func WriteDir(store cafs.Filestore, ds *dataset.Dataset, path string) error {
 		return err
 	}
 
-	datadst, err := os.Create(filepath.Join(path, fmt.Sprintf("data.%s", ds.Structure.Format.String())))
+	datadst, err := os.Create(filepath.Join(path, dataFilename(ds)))
 	if err != nil {
 		return err
 	}
+	defer datadst.Close()
 	if _, err = io.Copy(datadst, datasrc); err != nil {
 		return err
 	}
 
+	if cfg.DataPackage {
+		return writeDataPackage(ds, path)
+	}
 	return nil
 }
@@ -2,8 +2,9 @@ package generate
 
 import (
 	"bytes"
-	"encoding/csv"
+
 	"github.com/qri-io/dataset"
+	"github.com/qri-io/dataset/dsio"
 )
 
 // RandomDataOpts configures RandomData output
@@ -23,12 +24,38 @@ func RandomData(st *dataset.Structure, opts ...func(o *RandomDataOpts)) []byte {
 	for _, option := range opts {
 		option(opt)
 	}
-	if opt.NumRandRecords == 0 || opt.Format != dataset.CSVDataFormat {
+	if opt.NumRandRecords == 0 {
 		return nil
 	}
 
 	buf := bytes.NewBuffer(opt.Data)
-	if err := csv.NewWriter(buf).WriteAll(RandomStringRows(st.Schema.Fields, opt.NumRandRecords)); err != nil {
+	var w dsio.RowWriter
+	switch opt.Format {
+	case dataset.CSVDataFormat:
+		// csv header rows are never generated
+		csvst := &dataset.Structure{Format: dataset.CSVDataFormat, Schema: st.Schema}
+		if o, ok := st.FormatConfig.(*dataset.CSVOptions); ok && o != nil {
+			dialect := *o
+			dialect.HeaderRow = false
+			csvst.FormatConfig = &dialect
+		}
+		w = dsio.NewCSVWriter(csvst, buf)
+	case dataset.NDJSONDataFormat:
+		w = dsio.NewNDJSONWriter(st, buf)
+	default:
+		return nil
+	}
+
+	for _, row := range RandomStringRows(st.Schema.Fields, opt.NumRandRecords) {
+		rec := make([][]byte, len(row))
+		for i, cell := range row {
+			rec[i] = []byte(cell)
+		}
+		if err := w.WriteRow(rec); err != nil {
+			panic(err)
+		}
+	}
+	if err := w.Close(); err != nil {
 		panic(err)
 	}
 
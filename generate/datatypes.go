@@ -1,6 +1,7 @@
 package generate
 
 import (
+	"math/big"
 	"math/rand"
 	"net/url"
 	"time"
@@ -19,6 +20,8 @@ func RandomValue(t datatypes.Type) interface{} {
 		return randString(rand.Intn(100))
 	case datatypes.Float:
 		return rand.Float32()
+	case datatypes.Decimal:
+		return big.NewRat(rand.Int63(), 100)
 	case datatypes.Integer:
 		return rand.Int()
 	case datatypes.Boolean:
@@ -28,8 +31,22 @@ func RandomValue(t datatypes.Type) interface{} {
 			return map[string]interface{}{}
 		}
 		return []interface{}{}
+	case datatypes.Array:
+		return []interface{}{rand.Intn(100), randString(6)}
+	case datatypes.Object:
+		return map[string]interface{}{randString(6): rand.Intn(100)}
 	case datatypes.Date:
+		return time.Now().UTC().Truncate(time.Hour*24).AddDate(0, 0, rand.Intn(30)+1)
+	case datatypes.Time:
+		return time.Date(0, 1, 1, rand.Intn(24), rand.Intn(60), rand.Intn(60), 0, time.UTC)
+	case datatypes.DateTime:
 		return time.Now().Add(time.Hour * 24 * time.Duration(rand.Intn(30)+1))
+	case datatypes.Duration:
+		return time.Duration(rand.Int63n(int64(time.Hour * 24 * 30)))
+	case datatypes.GeoPoint:
+		return datatypes.Point{Lat: rand.Float64()*180 - 90, Lon: rand.Float64()*360 - 180}
+	case datatypes.GeoJSON:
+		return &datatypes.Geometry{Type: "Point", Coordinates: []interface{}{rand.Float64()*360 - 180, rand.Float64()*180 - 90}}
 	case datatypes.URL:
 		return &url.URL{
 			Scheme: "http",
@@ -56,6 +73,8 @@ func RandomStringValue(t datatypes.Type) string {
 	case datatypes.Integer:
 		str, _ := datatypes.Integer.ValueToString(rand.Int())
 		return str
+	case datatypes.Decimal:
+		return big.NewRat(rand.Int63(), 100).FloatString(2)
 	case datatypes.Boolean:
 		if rand.Intn(10) > 4 {
 			return "true"
@@ -66,8 +85,26 @@ func RandomStringValue(t datatypes.Type) string {
 			return "{}"
 		}
 		return "[]"
+	case datatypes.Array:
+		str, _ := datatypes.Array.ValueToString(RandomValue(datatypes.Array))
+		return str
+	case datatypes.Object:
+		str, _ := datatypes.Object.ValueToString(RandomValue(datatypes.Object))
+		return str
 	case datatypes.Date:
+		return time.Now().AddDate(0, 0, rand.Intn(30)+1).Format("2006-01-02")
+	case datatypes.Time:
+		return time.Date(0, 1, 1, rand.Intn(24), rand.Intn(60), rand.Intn(60), 0, time.UTC).Format("15:04:05")
+	case datatypes.DateTime:
 		return time.Now().Add(time.Hour * 24 * time.Duration(rand.Intn(30)+1)).Format(time.ANSIC)
+	case datatypes.Duration:
+		str, _ := datatypes.Duration.ValueToString(time.Duration(rand.Int63n(int64(time.Hour * 24 * 30))))
+		return str
+	case datatypes.GeoPoint:
+		return datatypes.Point{Lat: rand.Float64()*180 - 90, Lon: rand.Float64()*360 - 180}.String()
+	case datatypes.GeoJSON:
+		str, _ := datatypes.GeoJSON.ValueToString(RandomValue(datatypes.GeoJSON))
+		return str
 	case datatypes.URL:
 		return "http://bit.ly/" + randString(6)
 	}
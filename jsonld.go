@@ -0,0 +1,616 @@
+package dataset
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// JSON-LD vocabulary namespaces used in dataset documents
+const (
+	SchemaOrgNS = "http://schema.org/"
+	DCATNS      = "http://www.w3.org/ns/dcat#"
+	DCTermsNS   = "http://purl.org/dc/terms/"
+	FOAFNS      = "http://xmlns.com/foaf/0.1/"
+	OWLNS       = "http://www.w3.org/2002/07/owl#"
+	XSDNS       = "http://www.w3.org/2001/XMLSchema#"
+)
+
+// JSONLDProfile is the vocabulary a JSON-LD dataset document is written in
+type JSONLDProfile int
+
+const (
+	// SchemaOrgProfile writes schema.org Dataset documents, the vocabulary
+	// dataset search engines index. terms schema.org lacks (accrual
+	// periodicity & themes) are written with DCAT & Dublin Core terms
+	SchemaOrgProfile JSONLDProfile = iota
+	// DCATProfile writes DCAT-AP dcat:Dataset documents
+	DCATProfile
+)
+
+// String implements the stringer interface
+func (p JSONLDProfile) String() string {
+	switch p {
+	case SchemaOrgProfile:
+		return "schema.org"
+	case DCATProfile:
+		return "dcat"
+	}
+	return ""
+}
+
+// formatMediaTypes maps data formats to the media types distributions
+// declare
+var formatMediaTypes = map[DataFormat]string{
+	CSVDataFormat:     "text/csv",
+	JSONDataFormat:    "application/json",
+	XMLDataFormat:     "application/xml",
+	XLSDataFormat:     "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+	CDXJDataFormat:    "application/x-cdxj",
+	NDJSONDataFormat:  "application/x-ndjson",
+	ParquetDataFormat: "application/vnd.apache.parquet",
+	GeoJSONDataFormat: "application/geo+json",
+	SQLDataFormat:     "application/sql",
+}
+
+// JSONLDCfg configures JSON-LD documents created by Dataset.JSONLD
+type JSONLDCfg struct {
+	// Profile is the vocabulary to write, defaults to SchemaOrgProfile
+	Profile JSONLDProfile
+}
+
+// JSONLD describes a dataset's metadata as a JSON-LD Dataset document, in
+// schema.org or DCAT-AP terms. the dataset's DownloadURL & AccessURL become
+// a distribution, with a media type taken from the structure's format.
+// the returned document is ready for json.Marshal, and can be embedded in
+// web pages in a <script type="application/ld+json"> element
+func (ds *Dataset) JSONLD(options ...func(cfg *JSONLDCfg)) map[string]interface{} {
+	cfg := &JSONLDCfg{}
+	for _, opt := range options {
+		opt(cfg)
+	}
+
+	if cfg.Profile == DCATProfile {
+		return ds.dcatJSONLD()
+	}
+	return ds.schemaOrgJSONLD()
+}
+
+// schemaOrgJSONLD writes a schema.org Dataset document
+func (ds *Dataset) schemaOrgJSONLD() map[string]interface{} {
+	doc := map[string]interface{}{
+		"@context": []interface{}{"https://schema.org/", map[string]interface{}{"dcat": DCATNS, "dct": DCTermsNS}},
+		"@type":    "Dataset",
+	}
+	setLD(doc, "name", ds.Title)
+	setLD(doc, "description", ds.Description)
+	setLD(doc, "url", ds.Homepage)
+	setLD(doc, "identifier", ds.Identifier)
+	setLD(doc, "version", ds.Version)
+	setLD(doc, "image", ds.Image)
+	setLD(doc, "dct:accrualPeriodicity", ds.AccrualPeriodicity)
+	if len(ds.Keywords) > 0 {
+		doc["keywords"] = ds.Keywords
+	}
+	if len(ds.Language) > 0 {
+		doc["inLanguage"] = ds.Language
+	}
+	if len(ds.Theme) > 0 {
+		doc["dcat:theme"] = ds.Theme
+	}
+	if !ds.Timestamp.IsZero() {
+		doc["dateCreated"] = ds.Timestamp.UTC().Format(time.RFC3339)
+	}
+	if l := ds.License; l != nil {
+		switch {
+		case l.Type != "" && l.URL != "":
+			doc["license"] = map[string]interface{}{"@type": "CreativeWork", "name": l.Type, "url": l.URL}
+		case l.URL != "":
+			doc["license"] = l.URL
+		case l.Type != "":
+			doc["license"] = l.Type
+		}
+	}
+	if ds.Author != nil {
+		doc["creator"] = schemaOrgPerson(ds.Author)
+	}
+	if len(ds.Contributors) > 0 {
+		people := make([]interface{}, len(ds.Contributors))
+		for i, u := range ds.Contributors {
+			people[i] = schemaOrgPerson(u)
+		}
+		doc["contributor"] = people
+	}
+	if len(ds.Citations) > 0 {
+		works := make([]interface{}, len(ds.Citations))
+		for i, c := range ds.Citations {
+			work := map[string]interface{}{"@type": "CreativeWork"}
+			setLD(work, "name", c.Name)
+			setLD(work, "url", c.URL)
+			works[i] = work
+		}
+		doc["isBasedOn"] = works
+	}
+	if ds.DownloadURL != "" || ds.AccessURL != "" {
+		dist := map[string]interface{}{"@type": "DataDownload"}
+		setLD(dist, "contentUrl", ds.DownloadURL)
+		setLD(dist, "url", ds.AccessURL)
+		setLD(dist, "encodingFormat", ds.mediaType())
+		doc["distribution"] = []interface{}{dist}
+	}
+	return doc
+}
+
+// schemaOrgPerson writes a user as a schema.org Person
+func schemaOrgPerson(u *User) map[string]interface{} {
+	p := map[string]interface{}{"@type": "Person"}
+	setLD(p, "name", u.Fullname)
+	setLD(p, "email", u.Email)
+	setLD(p, "identifier", u.ID)
+	return p
+}
+
+// dcatJSONLD writes a DCAT-AP dcat:Dataset document
+func (ds *Dataset) dcatJSONLD() map[string]interface{} {
+	doc := map[string]interface{}{
+		"@context": map[string]interface{}{"dcat": DCATNS, "dct": DCTermsNS, "foaf": FOAFNS, "owl": OWLNS, "xsd": XSDNS},
+		"@type":    "dcat:Dataset",
+	}
+	setLD(doc, "dct:title", ds.Title)
+	setLD(doc, "dct:description", ds.Description)
+	setLD(doc, "dct:identifier", ds.Identifier)
+	setLD(doc, "owl:versionInfo", ds.Version)
+	setLD(doc, "dct:accrualPeriodicity", ds.AccrualPeriodicity)
+	if ds.Homepage != "" {
+		doc["dcat:landingPage"] = map[string]interface{}{"@id": ds.Homepage}
+	}
+	if ds.Image != "" {
+		doc["foaf:depiction"] = map[string]interface{}{"@id": ds.Image}
+	}
+	if len(ds.Keywords) > 0 {
+		doc["dcat:keyword"] = ds.Keywords
+	}
+	if len(ds.Language) > 0 {
+		doc["dct:language"] = ds.Language
+	}
+	if len(ds.Theme) > 0 {
+		doc["dcat:theme"] = ds.Theme
+	}
+	if !ds.Timestamp.IsZero() {
+		doc["dct:issued"] = map[string]interface{}{"@value": ds.Timestamp.UTC().Format(time.RFC3339), "@type": "xsd:dateTime"}
+	}
+	if l := ds.License; l != nil && (l.Type != "" || l.URL != "") {
+		lic := map[string]interface{}{"@type": "dct:LicenseDocument"}
+		setLD(lic, "@id", l.URL)
+		setLD(lic, "dct:title", l.Type)
+		doc["dct:license"] = lic
+	}
+	if ds.Author != nil {
+		doc["dct:creator"] = dcatAgent(ds.Author)
+	}
+	if len(ds.Contributors) > 0 {
+		agents := make([]interface{}, len(ds.Contributors))
+		for i, u := range ds.Contributors {
+			agents[i] = dcatAgent(u)
+		}
+		doc["dct:contributor"] = agents
+	}
+	if len(ds.Citations) > 0 {
+		sources := make([]interface{}, len(ds.Citations))
+		for i, c := range ds.Citations {
+			src := map[string]interface{}{}
+			setLD(src, "@id", c.URL)
+			setLD(src, "dct:title", c.Name)
+			sources[i] = src
+		}
+		doc["dct:source"] = sources
+	}
+	if ds.DownloadURL != "" || ds.AccessURL != "" {
+		dist := map[string]interface{}{"@type": "dcat:Distribution"}
+		if ds.DownloadURL != "" {
+			dist["dcat:downloadURL"] = map[string]interface{}{"@id": ds.DownloadURL}
+		}
+		if ds.AccessURL != "" {
+			dist["dcat:accessURL"] = map[string]interface{}{"@id": ds.AccessURL}
+		}
+		setLD(dist, "dcat:mediaType", ds.mediaType())
+		if ds.Length > 0 {
+			dist["dcat:byteSize"] = ds.Length
+		}
+		doc["dcat:distribution"] = []interface{}{dist}
+	}
+	return doc
+}
+
+// dcatAgent writes a user as a FOAF agent
+func dcatAgent(u *User) map[string]interface{} {
+	a := map[string]interface{}{"@type": "foaf:Agent"}
+	setLD(a, "foaf:name", u.Fullname)
+	setLD(a, "dct:identifier", u.ID)
+	if u.Email != "" {
+		a["foaf:mbox"] = map[string]interface{}{"@id": "mailto:" + u.Email}
+	}
+	return a
+}
+
+// mediaType gives the media type of the dataset's data, if known
+func (ds *Dataset) mediaType() string {
+	if ds.Structure == nil {
+		return ""
+	}
+	return formatMediaTypes[ds.Structure.Format]
+}
+
+// setLD sets a document property, omitting empty strings
+func setLD(doc map[string]interface{}, key, val string) {
+	if val != "" {
+		doc[key] = val
+	}
+}
+
+// DatasetFromJSONLD creates a dataset from the metadata of a JSON-LD
+// document. both schema.org & DCAT terms are read, in compacted or expanded
+// form. documents can be a single node, an array of nodes, or a @graph,
+// the first node typed as schema:Dataset or dcat:Dataset is used
+func DatasetFromJSONLD(data []byte) (*Dataset, error) {
+	var v interface{}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return nil, fmt.Errorf("error parsing json-ld: %s", err.Error())
+	}
+	node := findLDDataset(v, &ldContext{})
+	if node == nil {
+		return nil, fmt.Errorf("json-ld document has no dataset")
+	}
+
+	ds := &Dataset{
+		Title:              node.str(SchemaOrgNS+"name", DCTermsNS+"title"),
+		Description:        node.str(SchemaOrgNS+"description", DCTermsNS+"description"),
+		Homepage:           node.str(SchemaOrgNS+"url", DCATNS+"landingPage"),
+		Identifier:         node.str(SchemaOrgNS+"identifier", DCTermsNS+"identifier"),
+		Version:            node.str(SchemaOrgNS+"version", OWLNS+"versionInfo"),
+		Image:              node.str(SchemaOrgNS+"image", FOAFNS+"depiction"),
+		AccrualPeriodicity: node.str(DCTermsNS + "accrualPeriodicity"),
+		Language:           node.strs(SchemaOrgNS+"inLanguage", DCTermsNS+"language"),
+		Theme:              node.strs(DCATNS + "theme"),
+	}
+	// schema.org keywords are often a single comma separated string
+	for _, kw := range node.strs(SchemaOrgNS+"keywords", DCATNS+"keyword") {
+		for _, s := range strings.Split(kw, ",") {
+			if s = strings.TrimSpace(s); s != "" {
+				ds.Keywords = append(ds.Keywords, s)
+			}
+		}
+	}
+	if s := node.str(SchemaOrgNS+"dateCreated", SchemaOrgNS+"datePublished", DCTermsNS+"issued"); s != "" {
+		t, err := parseLDDate(s)
+		if err != nil {
+			return nil, err
+		}
+		ds.Timestamp = t
+	}
+
+	if l := node.get(SchemaOrgNS+"license", DCTermsNS+"license"); len(l) > 0 {
+		ds.License = ldLicense(l[0])
+	}
+	if a := node.get(SchemaOrgNS+"creator", SchemaOrgNS+"author", DCTermsNS+"creator"); len(a) > 0 {
+		ds.Author = ldUser(a[0])
+	}
+	for _, c := range node.get(SchemaOrgNS+"contributor", DCTermsNS+"contributor") {
+		ds.Contributors = append(ds.Contributors, ldUser(c))
+	}
+	for _, c := range node.get(SchemaOrgNS+"isBasedOn", SchemaOrgNS+"citation", DCTermsNS+"source") {
+		ds.Citations = append(ds.Citations, ldCitation(c))
+	}
+
+	// only the first distribution is read, datasets have a single body
+	for _, d := range node.get(SchemaOrgNS+"distribution", DCATNS+"distribution") {
+		dist, ok := d.(ldNode)
+		if !ok {
+			continue
+		}
+		ds.DownloadURL = dist.str(SchemaOrgNS+"contentUrl", DCATNS+"downloadURL")
+		ds.AccessURL = dist.str(DCATNS+"accessURL", SchemaOrgNS+"url")
+		if df := ldDataFormat(dist.str(SchemaOrgNS+"encodingFormat", DCATNS+"mediaType", DCTermsNS+"format")); df != UnknownDataFormat {
+			ds.Structure = &Structure{Format: df}
+		}
+		if size, err := strconv.Atoi(dist.str(DCATNS + "byteSize")); err == nil {
+			ds.Length = size
+		}
+		break
+	}
+	return ds, nil
+}
+
+// ldDataFormat gives the data format of a media type or format name
+func ldDataFormat(s string) DataFormat {
+	s = strings.ToLower(s)
+	for df, mt := range formatMediaTypes {
+		if s == mt {
+			return df
+		}
+	}
+	df, _ := ParseDataFormatString(s)
+	return df
+}
+
+// parseLDDate parses a date or datetime value
+func parseLDDate(s string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	if t, err := time.Parse("2006-01-02", s); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("invalid date: '%s'", s)
+}
+
+// ldLicense reads a license from a URL, a name, or a license node
+func ldLicense(v interface{}) *License {
+	if n, ok := v.(ldNode); ok {
+		return &License{
+			Type: n.str(SchemaOrgNS+"name", DCTermsNS+"title"),
+			URL:  n.str(SchemaOrgNS+"url", "@id"),
+		}
+	}
+	s := ldString(v)
+	if strings.Contains(s, "://") {
+		return &License{URL: s}
+	}
+	return &License{Type: s}
+}
+
+// ldUser reads a user from a person or agent node, or a bare name
+func ldUser(v interface{}) *User {
+	n, ok := v.(ldNode)
+	if !ok {
+		return &User{Fullname: ldString(v)}
+	}
+	return &User{
+		ID:       n.str(SchemaOrgNS+"identifier", DCTermsNS+"identifier"),
+		Fullname: n.str(SchemaOrgNS+"name", FOAFNS+"name"),
+		Email:    strings.TrimPrefix(n.str(SchemaOrgNS+"email", FOAFNS+"mbox"), "mailto:"),
+	}
+}
+
+// ldCitation reads a citation from a creative work node or a URL
+func ldCitation(v interface{}) *Citation {
+	n, ok := v.(ldNode)
+	if !ok {
+		return &Citation{URL: ldString(v)}
+	}
+	return &Citation{
+		Name: n.str(SchemaOrgNS+"name", DCTermsNS+"title"),
+		URL:  n.str(SchemaOrgNS+"url", "@id"),
+	}
+}
+
+// ldNode is a JSON-LD node with property names expanded to full IRIs.
+// values are scalars or nested ldNodes
+type ldNode map[string][]interface{}
+
+// get gives the values of the first listed property the node has
+func (n ldNode) get(iris ...string) []interface{} {
+	for _, iri := range iris {
+		if vals := n[iri]; len(vals) > 0 {
+			return vals
+		}
+	}
+	return nil
+}
+
+// strs gives the string values of the first listed property the node has
+func (n ldNode) strs(iris ...string) (strs []string) {
+	for _, v := range n.get(iris...) {
+		if s := ldString(v); s != "" {
+			strs = append(strs, s)
+		}
+	}
+	return strs
+}
+
+// str gives the first string value of the first listed property the node
+// has
+func (n ldNode) str(iris ...string) string {
+	if strs := n.strs(iris...); len(strs) > 0 {
+		return strs[0]
+	}
+	return ""
+}
+
+// isDataset reports weather the node is typed as a dataset
+func (n ldNode) isDataset() bool {
+	for _, t := range n.strs("@type") {
+		if t == SchemaOrgNS+"Dataset" || t == DCATNS+"Dataset" {
+			return true
+		}
+	}
+	return false
+}
+
+// ldString gives the string form of a scalar value. node references give
+// their @id
+func ldString(v interface{}) string {
+	switch x := v.(type) {
+	case string:
+		return x
+	case float64:
+		return strconv.FormatFloat(x, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(x)
+	case ldNode:
+		return x.str("@id")
+	}
+	return ""
+}
+
+// findLDDataset searches a decoded JSON-LD document for a dataset node
+func findLDDataset(v interface{}, ctx *ldContext) ldNode {
+	switch x := v.(type) {
+	case []interface{}:
+		for _, item := range x {
+			if n := findLDDataset(item, ctx); n != nil {
+				return n
+			}
+		}
+	case map[string]interface{}:
+		ctx = ctx.extend(x["@context"])
+		if graph, ok := x["@graph"]; ok {
+			return findLDDataset(graph, ctx)
+		}
+		if n := ctx.node(x); n.isDataset() {
+			return n
+		}
+	}
+	return nil
+}
+
+// ldContext is the subset of a JSON-LD context needed to expand property
+// names: a default vocabulary, and prefix & term definitions
+type ldContext struct {
+	vocab string
+	terms map[string]string
+}
+
+// extend creates a new context from a @context value
+func (c *ldContext) extend(v interface{}) *ldContext {
+	if v == nil {
+		return c
+	}
+	ctx := &ldContext{vocab: c.vocab, terms: map[string]string{}}
+	for k, iri := range c.terms {
+		ctx.terms[k] = iri
+	}
+
+	var defs []interface{}
+	if list, ok := v.([]interface{}); ok {
+		defs = list
+	} else {
+		defs = []interface{}{v}
+	}
+	for _, def := range defs {
+		switch d := def.(type) {
+		case string:
+			// remote contexts aren't fetched, schema.org is the only one
+			// that's recognized
+			if iri := normalizeLDIRI(strings.TrimSuffix(d, "/") + "/"); iri == SchemaOrgNS {
+				ctx.vocab = SchemaOrgNS
+			}
+		case map[string]interface{}:
+			for term, val := range d {
+				var iri string
+				switch tv := val.(type) {
+				case string:
+					iri = tv
+				case map[string]interface{}:
+					iri, _ = tv["@id"].(string)
+				}
+				if iri == "" {
+					continue
+				}
+				if term == "@vocab" {
+					ctx.vocab = normalizeLDIRI(iri)
+				} else if !strings.HasPrefix(term, "@") {
+					ctx.terms[term] = iri
+				}
+			}
+		}
+	}
+	return ctx
+}
+
+// wellKnownLDPrefixes are used for prefixes a document doesn't define
+var wellKnownLDPrefixes = map[string]string{
+	"schema":  SchemaOrgNS,
+	"dcat":    DCATNS,
+	"dct":     DCTermsNS,
+	"dcterms": DCTermsNS,
+	"foaf":    FOAFNS,
+	"owl":     OWLNS,
+	"xsd":     XSDNS,
+}
+
+// expand gives the full IRI of a property name or type
+func (c *ldContext) expand(name string) string {
+	if strings.HasPrefix(name, "@") {
+		return name
+	}
+	if iri, ok := c.terms[name]; ok {
+		return c.expandPrefix(iri)
+	}
+	if !strings.Contains(name, ":") && c.vocab != "" {
+		return c.vocab + name
+	}
+	return c.expandPrefix(name)
+}
+
+// expandPrefix expands a compact "prefix:suffix" IRI
+func (c *ldContext) expandPrefix(name string) string {
+	if i := strings.Index(name, ":"); i > 0 {
+		prefix, suffix := name[:i], name[i+1:]
+		if strings.HasPrefix(suffix, "//") {
+			return normalizeLDIRI(name)
+		}
+		if ns, ok := c.terms[prefix]; ok {
+			return normalizeLDIRI(ns + suffix)
+		}
+		if ns, ok := wellKnownLDPrefixes[prefix]; ok {
+			return ns + suffix
+		}
+	}
+	return name
+}
+
+// normalizeLDIRI maps https schema.org IRIs to their canonical http form
+func normalizeLDIRI(iri string) string {
+	if strings.HasPrefix(iri, "https://schema.org/") {
+		return "http" + strings.TrimPrefix(iri, "https")
+	}
+	return iri
+}
+
+// node expands the property names of a JSON object
+func (c *ldContext) node(obj map[string]interface{}) ldNode {
+	c = c.extend(obj["@context"])
+	n := ldNode{}
+	for k, v := range obj {
+		if k == "@context" {
+			continue
+		}
+		iri := c.expand(k)
+		for _, val := range c.values(v) {
+			if iri == "@type" {
+				if s, ok := val.(string); ok {
+					val = c.expand(s)
+				}
+			}
+			n[iri] = append(n[iri], val)
+		}
+	}
+	return n
+}
+
+// values flattens a property value to a list of scalars & nodes. value
+// objects give their @value
+func (c *ldContext) values(v interface{}) []interface{} {
+	switch x := v.(type) {
+	case nil:
+		return nil
+	case []interface{}:
+		var vals []interface{}
+		for _, item := range x {
+			vals = append(vals, c.values(item)...)
+		}
+		return vals
+	case map[string]interface{}:
+		if val, ok := x["@value"]; ok {
+			return c.values(val)
+		}
+		if list, ok := x["@list"]; ok {
+			return c.values(list)
+		}
+		return []interface{}{c.node(x)}
+	}
+	return []interface{}{v}
+}
@@ -0,0 +1,142 @@
+package dataset
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func jsonLDTestDataset() *Dataset {
+	return &Dataset{
+		Title:              "World Cities",
+		Description:        "cities of the world",
+		Homepage:           "https://example.com/cities",
+		Timestamp:          time.Date(2018, 1, 2, 3, 4, 5, 0, time.UTC),
+		Keywords:           []string{"cities", "population"},
+		Theme:              []string{"society"},
+		AccrualPeriodicity: "R/P1Y",
+		DownloadURL:        "https://example.com/cities.csv",
+		Length:             42,
+		License:            &License{Type: "CC-BY-4.0", URL: "https://creativecommons.org/licenses/by/4.0/"},
+		Author:             &User{Fullname: "ada", Email: "ada@example.com"},
+		Contributors:       []*User{{Fullname: "grace"}},
+		Citations:          []*Citation{{Name: "census", URL: "https://example.com/census"}},
+		Structure:          &Structure{Format: CSVDataFormat},
+	}
+}
+
+func TestDatasetJSONLD(t *testing.T) {
+	cases := []struct {
+		profile JSONLDProfile
+		expect  string
+	}{
+		{SchemaOrgProfile, `{"@context":["https://schema.org/",{"dcat":"http://www.w3.org/ns/dcat#","dct":"http://purl.org/dc/terms/"}],"@type":"Dataset",` +
+			`"contributor":[{"@type":"Person","name":"grace"}],"creator":{"@type":"Person","email":"ada@example.com","name":"ada"},` +
+			`"dateCreated":"2018-01-02T03:04:05Z","dcat:theme":["society"],"dct:accrualPeriodicity":"R/P1Y","description":"cities of the world",` +
+			`"distribution":[{"@type":"DataDownload","contentUrl":"https://example.com/cities.csv","encodingFormat":"text/csv"}],` +
+			`"isBasedOn":[{"@type":"CreativeWork","name":"census","url":"https://example.com/census"}],"keywords":["cities","population"],` +
+			`"license":{"@type":"CreativeWork","name":"CC-BY-4.0","url":"https://creativecommons.org/licenses/by/4.0/"},` +
+			`"name":"World Cities","url":"https://example.com/cities"}`},
+		{DCATProfile, `{"@context":{"dcat":"http://www.w3.org/ns/dcat#","dct":"http://purl.org/dc/terms/","foaf":"http://xmlns.com/foaf/0.1/","owl":"http://www.w3.org/2002/07/owl#","xsd":"http://www.w3.org/2001/XMLSchema#"},` +
+			`"@type":"dcat:Dataset","dcat:distribution":[{"@type":"dcat:Distribution","dcat:byteSize":42,"dcat:downloadURL":{"@id":"https://example.com/cities.csv"},"dcat:mediaType":"text/csv"}],` +
+			`"dcat:keyword":["cities","population"],"dcat:landingPage":{"@id":"https://example.com/cities"},"dcat:theme":["society"],` +
+			`"dct:accrualPeriodicity":"R/P1Y","dct:contributor":[{"@type":"foaf:Agent","foaf:name":"grace"}],` +
+			`"dct:creator":{"@type":"foaf:Agent","foaf:mbox":{"@id":"mailto:ada@example.com"},"foaf:name":"ada"},"dct:description":"cities of the world",` +
+			`"dct:issued":{"@type":"xsd:dateTime","@value":"2018-01-02T03:04:05Z"},` +
+			`"dct:license":{"@id":"https://creativecommons.org/licenses/by/4.0/","@type":"dct:LicenseDocument","dct:title":"CC-BY-4.0"},` +
+			`"dct:source":[{"@id":"https://example.com/census","dct:title":"census"}],"dct:title":"World Cities"}`},
+	}
+
+	for i, c := range cases {
+		doc := jsonLDTestDataset().JSONLD(func(cfg *JSONLDCfg) { cfg.Profile = c.profile })
+		got, err := json.Marshal(doc)
+		if err != nil {
+			t.Fatalf("case %d error marshaling: %s", i, err.Error())
+		}
+		if string(got) != c.expect {
+			t.Errorf("case %d document mismatch. expected:\n%s\ngot:\n%s", i, c.expect, string(got))
+		}
+	}
+}
+
+func TestDatasetFromJSONLDRoundTrip(t *testing.T) {
+	for _, profile := range []JSONLDProfile{SchemaOrgProfile, DCATProfile} {
+		expect := jsonLDTestDataset()
+		data, err := json.Marshal(expect.JSONLD(func(cfg *JSONLDCfg) { cfg.Profile = profile }))
+		if err != nil {
+			t.Fatalf("%s error marshaling: %s", profile, err.Error())
+		}
+		got, err := DatasetFromJSONLD(data)
+		if err != nil {
+			t.Fatalf("%s unexpected error: %s", profile, err.Error())
+		}
+		// the schema.org profile doesn't record byte size
+		if profile == SchemaOrgProfile {
+			expect.Length = 0
+		}
+		a, err := json.Marshal(expect)
+		if err != nil {
+			t.Fatalf("%s error marshaling dataset: %s", profile, err.Error())
+		}
+		b, err := json.Marshal(got)
+		if err != nil {
+			t.Fatalf("%s error marshaling dataset: %s", profile, err.Error())
+		}
+		if string(a) != string(b) {
+			t.Errorf("%s round trip mismatch. expected:\n%s\ngot:\n%s", profile, string(a), string(b))
+		}
+	}
+}
+
+func TestDatasetFromJSONLD(t *testing.T) {
+	doc := `{
+		"@context": {"@vocab": "https://schema.org/", "dc": "http://purl.org/dc/terms/"},
+		"@graph": [
+			{"@type": "Organization", "name": "acme"},
+			{
+				"@type": "Dataset",
+				"name": {"@value": "gdp", "@language": "en"},
+				"keywords": "economy, gdp",
+				"license": "https://opendatacommons.org/licenses/pddl/",
+				"author": "ada",
+				"datePublished": "2018-01-02",
+				"dc:accrualPeriodicity": "R/P1M",
+				"http://www.w3.org/ns/dcat#theme": {"@id": "http://publications.europa.eu/resource/authority/data-theme/ECON"},
+				"distribution": {"@type": "DataDownload", "contentUrl": "https://example.com/gdp.json", "encodingFormat": "json"}
+			}
+		]
+	}`
+	ds, err := DatasetFromJSONLD([]byte(doc))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+	if ds.Title != "gdp" || len(ds.Keywords) != 2 || ds.Keywords[1] != "gdp" || ds.AccrualPeriodicity != "R/P1M" {
+		t.Errorf("metadata mismatch: %s %v %s", ds.Title, ds.Keywords, ds.AccrualPeriodicity)
+	}
+	if ds.License.URL != "https://opendatacommons.org/licenses/pddl/" || ds.Author.Fullname != "ada" {
+		t.Errorf("license & author mismatch: %v %v", ds.License, ds.Author)
+	}
+	if !ds.Timestamp.Equal(time.Date(2018, 1, 2, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("timestamp mismatch: %s", ds.Timestamp)
+	}
+	if len(ds.Theme) != 1 || ds.Theme[0] != "http://publications.europa.eu/resource/authority/data-theme/ECON" {
+		t.Errorf("theme mismatch: %v", ds.Theme)
+	}
+	if ds.DownloadURL != "https://example.com/gdp.json" || ds.Structure == nil || ds.Structure.Format != JSONDataFormat {
+		t.Errorf("distribution mismatch: %s %v", ds.DownloadURL, ds.Structure)
+	}
+
+	cases := []struct {
+		doc, err string
+	}{
+		{`{`, "error parsing json-ld: unexpected end of JSON input"},
+		{`{"@context":"https://schema.org","@type":"Person","name":"ada"}`, "json-ld document has no dataset"},
+		{`[{"@context":"https://schema.org","@type":"Dataset","dateCreated":"last tuesday"}]`, "invalid date: 'last tuesday'"},
+	}
+	for i, c := range cases {
+		_, err := DatasetFromJSONLD([]byte(c.doc))
+		if !(err == nil && c.err == "" || err != nil && err.Error() == c.err) {
+			t.Errorf("case %d error mismatch. expected: '%s', got: '%s'", i, c.err, err)
+		}
+	}
+}
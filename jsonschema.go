@@ -0,0 +1,759 @@
+package dataset
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+
+	"github.com/qri-io/dataset/datatypes"
+)
+
+// JSONSchemaDraft is the JSON Schema version exported documents declare
+const JSONSchemaDraft = "http://json-schema.org/draft-07/schema#"
+
+// JSONSchema is a JSON Schema document, limited to the keywords that have
+// an equivalent in dataset schemas. all other keywords are kept in Extra
+type JSONSchema struct {
+	Schema      string
+	Title       string
+	Description string
+	// Type lists permitted JSON types, written as a string when there's
+	// only one
+	Type   []string
+	Format string
+	// Items is the schema of array elements
+	Items *JSONSchema
+	// Properties are the schemas of object properties, in order
+	Properties []*JSONSchemaProperty
+	// Required lists properties objects must have
+	Required      []string
+	MinLength     *int64
+	MaxLength     *int64
+	MinItems      *int64
+	MaxItems      *int64
+	MinProperties *int64
+	MaxProperties *int64
+	Pattern       string
+	Minimum       interface{}
+	Maximum       interface{}
+	Enum          []interface{}
+	// Extra holds keywords that aren't listed above
+	Extra map[string]json.RawMessage
+}
+
+// JSONSchemaProperty is a named property of an object schema
+type JSONSchemaProperty struct {
+	Name   string
+	Schema *JSONSchema
+}
+
+// JSONSchemaReport lists constructs that couldn't be mapped converting
+// between dataset & JSON schemas
+type JSONSchemaReport struct {
+	Unmapped []*JSONSchemaIssue `json:"unmapped"`
+}
+
+// JSONSchemaIssue describes a single construct lost in conversion. Path is
+// a dataset path like "fields.name" when exporting, a JSON pointer like
+// "#/items/properties/name" when importing
+type JSONSchemaIssue struct {
+	Path    string `json:"path"`
+	Message string `json:"message"`
+}
+
+// add records an unmapped construct
+func (r *JSONSchemaReport) add(path, format string, args ...interface{}) {
+	r.Unmapped = append(r.Unmapped, &JSONSchemaIssue{Path: path, Message: fmt.Sprintf(format, args...)})
+}
+
+// hasType reports weather the schema permits values of JSON type t
+func (s *JSONSchema) hasType(t string) bool {
+	for _, typ := range s.Type {
+		if typ == t {
+			return true
+		}
+	}
+	return false
+}
+
+// MarshalJSON satisfies the json.Marshaler interface, writing keywords in
+// a fixed order & properties in the order they're listed
+func (s JSONSchema) MarshalJSON() ([]byte, error) {
+	buf := &bytes.Buffer{}
+	buf.WriteByte('{')
+	write := func(key string, val interface{}) error {
+		data, err := json.Marshal(val)
+		if err != nil {
+			return fmt.Errorf("error marshaling %s: %s", key, err.Error())
+		}
+		if buf.Len() > 1 {
+			buf.WriteByte(',')
+		}
+		k, _ := json.Marshal(key)
+		buf.Write(k)
+		buf.WriteByte(':')
+		buf.Write(data)
+		return nil
+	}
+
+	keys := []struct {
+		key  string
+		val  interface{}
+		omit bool
+	}{
+		{"$schema", s.Schema, s.Schema == ""},
+		{"title", s.Title, s.Title == ""},
+		{"description", s.Description, s.Description == ""},
+		{"type", jsonSchemaType(s.Type), len(s.Type) == 0},
+		{"format", s.Format, s.Format == ""},
+		{"items", s.Items, s.Items == nil},
+		{"properties", jsonSchemaProperties(s.Properties), s.Properties == nil},
+		{"required", s.Required, len(s.Required) == 0},
+		{"minLength", s.MinLength, s.MinLength == nil},
+		{"maxLength", s.MaxLength, s.MaxLength == nil},
+		{"minItems", s.MinItems, s.MinItems == nil},
+		{"maxItems", s.MaxItems, s.MaxItems == nil},
+		{"minProperties", s.MinProperties, s.MinProperties == nil},
+		{"maxProperties", s.MaxProperties, s.MaxProperties == nil},
+		{"pattern", s.Pattern, s.Pattern == ""},
+		{"minimum", s.Minimum, s.Minimum == nil},
+		{"maximum", s.Maximum, s.Maximum == nil},
+		{"enum", s.Enum, s.Enum == nil},
+	}
+	for _, k := range keys {
+		if k.omit {
+			continue
+		}
+		if err := write(k.key, k.val); err != nil {
+			return nil, err
+		}
+	}
+
+	extra := make([]string, 0, len(s.Extra))
+	for key := range s.Extra {
+		extra = append(extra, key)
+	}
+	sort.Strings(extra)
+	for _, key := range extra {
+		if err := write(key, s.Extra[key]); err != nil {
+			return nil, err
+		}
+	}
+
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// UnmarshalJSON satisfies the json.Unmarshaler interface
+func (s *JSONSchema) UnmarshalJSON(data []byte) error {
+	// boolean schemas permit any value or no value
+	switch string(bytes.TrimSpace(data)) {
+	case "true":
+		*s = JSONSchema{}
+		return nil
+	case "false":
+		*s = JSONSchema{Extra: map[string]json.RawMessage{"not": json.RawMessage("{}")}}
+		return nil
+	}
+
+	raw := map[string]json.RawMessage{}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return fmt.Errorf("error unmarshaling json schema: %s", err.Error())
+	}
+
+	js := JSONSchema{}
+	for key, val := range raw {
+		var err error
+		switch key {
+		case "$schema":
+			err = json.Unmarshal(val, &js.Schema)
+		case "title":
+			err = json.Unmarshal(val, &js.Title)
+		case "description":
+			err = json.Unmarshal(val, &js.Description)
+		case "type":
+			var t jsonSchemaType
+			err = json.Unmarshal(val, &t)
+			js.Type = t
+		case "format":
+			err = json.Unmarshal(val, &js.Format)
+		case "items":
+			// tuple-style item arrays have no equivalent & are kept as is
+			if trimmed := bytes.TrimSpace(val); len(trimmed) > 0 && trimmed[0] == '[' {
+				js.addExtra(key, val)
+				continue
+			}
+			js.Items = &JSONSchema{}
+			err = json.Unmarshal(val, js.Items)
+		case "properties":
+			var props jsonSchemaProperties
+			err = json.Unmarshal(val, &props)
+			js.Properties = props
+		case "required":
+			err = json.Unmarshal(val, &js.Required)
+		case "minLength":
+			err = json.Unmarshal(val, &js.MinLength)
+		case "maxLength":
+			err = json.Unmarshal(val, &js.MaxLength)
+		case "minItems":
+			err = json.Unmarshal(val, &js.MinItems)
+		case "maxItems":
+			err = json.Unmarshal(val, &js.MaxItems)
+		case "minProperties":
+			err = json.Unmarshal(val, &js.MinProperties)
+		case "maxProperties":
+			err = json.Unmarshal(val, &js.MaxProperties)
+		case "pattern":
+			err = json.Unmarshal(val, &js.Pattern)
+		case "minimum":
+			err = json.Unmarshal(val, &js.Minimum)
+		case "maximum":
+			err = json.Unmarshal(val, &js.Maximum)
+		case "enum":
+			err = json.Unmarshal(val, &js.Enum)
+		default:
+			js.addExtra(key, val)
+		}
+		if err != nil {
+			return fmt.Errorf("invalid json schema %s: %s", key, err.Error())
+		}
+	}
+	*s = js
+	return nil
+}
+
+// addExtra keeps a keyword with no equivalent
+func (s *JSONSchema) addExtra(key string, val json.RawMessage) {
+	if s.Extra == nil {
+		s.Extra = map[string]json.RawMessage{}
+	}
+	s.Extra[key] = val
+}
+
+// jsonSchemaType is a list of types that marshals to a string when it has
+// only one element
+type jsonSchemaType []string
+
+// MarshalJSON satisfies the json.Marshaler interface
+func (t jsonSchemaType) MarshalJSON() ([]byte, error) {
+	if len(t) == 1 {
+		return json.Marshal(t[0])
+	}
+	return json.Marshal([]string(t))
+}
+
+// UnmarshalJSON satisfies the json.Unmarshaler interface
+func (t *jsonSchemaType) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		*t = jsonSchemaType{s}
+		return nil
+	}
+	types := []string{}
+	if err := json.Unmarshal(data, &types); err != nil {
+		return fmt.Errorf("type must be a string or an array of strings")
+	}
+	*t = jsonSchemaType(types)
+	return nil
+}
+
+// jsonSchemaProperties is an ordered list of properties that marshals to
+// an object
+type jsonSchemaProperties []*JSONSchemaProperty
+
+// MarshalJSON satisfies the json.Marshaler interface
+func (p jsonSchemaProperties) MarshalJSON() ([]byte, error) {
+	buf := &bytes.Buffer{}
+	buf.WriteByte('{')
+	for i, prop := range p {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		k, _ := json.Marshal(prop.Name)
+		v, err := json.Marshal(prop.Schema)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(k)
+		buf.WriteByte(':')
+		buf.Write(v)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// UnmarshalJSON satisfies the json.Unmarshaler interface, keeping
+// properties in the order they appear
+func (p *jsonSchemaProperties) UnmarshalJSON(data []byte) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	if tok, err := dec.Token(); err != nil || tok != json.Delim('{') {
+		return fmt.Errorf("properties must be an object")
+	}
+	props := jsonSchemaProperties{}
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		name, _ := tok.(string)
+		js := &JSONSchema{}
+		if err := dec.Decode(js); err != nil {
+			return fmt.Errorf("property %s: %s", name, err.Error())
+		}
+		props = append(props, &JSONSchemaProperty{Name: name, Schema: js})
+	}
+	*p = props
+	return nil
+}
+
+// JSONSchema converts a structure's schema to a JSON Schema document
+// describing an array of row objects
+func (s *Structure) JSONSchema() (*JSONSchema, *JSONSchemaReport, error) {
+	if s.Schema == nil {
+		return nil, nil, fmt.Errorf("structure has no schema")
+	}
+	js, report := s.Schema.JSONSchema()
+	if opts, ok := s.FormatConfig.(*JSONOptions); ok && opts.ArrayEntries {
+		report.add("formatConfig.arrayEntries", "rows are written as arrays, the JSON Schema describes rows as objects")
+	}
+	return js, report, nil
+}
+
+// JSONSchema converts a schema to a JSON Schema document describing an
+// array of row objects, along with a report of anything that has no JSON
+// Schema equivalent
+func (s *Schema) JSONSchema() (*JSONSchema, *JSONSchemaReport) {
+	report := &JSONSchemaReport{}
+	row := fieldsJSONSchema(s.Fields, "fields", report)
+	if len(s.PrimaryKey) > 0 {
+		report.add("primaryKey", "primary keys have no JSON Schema equivalent")
+	}
+	if len(s.ForeignKeys) > 0 {
+		report.add("foreignKeys", "foreign keys have no JSON Schema equivalent")
+	}
+	return &JSONSchema{
+		Schema: JSONSchemaDraft,
+		Type:   []string{"array"},
+		Items:  row,
+	}, report
+}
+
+// JSONSchema converts a field, including it's constraints, to the JSON
+// Schema of it's values
+func (f *Field) JSONSchema() (*JSONSchema, *JSONSchemaReport) {
+	report := &JSONSchemaReport{}
+	return fieldJSONSchema(f, "fields."+f.Name, report), report
+}
+
+// fieldsJSONSchema creates an object schema with a property for each field
+func fieldsJSONSchema(fields []*Field, path string, report *JSONSchemaReport) *JSONSchema {
+	js := &JSONSchema{Type: []string{"object"}, Properties: []*JSONSchemaProperty{}}
+	for _, f := range fields {
+		prop := fieldJSONSchema(f, path+"."+f.Name, report)
+		if f.Constraints != nil && f.Constraints.Required != nil && *f.Constraints.Required {
+			js.Required = append(js.Required, f.Name)
+		} else if len(prop.Type) > 0 {
+			// optional values are written as null
+			prop.Type = append(prop.Type, "null")
+			if prop.Enum != nil {
+				prop.Enum = append(prop.Enum, nil)
+			}
+		}
+		js.Properties = append(js.Properties, &JSONSchemaProperty{Name: f.Name, Schema: prop})
+	}
+	return js
+}
+
+// timeJSONFormats maps time types to JSON Schema formats
+var timeJSONFormats = map[datatypes.Type]string{
+	datatypes.Date:     "date",
+	datatypes.Time:     "time",
+	datatypes.DateTime: "date-time",
+}
+
+// fieldJSONSchema converts a single field to a JSON Schema
+func fieldJSONSchema(f *Field, path string, report *JSONSchemaReport) *JSONSchema {
+	js := &JSONSchema{Title: f.Title, Description: f.Description}
+	switch f.Type {
+	case datatypes.String:
+		js.Type = []string{"string"}
+	case datatypes.Integer:
+		js.Type = []string{"integer"}
+	case datatypes.Float, datatypes.Decimal:
+		js.Type = []string{"number"}
+	case datatypes.Boolean:
+		js.Type = []string{"boolean"}
+	case datatypes.Date, datatypes.Time, datatypes.DateTime:
+		js.Type = []string{"string"}
+		if format := strings.TrimSpace(f.Format); format == "" || format == "default" {
+			js.Format = timeJSONFormats[f.Type]
+		} else {
+			report.add(path, "%s format '%s' has no JSON Schema equivalent", f.Type.String(), f.Format)
+		}
+	case datatypes.URL:
+		js.Type, js.Format = []string{"string"}, "uri"
+	case datatypes.Duration:
+		js.Type, js.Format = []string{"string"}, "duration"
+	case datatypes.GeoPoint:
+		js.Type = []string{"string"}
+		report.add(path, "geopoint values have no JSON Schema equivalent, described as strings")
+	case datatypes.GeoJSON:
+		js.Type = []string{"object"}
+	case datatypes.Array:
+		js.Type = []string{"array"}
+		if f.Items != nil {
+			js.Items = fieldJSONSchema(f.Items, path+".items", report)
+		}
+	case datatypes.Object:
+		obj := fieldsJSONSchema(f.Properties, path, report)
+		js.Type, js.Properties, js.Required = obj.Type, obj.Properties, obj.Required
+	}
+
+	if f.MissingValue != nil {
+		report.add(path+".missingValue", "missing values have no JSON Schema equivalent")
+	}
+	if f.Constraints != nil {
+		constraintsJSONSchema(f, js, path+".constraints", report)
+	}
+	return js
+}
+
+// constraintsJSONSchema adds keywords for a field's constraints to js
+func constraintsJSONSchema(f *Field, js *JSONSchema, path string, report *JSONSchemaReport) {
+	c := f.Constraints
+	str := js.hasType("string")
+
+	if c.MinLength != nil || c.MaxLength != nil {
+		switch {
+		case str:
+			js.MinLength, js.MaxLength = c.MinLength, c.MaxLength
+		case f.Type == datatypes.Array:
+			js.MinItems, js.MaxItems = c.MinLength, c.MaxLength
+		case f.Type == datatypes.Object:
+			js.MinProperties, js.MaxProperties = c.MinLength, c.MaxLength
+		default:
+			report.add(path+".length", "length constraints on %s values have no JSON Schema equivalent", f.Type.String())
+		}
+	}
+
+	if c.Pattern != "" {
+		if str {
+			js.Pattern = "^(?:" + c.Pattern + ")$"
+		} else {
+			report.add(path+".pattern", "patterns on %s values have no JSON Schema equivalent", f.Type.String())
+		}
+	}
+
+	if c.Unique != nil && *c.Unique {
+		report.add(path+".unique", "unique values have no JSON Schema equivalent")
+	}
+
+	bound := func(name string, val interface{}) interface{} {
+		if val == nil {
+			return nil
+		}
+		if !js.hasType("integer") && !js.hasType("number") {
+			report.add(path+"."+name, "%s on %s values has no JSON Schema equivalent", name, f.Type.String())
+			return nil
+		}
+		v, err := jsonSchemaValue(f, val)
+		if err != nil {
+			report.add(path+"."+name, "invalid %s: %s", name, err.Error())
+			return nil
+		}
+		return v
+	}
+	js.Minimum = bound("minimum", c.Minimum)
+	js.Maximum = bound("maximum", c.Maximum)
+
+	if c.Enum != nil {
+		js.Enum = make([]interface{}, 0, len(c.Enum))
+		for _, e := range c.Enum {
+			v, err := jsonSchemaValue(f, e)
+			if err != nil {
+				report.add(path+".enum", "invalid enum value: %s", err.Error())
+				continue
+			}
+			js.Enum = append(js.Enum, v)
+		}
+	}
+}
+
+// jsonSchemaValue converts a constraint value to the value JSON data of
+// the field's type would hold. strings are parsed for numeric & boolean
+// fields
+func jsonSchemaValue(f *Field, val interface{}) (interface{}, error) {
+	str, ok := val.(string)
+	if !ok {
+		return val, nil
+	}
+	switch f.Type {
+	case datatypes.Integer, datatypes.Float, datatypes.Decimal, datatypes.Boolean:
+		v, err := f.Parse([]byte(str))
+		if err != nil {
+			return nil, err
+		}
+		if r, ok := v.(*big.Rat); ok {
+			s, _ := datatypes.Decimal.ValueToString(r)
+			return json.Number(s), nil
+		}
+		return v, nil
+	}
+	return val, nil
+}
+
+// ignoredJSONSchemaKeywords are annotations & references that don't
+// affect the shape of data
+var ignoredJSONSchemaKeywords = map[string]bool{
+	"$id":         true,
+	"$comment":    true,
+	"examples":    true,
+	"definitions": true,
+	"$defs":       true,
+}
+
+// SchemaFromJSONSchema builds a schema from a JSON Schema document that
+// describes an array of objects or a single object, with each property
+// becoming a field. keywords that can't be mapped are listed in the
+// returned report
+func SchemaFromJSONSchema(js *JSONSchema) (*Schema, *JSONSchemaReport, error) {
+	report := &JSONSchemaReport{}
+	row, path := js, "#"
+	if js.hasType("array") {
+		if js.Items == nil {
+			return nil, nil, fmt.Errorf("array schemas must have an items schema describing rows")
+		}
+		reportRowsExtra(js, path, report)
+		row, path = js.Items, "#/items"
+	}
+	if !row.hasType("object") && !(len(row.Type) == 0 && row.Properties != nil) {
+		return nil, nil, fmt.Errorf("JSON Schema must describe an array of objects or an object")
+	}
+
+	fields := jsonSchemaFields(row, path, report)
+	reportRowsExtra(row, path, report)
+	return &Schema{Fields: fields}, report, nil
+}
+
+// reportRowsExtra records keywords of the top-level schemas that describe
+// rows & lists of rows, which have no field to hold them
+func reportRowsExtra(js *JSONSchema, path string, report *JSONSchemaReport) {
+	if js.MinItems != nil || js.MaxItems != nil || js.MinProperties != nil || js.MaxProperties != nil {
+		report.add(path, "size constraints on rows are not mapped")
+	}
+	reportExtra(js, path, report)
+}
+
+// reportExtra records unmapped keywords of a schema, skipping any keywords
+// the caller has mapped
+func reportExtra(js *JSONSchema, path string, report *JSONSchemaReport, mapped ...string) {
+	keys := make([]string, 0, len(js.Extra))
+	for key := range js.Extra {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if ignoredJSONSchemaKeywords[key] || containsString(mapped, key) {
+			continue
+		}
+		if key == "additionalProperties" && string(bytes.TrimSpace(js.Extra[key])) == "false" {
+			// fields are the only properties rows can have
+			continue
+		}
+		report.add(path+"/"+key, "keyword '%s' is not mapped", key)
+	}
+}
+
+// jsonSchemaFields converts the properties of an object schema to fields
+func jsonSchemaFields(js *JSONSchema, path string, report *JSONSchemaReport) []*Field {
+	required := map[string]bool{}
+	for _, name := range js.Required {
+		required[name] = true
+	}
+
+	fields := make([]*Field, 0, len(js.Properties))
+	for _, prop := range js.Properties {
+		f := jsonSchemaField(prop.Name, prop.Schema, path+"/properties/"+prop.Name, report)
+		if required[prop.Name] {
+			yes := true
+			if f.Constraints == nil {
+				f.Constraints = &FieldConstraints{}
+			}
+			f.Constraints.Required = &yes
+			delete(required, prop.Name)
+		}
+		fields = append(fields, f)
+	}
+
+	missing := make([]string, 0, len(required))
+	for name := range required {
+		missing = append(missing, name)
+	}
+	sort.Strings(missing)
+	for _, name := range missing {
+		report.add(path+"/required", "required property '%s' is not defined", name)
+	}
+	return fields
+}
+
+// jsonSchemaField converts the schema of a single property to a field
+func jsonSchemaField(name string, js *JSONSchema, path string, report *JSONSchemaReport) *Field {
+	f := &Field{Name: name, Title: js.Title, Description: js.Description}
+
+	types := []string{}
+	for _, t := range js.Type {
+		if t != "null" {
+			types = append(types, t)
+		}
+	}
+	switch {
+	case len(types) == 0 && len(js.Type) > 0:
+		f.Type = datatypes.Any
+		report.add(path+"/type", "null-only values are not mapped")
+	case len(types) == 0 && js.Properties != nil:
+		types = []string{"object"}
+	case len(types) == 0 && js.Items != nil:
+		types = []string{"array"}
+	case len(types) > 1:
+		f.Type = datatypes.Any
+		report.add(path+"/type", "multiple types (%s) are not mapped", strings.Join(types, ", "))
+		types = nil
+	}
+
+	typ := ""
+	if len(types) == 1 {
+		typ = types[0]
+	}
+	switch typ {
+	case "":
+		if f.Type == datatypes.Unknown {
+			f.Type = datatypes.Any
+		}
+	case "string":
+		switch js.Format {
+		case "":
+			f.Type = datatypes.String
+		case "date":
+			f.Type = datatypes.Date
+		case "time":
+			f.Type = datatypes.Time
+		case "date-time":
+			f.Type = datatypes.DateTime
+		case "uri", "uri-reference", "iri", "iri-reference":
+			f.Type = datatypes.URL
+		case "duration":
+			f.Type = datatypes.Duration
+		default:
+			f.Type = datatypes.String
+			report.add(path+"/format", "format '%s' is not mapped", js.Format)
+		}
+	case "integer":
+		f.Type = datatypes.Integer
+	case "number":
+		f.Type = datatypes.Float
+	case "boolean":
+		f.Type = datatypes.Boolean
+	case "array":
+		f.Type = datatypes.Array
+		if js.Items != nil {
+			f.Items = jsonSchemaField("", js.Items, path+"/items", report)
+		}
+	case "object":
+		f.Type = datatypes.Object
+		f.Properties = jsonSchemaFields(js, path, report)
+	default:
+		f.Type = datatypes.Any
+		report.add(path+"/type", "type '%s' is not mapped", typ)
+	}
+
+	if js.Format != "" && typ != "string" {
+		report.add(path+"/format", "format '%s' is not mapped", js.Format)
+	}
+	if js.Items != nil && typ != "array" {
+		report.add(path+"/items", "items of %s values are not mapped", f.Type.String())
+	}
+	if js.Properties != nil && typ != "object" {
+		report.add(path+"/properties", "properties of %s values are not mapped", f.Type.String())
+	}
+
+	c := &FieldConstraints{}
+	lengths := []struct {
+		kind     string
+		min, max *int64
+	}{
+		{"string", js.MinLength, js.MaxLength},
+		{"array", js.MinItems, js.MaxItems},
+		{"object", js.MinProperties, js.MaxProperties},
+	}
+	for _, l := range lengths {
+		if l.min == nil && l.max == nil {
+			continue
+		}
+		if typ == l.kind {
+			c.MinLength, c.MaxLength = l.min, l.max
+		} else {
+			report.add(path, "%s length constraints on %s values are not mapped", l.kind, f.Type.String())
+		}
+	}
+
+	if js.Pattern != "" {
+		if typ == "string" {
+			c.Pattern = fieldPattern(js.Pattern)
+		} else {
+			report.add(path+"/pattern", "patterns on %s values are not mapped", f.Type.String())
+		}
+	}
+
+	if js.Minimum != nil || js.Maximum != nil {
+		if typ == "integer" || typ == "number" {
+			c.Minimum, c.Maximum = js.Minimum, js.Maximum
+		} else {
+			report.add(path, "minimum & maximum on %s values are not mapped", f.Type.String())
+		}
+	}
+
+	enum := js.Enum
+	if raw, ok := js.Extra["const"]; ok {
+		var v interface{}
+		if err := json.Unmarshal(raw, &v); err == nil {
+			enum = []interface{}{v}
+		}
+	}
+	for _, e := range enum {
+		if e != nil {
+			c.Enum = append(c.Enum, e)
+		}
+	}
+
+	if c.MinLength != nil || c.MaxLength != nil || c.Pattern != "" || c.Minimum != nil || c.Maximum != nil || c.Enum != nil {
+		f.Constraints = c
+	}
+
+	reportExtra(js, path, report, "const")
+	return f
+}
+
+// fieldPattern converts a JSON Schema pattern, which matches anywhere in
+// a value, to a field pattern, which must match values in full
+func fieldPattern(pattern string) string {
+	if strings.HasPrefix(pattern, "^(?:") && strings.HasSuffix(pattern, ")$") {
+		return pattern[4 : len(pattern)-2]
+	}
+	if strings.HasPrefix(pattern, "^") && strings.HasSuffix(pattern, "$") {
+		return pattern
+	}
+	return "(?s:.*)(?:" + pattern + ")(?s:.*)"
+}
+
+// containsString reports weather a slice contains str
+func containsString(s []string, str string) bool {
+	for _, v := range s {
+		if v == str {
+			return true
+		}
+	}
+	return false
+}
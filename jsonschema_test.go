@@ -0,0 +1,196 @@
+package dataset
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/qri-io/dataset/datatypes"
+)
+
+func TestSchemaJSONSchema(t *testing.T) {
+	yes, two, ten := true, int64(2), int64(10)
+	sch := &Schema{
+		Fields: []*Field{
+			{Name: "id", Type: datatypes.Integer, Constraints: &FieldConstraints{Required: &yes, Unique: &yes, Minimum: "1"}},
+			{Name: "name", Type: datatypes.String, Title: "Name", Constraints: &FieldConstraints{MinLength: &two, MaxLength: &ten, Pattern: "[a-z]+"}},
+			{Name: "score", Type: datatypes.Decimal, Constraints: &FieldConstraints{Enum: []interface{}{"1.50", 2.0}}},
+			{Name: "born", Type: datatypes.Date, Format: "%d/%m/%Y"},
+			{Name: "seen", Type: datatypes.DateTime},
+			{Name: "tags", Type: datatypes.Array, Items: &Field{Type: datatypes.String}, Constraints: &FieldConstraints{MaxLength: &two}},
+			{Name: "place", Type: datatypes.Object, Properties: []*Field{
+				{Name: "city", Type: datatypes.String, Constraints: &FieldConstraints{Required: &yes}},
+				{Name: "loc", Type: datatypes.GeoPoint},
+			}},
+			{Name: "extra", Type: datatypes.JSON},
+		},
+		PrimaryKey: FieldKey{"id"},
+	}
+
+	js, report := sch.JSONSchema()
+	data, err := json.Marshal(js)
+	if err != nil {
+		t.Fatalf("error marshaling json schema: %s", err.Error())
+	}
+	expect := `{"$schema":"http://json-schema.org/draft-07/schema#","type":"array","items":{"type":"object","properties":{` +
+		`"id":{"type":"integer","minimum":1},` +
+		`"name":{"title":"Name","type":["string","null"],"minLength":2,"maxLength":10,"pattern":"^(?:[a-z]+)$"},` +
+		`"score":{"type":["number","null"],"enum":[1.5,2,null]},` +
+		`"born":{"type":["string","null"]},` +
+		`"seen":{"type":["string","null"],"format":"date-time"},` +
+		`"tags":{"type":["array","null"],"items":{"type":"string"},"maxItems":2},` +
+		`"place":{"type":["object","null"],"properties":{"city":{"type":"string"},"loc":{"type":["string","null"]}},"required":["city"]},` +
+		`"extra":{}` +
+		`},"required":["id"]}}`
+	if string(data) != expect {
+		t.Errorf("json schema mismatch. expected:\n%s\ngot:\n%s", expect, string(data))
+	}
+
+	paths := []string{"fields.id.constraints.unique", "fields.born", "fields.place.loc", "primaryKey"}
+	if len(report.Unmapped) != len(paths) {
+		t.Fatalf("report length mismatch. expected: %d, got: %d", len(paths), len(report.Unmapped))
+	}
+	for i, p := range paths {
+		if report.Unmapped[i].Path != p {
+			t.Errorf("report %d path mismatch. expected: %s, got: %s (%s)", i, p, report.Unmapped[i].Path, report.Unmapped[i].Message)
+		}
+	}
+
+	if _, _, err := (&Structure{}).JSONSchema(); err == nil || err.Error() != "structure has no schema" {
+		t.Errorf("expected missing schema error, got: %v", err)
+	}
+	st := &Structure{Format: JSONDataFormat, FormatConfig: &JSONOptions{ArrayEntries: true}, Schema: &Schema{}}
+	if _, report, err := st.JSONSchema(); err != nil || len(report.Unmapped) != 1 || report.Unmapped[0].Path != "formatConfig.arrayEntries" {
+		t.Errorf("expected array entries to be reported")
+	}
+}
+
+func TestSchemaFromJSONSchema(t *testing.T) {
+	doc := `{
+		"$schema": "http://json-schema.org/draft-07/schema#",
+		"type": "array",
+		"items": {
+			"type": "object",
+			"required": ["id", "email", "missing"],
+			"additionalProperties": false,
+			"properties": {
+				"id": { "type": "integer", "minimum": 1, "exclusiveMaximum": 100 },
+				"email": { "type": "string", "format": "email" },
+				"name": { "type": ["string", "null"], "pattern": "^[A-Z]", "maxLength": 20 },
+				"status": { "enum": ["open", "closed", null] },
+				"kind": { "const": "person" },
+				"born": { "type": "string", "format": "date" },
+				"site": { "type": "string", "format": "uri" },
+				"tags": { "type": "array", "items": { "type": "string" }, "minItems": 1 },
+				"address": { "properties": { "city": { "type": "string" } }, "required": ["city"] },
+				"value": { "type": ["number", "string"] },
+				"ref": { "$ref": "#/definitions/thing" }
+			}
+		}
+	}`
+
+	js := &JSONSchema{}
+	if err := json.Unmarshal([]byte(doc), js); err != nil {
+		t.Fatalf("error unmarshaling json schema: %s", err.Error())
+	}
+	sch, report, err := SchemaFromJSONSchema(js)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+
+	got, err := json.Marshal(sch)
+	if err != nil {
+		t.Fatalf("error marshaling schema: %s", err.Error())
+	}
+	expect := `{"fields":[` +
+		`{"constraints":{"required":true,"minimum":1},"name":"id","type":"integer"},` +
+		`{"constraints":{"required":true},"name":"email","type":"string"},` +
+		`{"constraints":{"maxLength":20,"pattern":"(?s:.*)(?:^[A-Z])(?s:.*)"},"name":"name","type":"string"},` +
+		`{"constraints":{"enum":["open","closed"]},"name":"status","type":"any"},` +
+		`{"constraints":{"enum":["person"]},"name":"kind","type":"any"},` +
+		`{"name":"born","type":"date"},` +
+		`{"name":"site","type":"url"},` +
+		`{"constraints":{"minLength":1},"items":{"name":"","type":"string"},"name":"tags","type":"array"},` +
+		`{"name":"address","properties":[{"constraints":{"required":true},"name":"city","type":"string"}],"type":"object"},` +
+		`{"name":"value","type":"any"},` +
+		`{"name":"ref","type":"any"}` +
+		`]}`
+	if string(got) != expect {
+		t.Errorf("schema mismatch. expected:\n%s\ngot:\n%s", expect, string(got))
+	}
+
+	issues := []string{
+		"#/items/properties/id/exclusiveMaximum",
+		"#/items/properties/email/format",
+		"#/items/properties/value/type",
+		"#/items/properties/ref/$ref",
+		"#/items/required",
+	}
+	if len(report.Unmapped) != len(issues) {
+		for _, u := range report.Unmapped {
+			t.Logf("%s: %s", u.Path, u.Message)
+		}
+		t.Fatalf("report length mismatch. expected: %d, got: %d", len(issues), len(report.Unmapped))
+	}
+	for i, p := range issues {
+		if report.Unmapped[i].Path != p {
+			t.Errorf("report %d path mismatch. expected: %s, got: %s", i, p, report.Unmapped[i].Path)
+		}
+	}
+	if msg := report.Unmapped[4].Message; msg != "required property 'missing' is not defined" {
+		t.Errorf("message mismatch. got: %s", msg)
+	}
+
+	errs := []struct {
+		doc, err string
+	}{
+		{`{"type":"array"}`, "array schemas must have an items schema describing rows"},
+		{`{"type":"string"}`, "JSON Schema must describe an array of objects or an object"},
+		{`{"type":"array","items":{"type":"integer"}}`, "JSON Schema must describe an array of objects or an object"},
+	}
+	for i, c := range errs {
+		js := &JSONSchema{}
+		if err := json.Unmarshal([]byte(c.doc), js); err != nil {
+			t.Fatalf("case %d error unmarshaling: %s", i, err.Error())
+		}
+		if _, _, err := SchemaFromJSONSchema(js); err == nil || err.Error() != c.err {
+			t.Errorf("case %d error mismatch. expected: '%s', got: '%v'", i, c.err, err)
+		}
+	}
+}
+
+func TestJSONSchemaRoundTrip(t *testing.T) {
+	yes, one := true, int64(1)
+	sch := &Schema{
+		Fields: []*Field{
+			{Name: "id", Type: datatypes.Integer, Description: "identifier", Constraints: &FieldConstraints{Required: &yes, Minimum: float64(0)}},
+			{Name: "code", Type: datatypes.String, Constraints: &FieldConstraints{MinLength: &one, Pattern: "[A-Z]{2}"}},
+			{Name: "ratio", Type: datatypes.Float, Constraints: &FieldConstraints{Enum: []interface{}{0.5, 1.0}}},
+			{Name: "at", Type: datatypes.Time},
+			{Name: "points", Type: datatypes.Array, Items: &Field{Type: datatypes.Integer}},
+			{Name: "active", Type: datatypes.Boolean},
+		},
+	}
+
+	js, report := sch.JSONSchema()
+	if len(report.Unmapped) != 0 {
+		t.Errorf("expected no unmapped constructs, got: %d", len(report.Unmapped))
+	}
+	data, err := json.Marshal(js)
+	if err != nil {
+		t.Fatalf("error marshaling json schema: %s", err.Error())
+	}
+	got := &JSONSchema{}
+	if err := json.Unmarshal(data, got); err != nil {
+		t.Fatalf("error unmarshaling json schema: %s", err.Error())
+	}
+	imported, report, err := SchemaFromJSONSchema(got)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+	if len(report.Unmapped) != 0 {
+		t.Errorf("expected no unmapped constructs, got: %d", len(report.Unmapped))
+	}
+	if err := CompareSchemas(sch, imported); err != nil {
+		t.Errorf("round trip mismatch: %s", err.Error())
+	}
+}
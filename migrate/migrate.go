@@ -0,0 +1,654 @@
+package migrate
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/ipfs/go-datastore"
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/dataset/datatypes"
+	"github.com/qri-io/dataset/dsio"
+)
+
+// Syntax identifies transforms that record a migration
+const Syntax = "migration"
+
+// Op is the kind of change a migration step makes
+type Op string
+
+const (
+	// OpRename changes the name of Field to To
+	OpRename Op = "rename"
+	// OpDrop removes Field & it's values
+	OpDrop Op = "drop"
+	// OpAdd appends a new field named Field of Type, filling every row
+	// with Default
+	OpAdd Op = "add"
+	// OpReorder arranges fields in the order of Fields, which must list
+	// every field exactly once
+	OpReorder Op = "reorder"
+	// OpCast converts the values of Field to Type, written with Format
+	OpCast Op = "cast"
+	// OpSplit replaces Field with string fields named by Fields, splitting
+	// values on Separator
+	OpSplit Op = "split"
+	// OpMerge replaces Fields with a single string field named To, joining
+	// values with Separator
+	OpMerge Op = "merge"
+)
+
+// Step is a single change to a structure & it's data
+type Step struct {
+	Op        Op             `json:"op"`
+	Field     string         `json:"field,omitempty"`
+	Fields    []string       `json:"fields,omitempty"`
+	To        string         `json:"to,omitempty"`
+	Type      datatypes.Type `json:"type,omitempty"`
+	Format    string         `json:"format,omitempty"`
+	Default   string         `json:"default,omitempty"`
+	Separator string         `json:"separator,omitempty"`
+}
+
+// Migration is an ordered list of steps that rewrite data from one
+// structure to another
+type Migration struct {
+	Steps []*Step `json:"steps"`
+}
+
+// rowFunc rewrites a single row of data
+type rowFunc func(row [][]byte) ([][]byte, error)
+
+// plan is a migration compiled against a specific input structure
+type plan struct {
+	st    *dataset.Structure
+	funcs []rowFunc
+}
+
+// Structure gives the result of applying the migration to st. st is not
+// modified
+func (m *Migration) Structure(st *dataset.Structure) (*dataset.Structure, error) {
+	p, err := m.compile(st)
+	if err != nil {
+		return nil, err
+	}
+	return p.st, nil
+}
+
+// compile checks each step against the fields it will see, building the
+// output structure & the functions that rewrite rows
+func (m *Migration) compile(st *dataset.Structure) (*plan, error) {
+	if st == nil || st.Schema == nil {
+		return nil, fmt.Errorf("structure must have a schema to migrate")
+	}
+
+	sch := &dataset.Schema{
+		Fields:     make([]*dataset.Field, len(st.Schema.Fields)),
+		PrimaryKey: append(dataset.FieldKey{}, st.Schema.PrimaryKey...),
+	}
+	for i, f := range st.Schema.Fields {
+		cp := *f
+		sch.Fields[i] = &cp
+	}
+	for _, fk := range st.Schema.ForeignKeys {
+		cp := &dataset.ForeignKey{Fields: append(dataset.FieldKey{}, fk.Fields...)}
+		if fk.Reference != nil {
+			cp.Reference = &dataset.ForeignKeyReference{
+				Dataset: fk.Reference.Dataset,
+				Fields:  append(dataset.FieldKey{}, fk.Reference.Fields...),
+			}
+		}
+		sch.ForeignKeys = append(sch.ForeignKeys, cp)
+	}
+
+	p := &plan{
+		st: &dataset.Structure{
+			Format:        st.Format,
+			FormatConfig:  st.FormatConfig,
+			Encoding:      st.Encoding,
+			Compression:   st.Compression,
+			MissingValues: st.MissingValues,
+			Schema:        sch,
+		},
+	}
+	for i, s := range m.Steps {
+		fn, err := s.compile(p.st)
+		if err != nil {
+			return nil, fmt.Errorf("step %d (%s): %s", i, s.Op, err.Error())
+		}
+		p.funcs = append(p.funcs, fn)
+	}
+	return p, nil
+}
+
+// compile applies the step to the schema of st, returning a function that
+// applies the step to rows
+func (s *Step) compile(st *dataset.Structure) (rowFunc, error) {
+	sch := st.Schema
+	switch s.Op {
+	case OpRename:
+		i, err := fieldIndex(sch, s.Field)
+		if err != nil {
+			return nil, err
+		}
+		if s.To == "" {
+			return nil, fmt.Errorf("new field name is required")
+		}
+		if s.To != s.Field {
+			if _, err := fieldIndex(sch, s.To); err == nil {
+				return nil, fmt.Errorf("field '%s' already exists", s.To)
+			}
+		}
+		sch.Fields[i].Name = s.To
+		renameKeys(sch, s.Field, s.To)
+		return func(row [][]byte) ([][]byte, error) { return row, nil }, nil
+
+	case OpDrop:
+		i, err := fieldIndex(sch, s.Field)
+		if err != nil {
+			return nil, err
+		}
+		if err := checkKeys(sch, s.Field); err != nil {
+			return nil, err
+		}
+		sch.Fields = append(sch.Fields[:i], sch.Fields[i+1:]...)
+		return func(row [][]byte) ([][]byte, error) {
+			return removeCells(row, i), nil
+		}, nil
+
+	case OpAdd:
+		if s.Field == "" {
+			return nil, fmt.Errorf("field name is required")
+		}
+		if _, err := fieldIndex(sch, s.Field); err == nil {
+			return nil, fmt.Errorf("field '%s' already exists", s.Field)
+		}
+		f := &dataset.Field{Name: s.Field, Type: s.Type, Format: s.Format}
+		if f.Type == datatypes.Unknown {
+			f.Type = datatypes.String
+		}
+		if s.Default != "" {
+			if _, err := f.Parse([]byte(s.Default)); err != nil {
+				return nil, fmt.Errorf("invalid default: %s", err.Error())
+			}
+		}
+		sch.Fields = append(sch.Fields, f)
+		def := []byte(s.Default)
+		return func(row [][]byte) ([][]byte, error) {
+			return append(row, def), nil
+		}, nil
+
+	case OpReorder:
+		if len(s.Fields) != len(sch.Fields) {
+			return nil, fmt.Errorf("order must list all %d fields, got %d", len(sch.Fields), len(s.Fields))
+		}
+		idx := make([]int, len(s.Fields))
+		fields := make([]*dataset.Field, len(s.Fields))
+		seen := map[string]bool{}
+		for i, name := range s.Fields {
+			if seen[name] {
+				return nil, fmt.Errorf("field '%s' listed more than once", name)
+			}
+			seen[name] = true
+			j, err := fieldIndex(sch, name)
+			if err != nil {
+				return nil, err
+			}
+			idx[i], fields[i] = j, sch.Fields[j]
+		}
+		sch.Fields = fields
+		return func(row [][]byte) ([][]byte, error) {
+			out := make([][]byte, len(idx))
+			for i, j := range idx {
+				out[i] = cell(row, j)
+			}
+			return out, nil
+		}, nil
+
+	case OpCast:
+		i, err := fieldIndex(sch, s.Field)
+		if err != nil {
+			return nil, err
+		}
+		if s.Type == datatypes.Unknown {
+			return nil, fmt.Errorf("type is required")
+		}
+		from := sch.Fields[i]
+		cp := *from
+		to := &cp
+		to.Type, to.Format = s.Type, s.Format
+		cast, err := newCaster(from, to)
+		if err != nil {
+			return nil, err
+		}
+		sch.Fields[i] = to
+		return func(row [][]byte) ([][]byte, error) {
+			// null values are kept as-is, remaining null in the cast field
+			if i < len(row) && !st.IsNull(from, row[i]) {
+				val, err := cast(row[i])
+				if err != nil {
+					return nil, fmt.Errorf("field %s: %s", to.Name, err.Error())
+				}
+				row[i] = val
+			}
+			return row, nil
+		}, nil
+
+	case OpSplit:
+		i, err := fieldIndex(sch, s.Field)
+		if err != nil {
+			return nil, err
+		}
+		if s.Separator == "" {
+			return nil, fmt.Errorf("separator is required")
+		}
+		if len(s.Fields) == 0 {
+			return nil, fmt.Errorf("split requires at least one new field")
+		}
+		if err := checkKeys(sch, s.Field); err != nil {
+			return nil, err
+		}
+		added := make([]*dataset.Field, len(s.Fields))
+		for j, name := range s.Fields {
+			if _, err := fieldIndex(sch, name); err == nil && name != s.Field {
+				return nil, fmt.Errorf("field '%s' already exists", name)
+			}
+			added[j] = &dataset.Field{Name: name, Type: datatypes.String}
+		}
+		sch.Fields = append(sch.Fields[:i], append(added, sch.Fields[i+1:]...)...)
+		n, sep := len(s.Fields), []byte(s.Separator)
+		return func(row [][]byte) ([][]byte, error) {
+			parts := make([][]byte, n)
+			if v := cell(row, i); len(v) > 0 {
+				copy(parts, splitN(v, sep, n))
+			}
+			for j := range parts {
+				if parts[j] == nil {
+					parts[j] = []byte("")
+				}
+			}
+			if i >= len(row) {
+				return append(row, parts...), nil
+			}
+			return append(row[:i], append(parts, row[i+1:]...)...), nil
+		}, nil
+
+	case OpMerge:
+		if len(s.Fields) < 2 {
+			return nil, fmt.Errorf("merge requires at least two fields")
+		}
+		if s.To == "" {
+			return nil, fmt.Errorf("new field name is required")
+		}
+		idx := make([]int, len(s.Fields))
+		for j, name := range s.Fields {
+			k, err := fieldIndex(sch, name)
+			if err != nil {
+				return nil, err
+			}
+			if err := checkKeys(sch, name); err != nil {
+				return nil, err
+			}
+			idx[j] = k
+		}
+		if k, err := fieldIndex(sch, s.To); err == nil && !containsInt(idx, k) {
+			return nil, fmt.Errorf("field '%s' already exists", s.To)
+		}
+
+		// merged values take the place of the first merged field
+		pos, drop := idx[0], map[int]bool{}
+		for _, k := range idx {
+			drop[k] = true
+			if k < pos {
+				pos = k
+			}
+		}
+		// src holds the input index of each output field, -1 for the merged field
+		fields, src := []*dataset.Field{}, []int{}
+		for k, f := range sch.Fields {
+			if k == pos {
+				fields, src = append(fields, &dataset.Field{Name: s.To, Type: datatypes.String}), append(src, -1)
+			} else if !drop[k] {
+				fields, src = append(fields, f), append(src, k)
+			}
+		}
+		sch.Fields = fields
+		sep := []byte(s.Separator)
+		return func(row [][]byte) ([][]byte, error) {
+			out := make([][]byte, len(src))
+			for i, k := range src {
+				if k >= 0 {
+					out[i] = cell(row, k)
+					continue
+				}
+				vals := make([][]byte, len(idx))
+				for j, m := range idx {
+					vals[j] = cell(row, m)
+				}
+				out[i] = joinCells(vals, sep)
+			}
+			return out, nil
+		}, nil
+
+	default:
+		return nil, fmt.Errorf("unknown migration op: '%s'", s.Op)
+	}
+}
+
+// Reader wraps r in a reader that gives migrated rows under the migrated
+// structure
+func (m *Migration) Reader(r dsio.RowReader) (dsio.RowReader, error) {
+	p, err := m.compile(r.Structure())
+	if err != nil {
+		return nil, err
+	}
+	return &reader{r: r, p: p}, nil
+}
+
+// Apply migrates each row of r, writing the results to w. w should be
+// created with the migrated structure. Apply does not close w
+func (m *Migration) Apply(r dsio.RowReader, w dsio.RowWriter) error {
+	mr, err := m.Reader(r)
+	if err != nil {
+		return err
+	}
+	if len(w.Structure().Schema.Fields) != len(mr.Structure().Schema.Fields) {
+		return fmt.Errorf("writer has %d fields, migrated structure has %d", len(w.Structure().Schema.Fields), len(mr.Structure().Schema.Fields))
+	}
+	return dsio.EachRow(mr, func(num int, row [][]byte, err error) error {
+		if err != nil {
+			return err
+		}
+		return w.WriteRow(row)
+	})
+}
+
+// Transform records the migration as a transform that produces the
+// migrated version of ds. ds must have a path
+func (m *Migration) Transform(ds *dataset.Dataset) (*dataset.Transform, error) {
+	if ds == nil || ds.Structure == nil {
+		return nil, fmt.Errorf("dataset must have a structure to migrate")
+	}
+	if ds.Path().String() == "" {
+		return nil, fmt.Errorf("dataset must have a path to record a migration")
+	}
+	st, err := m.Structure(ds.Structure)
+	if err != nil {
+		return nil, err
+	}
+
+	// round-trip steps through JSON so config holds plain values, matching
+	// what unmarshaling a stored transform gives
+	data, err := json.Marshal(m.Steps)
+	if err != nil {
+		return nil, fmt.Errorf("error encoding steps: %s", err.Error())
+	}
+	var steps []interface{}
+	if err := json.Unmarshal(data, &steps); err != nil {
+		return nil, fmt.Errorf("error encoding steps: %s", err.Error())
+	}
+
+	return &dataset.Transform{
+		Syntax:    Syntax,
+		Structure: st,
+		Config:    map[string]interface{}{"steps": steps},
+		Resources: map[string]*dataset.Dataset{
+			"a": dataset.NewDatasetRef(ds.Path()),
+		},
+	}, nil
+}
+
+// FromTransform reads the migration recorded in a transform, along with
+// the path of the dataset it was applied to
+func FromTransform(t *dataset.Transform) (*Migration, datastore.Key, error) {
+	if t.Syntax != Syntax {
+		return nil, datastore.Key{}, fmt.Errorf("transform syntax '%s' is not a migration", t.Syntax)
+	}
+	data, err := json.Marshal(t.Config["steps"])
+	if err != nil {
+		return nil, datastore.Key{}, fmt.Errorf("error reading steps: %s", err.Error())
+	}
+	m := &Migration{}
+	if err := json.Unmarshal(data, &m.Steps); err != nil {
+		return nil, datastore.Key{}, fmt.Errorf("error reading steps: %s", err.Error())
+	}
+	var path datastore.Key
+	if src := t.Resources["a"]; src != nil {
+		path = src.Path()
+	}
+	return m, path, nil
+}
+
+// reader applies a compiled migration to each row read
+type reader struct {
+	r   dsio.RowReader
+	p   *plan
+	num int
+}
+
+// Structure gives the migrated structure
+func (r *reader) Structure() *dataset.Structure {
+	return r.p.st
+}
+
+// ReadRow reads & migrates one row
+func (r *reader) ReadRow() ([][]byte, error) {
+	row, err := r.r.ReadRow()
+	if err != nil {
+		return nil, err
+	}
+	num := r.num
+	r.num++
+
+	out := make([][]byte, len(row))
+	copy(out, row)
+	for i, fn := range r.p.funcs {
+		if out, err = fn(out); err != nil {
+			return nil, fmt.Errorf("row %d, step %d: %s", num, i, err.Error())
+		}
+	}
+	return out, nil
+}
+
+// newCaster gives a function that converts values of field from to the
+// type & format of field to
+func newCaster(from, to *dataset.Field) (func(value []byte) ([]byte, error), error) {
+	fromNum, err := from.NumberFormat()
+	if err != nil {
+		return nil, err
+	}
+	toNum, err := to.NumberFormat()
+	if err != nil {
+		return nil, err
+	}
+
+	switch to.Type {
+	case datatypes.String, datatypes.Any:
+		return func(value []byte) ([]byte, error) { return value, nil }, nil
+
+	case datatypes.Integer, datatypes.Float, datatypes.Decimal:
+		return func(value []byte) ([]byte, error) {
+			if len(value) == 0 {
+				return value, nil
+			}
+			plain := value
+			if isNumber(from.Type) {
+				var err error
+				if plain, err = fromNum.Normalize(value); err != nil {
+					return nil, fmt.Errorf("cannot cast '%s' to %s", string(value), to.Type.String())
+				}
+			}
+			r, err := datatypes.ParseDecimal(plain)
+			if err != nil {
+				return nil, fmt.Errorf("cannot cast '%s' to %s", string(value), to.Type.String())
+			}
+			var str string
+			switch to.Type {
+			case datatypes.Integer:
+				if !r.IsInt() || !r.Num().IsInt64() {
+					return nil, fmt.Errorf("cannot cast '%s' to %s", string(value), to.Type.String())
+				}
+				str = r.Num().String()
+			case datatypes.Float:
+				f, _ := r.Float64()
+				str = strconv.FormatFloat(f, 'g', -1, 64)
+			default:
+				str, _ = datatypes.Decimal.ValueToString(r)
+			}
+			return []byte(toNum.FormatValue(str)), nil
+		}, nil
+
+	case datatypes.Date, datatypes.Time, datatypes.DateTime:
+		toTime, err := datatypes.ParseTimeFormat(to.Type, to.Format)
+		if err != nil {
+			return nil, err
+		}
+		fromTime := toTime
+		if isTime(from.Type) {
+			if fromTime, err = datatypes.ParseTimeFormat(from.Type, from.Format); err != nil {
+				return nil, err
+			}
+		}
+		return func(value []byte) ([]byte, error) {
+			if len(value) == 0 {
+				return value, nil
+			}
+			t, err := fromTime.ParseValue(value)
+			if err != nil {
+				return nil, fmt.Errorf("cannot cast '%s' to %s", string(value), to.Type.String())
+			}
+			return []byte(toTime.FormatValue(t)), nil
+		}, nil
+
+	case datatypes.Boolean:
+		return func(value []byte) ([]byte, error) {
+			if len(value) == 0 {
+				return value, nil
+			}
+			b, err := datatypes.ParseBoolean(value)
+			if err != nil {
+				return nil, fmt.Errorf("cannot cast '%s' to %s", string(value), to.Type.String())
+			}
+			return []byte(strconv.FormatBool(b)), nil
+		}, nil
+
+	default:
+		return func(value []byte) ([]byte, error) {
+			if len(value) == 0 {
+				return value, nil
+			}
+			if _, err := to.Parse(value); err != nil {
+				return nil, fmt.Errorf("cannot cast '%s' to %s", string(value), to.Type.String())
+			}
+			return value, nil
+		}, nil
+	}
+}
+
+// isNumber reports weather t is a numeric type
+func isNumber(t datatypes.Type) bool {
+	return t == datatypes.Integer || t == datatypes.Float || t == datatypes.Decimal
+}
+
+// isTime reports weather t is a date or time type
+func isTime(t datatypes.Type) bool {
+	return t == datatypes.Date || t == datatypes.Time || t == datatypes.DateTime
+}
+
+// fieldIndex finds the position of a named field in a schema
+func fieldIndex(sch *dataset.Schema, name string) (int, error) {
+	for i, f := range sch.Fields {
+		if f.Name == name {
+			return i, nil
+		}
+	}
+	return -1, fmt.Errorf("field '%s' not found", name)
+}
+
+// renameKeys updates references to a field in a schema's keys
+func renameKeys(sch *dataset.Schema, from, to string) {
+	rename := func(key dataset.FieldKey) {
+		for i, name := range key {
+			if name == from {
+				key[i] = to
+			}
+		}
+	}
+	rename(sch.PrimaryKey)
+	for _, fk := range sch.ForeignKeys {
+		rename(fk.Fields)
+		if fk.Reference != nil && fk.Reference.Dataset == "" {
+			rename(fk.Reference.Fields)
+		}
+	}
+}
+
+// checkKeys errors if a field is part of a schema's primary or foreign keys,
+// which would no longer be valid once the field is removed
+func checkKeys(sch *dataset.Schema, name string) error {
+	uses := func(key dataset.FieldKey) bool {
+		for _, n := range key {
+			if n == name {
+				return true
+			}
+		}
+		return false
+	}
+	if uses(sch.PrimaryKey) {
+		return fmt.Errorf("field '%s' is part of the primary key", name)
+	}
+	for i, fk := range sch.ForeignKeys {
+		if uses(fk.Fields) || fk.Reference != nil && fk.Reference.Dataset == "" && uses(fk.Reference.Fields) {
+			return fmt.Errorf("field '%s' is part of foreign key %d", name, i)
+		}
+	}
+	return nil
+}
+
+// cell gives the value at index i of a row, or an empty value for short
+// rows
+func cell(row [][]byte, i int) []byte {
+	if i < len(row) {
+		return row[i]
+	}
+	return []byte("")
+}
+
+// removeCells removes the value at index i of a row
+func removeCells(row [][]byte, i int) [][]byte {
+	if i >= len(row) {
+		return row
+	}
+	return append(row[:i], row[i+1:]...)
+}
+
+// splitN splits value on sep into at most n parts, with the last part
+// holding any remainder
+func splitN(value, sep []byte, n int) [][]byte {
+	parts := strings.SplitN(string(value), string(sep), n)
+	out := make([][]byte, len(parts))
+	for i, p := range parts {
+		out[i] = []byte(p)
+	}
+	return out
+}
+
+// joinCells joins values with sep
+func joinCells(vals [][]byte, sep []byte) []byte {
+	strs := make([]string, len(vals))
+	for i, v := range vals {
+		strs[i] = string(v)
+	}
+	return []byte(strings.Join(strs, string(sep)))
+}
+
+// containsInt reports weather a slice contains i
+func containsInt(s []int, i int) bool {
+	for _, v := range s {
+		if v == i {
+			return true
+		}
+	}
+	return false
+}
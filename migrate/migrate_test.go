@@ -0,0 +1,338 @@
+package migrate
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/ipfs/go-datastore"
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/dataset/datatypes"
+	"github.com/qri-io/dataset/dsio"
+)
+
+func peopleStructure() *dataset.Structure {
+	return &dataset.Structure{
+		Format:       dataset.CSVDataFormat,
+		FormatConfig: &dataset.CSVOptions{HeaderRow: true},
+		Schema: &dataset.Schema{
+			Fields: []*dataset.Field{
+				{Name: "id", Type: datatypes.Integer},
+				{Name: "name", Type: datatypes.String},
+				{Name: "born", Type: datatypes.Date, Format: "%d/%m/%Y"},
+				{Name: "score", Type: datatypes.String},
+				{Name: "city", Type: datatypes.String},
+			},
+			PrimaryKey: dataset.FieldKey{"id"},
+		},
+	}
+}
+
+func TestMigrationStructure(t *testing.T) {
+	cases := []struct {
+		steps  []*Step
+		fields []string
+		err    string
+	}{
+		{[]*Step{}, []string{"id:integer", "name:string", "born:date", "score:string", "city:string"}, ""},
+		{[]*Step{{Op: OpRename, Field: "id", To: "person_id"}, {Op: OpDrop, Field: "city"}},
+			[]string{"person_id:integer", "name:string", "born:date", "score:string"}, ""},
+		{[]*Step{{Op: OpAdd, Field: "active", Type: datatypes.Boolean, Default: "true"}, {Op: OpCast, Field: "score", Type: datatypes.Float}},
+			[]string{"id:integer", "name:string", "born:date", "score:float", "city:string", "active:boolean"}, ""},
+		{[]*Step{{Op: OpReorder, Fields: []string{"city", "score", "born", "name", "id"}}},
+			[]string{"city:string", "score:string", "born:date", "name:string", "id:integer"}, ""},
+		{[]*Step{{Op: OpSplit, Field: "name", Fields: []string{"first", "last"}, Separator: " "}},
+			[]string{"id:integer", "first:string", "last:string", "born:date", "score:string", "city:string"}, ""},
+		{[]*Step{{Op: OpMerge, Fields: []string{"city", "name"}, To: "label", Separator: "-"}},
+			[]string{"id:integer", "label:string", "born:date", "score:string"}, ""},
+		{[]*Step{{Op: OpRename, Field: "nope", To: "x"}}, nil, "step 0 (rename): field 'nope' not found"},
+		{[]*Step{{Op: OpRename, Field: "id", To: "name"}}, nil, "step 0 (rename): field 'name' already exists"},
+		{[]*Step{{Op: OpDrop, Field: "id"}}, nil, "step 0 (drop): field 'id' is part of the primary key"},
+		{[]*Step{{Op: OpAdd, Field: "n", Type: datatypes.Integer, Default: "x"}}, nil, "step 0 (add): invalid default: strconv.ParseInt: parsing \"x\": invalid syntax"},
+		{[]*Step{{Op: OpReorder, Fields: []string{"id"}}}, nil, "step 0 (reorder): order must list all 5 fields, got 1"},
+		{[]*Step{{Op: OpCast, Field: "born"}}, nil, "step 0 (cast): type is required"},
+		{[]*Step{{Op: OpSplit, Field: "name", Fields: []string{"a"}}}, nil, "step 0 (split): separator is required"},
+		{[]*Step{{Op: OpMerge, Fields: []string{"name"}, To: "x"}}, nil, "step 0 (merge): merge requires at least two fields"},
+		{[]*Step{{Op: OpDrop, Field: "city"}, {Op: OpDrop, Field: "city"}}, nil, "step 1 (drop): field 'city' not found"},
+		{[]*Step{{Op: "explode"}}, nil, "step 0 (explode): unknown migration op: 'explode'"},
+	}
+
+	for i, c := range cases {
+		st := peopleStructure()
+		m := &Migration{Steps: c.steps}
+		got, err := m.Structure(st)
+		if !(err == nil && c.err == "" || err != nil && err.Error() == c.err) {
+			t.Errorf("case %d error mismatch. expected: '%s', got: '%s'", i, c.err, err)
+			continue
+		}
+		if c.err != "" {
+			continue
+		}
+		if len(got.Schema.Fields) != len(c.fields) {
+			t.Errorf("case %d field count mismatch. expected: %d, got: %d", i, len(c.fields), len(got.Schema.Fields))
+			continue
+		}
+		for j, f := range got.Schema.Fields {
+			if str := f.Name + ":" + f.Type.String(); str != c.fields[j] {
+				t.Errorf("case %d field %d mismatch. expected: %s, got: %s", i, j, c.fields[j], str)
+			}
+		}
+		if len(st.Schema.Fields) != 5 || st.Schema.Fields[0].Name != "id" || st.Schema.Fields[3].Type != datatypes.String {
+			t.Errorf("case %d modified input structure", i)
+		}
+	}
+}
+
+func TestMigrationRenameKeys(t *testing.T) {
+	st := peopleStructure()
+	st.Schema.ForeignKeys = []*dataset.ForeignKey{
+		{Fields: dataset.FieldKey{"name"}, Reference: &dataset.ForeignKeyReference{Fields: dataset.FieldKey{"id"}}},
+	}
+	m := &Migration{Steps: []*Step{{Op: OpRename, Field: "id", To: "pid"}}}
+	got, err := m.Structure(st)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+	if got.Schema.PrimaryKey[0] != "pid" || got.Schema.ForeignKeys[0].Reference.Fields[0] != "pid" {
+		t.Errorf("expected keys to be renamed, got: %v %v", got.Schema.PrimaryKey, got.Schema.ForeignKeys[0].Reference.Fields)
+	}
+	if st.Schema.PrimaryKey[0] != "id" || st.Schema.ForeignKeys[0].Reference.Fields[0] != "id" {
+		t.Errorf("input structure keys were modified")
+	}
+}
+
+func TestMigrationApply(t *testing.T) {
+	data := "id,name,born,score,city\n1,ada lovelace,10/12/1815,\"1,000.5\",london\n2,alan turing,23/06/1912,,wilmslow\n3,grace,09/12/1906,7,\n"
+	m := &Migration{Steps: []*Step{
+		{Op: OpRename, Field: "id", To: "person_id"},
+		{Op: OpCast, Field: "born", Type: datatypes.Date},
+		{Op: OpSplit, Field: "name", Fields: []string{"first", "last"}, Separator: " "},
+		{Op: OpCast, Field: "score", Type: datatypes.Float},
+		{Op: OpAdd, Field: "country", Default: "uk"},
+		{Op: OpMerge, Fields: []string{"city", "country"}, To: "place", Separator: ", "},
+		{Op: OpReorder, Fields: []string{"person_id", "last", "first", "place", "born", "score"}},
+	}}
+
+	// score values are strings with group characters
+	st := peopleStructure()
+	st.Schema.Fields[3].Type = datatypes.Float
+	st.Schema.Fields[3].GroupChar = ","
+	m.Steps[3].Type = datatypes.Integer
+
+	r, err := dsio.NewRowReader(st, strings.NewReader(data))
+	if err != nil {
+		t.Fatalf("error allocating row reader: %s", err.Error())
+	}
+	nst, err := m.Structure(st)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+	buf := &bytes.Buffer{}
+	w, err := dsio.NewRowWriter(nst, buf)
+	if err != nil {
+		t.Fatalf("error allocating row writer: %s", err.Error())
+	}
+	err = m.Apply(r, w)
+	if err == nil || err.Error() != "error reading row: row 0, step 3: field score: cannot cast '1,000.5' to integer" {
+		t.Errorf("expected cast error, got: %v", err)
+	}
+
+	m.Steps[3].Type = datatypes.Float
+	if r, err = dsio.NewRowReader(st, strings.NewReader(data)); err != nil {
+		t.Fatalf("error allocating row reader: %s", err.Error())
+	}
+	if nst, err = m.Structure(st); err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+	buf.Reset()
+	if w, err = dsio.NewRowWriter(nst, buf); err != nil {
+		t.Fatalf("error allocating row writer: %s", err.Error())
+	}
+	if err := m.Apply(r, w); err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("error closing writer: %s", err.Error())
+	}
+
+	expect := "person_id,last,first,place,born,score\n1,lovelace,ada,\"london, uk\",1815-12-10,\"1,000.5\"\n2,turing,alan,\"wilmslow, uk\",1912-06-23,\n3,,grace,\", uk\",1906-12-09,7\n"
+	if buf.String() != expect {
+		t.Errorf("data mismatch. expected:\n%s\ngot:\n%s", expect, buf.String())
+	}
+
+	short := &dataset.Structure{Format: dataset.CSVDataFormat, Schema: &dataset.Schema{Fields: []*dataset.Field{{Name: "a", Type: datatypes.String}}}}
+	if r, err = dsio.NewRowReader(st, strings.NewReader(data)); err != nil {
+		t.Fatalf("error allocating row reader: %s", err.Error())
+	}
+	if err := m.Apply(r, dsio.NewCSVWriter(short, &bytes.Buffer{})); err == nil || err.Error() != "writer has 1 fields, migrated structure has 6" {
+		t.Errorf("expected field count error, got: %v", err)
+	}
+}
+
+func TestMigrationMergeShortRows(t *testing.T) {
+	st := &dataset.Structure{
+		Format: dataset.CSVDataFormat,
+		Schema: &dataset.Schema{Fields: []*dataset.Field{
+			{Name: "a", Type: datatypes.String},
+			{Name: "b", Type: datatypes.String},
+			{Name: "c", Type: datatypes.String},
+			{Name: "d", Type: datatypes.String},
+		}},
+	}
+	cases := []struct {
+		fields []string
+		row    []string
+		expect []string
+	}{
+		{[]string{"a", "b", "c"}, []string{"x"}, []string{"x--", ""}},
+		{[]string{"a", "b", "c"}, []string{}, []string{"--", ""}},
+		{[]string{"c", "d"}, []string{"x", "y"}, []string{"x", "y", "-"}},
+		{[]string{"b", "d"}, []string{"x", "y", "z", "w"}, []string{"x", "y-w", "z"}},
+	}
+	for i, c := range cases {
+		m := &Migration{Steps: []*Step{{Op: OpMerge, Fields: c.fields, To: "m", Separator: "-"}}}
+		p, err := m.compile(st)
+		if err != nil {
+			t.Fatalf("case %d unexpected error: %s", i, err.Error())
+		}
+		row := make([][]byte, len(c.row))
+		for j, v := range c.row {
+			row[j] = []byte(v)
+		}
+		got, err := p.funcs[0](row)
+		if err != nil {
+			t.Errorf("case %d unexpected error: %s", i, err.Error())
+			continue
+		}
+		if len(got) != len(c.expect) {
+			t.Errorf("case %d cell count mismatch. expected: %d, got: %d", i, len(c.expect), len(got))
+			continue
+		}
+		for j, v := range got {
+			if string(v) != c.expect[j] {
+				t.Errorf("case %d cell %d mismatch. expected: '%s', got: '%s'", i, j, c.expect[j], string(v))
+			}
+		}
+	}
+}
+
+func TestMigrationCastMissingValues(t *testing.T) {
+	st := peopleStructure()
+	st.MissingValues = []string{"NA"}
+	data := "id,name,born,score,city\n1,ada,10/12/1815,NA,london\n2,alan,NA,7,NA\n"
+	m := &Migration{Steps: []*Step{
+		{Op: OpCast, Field: "score", Type: datatypes.Integer},
+		{Op: OpCast, Field: "born", Type: datatypes.Date},
+	}}
+
+	r, err := dsio.NewRowReader(st, strings.NewReader(data))
+	if err != nil {
+		t.Fatalf("error allocating row reader: %s", err.Error())
+	}
+	mr, err := m.Reader(r)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+	expect := [][]string{
+		{"1", "ada", "1815-12-10", "NA", "london"},
+		{"2", "alan", "NA", "7", "NA"},
+	}
+	for i, e := range expect {
+		row, err := mr.ReadRow()
+		if err != nil {
+			t.Fatalf("row %d unexpected error: %s", i, err.Error())
+		}
+		for j, cell := range row {
+			if string(cell) != e[j] {
+				t.Errorf("row %d cell %d mismatch. expected: '%s', got: '%s'", i, j, e[j], string(cell))
+			}
+		}
+	}
+	if mv := mr.Structure().MissingValues; len(mv) != 1 || mv[0] != "NA" {
+		t.Errorf("expected missing values to be kept, got: %v", mv)
+	}
+}
+
+func TestNewCaster(t *testing.T) {
+	cases := []struct {
+		from, to *dataset.Field
+		in, out  string
+		err      string
+	}{
+		{&dataset.Field{Type: datatypes.String}, &dataset.Field{Type: datatypes.Integer}, "12", "12", ""},
+		{&dataset.Field{Type: datatypes.String}, &dataset.Field{Type: datatypes.Integer}, "", "", ""},
+		{&dataset.Field{Type: datatypes.String}, &dataset.Field{Type: datatypes.Integer}, "1.5", "", "cannot cast '1.5' to integer"},
+		{&dataset.Field{Type: datatypes.Float}, &dataset.Field{Type: datatypes.Integer}, "3.0", "3", ""},
+		{&dataset.Field{Type: datatypes.Float, Locale: "de"}, &dataset.Field{Type: datatypes.Decimal}, "1.234,5", "1234.5", ""},
+		{&dataset.Field{Type: datatypes.Integer}, &dataset.Field{Type: datatypes.Float, Locale: "fr"}, "-1234", "-1 234", ""},
+		{&dataset.Field{Type: datatypes.DateTime}, &dataset.Field{Type: datatypes.Date, Format: "%Y/%m/%d"}, "2018-01-02T03:04:05Z", "2018/01/02", ""},
+		{&dataset.Field{Type: datatypes.String}, &dataset.Field{Type: datatypes.Date}, "2018-01-02", "2018-01-02", ""},
+		{&dataset.Field{Type: datatypes.String}, &dataset.Field{Type: datatypes.Date}, "tuesday", "", "cannot cast 'tuesday' to date"},
+		{&dataset.Field{Type: datatypes.String}, &dataset.Field{Type: datatypes.Boolean}, "1", "true", ""},
+		{&dataset.Field{Type: datatypes.Integer}, &dataset.Field{Type: datatypes.String}, "42", "42", ""},
+		{&dataset.Field{Type: datatypes.String}, &dataset.Field{Type: datatypes.URL}, "https://qri.io", "https://qri.io", ""},
+		{&dataset.Field{Type: datatypes.String}, &dataset.Field{Type: datatypes.JSON}, "{", "", "cannot cast '{' to json"},
+	}
+
+	for i, c := range cases {
+		cast, err := newCaster(c.from, c.to)
+		if err != nil {
+			t.Errorf("case %d unexpected error: %s", i, err.Error())
+			continue
+		}
+		got, err := cast([]byte(c.in))
+		if !(err == nil && c.err == "" || err != nil && err.Error() == c.err) {
+			t.Errorf("case %d error mismatch. expected: '%s', got: '%s'", i, c.err, err)
+			continue
+		}
+		if string(got) != c.out {
+			t.Errorf("case %d output mismatch. expected: '%s', got: '%s'", i, c.out, string(got))
+		}
+	}
+}
+
+func TestMigrationTransform(t *testing.T) {
+	m := &Migration{Steps: []*Step{
+		{Op: OpRename, Field: "city", To: "town"},
+		{Op: OpCast, Field: "score", Type: datatypes.Integer},
+	}}
+	ds := &dataset.Dataset{Structure: peopleStructure()}
+	if _, err := m.Transform(ds); err == nil || err.Error() != "dataset must have a path to record a migration" {
+		t.Errorf("expected path error, got: %v", err)
+	}
+
+	ds = dataset.NewDatasetRef(datastore.NewKey("/map/QmSource"))
+	ds.Structure = peopleStructure()
+	tf, err := m.Transform(ds)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+	if tf.Syntax != Syntax || tf.Structure.Schema.Fields[4].Name != "town" {
+		t.Errorf("transform mismatch: %s %v", tf.Syntax, tf.Structure.Schema.FieldNames())
+	}
+
+	data, err := json.Marshal(tf)
+	if err != nil {
+		t.Fatalf("error marshaling transform: %s", err.Error())
+	}
+	got := &dataset.Transform{}
+	if err := json.Unmarshal(data, got); err != nil {
+		t.Fatalf("error unmarshaling transform: %s", err.Error())
+	}
+
+	m2, path, err := FromTransform(got)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+	if path.String() != "/map/QmSource" {
+		t.Errorf("source path mismatch. expected: %s, got: %s", "/map/QmSource", path.String())
+	}
+	if len(m2.Steps) != 2 || m2.Steps[0].Op != OpRename || m2.Steps[0].To != "town" || m2.Steps[1].Type != datatypes.Integer {
+		t.Errorf("steps mismatch after round trip")
+	}
+
+	if _, _, err := FromTransform(&dataset.Transform{Syntax: "sql"}); err == nil || err.Error() != "transform syntax 'sql' is not a migration" {
+		t.Errorf("expected syntax error, got: %v", err)
+	}
+}
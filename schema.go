@@ -3,6 +3,9 @@ package dataset
 import (
 	"encoding/json"
 	"fmt"
+	"math/big"
+	"reflect"
+	"strconv"
 
 	"github.com/qri-io/dataset/datatypes"
 )
@@ -11,6 +14,8 @@ import (
 type Schema struct {
 	Fields     []*Field `json:"fields,omitempty"`
 	PrimaryKey FieldKey `json:"primaryKey,omitempty"`
+	// ForeignKeys reference fields of this or other datasets
+	ForeignKeys []*ForeignKey `json:"foreignKeys,omitempty"`
 }
 
 // FieldNames gives a slice of field names defined in schema
@@ -61,6 +66,9 @@ func (s *Schema) Assign(schemas ...*Schema) {
 		if sh.PrimaryKey != nil {
 			s.PrimaryKey = sh.PrimaryKey
 		}
+		if sh.ForeignKeys != nil {
+			s.ForeignKeys = sh.ForeignKeys
+		}
 
 		if s.Fields == nil && sh.Fields != nil {
 			s.Fields = sh.Fields
@@ -115,28 +123,70 @@ func (f *Field) Assign(fields ...*Field) {
 		if fd.Description != "" {
 			f.Description = fd.Description
 		}
+		if fd.Items != nil {
+			f.Items = fd.Items
+		}
+		if fd.Properties != nil {
+			f.Properties = fd.Properties
+		}
+		if fd.Locale != "" {
+			f.Locale = fd.Locale
+		}
+		if fd.DecimalChar != "" {
+			f.DecimalChar = fd.DecimalChar
+		}
+		if fd.GroupChar != "" {
+			f.GroupChar = fd.GroupChar
+		}
+		if fd.BareNumber != nil {
+			f.BareNumber = fd.BareNumber
+		}
 	}
 }
 
 // Field is a field descriptor
 type Field struct {
-	Name         string            `json:"name"`
-	Type         datatypes.Type    `json:"type,omitempty"`
+	Name string         `json:"name"`
+	Type datatypes.Type `json:"type,omitempty"`
+	// MissingValue is a raw value, or list of raw values that are read as
+	// null in this field. empty values are always null
 	MissingValue interface{}       `json:"missingValue,omitempty"`
 	Format       string            `json:"format,omitempty"`
 	Constraints  *FieldConstraints `json:"constraints,omitempty"`
 	Title        string            `json:"title,omitempty"`
 	Description  string            `json:"description,omitempty"`
+	// Items is the schema of elements of array fields
+	Items *Field `json:"items,omitempty"`
+	// Properties are the schemas of properties of object fields
+	Properties []*Field `json:"properties,omitempty"`
+	// Locale is a BCP 47 language tag like "de-DE" that sets the decimal &
+	// group characters of numeric fields
+	Locale string `json:"locale,omitempty"`
+	// DecimalChar separates the integer & fractional parts of numbers,
+	// overriding Locale. default is "."
+	DecimalChar string `json:"decimalChar,omitempty"`
+	// GroupChar separates groups of digits in numbers, overriding Locale.
+	// default is no group character
+	GroupChar string `json:"groupChar,omitempty"`
+	// BareNumber false permits leading & trailing non-numeric characters
+	// like currency symbols & percent signs in numbers. nil means true
+	BareNumber *bool `json:"bareNumber,omitempty"`
 }
 
 // field is a private struct for marshaling into and out of JSON
 // most importantly, keys are sorted by lexographical order
 type _field struct {
+	BareNumber   *bool             `json:"bareNumber,omitempty"`
 	Constraints  *FieldConstraints `json:"constraints,omitempty"`
+	DecimalChar  string            `json:"decimalChar,omitempty"`
 	Description  string            `json:"description,omitempty"`
 	Format       string            `json:"format,omitempty"`
+	GroupChar    string            `json:"groupChar,omitempty"`
+	Items        *Field            `json:"items,omitempty"`
+	Locale       string            `json:"locale,omitempty"`
 	MissingValue interface{}       `json:"missingValue,omitempty"`
 	Name         string            `json:"name"`
+	Properties   []*Field          `json:"properties,omitempty"`
 	Title        string            `json:"title,omitempty"`
 	Type         datatypes.Type    `json:"type,omitempty"`
 }
@@ -144,11 +194,17 @@ type _field struct {
 // MarshalJSON satisfies the json.Marshaler interface
 func (f Field) MarshalJSON() ([]byte, error) {
 	_f := &_field{
+		BareNumber:   f.BareNumber,
 		Constraints:  f.Constraints,
+		DecimalChar:  f.DecimalChar,
 		Description:  f.Description,
 		Format:       f.Format,
+		GroupChar:    f.GroupChar,
+		Items:        f.Items,
+		Locale:       f.Locale,
 		MissingValue: f.MissingValue,
 		Name:         f.Name,
+		Properties:   f.Properties,
 		Title:        f.Title,
 		Type:         f.Type,
 	}
@@ -163,54 +219,326 @@ func (f *Field) UnmarshalJSON(data []byte) error {
 	}
 
 	*f = Field{
+		BareNumber:   _f.BareNumber,
 		Constraints:  _f.Constraints,
+		DecimalChar:  _f.DecimalChar,
 		Description:  _f.Description,
 		Format:       _f.Format,
+		GroupChar:    _f.GroupChar,
+		Items:        _f.Items,
+		Locale:       _f.Locale,
 		MissingValue: _f.MissingValue,
 		Name:         _f.Name,
+		Properties:   _f.Properties,
 		Title:        _f.Title,
 		Type:         _f.Type,
 	}
 	return nil
 }
 
-// FieldKey allows a field key to be either a string or object
+// MissingValues gives the raw values read as null in the field, decoded
+// from MissingValue. the empty string is always null, and isn't included
+func (f *Field) MissingValues() []string {
+	var vals []interface{}
+	switch mv := f.MissingValue.(type) {
+	case nil:
+		return nil
+	case []interface{}:
+		vals = mv
+	case []string:
+		for _, v := range mv {
+			vals = append(vals, v)
+		}
+	default:
+		vals = []interface{}{mv}
+	}
+
+	strs := make([]string, 0, len(vals))
+	for _, v := range vals {
+		var str string
+		switch val := v.(type) {
+		case string:
+			str = val
+		case float64:
+			str = strconv.FormatFloat(val, 'f', -1, 64)
+		default:
+			str = fmt.Sprint(val)
+		}
+		if str != "" {
+			strs = append(strs, str)
+		}
+	}
+	return strs
+}
+
+// NumberFormat gives the format of the field's numeric values, starting
+// from the field's locale & applying any decimal & group characters
+func (f *Field) NumberFormat() (datatypes.NumberFormat, error) {
+	nf := datatypes.NumberFormat{}
+	if f.Locale != "" {
+		var err error
+		if nf, err = datatypes.LocaleNumberFormat(f.Locale); err != nil {
+			return nf, err
+		}
+	}
+	if f.DecimalChar != "" {
+		nf.DecimalChar = f.DecimalChar
+	}
+	if f.GroupChar != "" {
+		nf.GroupChar = f.GroupChar
+	}
+	if f.BareNumber != nil && !*f.BareNumber {
+		nf.Symbols = true
+	}
+	if nf.DecimalChar != "" && nf.DecimalChar == nf.GroupChar {
+		return nf, fmt.Errorf("decimalChar and groupChar cannot both be '%s'", nf.DecimalChar)
+	}
+	return nf, nil
+}
+
+// Parse reads a raw value of the field's type, honoring the field's
+// number format, the time format & zone of date, time & datetime fields,
+// and the precision & scale of decimal fields
+func (f *Field) Parse(value []byte) (interface{}, error) {
+	nf, err := f.NumberFormat()
+	if err != nil {
+		return nil, err
+	}
+	var tf *datatypes.TimeFormat
+	if (f.Type == datatypes.Date || f.Type == datatypes.Time || f.Type == datatypes.DateTime) && f.Format != "" {
+		t, err := datatypes.ParseTimeFormat(f.Type, f.Format)
+		if err != nil {
+			return nil, err
+		}
+		tf = &t
+	}
+	val, err := f.Type.Parse(value, func(o *datatypes.ParseOptions) {
+		o.Number = nf
+		o.Time = tf
+	})
+	if err != nil || f.Type != datatypes.Decimal || f.Format == "" {
+		return val, err
+	}
+
+	df, err := datatypes.ParseDecimalFormat(f.Format)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := df.FormatValue(val.(*big.Rat)); err != nil {
+		return nil, err
+	}
+	return val, nil
+}
+
+// ElementName gives the field's name, implementing datatypes.Element
+func (f *Field) ElementName() string {
+	return f.Name
+}
+
+// ElementType gives the field's type, implementing datatypes.Element
+func (f *Field) ElementType() datatypes.Type {
+	return f.Type
+}
+
+// ElementFormat gives the field's format, implementing datatypes.Element
+func (f *Field) ElementFormat() string {
+	return f.Format
+}
+
+// ElementItems gives the field's Items schema, implementing datatypes.Element
+func (f *Field) ElementItems() datatypes.Element {
+	if f.Items == nil {
+		return nil
+	}
+	return f.Items
+}
+
+// ElementProperties gives the field's Properties schemas, implementing
+// datatypes.Element
+func (f *Field) ElementProperties() []datatypes.Element {
+	props := make([]datatypes.Element, 0, len(f.Properties))
+	for _, p := range f.Properties {
+		if p != nil {
+			props = append(props, p)
+		}
+	}
+	return props
+}
+
+// FieldKey is a list of field names that together identify values. keys
+// of a single field can be written in json as a string
 type FieldKey []string
 
-// FieldConstraints is supposed to constrain the field,
-// this is totally unfinished, unimplemented, and needs lots of work
-// TODO - uh, finish this?
+// UnmarshalJSON satisfies the json.Unmarshaler interface, accepting a
+// single field name or an array of names
+func (k *FieldKey) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		*k = nil
+		return nil
+	}
+	var name string
+	if err := json.Unmarshal(data, &name); err == nil {
+		*k = FieldKey{name}
+		return nil
+	}
+	names := []string{}
+	if err := json.Unmarshal(data, &names); err != nil {
+		return fmt.Errorf("field key must be a string or array of strings")
+	}
+	*k = FieldKey(names)
+	return nil
+}
+
+// Indexes gives the position of each key field in a schema, erroring if
+// a field doesn't exist
+func (k FieldKey) Indexes(s *Schema) ([]int, error) {
+	idx := make([]int, len(k))
+	for i, name := range k {
+		idx[i] = -1
+		for j, f := range s.Fields {
+			if f.Name == name {
+				idx[i] = j
+				break
+			}
+		}
+		if idx[i] < 0 {
+			return nil, fmt.Errorf("field '%s' not found", name)
+		}
+	}
+	return idx, nil
+}
+
+// FieldConstraints constrain the values of a field beyond its type.
+// constraints are enforced by validate.DataErrors
 type FieldConstraints struct {
-	Required  *bool         `json:"required,omitempty"`
-	MinLength *int64        `json:"minLength,omitempty"`
-	MaxLength *int64        `json:"maxLength,omitempty"`
-	Unique    *bool         `json:"unique,omitempty"`
-	Pattern   string        `json:"pattern,omitempty"`
-	Minimum   interface{}   `json:"minimum,omitempty"`
-	Maximum   interface{}   `json:"maximum,omitempty"`
-	Enum      []interface{} `json:"enum,omitempty"`
+	// Required values cannot be empty
+	Required *bool `json:"required,omitempty"`
+	// MinLength & MaxLength bound the number of characters in values,
+	// or the number of elements in array & object values
+	MinLength *int64 `json:"minLength,omitempty"`
+	MaxLength *int64 `json:"maxLength,omitempty"`
+	// Unique values cannot repeat within a dataset
+	Unique *bool `json:"unique,omitempty"`
+	// Pattern is a regular expression values must match in full
+	Pattern string `json:"pattern,omitempty"`
+	// Minimum & Maximum are inclusive bounds on values, ordered by the
+	// field's type
+	Minimum interface{} `json:"minimum,omitempty"`
+	Maximum interface{} `json:"maximum,omitempty"`
+	// Enum lists all permitted values
+	Enum []interface{} `json:"enum,omitempty"`
+}
+
+// CompareFieldConstraints checks if all constraints of two FieldConstraints
+// pointers are equal, returning an error on the first mismatch, nil if equal
+func CompareFieldConstraints(a, b *FieldConstraints) error {
+	if a == nil && b == nil {
+		return nil
+	} else if a == nil && b != nil || a != nil && b == nil {
+		return fmt.Errorf("nil mismatch: %v != %v", a, b)
+	}
+
+	if !equalBoolPtrs(a.Required, b.Required) {
+		return fmt.Errorf("required mismatch")
+	}
+	if !equalInt64Ptrs(a.MinLength, b.MinLength) {
+		return fmt.Errorf("minLength mismatch")
+	}
+	if !equalInt64Ptrs(a.MaxLength, b.MaxLength) {
+		return fmt.Errorf("maxLength mismatch")
+	}
+	if !equalBoolPtrs(a.Unique, b.Unique) {
+		return fmt.Errorf("unique mismatch")
+	}
+	if a.Pattern != b.Pattern {
+		return fmt.Errorf("pattern mismatch: %s != %s", a.Pattern, b.Pattern)
+	}
+	if !reflect.DeepEqual(a.Minimum, b.Minimum) {
+		return fmt.Errorf("minimum mismatch: %v != %v", a.Minimum, b.Minimum)
+	}
+	if !reflect.DeepEqual(a.Maximum, b.Maximum) {
+		return fmt.Errorf("maximum mismatch: %v != %v", a.Maximum, b.Maximum)
+	}
+	if !reflect.DeepEqual(a.Enum, b.Enum) {
+		return fmt.Errorf("enum mismatch: %v != %v", a.Enum, b.Enum)
+	}
+	return nil
+}
+
+func equalBoolPtrs(a, b *bool) bool {
+	return a == nil && b == nil || a != nil && b != nil && *a == *b
 }
 
-// ForeignKey is supposed to be for supporting foreign key
-// references. It's also totally unfinished.
-// TODO - finish this
+func equalInt64Ptrs(a, b *int64) bool {
+	return a == nil && b == nil || a != nil && b != nil && *a == *b
+}
+
+// ForeignKey requires values of a group of fields to match values of
+// fields in a referenced dataset
 type ForeignKey struct {
+	// Fields are the referencing fields of this dataset
 	Fields FieldKey `json:"fields"`
-	// Reference
+	// Reference specifies the dataset & fields referenced
+	Reference *ForeignKeyReference `json:"reference"`
+}
+
+// ForeignKeyReference is the target of a foreign key
+type ForeignKeyReference struct {
+	// Dataset is the path of the referenced dataset. an empty path
+	// references the dataset the key belongs to
+	Dataset string `json:"dataset,omitempty"`
+	// Fields are the referenced fields, matched to the foreign key's fields
+	// by position
+	Fields FieldKey `json:"fields"`
+}
+
+// CompareForeignKeys checks if two ForeignKey pointers are equal,
+// returning an error on the first mismatch, nil if equal
+func CompareForeignKeys(a, b *ForeignKey) error {
+	if a == nil && b == nil {
+		return nil
+	} else if a == nil && b != nil || a != nil && b == nil {
+		return fmt.Errorf("nil mismatch: %v != %v", a, b)
+	}
+	if !reflect.DeepEqual(a.Fields, b.Fields) {
+		return fmt.Errorf("fields mismatch: %s != %s", a.Fields, b.Fields)
+	}
+	if a.Reference == nil && b.Reference == nil {
+		return nil
+	} else if a.Reference == nil || b.Reference == nil {
+		return fmt.Errorf("reference nil mismatch: %v != %v", a.Reference, b.Reference)
+	}
+	if a.Reference.Dataset != b.Reference.Dataset {
+		return fmt.Errorf("reference dataset mismatch: %s != %s", a.Reference.Dataset, b.Reference.Dataset)
+	}
+	if !reflect.DeepEqual(a.Reference.Fields, b.Reference.Fields) {
+		return fmt.Errorf("reference fields mismatch: %s != %s", a.Reference.Fields, b.Reference.Fields)
+	}
+	return nil
 }
 
 // CompareSchemas checks if all fields of two Schema pointers are equal,
 // returning an error on the first mismatch, nil if equal
 func CompareSchemas(a, b *Schema) error {
-	// if a.PrimaryKey != b.PrimaryKey {
-	// 	return fmt.Errorf("primary key mismatch: %s != %s", a.PrimaryKey, b.PrimaryKey)
-	// }
 	if a == nil && b == nil {
 		return nil
 	}
 	if a != nil && b == nil || a == nil && b != nil {
 		return fmt.Errorf("nil mismatch: %v != %v", a, b)
 	}
+	if len(a.PrimaryKey) != 0 || len(b.PrimaryKey) != 0 {
+		if !reflect.DeepEqual(a.PrimaryKey, b.PrimaryKey) {
+			return fmt.Errorf("primary key mismatch: %s != %s", a.PrimaryKey, b.PrimaryKey)
+		}
+	}
+	if len(a.ForeignKeys) != len(b.ForeignKeys) {
+		return fmt.Errorf("foreign keys length mismatch: %d != %d", len(a.ForeignKeys), len(b.ForeignKeys))
+	}
+	for i, fk := range a.ForeignKeys {
+		if err := CompareForeignKeys(fk, b.ForeignKeys[i]); err != nil {
+			return fmt.Errorf("foreign key %d mismatch: %s", i, err.Error())
+		}
+	}
 	if a.Fields == nil && b.Fields != nil || a.Fields != nil && b.Fields == nil {
 		return fmt.Errorf("fields slice mismatch: %s != %s", a.Fields, b.Fields)
 	}
@@ -253,6 +581,41 @@ func CompareFields(a, b *Field) error {
 	if a.Description != b.Description {
 		return fmt.Errorf("description mismatch: %s != %s", a.Description, b.Description)
 	}
+	if err := CompareFieldConstraints(a.Constraints, b.Constraints); err != nil {
+		return fmt.Errorf("constraints: %s", err.Error())
+	}
+	am, bm := a.MissingValues(), b.MissingValues()
+	if len(am) != len(bm) {
+		return fmt.Errorf("missingValue mismatch: %v != %v", a.MissingValue, b.MissingValue)
+	}
+	for i, v := range am {
+		if v != bm[i] {
+			return fmt.Errorf("missingValue mismatch: %v != %v", a.MissingValue, b.MissingValue)
+		}
+	}
+	if a.Locale != b.Locale {
+		return fmt.Errorf("locale mismatch: %s != %s", a.Locale, b.Locale)
+	}
+	if a.DecimalChar != b.DecimalChar {
+		return fmt.Errorf("decimalChar mismatch: %s != %s", a.DecimalChar, b.DecimalChar)
+	}
+	if a.GroupChar != b.GroupChar {
+		return fmt.Errorf("groupChar mismatch: %s != %s", a.GroupChar, b.GroupChar)
+	}
+	if (a.BareNumber == nil || *a.BareNumber) != (b.BareNumber == nil || *b.BareNumber) {
+		return fmt.Errorf("bareNumber mismatch")
+	}
+	if err := CompareFields(a.Items, b.Items); err != nil {
+		return fmt.Errorf("items: %s", err.Error())
+	}
+	if len(a.Properties) != len(b.Properties) {
+		return fmt.Errorf("properties length mismatch: %d != %d", len(a.Properties), len(b.Properties))
+	}
+	for i, p := range a.Properties {
+		if err := CompareFields(p, b.Properties[i]); err != nil {
+			return fmt.Errorf("property %d: %s", i, err.Error())
+		}
+	}
 
 	// TODO - finish comparison of field constraints, primary keys, format, etc.
 
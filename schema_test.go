@@ -1,8 +1,14 @@
 package dataset
 
 import (
+	"encoding/json"
 	"github.com/qri-io/compare"
+	"math/big"
+	"reflect"
 	"testing"
+	"time"
+
+	"github.com/qri-io/dataset/datatypes"
 )
 
 func TestSchemaFieldNames(t *testing.T) {
@@ -39,3 +45,247 @@ func TestSchemaFieldForName(t *testing.T) {
 		}
 	}
 }
+
+func TestFieldNestedJSON(t *testing.T) {
+	f := &Field{
+		Name: "mayor",
+		Type: datatypes.Object,
+		Properties: []*Field{
+			{Name: "name", Type: datatypes.String},
+			{Name: "terms", Type: datatypes.Array, Items: &Field{Type: datatypes.Integer}},
+		},
+	}
+
+	data, err := json.Marshal(f)
+	if err != nil {
+		t.Fatalf("error marshaling field: %s", err.Error())
+	}
+	expect := `{"name":"mayor","properties":[{"name":"name","type":"string"},{"items":{"name":"","type":"integer"},"name":"terms","type":"array"}],"type":"object"}`
+	if string(data) != expect {
+		t.Errorf("marshal mismatch. expected:\n%s\ngot:\n%s", expect, string(data))
+	}
+
+	got := &Field{}
+	if err := json.Unmarshal(data, got); err != nil {
+		t.Fatalf("error unmarshaling field: %s", err.Error())
+	}
+	if err := CompareFields(f, got); err != nil {
+		t.Errorf("round trip mismatch: %s", err.Error())
+	}
+}
+
+func TestFieldNumberFormat(t *testing.T) {
+	bare := false
+	f := &Field{Name: "price", Type: datatypes.Float, Locale: "de-DE", GroupChar: " ", BareNumber: &bare}
+
+	data, err := json.Marshal(f)
+	if err != nil {
+		t.Fatalf("error marshaling field: %s", err.Error())
+	}
+	expect := `{"bareNumber":false,"groupChar":" ","locale":"de-DE","name":"price","type":"float"}`
+	if string(data) != expect {
+		t.Errorf("marshal mismatch. expected:\n%s\ngot:\n%s", expect, string(data))
+	}
+	got := &Field{}
+	if err := json.Unmarshal(data, got); err != nil {
+		t.Fatalf("error unmarshaling field: %s", err.Error())
+	}
+	if err := CompareFields(f, got); err != nil {
+		t.Errorf("round trip mismatch: %s", err.Error())
+	}
+
+	cases := []struct {
+		field  *Field
+		value  string
+		expect interface{}
+		err    string
+	}{
+		{&Field{Type: datatypes.Integer}, "1234", int64(1234), ""},
+		{&Field{Type: datatypes.Integer}, "1,234", nil, `strconv.ParseInt: parsing "1,234": invalid syntax`},
+		{&Field{Type: datatypes.Integer, GroupChar: ","}, "1,234", int64(1234), ""},
+		{&Field{Type: datatypes.Float, Locale: "fr-FR"}, "1 234,5", 1234.5, ""},
+		{&Field{Type: datatypes.Float, Locale: "de-CH"}, "1'234.5", 1234.5, ""},
+		{&Field{Type: datatypes.Float, Locale: "de", DecimalChar: "."}, "1.5", nil, "decimalChar and groupChar cannot both be '.'"},
+		{f, "1 234,50 €", 1234.5, ""},
+		{&Field{Type: datatypes.Float, Locale: "klingon"}, "1", nil, "unknown locale: 'klingon'"},
+	}
+
+	for i, c := range cases {
+		got, err := c.field.Parse([]byte(c.value))
+		if !(err == nil && c.err == "" || err != nil && err.Error() == c.err) {
+			t.Errorf("case %d error mismatch. expected: '%s', got: '%s'", i, c.err, err)
+			continue
+		}
+		if c.err == "" && got != c.expect {
+			t.Errorf("case %d mismatch. expected: %v, got: %v", i, c.expect, got)
+		}
+	}
+}
+
+func TestFieldParseDecimalFormat(t *testing.T) {
+	cases := []struct {
+		field  *Field
+		value  string
+		expect string
+		err    string
+	}{
+		{&Field{Type: datatypes.Decimal}, "123456.789", "123456.789", ""},
+		{&Field{Type: datatypes.Decimal, Format: "5,2"}, "123.456", "123.456", ""},
+		{&Field{Type: datatypes.Decimal, Format: "5,2"}, "1234.5", "", "1234.50 exceeds decimal precision 5, scale 2"},
+		{&Field{Type: datatypes.Decimal, Format: "5,2", GroupChar: ","}, "1,234.5", "", "1234.50 exceeds decimal precision 5, scale 2"},
+		{&Field{Type: datatypes.Decimal, Format: "x"}, "1", "", "invalid decimal format: 'x'"},
+	}
+
+	for i, c := range cases {
+		got, err := c.field.Parse([]byte(c.value))
+		if !(err == nil && c.err == "" || err != nil && err.Error() == c.err) {
+			t.Errorf("case %d error mismatch. expected: '%s', got: '%s'", i, c.err, err)
+			continue
+		}
+		if c.err == "" && got.(*big.Rat).Cmp(mustRat(c.expect)) != 0 {
+			t.Errorf("case %d mismatch. expected: %s, got: %v", i, c.expect, got)
+		}
+	}
+}
+
+func mustRat(s string) *big.Rat {
+	r, _ := new(big.Rat).SetString(s)
+	return r
+}
+
+func TestFieldParseTimeFormat(t *testing.T) {
+	cases := []struct {
+		field  *Field
+		value  string
+		expect time.Time
+		err    string
+	}{
+		{&Field{Type: datatypes.Date}, "2017-01-01T10:00:00Z", time.Date(2017, 1, 1, 0, 0, 0, 0, time.UTC), ""},
+		{&Field{Type: datatypes.Date, Format: "%d/%m/%Y"}, "02/01/2017", time.Date(2017, 1, 2, 0, 0, 0, 0, time.UTC), ""},
+		{&Field{Type: datatypes.Date, Format: "%d/%m/%Y"}, "2017-01-02", time.Time{}, "invalid date: 2017-01-02. expected format '%d/%m/%Y'"},
+		{&Field{Type: datatypes.DateTime, Format: "tz=Europe/Berlin"}, "2017-01-02 12:00", time.Date(2017, 1, 2, 11, 0, 0, 0, time.UTC), ""},
+		{&Field{Type: datatypes.DateTime, Format: "tz=+01:00"}, "2017-01-02T12:00:00Z", time.Date(2017, 1, 2, 12, 0, 0, 0, time.UTC), ""},
+		{&Field{Type: datatypes.DateTime, Format: "tz=nowhere"}, "2017-01-02 12:00", time.Time{}, "invalid time zone: 'nowhere'"},
+	}
+
+	for i, c := range cases {
+		got, err := c.field.Parse([]byte(c.value))
+		if !(err == nil && c.err == "" || err != nil && err.Error() == c.err) {
+			t.Errorf("case %d error mismatch. expected: '%s', got: '%s'", i, c.err, err)
+			continue
+		}
+		if c.err == "" && !got.(time.Time).Equal(c.expect) {
+			t.Errorf("case %d mismatch. expected: %s, got: %s", i, c.expect, got)
+		}
+	}
+}
+
+func TestFieldMissingValues(t *testing.T) {
+	cases := []struct {
+		missing interface{}
+		expect  []string
+	}{
+		{nil, nil},
+		{"NA", []string{"NA"}},
+		{"", []string{}},
+		{float64(-999), []string{"-999"}},
+		{[]interface{}{"NA", "", float64(-1.5)}, []string{"NA", "-1.5"}},
+		{[]string{"-", "n/a"}, []string{"-", "n/a"}},
+	}
+
+	for i, c := range cases {
+		got := (&Field{MissingValue: c.missing}).MissingValues()
+		if len(got) != len(c.expect) {
+			t.Errorf("case %d length mismatch. expected: %v, got: %v", i, c.expect, got)
+			continue
+		}
+		for j, v := range c.expect {
+			if got[j] != v {
+				t.Errorf("case %d value %d mismatch. expected: %s, got: %s", i, j, v, got[j])
+			}
+		}
+	}
+
+	f := &Field{}
+	if err := json.Unmarshal([]byte(`{"name":"a","missingValue":["NA",-1]}`), f); err != nil {
+		t.Fatalf("error unmarshaling field: %s", err.Error())
+	}
+	if got := f.MissingValues(); len(got) != 2 || got[0] != "NA" || got[1] != "-1" {
+		t.Errorf("unmarshaled missing values mismatch: %v", got)
+	}
+}
+
+func TestCompareFieldConstraints(t *testing.T) {
+	yes, no, one, two := true, false, int64(1), int64(2)
+	cases := []struct {
+		a, b *FieldConstraints
+		err  string
+	}{
+		{nil, nil, ""},
+		{&FieldConstraints{}, nil, "nil mismatch: &{<nil> <nil> <nil> <nil>  <nil> <nil> []} != <nil>"},
+		{&FieldConstraints{Required: &yes}, &FieldConstraints{Required: &yes}, ""},
+		{&FieldConstraints{Required: &yes}, &FieldConstraints{Required: &no}, "required mismatch"},
+		{&FieldConstraints{MinLength: &one}, &FieldConstraints{}, "minLength mismatch"},
+		{&FieldConstraints{MaxLength: &one}, &FieldConstraints{MaxLength: &two}, "maxLength mismatch"},
+		{&FieldConstraints{Unique: &yes}, &FieldConstraints{}, "unique mismatch"},
+		{&FieldConstraints{Pattern: "a"}, &FieldConstraints{Pattern: "b"}, "pattern mismatch: a != b"},
+		{&FieldConstraints{Minimum: 1.0}, &FieldConstraints{Minimum: 2.0}, "minimum mismatch: 1 != 2"},
+		{&FieldConstraints{Maximum: "z"}, &FieldConstraints{Maximum: "z"}, ""},
+		{&FieldConstraints{Enum: []interface{}{"a", 1.0}}, &FieldConstraints{Enum: []interface{}{"a"}}, "enum mismatch: [a 1] != [a]"},
+	}
+
+	for i, c := range cases {
+		err := CompareFieldConstraints(c.a, c.b)
+		if !(err == nil && c.err == "" || err != nil && err.Error() == c.err) {
+			t.Errorf("case %d error mismatch. expected: '%s', got: '%s'", i, c.err, err)
+		}
+	}
+
+	a := &Field{Name: "a", Constraints: &FieldConstraints{Unique: &yes}}
+	b := &Field{Name: "a", Constraints: &FieldConstraints{Unique: &no}}
+	if err := CompareFields(a, b); err == nil || err.Error() != "constraints: unique mismatch" {
+		t.Errorf("expected constraints mismatch, got: %v", err)
+	}
+}
+
+func TestFieldKeyUnmarshalJSON(t *testing.T) {
+	cases := []struct {
+		data   string
+		expect FieldKey
+		err    string
+	}{
+		{`"id"`, FieldKey{"id"}, ""},
+		{`["country","year"]`, FieldKey{"country", "year"}, ""},
+		{`null`, nil, ""},
+		{`5`, nil, "field key must be a string or array of strings"},
+	}
+
+	for i, c := range cases {
+		got := FieldKey{}
+		err := json.Unmarshal([]byte(c.data), &got)
+		if !(err == nil && c.err == "" || err != nil && err.Error() == c.err) {
+			t.Errorf("case %d error mismatch. expected: '%s', got: '%s'", i, c.err, err)
+			continue
+		}
+		if c.err == "" && !reflect.DeepEqual(c.expect, got) {
+			t.Errorf("case %d mismatch. expected: %v, got: %v", i, c.expect, got)
+		}
+	}
+
+	sch := &Schema{}
+	if err := json.Unmarshal([]byte(`{"fields":[{"name":"id"},{"name":"parent"}],"primaryKey":"id","foreignKeys":[{"fields":"parent","reference":{"fields":"id"}}]}`), sch); err != nil {
+		t.Fatalf("error unmarshaling schema: %s", err.Error())
+	}
+	expect := &Schema{
+		Fields:      []*Field{{Name: "id"}, {Name: "parent"}},
+		PrimaryKey:  FieldKey{"id"},
+		ForeignKeys: []*ForeignKey{{Fields: FieldKey{"parent"}, Reference: &ForeignKeyReference{Fields: FieldKey{"id"}}}},
+	}
+	if err := CompareSchemas(expect, sch); err != nil {
+		t.Errorf("schema mismatch: %s", err.Error())
+	}
+	sch.ForeignKeys[0].Reference.Dataset = "/map/Qm"
+	if err := CompareSchemas(expect, sch); err == nil || err.Error() != "foreign key 0 mismatch: reference dataset mismatch:  != /map/Qm" {
+		t.Errorf("expected reference dataset mismatch, got: %v", err)
+	}
+}
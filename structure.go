@@ -28,6 +28,9 @@ type Structure struct {
 	// Compression specifies any compression on the source data,
 	// if empty assume no compression
 	Compression compression.Type `json:"compression,omitempty"`
+	// MissingValues lists raw values read as null in all fields that don't
+	// specify their own MissingValue. empty values are always null
+	MissingValues []string `json:"missingValues,omitempty"`
 	// Schema contains the schema definition for the underlying data
 	Schema *Schema `json:"schema"`
 }
@@ -48,14 +51,27 @@ func NewStructureRef(path datastore.Key) *Structure {
 // renaming all schema field names to standard variable names
 func (s *Structure) Abstract() *Structure {
 	a := &Structure{
-		Format:       s.Format,
-		FormatConfig: s.FormatConfig,
-		Encoding:     s.Encoding,
+		Format:        s.Format,
+		FormatConfig:  s.FormatConfig,
+		Encoding:      s.Encoding,
+		MissingValues: s.MissingValues,
 	}
 	if s.Schema != nil {
 		a.Schema = &Schema{
-			PrimaryKey: s.Schema.PrimaryKey,
-			Fields:     make([]*Field, len(s.Schema.Fields)),
+			Fields: make([]*Field, len(s.Schema.Fields)),
+		}
+		// keys refer to fields by name, so are renamed with their fields
+		names := map[string]string{}
+		for i, f := range s.Schema.Fields {
+			names[f.Name] = AbstractColumnName(i)
+		}
+		a.Schema.PrimaryKey = abstractKey(s.Schema.PrimaryKey, names)
+		for _, fk := range s.Schema.ForeignKeys {
+			afk := &ForeignKey{Fields: abstractKey(fk.Fields, names), Reference: fk.Reference}
+			if fk.Reference != nil && fk.Reference.Dataset == "" {
+				afk.Reference = &ForeignKeyReference{Fields: abstractKey(fk.Reference.Fields, names)}
+			}
+			a.Schema.ForeignKeys = append(a.Schema.ForeignKeys, afk)
 		}
 		for i, f := range s.Schema.Fields {
 			a.Schema.Fields[i] = &Field{
@@ -70,6 +86,18 @@ func (s *Structure) Abstract() *Structure {
 	return a
 }
 
+// abstractKey renames the fields of a key to their abstract names
+func abstractKey(key FieldKey, names map[string]string) FieldKey {
+	if key == nil {
+		return nil
+	}
+	ak := make(FieldKey, len(key))
+	for i, name := range key {
+		ak[i] = names[name]
+	}
+	return ak
+}
+
 // Hash gives the hash of this structure
 func (s *Structure) Hash() (string, error) {
 	return JSONHash(s)
@@ -78,11 +106,12 @@ func (s *Structure) Hash() (string, error) {
 // separate type for marshalling into & out of
 // most importantly, struct names must be sorted lexographically
 type _structure struct {
-	Compression  compression.Type       `json:"compression,omitempty"`
-	Encoding     string                 `json:"encoding,omitempty"`
-	Format       DataFormat             `json:"format"`
-	FormatConfig map[string]interface{} `json:"formatConfig,omitempty"`
-	Schema       *Schema                `json:"schema,omitempty"`
+	Compression   compression.Type       `json:"compression,omitempty"`
+	Encoding      string                 `json:"encoding,omitempty"`
+	Format        DataFormat             `json:"format"`
+	FormatConfig  map[string]interface{} `json:"formatConfig,omitempty"`
+	MissingValues []string               `json:"missingValues,omitempty"`
+	Schema        *Schema                `json:"schema,omitempty"`
 }
 
 // MarshalJSON satisfies the json.Marshaler interface
@@ -97,11 +126,12 @@ func (s Structure) MarshalJSON() (data []byte, err error) {
 	}
 
 	return json.Marshal(&_structure{
-		Compression:  s.Compression,
-		Encoding:     s.Encoding,
-		Format:       s.Format,
-		FormatConfig: opt,
-		Schema:       s.Schema,
+		Compression:   s.Compression,
+		Encoding:      s.Encoding,
+		Format:        s.Format,
+		FormatConfig:  opt,
+		MissingValues: s.MissingValues,
+		Schema:        s.Schema,
 	})
 }
 
@@ -130,11 +160,12 @@ func (s *Structure) UnmarshalJSON(data []byte) (err error) {
 	}
 
 	*s = Structure{
-		Compression:  _s.Compression,
-		Encoding:     _s.Encoding,
-		Format:       _s.Format,
-		FormatConfig: fmtCfg,
-		Schema:       _s.Schema,
+		Compression:   _s.Compression,
+		Encoding:      _s.Encoding,
+		Format:        _s.Format,
+		FormatConfig:  fmtCfg,
+		MissingValues: _s.MissingValues,
+		Schema:        _s.Schema,
 	}
 
 	// TODO - question of weather we should not accept
@@ -148,7 +179,7 @@ func (s *Structure) UnmarshalJSON(data []byte) (err error) {
 
 // IsEmpty checks to see if structure has any fields other than the internal path
 func (s *Structure) IsEmpty() bool {
-	return s.Format == UnknownDataFormat && s.FormatConfig == nil && s.Encoding == "" && s.Schema == nil
+	return s.Format == UnknownDataFormat && s.FormatConfig == nil && s.Encoding == "" && s.MissingValues == nil && s.Schema == nil
 }
 
 // Assign collapses all properties of a group of structures on to one
@@ -174,6 +205,9 @@ func (s *Structure) Assign(structures ...*Structure) {
 		if st.Compression != compression.None {
 			s.Compression = st.Compression
 		}
+		if st.MissingValues != nil {
+			s.MissingValues = st.MissingValues
+		}
 
 		if s.Schema == nil && st.Schema != nil {
 			s.Schema = st.Schema
@@ -183,6 +217,33 @@ func (s *Structure) Assign(structures ...*Structure) {
 	}
 }
 
+// NullValues gives the raw values read as null in a field of the structure,
+// the field's MissingValues if it has any, otherwise the structure's
+// MissingValues
+func (s *Structure) NullValues(f *Field) []string {
+	if vals := f.MissingValues(); len(vals) > 0 {
+		return vals
+	}
+	return s.MissingValues
+}
+
+// IsNull reports weather a raw value of a field of the structure is null,
+// either empty or one of the field's NullValues
+func (s *Structure) IsNull(f *Field, value []byte) bool {
+	if len(value) == 0 {
+		return true
+	}
+	if f.MissingValue == nil && len(s.MissingValues) == 0 {
+		return false
+	}
+	for _, v := range s.NullValues(f) {
+		if string(value) == v {
+			return true
+		}
+	}
+	return false
+}
+
 // StringFieldIndex gives the index of a field who's name matches s
 // it returns -1 if no match is found
 func (s *Structure) StringFieldIndex(str string) int {
@@ -264,6 +325,14 @@ func CompareStructures(a, b *Structure) error {
 	if err := CompareSchemas(a.Schema, b.Schema); err != nil {
 		return fmt.Errorf("Schema mismatch: %s", err.Error())
 	}
+	if len(a.MissingValues) != len(b.MissingValues) {
+		return fmt.Errorf("MissingValues length mismatch: %d != %d", len(a.MissingValues), len(b.MissingValues))
+	}
+	for i, v := range a.MissingValues {
+		if v != b.MissingValues[i] {
+			return fmt.Errorf("MissingValues mismatch: %s != %s", v, b.MissingValues[i])
+		}
+	}
 
 	return nil
 }
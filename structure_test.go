@@ -51,6 +51,35 @@ func TestStructureAbstract(t *testing.T) {
 		in, out *Structure
 	}{
 		{AirportCodesStructure, AirportCodesStructureAbstract},
+		{&Structure{
+			Format: CSVDataFormat,
+			Schema: &Schema{
+				Fields: []*Field{
+					{Name: "id", Type: datatypes.Integer},
+					{Name: "parent", Type: datatypes.Integer},
+					{Name: "country", Type: datatypes.String},
+				},
+				PrimaryKey: FieldKey{"id"},
+				ForeignKeys: []*ForeignKey{
+					{Fields: FieldKey{"parent"}, Reference: &ForeignKeyReference{Fields: FieldKey{"id"}}},
+					{Fields: FieldKey{"country"}, Reference: &ForeignKeyReference{Dataset: "/map/Qm", Fields: FieldKey{"code"}}},
+				},
+			},
+		}, &Structure{
+			Format: CSVDataFormat,
+			Schema: &Schema{
+				Fields: []*Field{
+					{Name: "a", Type: datatypes.Integer},
+					{Name: "b", Type: datatypes.Integer},
+					{Name: "c", Type: datatypes.String},
+				},
+				PrimaryKey: FieldKey{"a"},
+				ForeignKeys: []*ForeignKey{
+					{Fields: FieldKey{"b"}, Reference: &ForeignKeyReference{Fields: FieldKey{"a"}}},
+					{Fields: FieldKey{"c"}, Reference: &ForeignKeyReference{Dataset: "/map/Qm", Fields: FieldKey{"code"}}},
+				},
+			},
+		}},
 	}
 
 	for i, c := range cases {
@@ -150,6 +179,54 @@ func TestStructureUnmarshalJSON(t *testing.T) {
 	}
 }
 
+func TestStructureIsNull(t *testing.T) {
+	st := &Structure{
+		Format:        CSVDataFormat,
+		MissingValues: []string{"NA", "-"},
+		Schema: &Schema{Fields: []*Field{
+			{Name: "a", Type: datatypes.Integer},
+			{Name: "b", Type: datatypes.Integer, MissingValue: []interface{}{"-999"}},
+		}},
+	}
+	cases := []struct {
+		field  int
+		value  string
+		expect bool
+	}{
+		{0, "", true},
+		{0, "NA", true},
+		{0, "-", true},
+		{0, "-999", false},
+		{0, "1", false},
+		{1, "", true},
+		{1, "-999", true},
+		// field missing values replace the structure's
+		{1, "NA", false},
+	}
+
+	for i, c := range cases {
+		if got := st.IsNull(st.Schema.Fields[c.field], []byte(c.value)); got != c.expect {
+			t.Errorf("case %d mismatch. expected: %t, got: %t", i, c.expect, got)
+		}
+	}
+
+	data, err := json.Marshal(st)
+	if err != nil {
+		t.Fatalf("error marshaling structure: %s", err.Error())
+	}
+	expect := `{"format":"csv","missingValues":["NA","-"],"schema":{"fields":[{"name":"a","type":"integer"},{"missingValue":["-999"],"name":"b","type":"integer"}]}}`
+	if string(data) != expect {
+		t.Errorf("marshal mismatch. expected:\n%s\ngot:\n%s", expect, string(data))
+	}
+	got := &Structure{}
+	if err := json.Unmarshal(data, got); err != nil {
+		t.Fatalf("error unmarshaling structure: %s", err.Error())
+	}
+	if err := CompareStructures(st, got); err != nil {
+		t.Errorf("round trip mismatch: %s", err.Error())
+	}
+}
+
 func TestStructureMarshalJSON(t *testing.T) {
 	cases := []struct {
 		in  *Structure
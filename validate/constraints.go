@@ -0,0 +1,201 @@
+package validate
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"unicode/utf8"
+
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/dataset/datatypes"
+)
+
+// constraintChecker enforces a field's constraints on each value of a
+// column. checkers are stateful, remembering values for unique constraints
+// across all rows of a stream
+type constraintChecker struct {
+	field   *dataset.Field
+	cons    *dataset.FieldConstraints
+	nf      datatypes.NumberFormat
+	cmp     *datatypes.Comparer
+	pattern *regexp.Regexp
+	minimum []byte
+	maximum []byte
+	enum    [][]byte
+	// row numbers values were first seen in, nil unless values must be unique
+	seen map[string]int
+}
+
+// newConstraintChecker creates a checker for a field's constraints,
+// returning nil if the field has no constraints
+func newConstraintChecker(f *dataset.Field) (*constraintChecker, error) {
+	c := f.Constraints
+	if c == nil {
+		return nil, nil
+	}
+
+	cc := &constraintChecker{field: f, cons: c}
+	var err error
+	if cc.nf, err = f.NumberFormat(); err != nil {
+		return nil, err
+	}
+	if cc.cmp, err = datatypes.NewComparer(f.Type, f.Format, datatypes.NullsLow); err != nil {
+		return nil, err
+	}
+
+	if c.MinLength != nil && c.MaxLength != nil && *c.MinLength > *c.MaxLength {
+		return nil, fmt.Errorf("minLength %d is greater than maxLength %d", *c.MinLength, *c.MaxLength)
+	}
+	if c.Pattern != "" {
+		// patterns must match the whole value
+		if cc.pattern, err = regexp.Compile("^(?:" + c.Pattern + ")$"); err != nil {
+			return nil, fmt.Errorf("invalid pattern: %s", err.Error())
+		}
+	}
+	if c.Minimum != nil {
+		if cc.minimum, err = cc.constraintValue(c.Minimum); err != nil {
+			return nil, fmt.Errorf("invalid minimum: %s", err.Error())
+		}
+	}
+	if c.Maximum != nil {
+		if cc.maximum, err = cc.constraintValue(c.Maximum); err != nil {
+			return nil, fmt.Errorf("invalid maximum: %s", err.Error())
+		}
+	}
+	for _, v := range c.Enum {
+		val, err := cc.constraintValue(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid enum value: %s", err.Error())
+		}
+		cc.enum = append(cc.enum, val)
+	}
+	if c.Unique != nil && *c.Unique {
+		cc.seen = map[string]int{}
+	}
+	return cc, nil
+}
+
+// constraintValue converts a constraint value decoded from json to raw
+// bytes of the field's type, checking the value can be compared
+func (cc *constraintChecker) constraintValue(v interface{}) ([]byte, error) {
+	var raw []byte
+	switch val := v.(type) {
+	case string:
+		raw = []byte(val)
+	case float64:
+		raw = []byte(strconv.FormatFloat(val, 'f', -1, 64))
+	case int:
+		raw = []byte(strconv.Itoa(val))
+	case int64:
+		raw = []byte(strconv.FormatInt(val, 10))
+	case bool:
+		raw = []byte(strconv.FormatBool(val))
+	default:
+		data, err := json.Marshal(v)
+		if err != nil {
+			return nil, err
+		}
+		raw = data
+	}
+	if _, err := cc.cmp.Compare(raw, raw); err != nil {
+		return nil, err
+	}
+	return raw, nil
+}
+
+// required reports weather the checker's field must have a value
+func (cc *constraintChecker) required() bool {
+	return cc.cons.Required != nil && *cc.cons.Required
+}
+
+// check enforces constraints on a cell that's a valid value of the field's
+// type, returning the first constraint the cell violates. num is the row
+// number of the cell
+func (cc *constraintChecker) check(num int, cell []byte) error {
+	c := cc.cons
+	if len(cell) == 0 {
+		if cc.required() {
+			return fmt.Errorf("value is required")
+		}
+		return nil
+	}
+
+	value := cell
+	if cc.field.Type == datatypes.Integer || cc.field.Type == datatypes.Float || cc.field.Type == datatypes.Decimal {
+		var err error
+		if value, err = cc.nf.Normalize(cell); err != nil {
+			return err
+		}
+	}
+
+	if c.MinLength != nil || c.MaxLength != nil {
+		length, err := valueLength(cc.field.Type, cell)
+		if err != nil {
+			return err
+		}
+		if c.MinLength != nil && length < *c.MinLength {
+			return fmt.Errorf("length %d is less than minLength %d", length, *c.MinLength)
+		}
+		if c.MaxLength != nil && length > *c.MaxLength {
+			return fmt.Errorf("length %d is greater than maxLength %d", length, *c.MaxLength)
+		}
+	}
+
+	if cc.pattern != nil && !cc.pattern.Match(cell) {
+		return fmt.Errorf("value '%s' does not match pattern '%s'", string(cell), c.Pattern)
+	}
+
+	if cc.minimum != nil {
+		if res, err := cc.cmp.Compare(value, cc.minimum); err != nil {
+			return err
+		} else if res < 0 {
+			return fmt.Errorf("value %s is less than minimum %s", string(cell), string(cc.minimum))
+		}
+	}
+	if cc.maximum != nil {
+		if res, err := cc.cmp.Compare(value, cc.maximum); err != nil {
+			return err
+		} else if res > 0 {
+			return fmt.Errorf("value %s is greater than maximum %s", string(cell), string(cc.maximum))
+		}
+	}
+
+	if cc.enum != nil {
+		found := false
+		for _, e := range cc.enum {
+			if res, err := cc.cmp.Compare(value, e); err != nil {
+				return err
+			} else if res == 0 {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("value %s is not one of the enum values", string(cell))
+		}
+	}
+
+	if cc.seen != nil {
+		if first, ok := cc.seen[string(value)]; ok {
+			return fmt.Errorf("value %s is not unique, first seen in row %d", string(cell), first)
+		}
+		cc.seen[string(value)] = num
+	}
+	return nil
+}
+
+// valueLength gives the length of a value for minLength & maxLength
+// constraints: the number of elements in arrays, properties in objects,
+// and characters in all other values
+func valueLength(t datatypes.Type, cell []byte) (int64, error) {
+	switch t {
+	case datatypes.Array:
+		arr, err := datatypes.ParseArray(cell)
+		return int64(len(arr)), err
+	case datatypes.Object:
+		obj, err := datatypes.ParseObject(cell)
+		return int64(len(obj)), err
+	}
+	return int64(utf8.RuneCount(cell)), nil
+}
@@ -0,0 +1,172 @@
+package validate
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/dataset/datatypes"
+	"github.com/qri-io/dataset/dsio"
+)
+
+func TestNewConstraintChecker(t *testing.T) {
+	two, one := int64(2), int64(1)
+	cases := []struct {
+		field *dataset.Field
+		err   string
+	}{
+		{&dataset.Field{Type: datatypes.String}, ""},
+		{&dataset.Field{Type: datatypes.String, Constraints: &dataset.FieldConstraints{Pattern: "[a-z]+"}}, ""},
+		{&dataset.Field{Type: datatypes.String, Constraints: &dataset.FieldConstraints{Pattern: "[a-z"}}, "invalid pattern: error parsing regexp: missing closing ]: `[a-z)$`"},
+		{&dataset.Field{Type: datatypes.String, Constraints: &dataset.FieldConstraints{MinLength: &two, MaxLength: &one}}, "minLength 2 is greater than maxLength 1"},
+		{&dataset.Field{Type: datatypes.Integer, Constraints: &dataset.FieldConstraints{Minimum: float64(1), Maximum: "10"}}, ""},
+		{&dataset.Field{Type: datatypes.Integer, Constraints: &dataset.FieldConstraints{Minimum: 1.5}}, `invalid minimum: strconv.ParseInt: parsing "1.5": invalid syntax`},
+		{&dataset.Field{Type: datatypes.Date, Constraints: &dataset.FieldConstraints{Maximum: "tomorrow"}}, "invalid maximum: invalid date: tomorrow"},
+		{&dataset.Field{Type: datatypes.Boolean, Constraints: &dataset.FieldConstraints{Enum: []interface{}{true, "maybe"}}}, `invalid enum value: strconv.ParseBool: parsing "maybe": invalid syntax`},
+		{&dataset.Field{Type: datatypes.Float, Locale: "xx", Constraints: &dataset.FieldConstraints{}}, "unknown locale: 'xx'"},
+	}
+
+	for i, c := range cases {
+		_, err := newConstraintChecker(c.field)
+		if !(err == nil && c.err == "" || err != nil && err.Error() == c.err) {
+			t.Errorf("case %d error mismatch. expected: '%s', got: '%s'", i, c.err, err)
+		}
+	}
+}
+
+func TestConstraintCheckerCheck(t *testing.T) {
+	yes, two, four := true, int64(2), int64(4)
+	cases := []struct {
+		field  *dataset.Field
+		values []string
+		errs   []string
+	}{
+		{&dataset.Field{Type: datatypes.String, Constraints: &dataset.FieldConstraints{Required: &yes}},
+			[]string{"a", ""},
+			[]string{"", "value is required"}},
+		{&dataset.Field{Type: datatypes.String, Constraints: &dataset.FieldConstraints{MinLength: &two, MaxLength: &four}},
+			[]string{"ab", "a", "abcde", "äöü", ""},
+			[]string{"", "length 1 is less than minLength 2", "length 5 is greater than maxLength 4", "", ""}},
+		{&dataset.Field{Type: datatypes.Array, Constraints: &dataset.FieldConstraints{MaxLength: &two}},
+			[]string{"[1,2]", "[1,2,3]"},
+			[]string{"", "length 3 is greater than maxLength 2"}},
+		{&dataset.Field{Type: datatypes.String, Constraints: &dataset.FieldConstraints{Pattern: "[A-Z]{2}"}},
+			[]string{"US", "USA", "us"},
+			[]string{"", "value 'USA' does not match pattern '[A-Z]{2}'", "value 'us' does not match pattern '[A-Z]{2}'"}},
+		{&dataset.Field{Type: datatypes.Integer, Constraints: &dataset.FieldConstraints{Minimum: float64(0), Maximum: float64(100)}},
+			[]string{"0", "100", "-1", "101"},
+			[]string{"", "", "value -1 is less than minimum 0", "value 101 is greater than maximum 100"}},
+		{&dataset.Field{Type: datatypes.Float, GroupChar: ",", Constraints: &dataset.FieldConstraints{Maximum: float64(1000)}},
+			[]string{"999.5", "1,000.5"},
+			[]string{"", "value 1,000.5 is greater than maximum 1000"}},
+		{&dataset.Field{Type: datatypes.Date, Format: "%d/%m/%Y", Constraints: &dataset.FieldConstraints{Minimum: "01/02/2020"}},
+			[]string{"01/02/2020", "31/01/2020"},
+			[]string{"", "value 31/01/2020 is less than minimum 01/02/2020"}},
+		{&dataset.Field{Type: datatypes.Decimal, Constraints: &dataset.FieldConstraints{Enum: []interface{}{"1.50", 2.0}}},
+			[]string{"1.5", "2", "3"},
+			[]string{"", "", "value 3 is not one of the enum values"}},
+		{&dataset.Field{Type: datatypes.Integer, Constraints: &dataset.FieldConstraints{Unique: &yes}},
+			[]string{"1", "2", "1", "", ""},
+			[]string{"", "", "value 1 is not unique, first seen in row 0", "", ""}},
+	}
+
+	for i, c := range cases {
+		cc, err := newConstraintChecker(c.field)
+		if err != nil {
+			t.Errorf("case %d unexpected error: %s", i, err.Error())
+			continue
+		}
+		for j, v := range c.values {
+			err := cc.check(j, []byte(v))
+			if !(err == nil && c.errs[j] == "" || err != nil && err.Error() == c.errs[j]) {
+				t.Errorf("case %d value %d error mismatch. expected: '%s', got: '%s'", i, j, c.errs[j], err)
+			}
+		}
+	}
+}
+
+func TestDataErrorsConstraints(t *testing.T) {
+	yes, min := true, int64(3)
+	st := &dataset.Structure{
+		Format:       dataset.CSVDataFormat,
+		FormatConfig: &dataset.CSVOptions{HeaderRow: true},
+		Schema: &dataset.Schema{Fields: []*dataset.Field{
+			{Name: "id", Type: datatypes.Integer, Constraints: &dataset.FieldConstraints{Required: &yes, Unique: &yes}},
+			{Name: "name", Type: datatypes.String, Constraints: &dataset.FieldConstraints{MinLength: &min}},
+			{Name: "status", Type: datatypes.String, Constraints: &dataset.FieldConstraints{Enum: []interface{}{"open", "closed"}}},
+		}},
+	}
+	data := "id,name,status\n1,alice,open\n2,bo,closed\n1,carol,pending\n,dave,open\n"
+	r, err := dsio.NewRowReader(st, strings.NewReader(data))
+	if err != nil {
+		t.Fatalf("error allocating row reader: %s", err.Error())
+	}
+
+	got, count, err := DataErrors(r)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+	if count != 4 {
+		t.Errorf("count mismatch. expected: %d, got: %d", 4, count)
+	}
+
+	expect := [][]string{
+		{"1", "", "length 2 is less than minLength 3", ""},
+		{"2", "value 1 is not unique, first seen in row 0", "", "value pending is not one of the enum values"},
+		{"3", "value is required", "", ""},
+	}
+	for i, e := range expect {
+		row, err := got.ReadRow()
+		if err != nil {
+			t.Fatalf("row %d error: %s", i, err.Error())
+		}
+		for j, cell := range row {
+			if string(cell) != e[j] {
+				t.Errorf("row %d, column %d mismatch. expected: '%s', got: '%s'", i, j, e[j], string(cell))
+			}
+		}
+	}
+
+	st.Schema.Fields[1].Constraints.Pattern = "("
+	if r, err = dsio.NewRowReader(st, strings.NewReader(data)); err != nil {
+		t.Fatalf("error allocating row reader: %s", err.Error())
+	}
+	if _, _, err := DataErrors(r); err == nil || err.Error() != "field name constraints: invalid pattern: error parsing regexp: missing closing ): `^(?:()$`" {
+		t.Errorf("expected invalid pattern error, got: %v", err)
+	}
+}
+
+func TestDataErrorsEmptyCells(t *testing.T) {
+	yes := true
+	st := &dataset.Structure{
+		Format:       dataset.CSVDataFormat,
+		FormatConfig: &dataset.CSVOptions{HeaderRow: true},
+		Schema: &dataset.Schema{Fields: []*dataset.Field{
+			{Name: "id", Type: datatypes.Integer, Constraints: &dataset.FieldConstraints{Required: &yes}},
+			{Name: "score", Type: datatypes.Integer, Constraints: &dataset.FieldConstraints{Required: new(bool)}},
+			{Name: "day", Type: datatypes.Date},
+		}},
+	}
+	r, err := dsio.NewRowReader(st, strings.NewReader("id,score,day\n1,,\n,,\n"))
+	if err != nil {
+		t.Fatalf("error allocating row reader: %s", err.Error())
+	}
+
+	got, count, err := DataErrors(r)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+	if count != 1 {
+		t.Errorf("count mismatch. expected: %d, got: %d", 1, count)
+	}
+	row, err := got.ReadRow()
+	if err != nil {
+		t.Fatalf("error reading errors: %s", err.Error())
+	}
+	expect := []string{"1", "value is required", "", ""}
+	for j, cell := range row {
+		if string(cell) != expect[j] {
+			t.Errorf("column %d mismatch. expected: '%s', got: '%s'", j, expect[j], string(cell))
+		}
+	}
+}
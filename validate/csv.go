@@ -1,19 +1,23 @@
 package validate
 
 import (
-	"encoding/csv"
 	"fmt"
 	"io"
+
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/dataset/dsio"
 )
 
 // CheckCsvRowLengths ensures that csv input has
 // the same number of columns in every row and otherwise
-// returns an error
-func CheckCsvRowLengths(r io.Reader) error {
-	csvReader := csv.NewReader(r)
+// returns an error. opts sets the csv dialect to read with,
+// nil opts reads comma-delimited csv, trimming leading space
+func CheckCsvRowLengths(r io.Reader, opts *dataset.CSVOptions) error {
+	if opts == nil {
+		opts = &dataset.CSVOptions{TrimLeadingSpace: true}
+	}
+	csvReader := dsio.NewCSVRecordReader(opts, r)
 	csvReader.FieldsPerRecord = -1
-	csvReader.TrimLeadingSpace = true
-	//csvReader.LazyQuotes = true
 	firstRow, err := csvReader.Read()
 	rowLen := len(firstRow)
 	if err != nil {
@@ -3,23 +3,31 @@ package validate
 import (
 	"strings"
 	"testing"
+
+	"github.com/qri-io/dataset"
 )
 
 func TestCheckCsvRowLengths(t *testing.T) {
 	cases := []struct {
 		input string
+		opts  *dataset.CSVOptions
 		err   string
 	}{
-		{rawText1, ""},
-		{rawText2, ""},
-		{rawText2b, ""},
-		{rawText3, ""}, //Note: since there are no commas this should pass
-		{rawText4, "error: inconsistent column length on line 4 of length 2 (rather than 1). ensure all csv columns same length"},
+		{rawText1, nil, ""},
+		{rawText2, nil, ""},
+		{rawText2b, nil, ""},
+		{rawText3, nil, ""}, //Note: since there are no commas this should pass
+		{rawText4, nil, "error: inconsistent column length on line 4 of length 2 (rather than 1). ensure all csv columns same length"},
+		{"a\tb\n1\t2\n3\t4", &dataset.CSVOptions{Delimiter: "\t"}, ""},
+		{"a;b\n1;2\n3,4", &dataset.CSVOptions{Delimiter: ";"}, "error: inconsistent column length on line 2 of length 1 (rather than 2). ensure all csv columns same length"},
+		{"# header comment\na,b\n# a,b,c\n1,2", &dataset.CSVOptions{Comment: "#"}, ""},
+		{"a,b\n'1,2',3\n4,5", &dataset.CSVOptions{Quote: "'"}, ""},
+		{"a,b\n1,2\"3\n4,5", &dataset.CSVOptions{LazyQuotes: true}, ""},
 	}
 
 	for i, c := range cases {
 		r := strings.NewReader(c.input)
-		err := CheckCsvRowLengths(r)
+		err := CheckCsvRowLengths(r, c.opts)
 		if !(err == nil && c.err == "" || err != nil && err.Error() == c.err) {
 			t.Errorf("case [%d] error mismatch. expected: '%s', got: '%s'", i, c.err, err)
 		}
@@ -1,33 +1,62 @@
 package validate
 
 import (
+	"bytes"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"strconv"
+	"strings"
 
 	"github.com/datatogether/cdxj"
 	"github.com/qri-io/dataset"
+	"github.com/qri-io/dataset/charset"
 	"github.com/qri-io/dataset/datatypes"
 	"github.com/qri-io/dataset/dsio"
 )
 
 // DataFormat ensures that for each accepted dataset.DataFormat,
 // we havea well-formed dataset (eg. for csv, we need rows to all
-// be of same length)
-func DataFormat(df dataset.DataFormat, r io.Reader) error {
-	switch df {
+// be of same length). data is read according to the structure's
+// format & format config, csv data with the structure's dialect.
+// text formats are checked for invalid byte sequences in the structure's
+// encoding when one is set
+func DataFormat(st *dataset.Structure, r io.Reader) error {
+	transcoded := false
+	if st.Encoding != "" && st.Format != dataset.XLSDataFormat && st.Format != dataset.ParquetDataFormat {
+		data, err := ioutil.ReadAll(r)
+		if err != nil {
+			return fmt.Errorf("error: reading data: %s", err.Error())
+		}
+		if err := CheckEncoding(bytes.NewReader(data), st.Encoding); err != nil {
+			return err
+		}
+		if r, err = charset.NewReader(st.Encoding, bytes.NewReader(data)); err != nil {
+			return fmt.Errorf("error: %s", err.Error())
+		}
+		transcoded = !charset.IsUTF8(st.Encoding)
+	}
+
+	switch st.Format {
 	// explicitly supported at present
 	case dataset.CSVDataFormat:
-		return CheckCsvRowLengths(r)
+		opts, _ := st.FormatConfig.(*dataset.CSVOptions)
+		return CheckCsvRowLengths(r, opts)
 	case dataset.CDXJDataFormat:
 		return cdxj.Validate(r)
+	case dataset.XLSDataFormat:
+		return CheckXLSXWorkbook(r)
+	case dataset.XMLDataFormat:
+		return checkXMLWellFormed(r, transcoded)
+	case dataset.NDJSONDataFormat:
+		return CheckNDJSONLines(r)
+	case dataset.ParquetDataFormat:
+		return CheckParquetFile(r)
+	case dataset.GeoJSONDataFormat:
+		return CheckGeoJSON(r)
 	// explicitly unsupported at present
 	case dataset.JSONDataFormat:
 		return fmt.Errorf("error: data format 'JsonData' not currently supported")
-	case dataset.XLSDataFormat:
-		return fmt.Errorf("error: data format 'XlsData' not currently supported")
-	case dataset.XMLDataFormat:
-		return fmt.Errorf("error: data format 'XmlData' not currently supported")
 	// *implicitly unsupported
 	case dataset.UnknownDataFormat:
 		return fmt.Errorf("error: unknown data format not currently supported")
@@ -74,8 +103,18 @@ func DataErrors(r dsio.RowReader, options ...func(*DataErrorsCfg)) (errors dsio.
 			},
 		},
 	}
-	for _, f := range r.Structure().Schema.Fields {
+	st := r.Structure()
+	fields := st.Schema.Fields
+	checkers := make([]*constraintChecker, len(fields))
+	for i, f := range fields {
 		vst.Schema.Fields = append(vst.Schema.Fields, &dataset.Field{Name: f.Name + "_error", Type: datatypes.String})
+		if checkers[i], err = newConstraintChecker(f); err != nil {
+			return nil, 0, fmt.Errorf("field %s constraints: %s", f.Name, err.Error())
+		}
+	}
+	pk, err := newPrimaryKeyChecker(st.Schema)
+	if err != nil {
+		return nil, 0, err
 	}
 
 	buf, err := dsio.NewStructuredBuffer(vst)
@@ -88,10 +127,23 @@ func DataErrors(r dsio.RowReader, options ...func(*DataErrorsCfg)) (errors dsio.
 			return err
 		}
 
-		errData, errNum, err := validateRow(r.Structure().Schema.Fields, num, row)
+		row = nullRow(st, row)
+		errData, errNum, err := validateRow(fields, checkers, num, row)
 		if err != nil {
 			return err
 		}
+		if pk != nil {
+			// primary key errors are reported in each key field without
+			// another error
+			if e := pk.check(num, row); e != nil {
+				for _, i := range pk.idx {
+					if len(errData[i+1]) == 0 {
+						errData[i+1] = []byte(e.Error())
+						errNum++
+					}
+				}
+			}
+		}
 
 		count += errNum
 		if errNum != 0 {
@@ -113,7 +165,10 @@ func DataErrors(r dsio.RowReader, options ...func(*DataErrorsCfg)) (errors dsio.
 	return
 }
 
-func validateRow(fields []*dataset.Field, num int, row [][]byte) ([][]byte, int, error) {
+// validateRow checks each cell of a row is a valid value of its field's
+// type that meets the field's constraints. checkers are nil for fields
+// without constraints
+func validateRow(fields []*dataset.Field, checkers []*constraintChecker, num int, row [][]byte) ([][]byte, int, error) {
 	count := 0
 	errors := make([][]byte, len(fields)+1)
 	errors[0] = []byte(strconv.FormatInt(int64(num), 10))
@@ -122,7 +177,21 @@ func validateRow(fields []*dataset.Field, num int, row [][]byte) ([][]byte, int,
 	}
 
 	for i, f := range fields {
-		_, e := f.Type.Parse(row[i])
+		var e error
+		cc := checkers[i]
+		if len(row[i]) == 0 {
+			// empty cells are null, which is only an error for required fields
+			if cc != nil {
+				e = cc.check(num, row[i])
+			}
+		} else if f.Type == datatypes.Array || f.Type == datatypes.Object {
+			e = checkNested(f, row[i])
+		} else {
+			_, e = f.Parse(row[i])
+		}
+		if e == nil && cc != nil && len(row[i]) > 0 {
+			e = cc.check(num, row[i])
+		}
 		if e != nil {
 			count++
 			errors[i+1] = []byte(e.Error())
@@ -134,6 +203,34 @@ func validateRow(fields []*dataset.Field, num int, row [][]byte) ([][]byte, int,
 	return errors, count, nil
 }
 
+// nullRow gives a copy of a row with null values of each field replaced by
+// empty cells, so null markers are checked as missing values
+func nullRow(st *dataset.Structure, row [][]byte) [][]byte {
+	cells := make([][]byte, len(row))
+	for i, c := range row {
+		if i < len(st.Schema.Fields) && st.IsNull(st.Schema.Fields[i], c) {
+			cells[i] = []byte{}
+			continue
+		}
+		cells[i] = c
+	}
+	return cells
+}
+
+// checkNested validates an array or object cell against the field's
+// element schemas, combining errors at all nested paths into one
+func checkNested(f *dataset.Field, cell []byte) error {
+	errs := datatypes.ValidateElement(f, cell)
+	if len(errs) == 0 {
+		return nil
+	}
+	msgs := make([]string, len(errs))
+	for i, e := range errs {
+		msgs[i] = e.Error()
+	}
+	return fmt.Errorf("%s", strings.Join(msgs, "; "))
+}
+
 // func (ds *Resource) ValidateDeadLinks(store fs.Store) (validation *Resource, data []byte, count int, err error) {
 // 	proj := map[int]int{}
 // 	validation = &Resource{
@@ -6,6 +6,7 @@ import (
 	"testing"
 
 	"github.com/qri-io/dataset"
+	"github.com/qri-io/dataset/datatypes"
 )
 
 func TestDataFormat(t *testing.T) {
@@ -21,12 +22,17 @@ func TestDataFormat(t *testing.T) {
 		{
 			dataset.XLSDataFormat,
 			rawText1,
-			"error: data format 'XlsData' not currently supported",
+			"error: invalid xlsx data: zip: not a valid zip file",
 		},
 		{
 			dataset.XMLDataFormat,
 			rawText1,
-			"error: data format 'XmlData' not currently supported",
+			"error: xml contains text outside of the root element",
+		},
+		{
+			dataset.NDJSONDataFormat,
+			rawText1,
+			"error: invalid json on line 1: invalid character 'i' in literal false (expecting 'a')",
 		},
 		{
 			dataset.UnknownDataFormat,
@@ -53,7 +59,7 @@ func TestDataFormat(t *testing.T) {
 	}
 	for i, c := range cases {
 		r := strings.NewReader(c.input)
-		err := DataFormat(c.df, r)
+		err := DataFormat(&dataset.Structure{Format: c.df}, r)
 		if !(err == nil && c.err == "" || err != nil && err.Error() == c.err) {
 			t.Errorf("case [%d] error mismatch. expected: '%s', got: '%s'", i, c.err, err)
 			continue
@@ -61,6 +67,51 @@ func TestDataFormat(t *testing.T) {
 	}
 }
 
+func TestDataFormatCSVOptions(t *testing.T) {
+	cases := []struct {
+		opts dataset.FormatConfig
+		err  string
+	}{
+		{nil, "error: inconsistent column length on line 1 of length 2 (rather than 1). ensure all csv columns same length"},
+		{&dataset.CSVOptions{Delimiter: ";"}, ""},
+		{&dataset.CSVOptions{Delimiter: ","}, "error: inconsistent column length on line 1 of length 2 (rather than 1). ensure all csv columns same length"},
+		{&dataset.CSVOptions{Delimiter: "\t"}, ""},
+	}
+	for i, c := range cases {
+		st := &dataset.Structure{Format: dataset.CSVDataFormat, FormatConfig: c.opts}
+		err := DataFormat(st, strings.NewReader("a;b\nc,d;e\n"))
+		if !(err == nil && c.err == "" || err != nil && err.Error() == c.err) {
+			t.Errorf("case %d error mismatch. expected: '%s', got: '%s'", i, c.err, err)
+		}
+	}
+}
+
+func TestDataFormatEncoding(t *testing.T) {
+	cases := []struct {
+		format   dataset.DataFormat
+		encoding string
+		data     string
+		err      string
+	}{
+		{dataset.CSVDataFormat, "", "a,b\nc,\xff\n", ""},
+		{dataset.CSVDataFormat, "utf-8", "a,b\ncafé,d\n", ""},
+		{dataset.CSVDataFormat, "utf-8", "a,b\nc,\xff\n", "error: invalid utf-8 byte sequence on line 2 at byte offset 2"},
+		{dataset.CSVDataFormat, "windows-1252", "a,b\ncaf\xe9,d\n", ""},
+		{dataset.CSVDataFormat, "utf-16le", "a\x00,\x00b\x00\n\x00c\x00,\x00d\x00\n\x00", ""},
+		{dataset.CSVDataFormat, "utf-16le", "a\x00,\x00b\x00\n\x00c\x00\n\x00", "error: inconsistent column length on line 1 of length 1 (rather than 2). ensure all csv columns same length"},
+		{dataset.NDJSONDataFormat, "utf-8", "{\"a\":\"\xc3\"}\n", "error: invalid utf-8 byte sequence on line 1 at byte offset 6"},
+		{dataset.XMLDataFormat, "iso-8859-1", "<?xml version=\"1.0\" encoding=\"ISO-8859-1\"?><caf\xe9s/>", ""},
+		{dataset.CSVDataFormat, "klingon", "a,b\n", "error: unsupported encoding: 'klingon'"},
+	}
+	for i, c := range cases {
+		st := &dataset.Structure{Format: c.format, Encoding: c.encoding}
+		err := DataFormat(st, strings.NewReader(c.data))
+		if !(err == nil && c.err == "" || err != nil && err.Error() == c.err) {
+			t.Errorf("case %d error mismatch. expected: '%s', got: '%s'", i, c.err, err)
+		}
+	}
+}
+
 func TestDataErrors(t *testing.T) {
 	cases := []struct {
 		structure *dataset.Structure
@@ -72,6 +123,7 @@ func TestDataErrors(t *testing.T) {
 	}{
 		// {namesStructure, rawText2, DefaultDataErrorsCfg(), 0, ""},
 		{namesStructure, rawText2c, DefaultDataErrorsCfg(), 1, ""},
+		{namesJSONStructure, rawJSONText2c, DefaultDataErrorsCfg(), 1, ""},
 	}
 
 	for i, c := range cases {
@@ -98,3 +150,145 @@ func TestDataErrors(t *testing.T) {
 		}
 	}
 }
+
+func TestDataErrorsNested(t *testing.T) {
+	r, err := dsio.NewRowReader(nestedJSONStructure, strings.NewReader(rawNestedJSONText))
+	if err != nil {
+		t.Fatalf("error allocating row reader: %s", err.Error())
+	}
+
+	got, count, err := DataErrors(r)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+	if count != 3 {
+		t.Errorf("count mismatch. expected: %d, got: %d", 3, count)
+	}
+
+	expect := [][]string{
+		{"1", "", "scores[1]: expected integer, got string", "profile.joined: invalid date: yesterday"},
+		{"2", "", "", "profile.links[1]: expected url, got number"},
+	}
+	for i, e := range expect {
+		row, err := got.ReadRow()
+		if err != nil {
+			t.Fatalf("row %d error: %s", i, err.Error())
+		}
+		for j, cell := range row {
+			if string(cell) != e[j] {
+				t.Errorf("row %d, column %d mismatch. expected: '%s', got: '%s'", i, j, e[j], string(cell))
+			}
+		}
+	}
+}
+
+func TestDataErrorsNumberFormat(t *testing.T) {
+	st := &dataset.Structure{
+		Format:       dataset.CSVDataFormat,
+		FormatConfig: &dataset.CSVOptions{HeaderRow: true},
+		Schema: &dataset.Schema{Fields: []*dataset.Field{
+			{Name: "units", Type: datatypes.Integer, GroupChar: ","},
+			{Name: "price", Type: datatypes.Float, Locale: "de"},
+		}},
+	}
+	data := "units,price\n\"1,234\",\"1.234,5\"\n1.5,\"1,234.5\"\n12,\"0,5\"\n"
+	r, err := dsio.NewRowReader(st, strings.NewReader(data))
+	if err != nil {
+		t.Fatalf("error allocating row reader: %s", err.Error())
+	}
+
+	got, count, err := DataErrors(r)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+	if count != 2 {
+		t.Errorf("count mismatch. expected: %d, got: %d", 2, count)
+	}
+
+	row, err := got.ReadRow()
+	if err != nil {
+		t.Fatalf("error reading errors row: %s", err.Error())
+	}
+	expect := []string{"1", "invalid integer: 1.5", "invalid float: 1,234.5"}
+	for j, cell := range row {
+		if string(cell) != expect[j] {
+			t.Errorf("column %d mismatch. expected: '%s', got: '%s'", j, expect[j], string(cell))
+		}
+	}
+}
+
+func TestDataErrorsDecimalFormat(t *testing.T) {
+	st := &dataset.Structure{
+		Format:       dataset.CSVDataFormat,
+		FormatConfig: &dataset.CSVOptions{HeaderRow: true},
+		Schema: &dataset.Schema{Fields: []*dataset.Field{
+			{Name: "price", Type: datatypes.Decimal, Format: "5,2"},
+		}},
+	}
+	data := "price\n123.45\n1234.5\n-0.5\n"
+	r, err := dsio.NewRowReader(st, strings.NewReader(data))
+	if err != nil {
+		t.Fatalf("error allocating row reader: %s", err.Error())
+	}
+
+	got, count, err := DataErrors(r)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+	if count != 1 {
+		t.Errorf("count mismatch. expected: %d, got: %d", 1, count)
+	}
+
+	row, err := got.ReadRow()
+	if err != nil {
+		t.Fatalf("error reading errors row: %s", err.Error())
+	}
+	expect := []string{"1", "1234.50 exceeds decimal precision 5, scale 2"}
+	for j, cell := range row {
+		if string(cell) != expect[j] {
+			t.Errorf("column %d mismatch. expected: '%s', got: '%s'", j, expect[j], string(cell))
+		}
+	}
+}
+
+func TestDataErrorsMissingValues(t *testing.T) {
+	yes := true
+	st := &dataset.Structure{
+		Format:        dataset.CSVDataFormat,
+		MissingValues: []string{"NA"},
+		Schema: &dataset.Schema{Fields: []*dataset.Field{
+			{Name: "id", Type: datatypes.Integer, Constraints: &dataset.FieldConstraints{Required: &yes}},
+			{Name: "pop", Type: datatypes.Integer},
+			{Name: "rank", Type: datatypes.Integer, MissingValue: "-"},
+		}},
+	}
+	data := "1,NA,-\nNA,35000,2\n3,n/a,NA\n"
+	r, err := dsio.NewRowReader(st, strings.NewReader(data))
+	if err != nil {
+		t.Fatalf("error allocating row reader: %s", err.Error())
+	}
+
+	got, count, err := DataErrors(r)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+	if count != 3 {
+		t.Errorf("count mismatch. expected: %d, got: %d", 3, count)
+	}
+
+	expect := [][]string{
+		{"1", "value is required", "", ""},
+		{"2", "", "strconv.ParseInt: parsing \"n/a\": invalid syntax", "strconv.ParseInt: parsing \"NA\": invalid syntax"},
+	}
+	for i, e := range expect {
+		row, err := got.ReadRow()
+		if err != nil {
+			t.Fatalf("error reading errors row %d: %s", i, err.Error())
+		}
+		for j, cell := range row {
+			if string(cell) != e[j] {
+				t.Errorf("row %d column %d mismatch. expected: '%s', got: '%s'", i, j, e[j], string(cell))
+			}
+		}
+	}
+}
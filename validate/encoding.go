@@ -0,0 +1,47 @@
+package validate
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"unicode/utf8"
+
+	"github.com/qri-io/dataset/charset"
+)
+
+// CheckEncoding ensures that r is valid text in the named encoding,
+// reporting the line number & byte offset within the line of the first
+// invalid byte sequence. lines count newlines, not records, and start at 1.
+// offsets into non-utf-8 lines are positions in the line once transcoded
+// to utf-8. the empty string names utf-8 encoding
+func CheckEncoding(r io.Reader, encoding string) error {
+	canonical, _, err := charset.Lookup(encoding)
+	if err != nil {
+		return fmt.Errorf("error: %s", err.Error())
+	}
+
+	// non-utf-8 data is transcoded, with invalid sequences
+	// replaced by utf8.RuneError
+	if canonical != charset.UTF8 {
+		if r, err = charset.NewReader(canonical, r); err != nil {
+			return fmt.Errorf("error: %s", err.Error())
+		}
+	}
+
+	rd := bufio.NewReader(r)
+	for lineNum := 1; ; lineNum++ {
+		line, err := rd.ReadBytes('\n')
+		for i := 0; i < len(line); {
+			c, size := utf8.DecodeRune(line[i:])
+			if c == utf8.RuneError && (size == 1 || canonical != charset.UTF8) {
+				return fmt.Errorf("error: invalid %s byte sequence on line %d at byte offset %d", canonical, lineNum, i)
+			}
+			i += size
+		}
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return fmt.Errorf("error: reading data: %s", err.Error())
+		}
+	}
+}
@@ -0,0 +1,31 @@
+package validate
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestCheckEncoding(t *testing.T) {
+	cases := []struct {
+		data     string
+		encoding string
+		err      string
+	}{
+		{"a,b\nc,d\n", "", ""},
+		{"a,b\ncafé,d\n", "utf-8", ""},
+		{"a,b\ncaf\xe9,d\n", "windows-1252", ""},
+		{"\xff\xfea\x00,\x00b\x00\n\x00", "utf-16", ""},
+		{"a,b\nc,\xff\n", "", "error: invalid utf-8 byte sequence on line 2 at byte offset 2"},
+		{"a,\xc3\n", "utf-8", "error: invalid utf-8 byte sequence on line 1 at byte offset 2"},
+		{"a\x00,\x00b", "utf-16le", "error: invalid utf-16le byte sequence on line 1 at byte offset 2"},
+		{"a,\"b\nc\",d\ne,\xff\n", "", "error: invalid utf-8 byte sequence on line 3 at byte offset 2"},
+		{"a,b\n", "klingon", "error: unsupported encoding: 'klingon'"},
+	}
+
+	for i, c := range cases {
+		err := CheckEncoding(bytes.NewBufferString(c.data), c.encoding)
+		if !(err == nil && c.err == "" || err != nil && err.Error() == c.err) {
+			t.Errorf("case %d error mismatch. expected: '%s', got: '%s'", i, c.err, err)
+		}
+	}
+}
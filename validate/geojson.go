@@ -0,0 +1,41 @@
+package validate
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/qri-io/dataset/datatypes"
+)
+
+// CheckGeoJSON ensures input is a GeoJSON feature collection, where each
+// feature has a valid geometry with coordinates in range, or a null geometry
+func CheckGeoJSON(r io.Reader) error {
+	doc := struct {
+		Type     string `json:"type"`
+		Features []struct {
+			Type     string          `json:"type"`
+			Geometry json.RawMessage `json:"geometry"`
+		} `json:"features"`
+	}{}
+	if err := json.NewDecoder(r).Decode(&doc); err != nil {
+		return fmt.Errorf("error: invalid geojson: %s", err.Error())
+	}
+	if doc.Type != "FeatureCollection" {
+		return fmt.Errorf("error: expected type 'FeatureCollection', got '%s'", doc.Type)
+	}
+
+	for i, feat := range doc.Features {
+		if feat.Type != "Feature" {
+			return fmt.Errorf("error: feature %d: expected type 'Feature', got '%s'", i, feat.Type)
+		}
+		if len(feat.Geometry) == 0 || bytes.Equal(feat.Geometry, []byte("null")) {
+			continue
+		}
+		if _, err := datatypes.ParseGeoJSON(feat.Geometry); err != nil {
+			return fmt.Errorf("error: feature %d: %s", i, err.Error())
+		}
+	}
+	return nil
+}
@@ -0,0 +1,27 @@
+package validate
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCheckGeoJSON(t *testing.T) {
+	cases := []struct {
+		input string
+		err   string
+	}{
+		{`{"type":"FeatureCollection","features":[]}`, ""},
+		{`{"type":"FeatureCollection","features":[{"type":"Feature","geometry":{"type":"Point","coordinates":[1,2]},"properties":{"a":1}},{"type":"Feature","geometry":null}]}`, ""},
+		{`{"type":"Feature","geometry":null}`, "error: expected type 'FeatureCollection', got 'Feature'"},
+		{`{"type":"FeatureCollection","features":[{"type":"Point","coordinates":[1,2]}]}`, "error: feature 0: expected type 'Feature', got 'Point'"},
+		{`{"type":"FeatureCollection","features":[{"type":"Feature","geometry":null},{"type":"Feature","geometry":{"type":"Point","coordinates":[1,200]}}]}`, "error: feature 1: invalid geojson: latitude 200 must be between -90 and 90"},
+		{`{"type":"FeatureCollection",`, "error: invalid geojson: unexpected EOF"},
+	}
+
+	for i, c := range cases {
+		err := CheckGeoJSON(strings.NewReader(c.input))
+		if !(err == nil && c.err == "" || err != nil && err.Error() == c.err) {
+			t.Errorf("case [%d] error mismatch. expected: '%s', got: '%s'", i, c.err, err)
+		}
+	}
+}
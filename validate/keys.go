@@ -0,0 +1,229 @@
+package validate
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ipfs/go-datastore"
+	"github.com/qri-io/cafs"
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/dataset/datatypes"
+	"github.com/qri-io/dataset/dsfs"
+	"github.com/qri-io/dataset/dsio"
+)
+
+// keySep separates the values of multi-field keys
+const keySep = "\x1f"
+
+// rowKey joins the cells of key fields into a single key value, reporting
+// weather any of the cells are empty
+func rowKey(row [][]byte, idx []int) (key string, null bool) {
+	vals := make([]string, len(idx))
+	for i, j := range idx {
+		if j >= len(row) || len(row[j]) == 0 {
+			return "", true
+		}
+		vals[i] = string(row[j])
+	}
+	return strings.Join(vals, keySep), false
+}
+
+// keyString formats a key value for error messages
+func keyString(key string) string {
+	return "(" + strings.Replace(key, keySep, ", ", -1) + ")"
+}
+
+// primaryKeyChecker enforces that primary key values are unique &
+// non-null across all rows of a stream
+type primaryKeyChecker struct {
+	key  dataset.FieldKey
+	idx  []int
+	seen map[string]int
+}
+
+// newPrimaryKeyChecker creates a checker for a schema's primary key,
+// returning nil if the schema has no primary key
+func newPrimaryKeyChecker(sch *dataset.Schema) (*primaryKeyChecker, error) {
+	if len(sch.PrimaryKey) == 0 {
+		return nil, nil
+	}
+	idx, err := sch.PrimaryKey.Indexes(sch)
+	if err != nil {
+		return nil, fmt.Errorf("primary key: %s", err.Error())
+	}
+	return &primaryKeyChecker{key: sch.PrimaryKey, idx: idx, seen: map[string]int{}}, nil
+}
+
+// check returns an error if the primary key of a row is null or repeats
+// the key of an earlier row. num is the row number
+func (pk *primaryKeyChecker) check(num int, row [][]byte) error {
+	key, null := rowKey(row, pk.idx)
+	if null {
+		return fmt.Errorf("primary key (%s) cannot be null", strings.Join(pk.key, ", "))
+	}
+	if first, ok := pk.seen[key]; ok {
+		return fmt.Errorf("primary key %s is not unique, first seen in row %d", keyString(key), first)
+	}
+	pk.seen[key] = num
+	return nil
+}
+
+// foreignKeyChecker tracks the referenced values of a foreign key
+type foreignKeyChecker struct {
+	fk     *dataset.ForeignKey
+	idx    []int
+	refIdx []int
+	// values of the referenced fields
+	values map[string]bool
+}
+
+// self reports weather the foreign key references its own dataset
+func (c *foreignKeyChecker) self() bool {
+	return c.fk.Reference.Dataset == ""
+}
+
+// name gives a description of the referenced dataset & fields
+func (c *foreignKeyChecker) name() string {
+	ds := c.fk.Reference.Dataset
+	if c.self() {
+		ds = "self"
+	}
+	return ds + " (" + strings.Join(c.fk.Reference.Fields, ", ") + ")"
+}
+
+// ForeignKeyErrors checks the foreign keys of a dataset, loading datasets
+// they reference from store. the returned reader has a row for each row of
+// r with dangling references, giving the row index & an error for each
+// foreign key. rows with null values in any key field are not checked
+func ForeignKeyErrors(store cafs.Filestore, r dsio.RowReader) (errors dsio.RowReader, count int, err error) {
+	st := r.Structure()
+	if st.Schema == nil {
+		return nil, 0, fmt.Errorf("structure must have a schema to check foreign keys")
+	}
+	if err = checkSchemaKeys(st.Schema); err != nil {
+		return nil, 0, err
+	}
+
+	vst := &dataset.Structure{
+		Format: dataset.CSVDataFormat,
+		Schema: &dataset.Schema{
+			Fields: []*dataset.Field{
+				{Name: "row_index", Type: datatypes.Integer},
+			},
+		},
+	}
+
+	checkers := make([]*foreignKeyChecker, len(st.Schema.ForeignKeys))
+	for i, fk := range st.Schema.ForeignKeys {
+		vst.Schema.Fields = append(vst.Schema.Fields, &dataset.Field{Name: strings.Join(fk.Fields, "_") + "_fkey_error", Type: datatypes.String})
+		c := &foreignKeyChecker{fk: fk, values: map[string]bool{}}
+		if c.idx, err = fk.Fields.Indexes(st.Schema); err != nil {
+			return nil, 0, fmt.Errorf("foreign key %d: %s", i, err.Error())
+		}
+		if c.self() {
+			c.refIdx, _ = fk.Reference.Fields.Indexes(st.Schema)
+		} else if err = c.loadReference(store); err != nil {
+			return nil, 0, fmt.Errorf("foreign key %d: %s", i, err.Error())
+		}
+		checkers[i] = c
+	}
+
+	// keys that reference the dataset being read can only be checked once
+	// all rows are read, so referencing values are kept by row
+	keys := map[int][]string{}
+	err = dsio.EachRow(r, func(num int, row [][]byte, err error) error {
+		if err != nil {
+			return err
+		}
+		row = nullRow(st, row)
+		for i, c := range checkers {
+			if c.self() {
+				if key, null := rowKey(row, c.refIdx); !null {
+					c.values[key] = true
+				}
+			}
+			if key, null := rowKey(row, c.idx); !null {
+				if keys[num] == nil {
+					keys[num] = make([]string, len(checkers))
+				}
+				keys[num][i] = key
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	buf, err := dsio.NewStructuredBuffer(vst)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error creating a row buffer: %s", err.Error())
+	}
+
+	rows := make([]int, 0, len(keys))
+	for num := range keys {
+		rows = append(rows, num)
+	}
+	sort.Ints(rows)
+	for _, num := range rows {
+		errRow := make([][]byte, len(checkers)+1)
+		errRow[0] = []byte(strconv.Itoa(num))
+		dangling := false
+		for i, c := range checkers {
+			errRow[i+1] = []byte("")
+			key := keys[num][i]
+			if key != "" && !c.values[key] {
+				errRow[i+1] = []byte(fmt.Sprintf("%s not found in %s", keyString(key), c.name()))
+				dangling = true
+				count++
+			}
+		}
+		if dangling {
+			if err = buf.WriteRow(errRow); err != nil {
+				return nil, 0, err
+			}
+		}
+	}
+
+	if err = buf.Close(); err != nil {
+		return nil, 0, fmt.Errorf("error closing validation buffer: %s", err.Error())
+	}
+	return buf, count, nil
+}
+
+// loadReference reads the referenced fields of all rows of a referenced
+// dataset
+func (c *foreignKeyChecker) loadReference(store cafs.Filestore) error {
+	ref := c.fk.Reference
+	ds, err := dsfs.LoadDataset(store, datastore.NewKey(ref.Dataset))
+	if err != nil {
+		return fmt.Errorf("error loading referenced dataset %s: %s", ref.Dataset, err.Error())
+	}
+	if ds.Structure == nil || ds.Structure.Schema == nil {
+		return fmt.Errorf("referenced dataset %s has no schema", ref.Dataset)
+	}
+	if c.refIdx, err = ref.Fields.Indexes(ds.Structure.Schema); err != nil {
+		return fmt.Errorf("referenced dataset %s: %s", ref.Dataset, err.Error())
+	}
+
+	f, err := dsfs.LoadData(store, ds)
+	if err != nil {
+		return fmt.Errorf("error loading referenced dataset %s data: %s", ref.Dataset, err.Error())
+	}
+	rr, err := dsio.NewRowReader(ds.Structure, f)
+	if err != nil {
+		return fmt.Errorf("error reading referenced dataset %s: %s", ref.Dataset, err.Error())
+	}
+	return dsio.EachRow(rr, func(num int, row [][]byte, err error) error {
+		if err != nil {
+			return fmt.Errorf("error reading referenced dataset %s: %s", ref.Dataset, err.Error())
+		}
+		row = nullRow(ds.Structure, row)
+		if key, null := rowKey(row, c.refIdx); !null {
+			c.values[key] = true
+		}
+		return nil
+	})
+}
@@ -0,0 +1,147 @@
+package validate
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/qri-io/cafs/memfs"
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/dataset/datatypes"
+	"github.com/qri-io/dataset/dsfs"
+	"github.com/qri-io/dataset/dsio"
+)
+
+func TestDataErrorsPrimaryKey(t *testing.T) {
+	st := &dataset.Structure{
+		Format:       dataset.CSVDataFormat,
+		FormatConfig: &dataset.CSVOptions{HeaderRow: true},
+		Schema: &dataset.Schema{
+			Fields: []*dataset.Field{
+				{Name: "country", Type: datatypes.String},
+				{Name: "year", Type: datatypes.Integer},
+				{Name: "pop", Type: datatypes.Integer},
+			},
+			PrimaryKey: dataset.FieldKey{"country", "year"},
+		},
+	}
+	data := "country,year,pop\nca,2010,34\nca,2011,35\nca,2010,36\n,2012,37\nus,x,300\n"
+	r, err := dsio.NewRowReader(st, strings.NewReader(data))
+	if err != nil {
+		t.Fatalf("error allocating row reader: %s", err.Error())
+	}
+
+	got, count, err := DataErrors(r)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+	if count != 5 {
+		t.Errorf("count mismatch. expected: %d, got: %d", 5, count)
+	}
+
+	expect := [][]string{
+		{"2", "primary key (ca, 2010) is not unique, first seen in row 0", "primary key (ca, 2010) is not unique, first seen in row 0", ""},
+		{"3", "primary key (country, year) cannot be null", "primary key (country, year) cannot be null", ""},
+		{"4", "", `strconv.ParseInt: parsing "x": invalid syntax`, ""},
+	}
+	for i, e := range expect {
+		row, err := got.ReadRow()
+		if err != nil {
+			t.Fatalf("row %d error: %s", i, err.Error())
+		}
+		for j, cell := range row {
+			if string(cell) != e[j] {
+				t.Errorf("row %d, column %d mismatch. expected: '%s', got: '%s'", i, j, e[j], string(cell))
+			}
+		}
+	}
+
+	st.Schema.PrimaryKey = dataset.FieldKey{"id"}
+	if r, err = dsio.NewRowReader(st, strings.NewReader(data)); err != nil {
+		t.Fatalf("error allocating row reader: %s", err.Error())
+	}
+	if _, _, err := DataErrors(r); err == nil || err.Error() != "primary key: field 'id' not found" {
+		t.Errorf("expected missing primary key field error, got: %v", err)
+	}
+}
+
+func TestForeignKeyErrors(t *testing.T) {
+	store := memfs.NewMapstore()
+	datapath, err := store.Put(memfs.NewMemfileBytes("data.csv", []byte("code,name\nca,canada\nus,united states\n")), false)
+	if err != nil {
+		t.Fatalf("error putting test data in store: %s", err.Error())
+	}
+	countries := &dataset.Dataset{
+		Title: "countries",
+		Structure: &dataset.Structure{
+			Format:       dataset.CSVDataFormat,
+			FormatConfig: &dataset.CSVOptions{HeaderRow: true},
+			Schema: &dataset.Schema{
+				Fields: []*dataset.Field{
+					{Name: "code", Type: datatypes.String},
+					{Name: "name", Type: datatypes.String},
+				},
+			},
+		},
+		Data: datapath.String(),
+	}
+	cpath, err := dsfs.SaveDataset(store, countries, true)
+	if err != nil {
+		t.Fatalf("error saving referenced dataset: %s", err.Error())
+	}
+
+	st := &dataset.Structure{
+		Format:       dataset.CSVDataFormat,
+		FormatConfig: &dataset.CSVOptions{HeaderRow: true},
+		Schema: &dataset.Schema{
+			Fields: []*dataset.Field{
+				{Name: "id", Type: datatypes.Integer},
+				{Name: "country", Type: datatypes.String},
+				{Name: "parent", Type: datatypes.Integer},
+			},
+			ForeignKeys: []*dataset.ForeignKey{
+				{Fields: dataset.FieldKey{"country"}, Reference: &dataset.ForeignKeyReference{Dataset: cpath.String(), Fields: dataset.FieldKey{"code"}}},
+				{Fields: dataset.FieldKey{"parent"}, Reference: &dataset.ForeignKeyReference{Fields: dataset.FieldKey{"id"}}},
+			},
+		},
+	}
+	data := "id,country,parent\n1,ca,\n2,mx,1\n3,us,4\n4,,9\n5,fr,2\n"
+	r, err := dsio.NewRowReader(st, strings.NewReader(data))
+	if err != nil {
+		t.Fatalf("error allocating row reader: %s", err.Error())
+	}
+
+	got, count, err := ForeignKeyErrors(store, r)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+	if count != 3 {
+		t.Errorf("count mismatch. expected: %d, got: %d", 3, count)
+	}
+
+	ref := cpath.String() + " (code)"
+	expect := [][]string{
+		{"1", "(mx) not found in " + ref, ""},
+		{"3", "", "(9) not found in self (id)"},
+		{"4", "(fr) not found in " + ref, ""},
+	}
+	for i, e := range expect {
+		row, err := got.ReadRow()
+		if err != nil {
+			t.Fatalf("row %d error: %s", i, err.Error())
+		}
+		for j, cell := range row {
+			if string(cell) != e[j] {
+				t.Errorf("row %d, column %d mismatch. expected: '%s', got: '%s'", i, j, e[j], string(cell))
+			}
+		}
+	}
+
+	st.Schema.ForeignKeys[0].Reference.Fields = dataset.FieldKey{"iso"}
+	if r, err = dsio.NewRowReader(st, strings.NewReader(data)); err != nil {
+		t.Fatalf("error allocating row reader: %s", err.Error())
+	}
+	expectErr := "foreign key 0: referenced dataset " + cpath.String() + ": field 'iso' not found"
+	if _, _, err := ForeignKeyErrors(store, r); err == nil || err.Error() != expectErr {
+		t.Errorf("error mismatch. expected: '%s', got: '%v'", expectErr, err)
+	}
+}
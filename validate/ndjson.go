@@ -0,0 +1,32 @@
+package validate
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// CheckNDJSONLines ensures each non-blank line of newline-delimited
+// json input is a single, valid json value
+func CheckNDJSONLines(r io.Reader) error {
+	rd := bufio.NewReader(r)
+	for lineNum := 1; ; lineNum++ {
+		line, err := rd.ReadBytes('\n')
+		if err != nil && err != io.EOF {
+			return fmt.Errorf("error: reading ndjson: %s", err.Error())
+		}
+
+		if line = bytes.TrimSpace(line); len(line) > 0 {
+			var raw json.RawMessage
+			if e := json.Unmarshal(line, &raw); e != nil {
+				return fmt.Errorf("error: invalid json on line %d: %s", lineNum, e.Error())
+			}
+		}
+
+		if err == io.EOF {
+			return nil
+		}
+	}
+}
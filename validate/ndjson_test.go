@@ -0,0 +1,27 @@
+package validate
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCheckNDJSONLines(t *testing.T) {
+	cases := []struct {
+		input string
+		err   string
+	}{
+		{emptyRawText, ""},
+		{"{\"a\":1}\n{\"a\":2}", ""},
+		{"{\"a\":1}\r\n\n[1,2,3]\n\"scalar\"\n", ""},
+		{"{\"a\":1}\n{\"a\":", "error: invalid json on line 2: unexpected end of JSON input"},
+		{"{\"a\":1} {\"a\":2}\n", "error: invalid json on line 1: invalid character '{' after top-level value"},
+		{rawText1, "error: invalid json on line 1: invalid character 'i' in literal false (expecting 'a')"},
+	}
+
+	for i, c := range cases {
+		err := CheckNDJSONLines(strings.NewReader(c.input))
+		if !(err == nil && c.err == "" || err != nil && err.Error() == c.err) {
+			t.Errorf("case [%d] error mismatch. expected: '%s', got: '%s'", i, c.err, err)
+		}
+	}
+}
@@ -0,0 +1,23 @@
+package validate
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/dataset/dsio"
+)
+
+// CheckParquetFile ensures that r is a parquet file with a flat schema
+// who's columns can all be read
+func CheckParquetFile(r io.Reader) error {
+	rr := dsio.NewParquetReader(&dataset.Structure{Format: dataset.ParquetDataFormat}, r)
+	for {
+		if _, err := rr.ReadRow(); err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return fmt.Errorf("error: %s", err.Error())
+		}
+	}
+}
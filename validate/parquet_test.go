@@ -0,0 +1,44 @@
+package validate
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/dataset/datatypes"
+	"github.com/qri-io/dataset/dsio"
+)
+
+func TestCheckParquetFile(t *testing.T) {
+	st := &dataset.Structure{
+		Format: dataset.ParquetDataFormat,
+		Schema: &dataset.Schema{Fields: []*dataset.Field{
+			{Name: "a", Type: datatypes.String},
+			{Name: "b", Type: datatypes.Integer},
+		}},
+	}
+	buf := &bytes.Buffer{}
+	w := dsio.NewParquetWriter(st, buf)
+	if err := w.WriteRow([][]byte{[]byte("foo"), []byte("1")}); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		input string
+		err   string
+	}{
+		{buf.String(), ""},
+		{rawText1, "error: invalid parquet data: missing PAR1 magic bytes"},
+	}
+
+	for i, c := range cases {
+		err := CheckParquetFile(strings.NewReader(c.input))
+		if !(err == nil && c.err == "" || err != nil && err.Error() == c.err) {
+			t.Errorf("case [%d] error mismatch. expected: '%s', got: '%s'", i, c.err, err)
+		}
+	}
+}
@@ -21,5 +21,33 @@ func Structure(s *dataset.Structure) error {
 		}
 		checkedFieldNames[field.Name] = true
 	}
+	return checkSchemaKeys(s.Schema)
+}
+
+// checkSchemaKeys ensures primary & foreign keys refer to fields in the
+// schema, and foreign keys reference as many fields as they contain
+func checkSchemaKeys(sch *dataset.Schema) error {
+	if _, err := sch.PrimaryKey.Indexes(sch); err != nil {
+		return fmt.Errorf("error: primary key: %s", err.Error())
+	}
+	for i, fk := range sch.ForeignKeys {
+		if len(fk.Fields) == 0 {
+			return fmt.Errorf("error: foreign key %d: fields are required", i)
+		}
+		if _, err := fk.Fields.Indexes(sch); err != nil {
+			return fmt.Errorf("error: foreign key %d: %s", i, err.Error())
+		}
+		if fk.Reference == nil {
+			return fmt.Errorf("error: foreign key %d: reference is required", i)
+		}
+		if len(fk.Reference.Fields) != len(fk.Fields) {
+			return fmt.Errorf("error: foreign key %d: %d fields cannot reference %d fields", i, len(fk.Fields), len(fk.Reference.Fields))
+		}
+		if fk.Reference.Dataset == "" {
+			if _, err := fk.Reference.Fields.Indexes(sch); err != nil {
+				return fmt.Errorf("error: foreign key %d: reference %s", i, err.Error())
+			}
+		}
+	}
 	return nil
 }
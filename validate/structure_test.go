@@ -40,3 +40,30 @@ func structureTestHelper(s []string) *dataset.Structure {
 	structure := &dataset.Structure{Schema: schema}
 	return structure
 }
+
+func TestStructureKeys(t *testing.T) {
+	cases := []struct {
+		primaryKey  dataset.FieldKey
+		foreignKeys []*dataset.ForeignKey
+		err         string
+	}{
+		{dataset.FieldKey{"a", "b"}, nil, ""},
+		{dataset.FieldKey{"c"}, nil, "error: primary key: field 'c' not found"},
+		{nil, []*dataset.ForeignKey{{Fields: dataset.FieldKey{"a"}, Reference: &dataset.ForeignKeyReference{Dataset: "/map/Qm", Fields: dataset.FieldKey{"x"}}}}, ""},
+		{nil, []*dataset.ForeignKey{{Fields: dataset.FieldKey{"b"}, Reference: &dataset.ForeignKeyReference{Fields: dataset.FieldKey{"a"}}}}, ""},
+		{nil, []*dataset.ForeignKey{{Reference: &dataset.ForeignKeyReference{Fields: dataset.FieldKey{"a"}}}}, "error: foreign key 0: fields are required"},
+		{nil, []*dataset.ForeignKey{{Fields: dataset.FieldKey{"z"}, Reference: &dataset.ForeignKeyReference{Fields: dataset.FieldKey{"a"}}}}, "error: foreign key 0: field 'z' not found"},
+		{nil, []*dataset.ForeignKey{{Fields: dataset.FieldKey{"a"}}}, "error: foreign key 0: reference is required"},
+		{nil, []*dataset.ForeignKey{{Fields: dataset.FieldKey{"a", "b"}, Reference: &dataset.ForeignKeyReference{Dataset: "/map/Qm", Fields: dataset.FieldKey{"x"}}}}, "error: foreign key 0: 2 fields cannot reference 1 fields"},
+		{nil, []*dataset.ForeignKey{{Fields: dataset.FieldKey{"a"}, Reference: &dataset.ForeignKeyReference{Fields: dataset.FieldKey{"x"}}}}, "error: foreign key 0: reference field 'x' not found"},
+	}
+	for i, c := range cases {
+		s := structureTestHelper([]string{"a", "b"})
+		s.Schema.PrimaryKey = c.primaryKey
+		s.Schema.ForeignKeys = c.foreignKeys
+		err := Structure(s)
+		if !(err == nil && c.err == "" || err != nil && err.Error() == c.err) {
+			t.Errorf("case [%d] error mismatch. expected: '%s', got: '%s'", i, c.err, err)
+		}
+	}
+}
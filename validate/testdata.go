@@ -28,6 +28,39 @@ var namesStructure = &dataset.Structure{
 	},
 }
 
+var namesJSONStructure = &dataset.Structure{
+	Format: dataset.JSONDataFormat,
+	Schema: namesStructure.Schema,
+}
+
+// error in last entry "age" value, keys out of schema order
+var rawJSONText2c = `[
+{"first_name":"Rob","last_name":"Pike","username":"rob","age":22},
+{"last_name":"Griesemer","first_name":"Robert","username":"gri","age":100},
+{"first_name":"abc","last_name":"def,ghi","username":"jkl","age":"_"}
+]`
+
+var nestedJSONStructure = &dataset.Structure{
+	Format: dataset.JSONDataFormat,
+	Schema: &dataset.Schema{
+		Fields: []*dataset.Field{
+			{Name: "username", Type: datatypes.String},
+			{Name: "scores", Type: datatypes.Array, Items: &dataset.Field{Type: datatypes.Integer}},
+			{Name: "profile", Type: datatypes.Object, Properties: []*dataset.Field{
+				{Name: "joined", Type: datatypes.Date},
+				{Name: "links", Type: datatypes.Array, Items: &dataset.Field{Type: datatypes.URL}},
+			}},
+		},
+	},
+}
+
+// errors in nested values of the second & third entries
+var rawNestedJSONText = `[
+{"username":"rob","scores":[1,2,3],"profile":{"joined":"2009-11-10","links":["https://golang.org"]}},
+{"username":"gri","scores":[4,"five"],"profile":{"joined":"yesterday","links":[]}},
+{"username":"ken","scores":null,"profile":{"links":["https://golang.org",7]}}
+]`
+
 // has nonNumeric quotes and comma inside quotes on last line
 var rawText2 = `"first_name","last_name","username","age"
 "Rob","Pike","rob", 22
@@ -0,0 +1,21 @@
+package validate
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/360EntSecGroup-Skylar/excelize"
+)
+
+// CheckXLSXWorkbook ensures that r is a readable excel
+// workbook with at least one worksheet
+func CheckXLSXWorkbook(r io.Reader) error {
+	f, err := excelize.OpenReader(r)
+	if err != nil {
+		return fmt.Errorf("error: invalid xlsx data: %s", err.Error())
+	}
+	if len(f.GetSheetMap()) == 0 {
+		return fmt.Errorf("error: xlsx workbook has no worksheets")
+	}
+	return nil
+}
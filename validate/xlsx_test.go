@@ -0,0 +1,31 @@
+package validate
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/360EntSecGroup-Skylar/excelize"
+)
+
+func TestCheckXLSXWorkbook(t *testing.T) {
+	wb := &bytes.Buffer{}
+	if err := excelize.NewFile().Write(wb); err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		input string
+		err   string
+	}{
+		{wb.String(), ""},
+		{rawText1, "error: invalid xlsx data: zip: not a valid zip file"},
+	}
+
+	for i, c := range cases {
+		err := CheckXLSXWorkbook(strings.NewReader(c.input))
+		if !(err == nil && c.err == "" || err != nil && err.Error() == c.err) {
+			t.Errorf("case [%d] error mismatch. expected: '%s', got: '%s'", i, c.err, err)
+		}
+	}
+}
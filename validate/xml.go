@@ -0,0 +1,65 @@
+package validate
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+
+	"github.com/qri-io/dataset/charset"
+)
+
+// CheckXMLWellFormed ensures that xml input is well-formed, with
+// properly nested elements and a single root element. documents may
+// declare any encoding supported by the charset package
+func CheckXMLWellFormed(r io.Reader) error {
+	return checkXMLWellFormed(r, false)
+}
+
+// checkXMLWellFormed checks xml that may already be transcoded to utf-8,
+// in which case declared encodings are ignored
+func checkXMLWellFormed(r io.Reader, transcoded bool) error {
+	d := xml.NewDecoder(r)
+	d.CharsetReader = charset.NewReader
+	if transcoded {
+		d.CharsetReader = func(label string, input io.Reader) (io.Reader, error) {
+			return input, nil
+		}
+	}
+	depth := 0
+	roots := 0
+	for {
+		tok, err := d.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			if se, ok := err.(*xml.SyntaxError); ok {
+				return fmt.Errorf("error: malformed xml on line %d: %s", se.Line, se.Msg)
+			}
+			return fmt.Errorf("error: reading xml: %s", err.Error())
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			if depth == 0 {
+				roots++
+				if roots > 1 {
+					return fmt.Errorf("error: xml must have a single root element, found second root element '%s'", t.Name.Local)
+				}
+			}
+			depth++
+		case xml.EndElement:
+			depth--
+		case xml.CharData:
+			if depth == 0 && len(bytes.TrimSpace(t)) > 0 {
+				return fmt.Errorf("error: xml contains text outside of the root element")
+			}
+		}
+	}
+
+	if roots == 0 {
+		return fmt.Errorf("error: xml has no root element")
+	}
+	return nil
+}
@@ -0,0 +1,28 @@
+package validate
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCheckXMLWellFormed(t *testing.T) {
+	cases := []struct {
+		input string
+		err   string
+	}{
+		{`<?xml version="1.0"?><rows><row a="1"><b>2</b></row></rows>`, ""},
+		{rawText3, ""},
+		{emptyRawText, "error: xml has no root element"},
+		{rawText1, "error: xml contains text outside of the root element"},
+		{`<rows></rows><rows></rows>`, "error: xml must have a single root element, found second root element 'rows'"},
+		{"<rows>\n<row></rows>", "error: malformed xml on line 2: element <row> closed by </rows>"},
+		{`<rows><row a=1></row></rows>`, "error: malformed xml on line 1: unquoted or missing attribute value in element"},
+	}
+
+	for i, c := range cases {
+		err := CheckXMLWellFormed(strings.NewReader(c.input))
+		if !(err == nil && c.err == "" || err != nil && err.Error() == c.err) {
+			t.Errorf("case [%d] error mismatch. expected: '%s', got: '%s'", i, c.err, err)
+		}
+	}
+}